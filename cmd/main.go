@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
 	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
 	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog/log"
@@ -31,12 +35,174 @@ func main() {
 	httpClient := &http.Client{
 		Timeout: 10 * time.Second,
 	}
+	exchangeRateRepository, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepository.GetBoltDB(), "exchange_rates")
+	if err != nil {
+		log.Fatal().Err(err).Msg("the exchange rate repository creation failed")
+	}
+	conversionJobRepository, err := repository.NewConversionJobRepositoryBoltDB(transactionRepository.GetBoltDB(), "conversion_jobs")
+	if err != nil {
+		log.Fatal().Err(err).Msg("the conversion job repository creation failed")
+	}
 	treasuryExchangeRateConverter := client.NewConcreteTreasuryExchangeRateAdapter(httpClient)
-	transactionService := services.NewTransactionService(transactionRepository, treasuryExchangeRateConverter)
-	transactionHandler := handler.NewTransactionHandler(*transactionService)
+	// Fails fast while the Treasury API is down, and lets the cache serve the stale exchange rates meanwhile
+	circuitBreakerExchangeRateConverter := client.NewCircuitBreakerTreasuryExchangeRateAdapter(
+		treasuryExchangeRateConverter, parseIntEnv("EXCHANGE_RATE_CIRCUIT_FAILURE_THRESHOLD"),
+		parseIntEnv("EXCHANGE_RATE_CIRCUIT_SUCCESS_THRESHOLD"), parseDurationEnv("EXCHANGE_RATE_CIRCUIT_OPEN_TIMEOUT"), nil)
+	// Falls back on the other configured providers when the preferred one fails
+	compositeExchangeRateConverter, treasuryConfigured := newExchangeRateProviders(circuitBreakerExchangeRateConverter, httpClient)
+	cachingExchangeRateConverter := client.NewCachingTreasuryExchangeRateAdapter(compositeExchangeRateConverter,
+		parseDurationEnv("EXCHANGE_RATE_CACHE_TTL"), client.DefaultExchangeRateCacheEntries)
+	for currencyName, ttl := range parseCurrencyDurationsEnv("EXCHANGE_RATE_CACHE_CURRENCY_TTLS") {
+		cachingExchangeRateConverter.WithCurrencyTTL(currencyName, ttl)
+	}
+	rateSelectionPolicy := newRateSelectionPolicy()
+	transactionService := services.NewTransactionService(transactionRepository, exchangeRateRepository,
+		cachingExchangeRateConverter, rateSelectionPolicy).WithIdempotencyKeyTTL(parseDurationEnv("IDEMPOTENCY_KEY_TTL"))
+
+	// Keeps the local exchange rate store up to date in the background. The store only holds Treasury exchange rates,
+	// so it is synchronized from the Treasury alone, never from the fallback providers
+	if treasuryConfigured {
+		exchangeRateSyncService := services.NewExchangeRateSyncService(exchangeRateRepository,
+			circuitBreakerExchangeRateConverter, parseDurationEnv("EXCHANGE_RATE_SYNC_INTERVAL"))
+		exchangeRateSyncService.Start(context.Background())
+		defer exchangeRateSyncService.Stop()
+	}
+
+	// Converts the stored transactions of the conversion jobs in the background
+	conversionJobService := services.NewConversionJobService(conversionJobRepository, transactionService,
+		parseIntEnv("CONVERSION_JOB_WORKERS"))
+	conversionJobService.Start(context.Background())
+	defer conversionJobService.Stop()
+
+	transactionHandler := handler.NewTransactionHandler(*transactionService).WithConversionJobService(conversionJobService)
+	transactionHandler.RegisterHealthCheck("exchange_rate_cache", func() interface{} {
+		return cachingExchangeRateConverter.Stats()
+	})
+	transactionHandler.RegisterHealthCheck("exchange_rate_circuit_breaker", func() interface{} {
+		return circuitBreakerExchangeRateConverter.Stats()
+	})
 	transactionHandler.StartServer(serverPort)
 }
 
+// parseDurationEnv reads a duration (e.g. "6h") from an environment variable. It returns 0 when the variable is not
+// set or is invalid, so the default value is used.
+func parseDurationEnv(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Warn().Err(err).Str("variable", name).Msg("invalid duration, using the default value")
+		return 0
+	}
+	return duration
+}
+
+// parseCurrencyDurationsEnv reads durations by currency (e.g. "JPY=15m,EUR=6h") from an environment variable. The
+// invalid entries are left out, so the default value is used for their currency.
+func parseCurrencyDurationsEnv(name string) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	value := os.Getenv(name)
+	if value == "" {
+		return durations
+	}
+	for _, entry := range strings.Split(value, ",") {
+		currencyName, durationValue, found := strings.Cut(entry, "=")
+		duration, err := time.ParseDuration(strings.TrimSpace(durationValue))
+		if !found || err != nil {
+			log.Warn().Err(err).Str("variable", name).Str("entry", entry).Msg("invalid currency duration, using the default value")
+			continue
+		}
+		durations[strings.TrimSpace(currencyName)] = duration
+	}
+	return durations
+}
+
+// parseIntEnv reads an integer from an environment variable. It returns 0 when the variable is not set or is
+// invalid, so the default value is used.
+func parseIntEnv(name string) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Warn().Err(err).Str("variable", name).Msg("invalid integer, using the default value")
+		return 0
+	}
+	return number
+}
+
+// newExchangeRateProviders creates the composite of the exchange rate providers listed, in priority order, in the
+// EXCHANGE_RATE_PROVIDERS environment variable (e.g. "treasury,ecb,file"). The Treasury API is the only provider when
+// the variable is not set, and the file provider reads the file of the EXCHANGE_RATE_FILE environment variable. It
+// also reports whether the Treasury API is one of the providers.
+func newExchangeRateProviders(treasuryExchangeRateConverter client.TreasuryExchangeRateAdapter,
+	httpClient client.HTTPClient) (*client.CompositeExchangeRateAdapter, bool) {
+	names := os.Getenv("EXCHANGE_RATE_PROVIDERS")
+	if names == "" {
+		names = domain.ExchangeRateSourceTreasury
+	}
+
+	providers := make([]client.ExchangeRateProvider, 0)
+	treasuryConfigured := false
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		var adapter client.TreasuryExchangeRateAdapter
+		switch name {
+		case domain.ExchangeRateSourceTreasury:
+			adapter = treasuryExchangeRateConverter
+			treasuryConfigured = true
+		case domain.ExchangeRateSourceECB:
+			adapter = client.NewECBExchangeRateAdapter(httpClient)
+		case domain.ExchangeRateSourceFile:
+			fileExchangeRateConverter, err := client.NewFileExchangeRateAdapter(os.Getenv("EXCHANGE_RATE_FILE"))
+			if err != nil {
+				log.Fatal().Err(err).Str("variable", "EXCHANGE_RATE_FILE").Msg("the exchange rate file could not be loaded")
+			}
+			adapter = fileExchangeRateConverter
+		default:
+			log.Fatal().Str("variable", "EXCHANGE_RATE_PROVIDERS").Str("provider", name).Msg("unknown exchange rate provider")
+		}
+		providers = append(providers, client.ExchangeRateProvider{Name: name, Adapter: adapter})
+	}
+
+	compositeExchangeRateConverter, err := client.NewCompositeExchangeRateAdapter(providers...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("the exchange rate providers creation failed")
+	}
+	log.Info().Str("providers", names).Msg("exchange rate providers configured")
+	return compositeExchangeRateConverter, treasuryConfigured
+}
+
+// newRateSelectionPolicy creates the exchange rate selection policy from the EXCHANGE_RATE_SELECTION_POLICY and
+// EXCHANGE_RATE_LOOKBACK_MONTHS environment variables. The defaults of the domain are used for unset variables.
+func newRateSelectionPolicy() domain.RateSelectionPolicy {
+	name := os.Getenv("EXCHANGE_RATE_SELECTION_POLICY")
+	if name == "" {
+		name = domain.RateSelectionLatestOnOrBefore
+	}
+	lookbackMonths := domain.DefaultRateSelectionLookbackMonths
+	if value := os.Getenv("EXCHANGE_RATE_LOOKBACK_MONTHS"); value != "" {
+		parsedLookbackMonths, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatal().Err(err).Str("variable", "EXCHANGE_RATE_LOOKBACK_MONTHS").Msg("invalid number of months")
+		}
+		lookbackMonths = parsedLookbackMonths
+	}
+
+	rateSelectionPolicy, errs := domain.NewRateSelectionPolicy(name, lookbackMonths)
+	if len(errs) > 0 {
+		log.Fatal().Errs("validation_errors", errs).Msg("invalid exchange rate selection policy")
+	}
+	log.Info().
+		Str("policy", rateSelectionPolicy.Name()).
+		Int("lookback_months", rateSelectionPolicy.LookbackMonths()).
+		Msg("exchange rate selection policy configured")
+	return rateSelectionPolicy
+}
+
 // loadEnvIfNeeded checks if the SERVER_PORT variable is set and loads the .env file if not.
 func loadEnvIfNeeded() {
 	// Check if the SERVER_PORT environment variable is set
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains a composite of TreasuryExchangeRateAdapter implementations, tried in priority order so another
+// provider answers when one of them fails or has no exchange rates.
+
+// ExchangeRateProvider is a named provider of exchange rates of a CompositeExchangeRateAdapter.
+type ExchangeRateProvider struct {
+	// Name identifies the provider in the logs (e.g. "treasury").
+	Name    string
+	Adapter TreasuryExchangeRateAdapter
+}
+
+// CompositeExchangeRateAdapter implements the TreasuryExchangeRateAdapter interface with several providers. Each call
+// goes to the providers in priority order and returns the answer of the first one that succeeds. When they all fail,
+// the error of the first provider is returned. A call abandoned by its caller is not passed on to the next provider.
+type CompositeExchangeRateAdapter struct {
+	providers []ExchangeRateProvider
+}
+
+// NewCompositeExchangeRateAdapter creates a new CompositeExchangeRateAdapter with providers in priority order, the
+// first one being the preferred one.
+func NewCompositeExchangeRateAdapter(providers ...ExchangeRateProvider) (*CompositeExchangeRateAdapter, error) {
+	if len(providers) == 0 {
+		return nil, ErrNoExchangeRateProviders
+	}
+	return &CompositeExchangeRateAdapter{providers: providers}, nil
+}
+
+// GetExchangeRates retrieves all the exchange rates for a currency from the first provider that has them.
+func (c *CompositeExchangeRateAdapter) GetExchangeRates(ctx context.Context,
+	currencyName string) ([]*domain.ExchangeRate, error) {
+	return firstSuccess(ctx, c, "GetExchangeRates", func(adapter TreasuryExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+		return adapter.GetExchangeRates(ctx, currencyName)
+	})
+}
+
+// GetExchangeRatesBetween retrieves the exchange rates for a currency with a date of record between two dates from
+// the first provider that has them.
+func (c *CompositeExchangeRateAdapter) GetExchangeRatesBetween(ctx context.Context, currencyName string,
+	from, to time.Time) ([]*domain.ExchangeRate, error) {
+	return firstSuccess(ctx, c, "GetExchangeRatesBetween", func(adapter TreasuryExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+		return adapter.GetExchangeRatesBetween(ctx, currencyName, from, to)
+	})
+}
+
+// GetExchangeRatesSince retrieves the exchange rates of all the currencies with a date of record after the given date
+// from the first provider that answers.
+func (c *CompositeExchangeRateAdapter) GetExchangeRatesSince(ctx context.Context,
+	since time.Time) ([]*domain.ExchangeRate, error) {
+	return firstSuccess(ctx, c, "GetExchangeRatesSince", func(adapter TreasuryExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+		return adapter.GetExchangeRatesSince(ctx, since)
+	})
+}
+
+// GetCurrencies retrieves the supported currencies from the first provider that answers.
+func (c *CompositeExchangeRateAdapter) GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	return firstSuccess(ctx, c, "GetCurrencies", func(adapter TreasuryExchangeRateAdapter) ([]*domain.SupportedCurrency, error) {
+		return adapter.GetCurrencies(ctx)
+	})
+}
+
+// firstSuccess makes a call to the providers in priority order until one of them succeeds.
+func firstSuccess[T any](ctx context.Context, c *CompositeExchangeRateAdapter, method string,
+	call func(TreasuryExchangeRateAdapter) (T, error)) (T, error) {
+	var firstErr error
+	for i, provider := range c.providers {
+		result, err := call(provider.Adapter)
+		if err == nil {
+			if i > 0 {
+				log.Info().Str("method", method).Str("provider", provider.Name).Msg("exchange rates from a fallback provider")
+			}
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			var zero T
+			return zero, ctx.Err()
+		}
+
+		log.Warn().Err(err).Str("method", method).Str("provider", provider.Name).Msg("exchange rate provider failed")
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	var zero T
+	return zero, firstErr
+}
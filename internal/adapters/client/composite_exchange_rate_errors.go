@@ -0,0 +1,10 @@
+package client
+
+import "errors"
+
+// This file defines error variables related to the composite of exchange rate providers.
+
+var (
+	// ErrNoExchangeRateProviders is returned when a composite of exchange rate providers is created without providers.
+	ErrNoExchangeRateProviders = errors.New("at least one exchange rate provider is required")
+)
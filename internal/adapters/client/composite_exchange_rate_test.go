@@ -0,0 +1,160 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the CompositeExchangeRateAdapter. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and mocking, and runs the tests in parallel.
+
+// TestCompositeExchangeRateAdapter tests the fallback of the CompositeExchangeRateAdapter. It tests the following
+// scenarios:
+//
+// 1. Preferred Provider Answers.
+// 2. Fallback On A Failure.
+// 3. Fallback On Missing Exchange Rates.
+// 4. All Providers Fail.
+func TestCompositeExchangeRateAdapter(t *testing.T) {
+	treasuryRate := &domain.ExchangeRate{CurrencyName: "Real", RateText: "5.43", Source: domain.ExchangeRateSourceTreasury}
+	ecbRate := &domain.ExchangeRate{CurrencyName: "Real", RateText: "5.44", Source: domain.ExchangeRateSourceECB}
+
+	tests := []struct {
+		name          string
+		treasuryRates []*domain.ExchangeRate
+		treasuryError error
+		ecbRates      []*domain.ExchangeRate
+		ecbError      error
+		// ecbCalled tells whether the fallback provider is asked
+		ecbCalled     bool
+		expectedRates []*domain.ExchangeRate
+		expectedError error
+	}{
+		{
+			name:          "Preferred Provider Answers",
+			treasuryRates: []*domain.ExchangeRate{treasuryRate},
+			expectedRates: []*domain.ExchangeRate{treasuryRate},
+		},
+		{
+			name:          "Fallback On A Failure",
+			treasuryError: domain.ErrExchangeRatesUnavailable,
+			ecbRates:      []*domain.ExchangeRate{ecbRate},
+			ecbCalled:     true,
+			expectedRates: []*domain.ExchangeRate{ecbRate},
+		},
+		{
+			name:          "Fallback On Missing Exchange Rates",
+			treasuryError: client.ErrExchangeRateNotFound,
+			ecbRates:      []*domain.ExchangeRate{ecbRate},
+			ecbCalled:     true,
+			expectedRates: []*domain.ExchangeRate{ecbRate},
+		},
+		{
+			name:          "All Providers Fail",
+			treasuryError: client.ErrNetworkIssue,
+			ecbError:      client.ErrExchangeRateNotFound,
+			ecbCalled:     true,
+			expectedError: client.ErrNetworkIssue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			treasuryAdapter := new(client.MockTreasuryExchangeRateAdapter)
+			treasuryAdapter.On("GetExchangeRates", "Real").Return(tt.treasuryRates, tt.treasuryError).Once()
+			ecbAdapter := new(client.MockTreasuryExchangeRateAdapter)
+			if tt.ecbCalled {
+				ecbAdapter.On("GetExchangeRates", "Real").Return(tt.ecbRates, tt.ecbError).Once()
+			}
+			composite, err := client.NewCompositeExchangeRateAdapter(
+				client.ExchangeRateProvider{Name: domain.ExchangeRateSourceTreasury, Adapter: treasuryAdapter},
+				client.ExchangeRateProvider{Name: domain.ExchangeRateSourceECB, Adapter: ecbAdapter},
+			)
+			require.NoError(t, err)
+
+			exchangeRates, err := composite.GetExchangeRates(context.Background(), "Real")
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, exchangeRates)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedRates, exchangeRates)
+			}
+			treasuryAdapter.AssertExpectations(t)
+			ecbAdapter.AssertExpectations(t)
+		})
+	}
+}
+
+// TestCompositeExchangeRateAdapterCalls tests the calls of the CompositeExchangeRateAdapter. It tests the following
+// scenarios:
+//
+// 1. No Providers.
+// 2. Canceled Request Not Passed On.
+// 3. Every Method Falls Back.
+func TestCompositeExchangeRateAdapterCalls(t *testing.T) {
+	t.Run("No Providers", func(t *testing.T) {
+		t.Parallel()
+		composite, err := client.NewCompositeExchangeRateAdapter()
+		assert.ErrorIs(t, err, client.ErrNoExchangeRateProviders)
+		assert.Nil(t, composite)
+	})
+
+	t.Run("Canceled Request Not Passed On", func(t *testing.T) {
+		t.Parallel()
+		treasuryAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		treasuryAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency(nil), context.Canceled).Once()
+		ecbAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		composite, err := client.NewCompositeExchangeRateAdapter(
+			client.ExchangeRateProvider{Name: domain.ExchangeRateSourceTreasury, Adapter: treasuryAdapter},
+			client.ExchangeRateProvider{Name: domain.ExchangeRateSourceECB, Adapter: ecbAdapter},
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = composite.GetCurrencies(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		treasuryAdapter.AssertExpectations(t)
+		ecbAdapter.AssertNotCalled(t, "GetCurrencies")
+	})
+
+	t.Run("Every Method Falls Back", func(t *testing.T) {
+		t.Parallel()
+		from, to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+		treasuryAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		treasuryAdapter.On("GetExchangeRatesBetween", "Real", from, to).Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue)
+		treasuryAdapter.On("GetExchangeRatesSince", mock.Anything).Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue)
+		treasuryAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency(nil), client.ErrNetworkIssue)
+		fileAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		fileAdapter.On("GetExchangeRatesBetween", "Real", from, to).Return([]*domain.ExchangeRate{{RateText: "5.43"}}, nil)
+		fileAdapter.On("GetExchangeRatesSince", mock.Anything).Return([]*domain.ExchangeRate{}, nil)
+		fileAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency{{CountryCurrencyDesc: "Brazil-Real"}}, nil)
+		composite, err := client.NewCompositeExchangeRateAdapter(
+			client.ExchangeRateProvider{Name: domain.ExchangeRateSourceTreasury, Adapter: treasuryAdapter},
+			client.ExchangeRateProvider{Name: domain.ExchangeRateSourceFile, Adapter: fileAdapter},
+		)
+		require.NoError(t, err)
+
+		exchangeRates, err := composite.GetExchangeRatesBetween(context.Background(), "Real", from, to)
+		require.NoError(t, err)
+		assert.Len(t, exchangeRates, 1)
+		exchangeRates, err = composite.GetExchangeRatesSince(context.Background(), from)
+		require.NoError(t, err)
+		assert.Empty(t, exchangeRates)
+		currencies, err := composite.GetCurrencies(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, currencies, 1)
+		fileAdapter.AssertExpectations(t)
+	})
+}
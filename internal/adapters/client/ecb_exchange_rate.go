@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the implementation of the ExchangeRateService port using the euro foreign exchange reference
+// rates of the European Central Bank.
+
+// Constants for the ECB reference rates. Change these if the ECB changes them.
+const (
+	ecbAPIEndpoint = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+	// ecbRatesCacheTTL is the time the downloaded reference rates are kept. The ECB publishes them once a day.
+	ecbRatesCacheTTL = 1 * time.Hour
+	// ecbRateDecimals is the number of decimal places of the exchange rates to USD derived from the reference rates.
+	ecbRateDecimals = 6
+)
+
+// ECBExchangeRateAdapter implements the TreasuryExchangeRateAdapter interface with the euro reference rates of the
+// ECB. The reference rates give the units of each currency that one euro buys, so they are turned into the units of
+// each currency that one USD buys with the USD reference rate of the same day, like the Treasury exchange rates. The
+// whole history is downloaded at once and kept in memory for a while.
+type ECBExchangeRateAdapter struct {
+	client      HTTPClient
+	apiEndpoint string
+	retryPolicy *RetryPolicy
+
+	mutex         sync.Mutex
+	exchangeRates exchangeRateTable
+	fetchedAt     time.Time
+}
+
+// NewECBExchangeRateAdapter creates a new ECBExchangeRateAdapter with the given HTTPClient. The requests are retried
+// with the default retry policy.
+func NewECBExchangeRateAdapter(client HTTPClient) *ECBExchangeRateAdapter {
+	return &ECBExchangeRateAdapter{
+		client:      client,
+		apiEndpoint: ecbAPIEndpoint,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+}
+
+// WithRetryPolicy replaces the retry policy of the requests to the ECB, and returns the adapter.
+func (a *ECBExchangeRateAdapter) WithRetryPolicy(retryPolicy *RetryPolicy) *ECBExchangeRateAdapter {
+	a.retryPolicy = retryPolicy
+	return a
+}
+
+// GetExchangeRates retrieves all the exchange rates for a currency, most recent first. The currency can be given as
+// an ISO 4217 code (e.g. "JPY"), as a Treasury country-currency description (e.g. "Japan-Yen") or as a currency name
+// (e.g. "Yen").
+func (a *ECBExchangeRateAdapter) GetExchangeRates(ctx context.Context, currencyName string) ([]*domain.ExchangeRate, error) {
+	exchangeRates, err := a.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates.find(currencyName, time.Time{}, time.Time{})
+}
+
+// GetExchangeRatesBetween retrieves the exchange rates for a currency with a date of record between two dates,
+// inclusive. The currency is given as for GetExchangeRates.
+func (a *ECBExchangeRateAdapter) GetExchangeRatesBetween(ctx context.Context, currencyName string,
+	from, to time.Time) ([]*domain.ExchangeRate, error) {
+	exchangeRates, err := a.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates.find(currencyName, from, to)
+}
+
+// GetExchangeRatesSince retrieves the exchange rates of all the currencies with a date of record after the given
+// date, most recent first.
+func (a *ECBExchangeRateAdapter) GetExchangeRatesSince(ctx context.Context, since time.Time) ([]*domain.ExchangeRate, error) {
+	exchangeRates, err := a.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates.since(since), nil
+}
+
+// GetCurrencies retrieves the currencies with reference rates, with the date of their latest exchange rate.
+func (a *ECBExchangeRateAdapter) GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	exchangeRates, err := a.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates.currencies()
+}
+
+// load returns the exchange rates derived from the reference rates, downloading them again once they are older
+// than the cache TTL.
+func (a *ECBExchangeRateAdapter) load(ctx context.Context) (exchangeRateTable, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.exchangeRates != nil && time.Since(a.fetchedAt) < ecbRatesCacheTTL {
+		return a.exchangeRates, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.apiEndpoint, nil)
+	if err != nil {
+		log.Error().Err(err).Str("url", a.apiEndpoint).Msg("error building the ECB request")
+		return nil, err
+	}
+	resp, err := a.retryPolicy.Do(a.client, req)
+	if ctx.Err() != nil {
+		log.Warn().Err(ctx.Err()).Msg("ECB request aborted")
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("error fetching data from the ECB")
+		return nil, ErrNetworkIssue
+	}
+	defer closeResponse(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		log.Error().Int("status_code", resp.StatusCode).Msg("unexpected ECB response")
+		return nil, ErrECBResponse
+	}
+	exchangeRates, err := ParseECBExchangeRates(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	a.exchangeRates = newExchangeRateTable(exchangeRates)
+	a.fetchedAt = time.Now()
+	log.Info().Int("exchange_rates", len(exchangeRates)).Msg("ECB reference rates fetched")
+	return a.exchangeRates, nil
+}
+
+// ecbEnvelope is the XML document of the ECB reference rates, with the rates of each day.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECBExchangeRates reads the euro reference rates published by the ECB, and turns them into exchange rates to
+// USD: the rate of a currency is its reference rate divided by the USD reference rate of the same day, rounded to
+// six decimal places, and the rate of the euro is the inverse of the USD reference rate. The days without a USD
+// reference rate are skipped.
+func ParseECBExchangeRates(r io.Reader) ([]*domain.ExchangeRate, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		log.Error().Err(err).Msg("error decoding ECB response")
+		return nil, ErrDecodingECBResponse
+	}
+
+	exchangeRates := make([]*domain.ExchangeRate, 0)
+	for _, day := range envelope.Days {
+		dateOfRecord, err := time.Parse(time.DateOnly, day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("%w: date %q", ErrInvalidECBRecord, day.Time)
+		}
+
+		// Reference rates are in units of currency per euro
+		ratesPerEuro := make(map[string]*big.Rat, len(day.Rates))
+		for _, rate := range day.Rates {
+			rateRat, err := domain.ParseDecimal(rate.Rate)
+			if err != nil || rateRat.Sign() <= 0 {
+				return nil, fmt.Errorf("%w: rate %q of %s on %s", ErrInvalidECBRecord, rate.Rate, rate.Currency, day.Time)
+			}
+			ratesPerEuro[strings.ToUpper(strings.TrimSpace(rate.Currency))] = rateRat
+		}
+		usdPerEuro, ok := ratesPerEuro[domain.CurrencyUSD]
+		if !ok {
+			log.Debug().Str("date", day.Time).Msg("no USD reference rate, skipping the day")
+			continue
+		}
+		ratesPerEuro["EUR"] = big.NewRat(1, 1)
+		delete(ratesPerEuro, domain.CurrencyUSD)
+
+		for code, ratePerEuro := range ratesPerEuro {
+			ratePerUSD := new(big.Rat).Quo(ratePerEuro, usdPerEuro)
+			exchangeRate, err := newECBExchangeRate(code, ratePerUSD, dateOfRecord)
+			if err != nil {
+				return nil, err
+			}
+			exchangeRates = append(exchangeRates, exchangeRate)
+		}
+	}
+
+	return exchangeRates, nil
+}
+
+// newECBExchangeRate creates the exchange rate of a currency to USD derived from the reference rates, named after
+// the Treasury country-currency of its code when there is one.
+func newECBExchangeRate(code string, ratePerUSD *big.Rat, dateOfRecord time.Time) (*domain.ExchangeRate, error) {
+	currencyName, countryCurrencyDesc := code, ""
+	if desc, err := domain.ResolveTreasuryCurrency(code); err == nil {
+		countryCurrencyDesc = desc
+		currencyName = desc[strings.LastIndex(desc, "-")+1:]
+	}
+
+	exchangeRate, errs := domain.NewExchangeRate(currencyName, formatRate(ratePerUSD, ecbRateDecimals), dateOfRecord)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s on %s: %v", ErrInvalidECBRecord, code, dateOfRecord.Format(time.DateOnly), errs)
+	}
+	exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+	exchangeRate.CurrencyCode = code
+	exchangeRate.Source = domain.ExchangeRateSourceECB
+	return exchangeRate, nil
+}
+
+// formatRate formats a rate rounded half away from zero to a number of decimal places, without trailing zeros.
+func formatRate(rate *big.Rat, decimals int) string {
+	text := rate.FloatString(decimals)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return text
+}
@@ -0,0 +1,17 @@
+package client
+
+import "errors"
+
+// This file defines error variables related to the ExchangeRateService port implementation using the ECB reference
+// rates.
+
+var (
+	// ErrECBResponse is returned when the ECB returns an error.
+	ErrECBResponse = errors.New("error from the ECB API")
+
+	// ErrDecodingECBResponse is returned when the ECB response cannot be decoded into the expected format.
+	ErrDecodingECBResponse = errors.New("error decoding response from the ECB API")
+
+	// ErrInvalidECBRecord is returned when a date or a rate of the ECB reference rates cannot be parsed.
+	ErrInvalidECBRecord = errors.New("invalid ECB reference rate")
+)
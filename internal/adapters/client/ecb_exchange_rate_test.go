@@ -0,0 +1,217 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the ECBExchangeRateAdapter and the parsing of the ECB reference rates. It uses Table
+// Driven Tests to test different scenarios. It uses Testify for assertions and mocking, and runs the tests in
+// parallel.
+
+// ecbTestEndpoint is the URL of the ECB reference rates requested by the adapter.
+const ecbTestEndpoint = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+
+// ecbTestDocument is an excerpt of the ECB reference rates, with a day without USD reference rate.
+const ecbTestDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-10-01">
+			<Cube currency="USD" rate="1.1"/>
+			<Cube currency="JPY" rate="160"/>
+		</Cube>
+		<Cube time="2024-09-30">
+			<Cube currency="USD" rate="1.2"/>
+			<Cube currency="JPY" rate="156"/>
+		</Cube>
+		<Cube time="2024-09-27">
+			<Cube currency="JPY" rate="155"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+// newECBResponse creates an HTTP response of the ECB with a status code and a body.
+func newECBResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// TestParseECBExchangeRates tests the ParseECBExchangeRates function. It tests the following scenarios:
+//
+// 1. Reference Rates Turned Into Rates To USD.
+// 2. Invalid XML.
+// 3. Invalid Date.
+// 4. Invalid Rate.
+func TestParseECBExchangeRates(t *testing.T) {
+	tests := []struct {
+		name          string
+		document      string
+		expectedRates map[string]string
+		expectedError error
+	}{
+		{
+			name:     "Reference Rates Turned Into Rates To USD",
+			document: ecbTestDocument,
+			expectedRates: map[string]string{
+				"2024-10-01 EUR": "0.909091",
+				"2024-10-01 JPY": "145.454545",
+				"2024-09-30 EUR": "0.833333",
+				"2024-09-30 JPY": "130",
+			},
+		},
+		{
+			name:          "Invalid XML",
+			document:      `<Envelope><Cube>`,
+			expectedError: client.ErrDecodingECBResponse,
+		},
+		{
+			name:          "Invalid Date",
+			document:      `<Envelope><Cube><Cube time="01/10/2024"><Cube currency="USD" rate="1.1"/></Cube></Cube></Envelope>`,
+			expectedError: client.ErrInvalidECBRecord,
+		},
+		{
+			name:          "Invalid Rate",
+			document:      `<Envelope><Cube><Cube time="2024-10-01"><Cube currency="USD" rate="N/A"/></Cube></Cube></Envelope>`,
+			expectedError: client.ErrInvalidECBRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			exchangeRates, err := client.ParseECBExchangeRates(strings.NewReader(tt.document))
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, exchangeRates)
+				return
+			}
+			require.NoError(t, err)
+			rates := make(map[string]string, len(exchangeRates))
+			for _, exchangeRate := range exchangeRates {
+				rates[exchangeRate.DateOfRecord.Format(time.DateOnly)+" "+exchangeRate.CurrencyCode] = exchangeRate.RateText
+				assert.Equal(t, domain.ExchangeRateSourceECB, exchangeRate.Source)
+			}
+			assert.Equal(t, tt.expectedRates, rates)
+		})
+	}
+}
+
+// TestECBExchangeRateAdapter tests the ECBExchangeRateAdapter. It tests the following scenarios:
+//
+// 1. Exchange Rates By Code.
+// 2. Exchange Rates By Currency Name Between Dates.
+// 3. Unknown Currency.
+// 4. Non-200 Status Code.
+// 5. Network Issue.
+func TestECBExchangeRateAdapter(t *testing.T) {
+	tests := []struct {
+		name          string
+		call          func(*client.ECBExchangeRateAdapter) ([]*domain.ExchangeRate, error)
+		mockResponse  *http.Response
+		mockError     error
+		expectedDates []string
+		expectedError error
+	}{
+		{
+			name: "Exchange Rates By Code",
+			call: func(adapter *client.ECBExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+				return adapter.GetExchangeRates(context.Background(), "JPY")
+			},
+			mockResponse:  newECBResponse(http.StatusOK, ecbTestDocument),
+			expectedDates: []string{"2024-10-01", "2024-09-30"},
+		},
+		{
+			name: "Exchange Rates By Currency Name Between Dates",
+			call: func(adapter *client.ECBExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+				return adapter.GetExchangeRatesBetween(context.Background(), "euro zone-euro",
+					time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+			},
+			mockResponse:  newECBResponse(http.StatusOK, ecbTestDocument),
+			expectedDates: []string{"2024-09-30"},
+		},
+		{
+			name: "Unknown Currency",
+			call: func(adapter *client.ECBExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+				return adapter.GetExchangeRates(context.Background(), "BRL")
+			},
+			mockResponse:  newECBResponse(http.StatusOK, ecbTestDocument),
+			expectedError: client.ErrExchangeRateNotFound,
+		},
+		{
+			name: "Non-200 Status Code",
+			call: func(adapter *client.ECBExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+				return adapter.GetExchangeRates(context.Background(), "JPY")
+			},
+			mockResponse:  newECBResponse(http.StatusNotFound, ""),
+			expectedError: client.ErrECBResponse,
+		},
+		{
+			name: "Network Issue",
+			call: func(adapter *client.ECBExchangeRateAdapter) ([]*domain.ExchangeRate, error) {
+				return adapter.GetExchangeRatesSince(context.Background(), time.Time{})
+			},
+			mockError:     assert.AnError,
+			expectedError: client.ErrNetworkIssue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mockClient := new(client.MockTreasuryExchangeRateAdapter)
+			mockClient.On("Do", ecbTestEndpoint).Return(tt.mockResponse, tt.mockError)
+			adapter := client.NewECBExchangeRateAdapter(mockClient).WithRetryPolicy(newNoWaitRetryPolicy())
+
+			exchangeRates, err := tt.call(adapter)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, exchangeRates)
+				return
+			}
+			require.NoError(t, err)
+			dates := make([]string, len(exchangeRates))
+			for i, exchangeRate := range exchangeRates {
+				dates[i] = exchangeRate.DateOfRecord.Format(time.DateOnly)
+			}
+			assert.Equal(t, tt.expectedDates, dates)
+		})
+	}
+}
+
+// TestECBExchangeRateAdapterCurrencies tests that the ECBExchangeRateAdapter downloads the reference rates once for
+// several calls, and lists their currencies under their Treasury names.
+func TestECBExchangeRateAdapterCurrencies(t *testing.T) {
+	t.Parallel()
+	mockClient := new(client.MockTreasuryExchangeRateAdapter)
+	mockClient.On("Do", ecbTestEndpoint).Return(newECBResponse(http.StatusOK, ecbTestDocument), nil).Once()
+	adapter := client.NewECBExchangeRateAdapter(mockClient)
+
+	currencies, err := adapter.GetCurrencies(context.Background())
+	require.NoError(t, err)
+	exchangeRates, err := adapter.GetExchangeRates(context.Background(), "Yen")
+	require.NoError(t, err)
+
+	require.Len(t, currencies, 2)
+	assert.Equal(t, "Euro Zone-Euro", currencies[0].CountryCurrencyDesc)
+	assert.Equal(t, "EUR", currencies[0].CurrencyCode)
+	assert.Equal(t, "Japan-Yen", currencies[1].CountryCurrencyDesc)
+	assert.Equal(t, "JPY", currencies[1].CurrencyCode)
+	assert.Len(t, exchangeRates, 2)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+)
+
+// This file contains the in-memory table of exchange rates shared by the providers that load a whole document of
+// exchange rates at once (e.g. the ECB reference rates or a local file).
+
+// exchangeRateTable holds exchange rates sorted most recent first.
+type exchangeRateTable []*domain.ExchangeRate
+
+// newExchangeRateTable creates an exchangeRateTable from exchange rates in any order.
+func newExchangeRateTable(exchangeRates []*domain.ExchangeRate) exchangeRateTable {
+	table := make(exchangeRateTable, len(exchangeRates))
+	copy(table, exchangeRates)
+	sort.SliceStable(table, func(i, j int) bool {
+		return table[i].DateOfRecord.After(table[j].DateOfRecord)
+	})
+	return table
+}
+
+// find returns the exchange rates of a currency with a date of record between two dates, inclusive. A zero date
+// leaves its side of the range open. The currency can be given as an ISO 4217 code (e.g. "EUR"), as a
+// country-currency description (e.g. "Euro Zone-Euro") or as a currency name (e.g. "Euro"). It returns
+// ErrExchangeRateNotFound when there are none.
+func (t exchangeRateTable) find(currencyName string, from, to time.Time) ([]*domain.ExchangeRate, error) {
+	currencyName = strings.TrimSpace(currencyName)
+	exchangeRates := make([]*domain.ExchangeRate, 0)
+	for _, exchangeRate := range t {
+		if !matchesCurrency(exchangeRate, currencyName) ||
+			(!from.IsZero() && exchangeRate.DateOfRecord.Before(from)) ||
+			(!to.IsZero() && exchangeRate.DateOfRecord.After(to)) {
+			continue
+		}
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+
+	if len(exchangeRates) == 0 {
+		return nil, ErrExchangeRateNotFound
+	}
+	return exchangeRates, nil
+}
+
+// since returns the exchange rates of all the currencies with a date of record after a date, most recent first.
+func (t exchangeRateTable) since(since time.Time) []*domain.ExchangeRate {
+	exchangeRates := make([]*domain.ExchangeRate, 0)
+	for _, exchangeRate := range t {
+		if exchangeRate.DateOfRecord.After(since) {
+			exchangeRates = append(exchangeRates, exchangeRate)
+		}
+	}
+	return exchangeRates
+}
+
+// currencies returns the currencies of the table sorted by country-currency name, each with its latest date of
+// record. A currency without country-currency description is named after its code.
+func (t exchangeRateTable) currencies() ([]*domain.SupportedCurrency, error) {
+	currencies := make([]*domain.SupportedCurrency, 0)
+	seen := make(map[string]bool)
+	// The table is sorted most recent first, so the first exchange rate of a currency is its latest one
+	for _, exchangeRate := range t {
+		countryCurrencyDesc := exchangeRate.CountryCurrencyDesc
+		if countryCurrencyDesc == "" {
+			countryCurrencyDesc = exchangeRate.CurrencyCode
+		}
+		if countryCurrencyDesc == "" || seen[countryCurrencyDesc] {
+			continue
+		}
+		seen[countryCurrencyDesc] = true
+
+		// Internal codes of a file (e.g. "XYZ") are not ISO 4217 codes, so their currencies are listed without code
+		currencyCode := exchangeRate.CurrencyCode
+		if _, ok := domain.LookupCurrency(currencyCode); !ok {
+			currencyCode = ""
+		}
+		currency, errs := domain.NewSupportedCurrency(countryCurrencyDesc, exchangeRate.CurrencyName, currencyCode,
+			exchangeRate.DateOfRecord)
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		currencies = append(currencies, currency)
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].CountryCurrencyDesc < currencies[j].CountryCurrencyDesc
+	})
+	return currencies, nil
+}
+
+// matchesCurrency reports whether an exchange rate is of a currency given as an ISO 4217 code, a country-currency
+// description or a currency name. The descriptions and names are compared ignoring case.
+func matchesCurrency(exchangeRate *domain.ExchangeRate, currencyName string) bool {
+	if domain.IsCurrencyCode(currencyName) {
+		return exchangeRate.CurrencyCode == currencyName
+	}
+	return strings.EqualFold(exchangeRate.CountryCurrencyDesc, currencyName) ||
+		strings.EqualFold(exchangeRate.CurrencyName, currencyName)
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the implementation of the ExchangeRateService port using a local CSV or JSON file of exchange
+// rates, for offline use or for internal rates.
+
+// Columns of a CSV exchange rate file, and keys of the objects of a JSON exchange rate file.
+const (
+	fileCurrencyCodeField        = "currency_code"
+	fileCountryCurrencyDescField = "country_currency_desc"
+	fileCurrencyNameField        = "currency_name"
+	fileExchangeRateField        = "exchange_rate"
+	fileRecordDateField          = "record_date"
+)
+
+// exchangeRateFileRecord is an exchange rate of a CSV or JSON exchange rate file. The rate is given as many units of
+// the currency as one USD buys, like the Treasury exchange rates, and the currency by its ISO 4217 code and/or its
+// Treasury country-currency description.
+type exchangeRateFileRecord struct {
+	CurrencyCode        string `json:"currency_code"`
+	CountryCurrencyDesc string `json:"country_currency_desc"`
+	CurrencyName        string `json:"currency_name"`
+	ExchangeRate        string `json:"exchange_rate"`
+	RecordDate          string `json:"record_date"`
+}
+
+// FileExchangeRateAdapter implements the TreasuryExchangeRateAdapter interface with the exchange rates of a local
+// file, read once when the adapter is created.
+type FileExchangeRateAdapter struct {
+	exchangeRates exchangeRateTable
+}
+
+// NewFileExchangeRateAdapter creates a new FileExchangeRateAdapter with the exchange rates of a file. The format is
+// chosen by the extension of the file: ".csv" or ".json".
+func NewFileExchangeRateAdapter(path string) (*FileExchangeRateAdapter, error) {
+	var parse func(io.Reader) ([]*domain.ExchangeRate, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		parse = ParseExchangeRatesCSV
+	case ".json":
+		parse = ParseExchangeRatesJSON
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedExchangeRateFile, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReadingExchangeRateFile, err)
+	}
+	defer file.Close()
+
+	exchangeRates, err := parse(file)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("path", path).Int("exchange_rates", len(exchangeRates)).Msg("exchange rate file loaded")
+	return &FileExchangeRateAdapter{exchangeRates: newExchangeRateTable(exchangeRates)}, nil
+}
+
+// GetExchangeRates retrieves all the exchange rates of the file for a currency, most recent first. The currency can
+// be given as an ISO 4217 code (e.g. "JPY"), as a Treasury country-currency description (e.g. "Japan-Yen") or as a
+// currency name (e.g. "Yen").
+func (a *FileExchangeRateAdapter) GetExchangeRates(_ context.Context, currencyName string) ([]*domain.ExchangeRate, error) {
+	return a.exchangeRates.find(currencyName, time.Time{}, time.Time{})
+}
+
+// GetExchangeRatesBetween retrieves the exchange rates of the file for a currency with a date of record between two
+// dates, inclusive. The currency is given as for GetExchangeRates.
+func (a *FileExchangeRateAdapter) GetExchangeRatesBetween(_ context.Context, currencyName string,
+	from, to time.Time) ([]*domain.ExchangeRate, error) {
+	return a.exchangeRates.find(currencyName, from, to)
+}
+
+// GetExchangeRatesSince retrieves the exchange rates of the file with a date of record after the given date, most
+// recent first.
+func (a *FileExchangeRateAdapter) GetExchangeRatesSince(_ context.Context, since time.Time) ([]*domain.ExchangeRate, error) {
+	return a.exchangeRates.since(since), nil
+}
+
+// GetCurrencies retrieves the currencies of the file, with the date of their latest exchange rate.
+func (a *FileExchangeRateAdapter) GetCurrencies(_ context.Context) ([]*domain.SupportedCurrency, error) {
+	return a.exchangeRates.currencies()
+}
+
+// ParseExchangeRatesCSV reads exchange rates from a CSV document. The first line is a header naming the columns:
+// "currency_code" and/or "country_currency_desc", "exchange_rate" and "record_date" (YYYY-MM-DD) are required, and
+// "currency_name" is optional. The columns can be in any order.
+func ParseExchangeRatesCSV(r io.Reader) ([]*domain.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header: %w", ErrInvalidExchangeRateFileRecord, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{fileExchangeRateField, fileRecordDateField} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidExchangeRateFileRecord, required)
+		}
+	}
+	column := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	exchangeRates := make([]*domain.ExchangeRate, 0)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrReadingExchangeRateFile, err)
+		}
+
+		exchangeRate, err := newFileExchangeRate(exchangeRateFileRecord{
+			CurrencyCode:        column(row, fileCurrencyCodeField),
+			CountryCurrencyDesc: column(row, fileCountryCurrencyDescField),
+			CurrencyName:        column(row, fileCurrencyNameField),
+			ExchangeRate:        column(row, fileExchangeRateField),
+			RecordDate:          column(row, fileRecordDateField),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+
+	return exchangeRates, nil
+}
+
+// ParseExchangeRatesJSON reads exchange rates from a JSON array of objects with the same keys as the columns of a
+// CSV exchange rate file.
+func ParseExchangeRatesJSON(r io.Reader) ([]*domain.ExchangeRate, error) {
+	var records []exchangeRateFileRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReadingExchangeRateFile, err)
+	}
+
+	exchangeRates := make([]*domain.ExchangeRate, 0, len(records))
+	for i, record := range records {
+		exchangeRate, err := newFileExchangeRate(record)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+
+	return exchangeRates, nil
+}
+
+// newFileExchangeRate creates the exchange rate of a file record. The ISO 4217 code and the Treasury country-currency
+// description fill each other in when only one of them is given, and the currency name defaults to the part of the
+// description after the country, or to the code.
+func newFileExchangeRate(record exchangeRateFileRecord) (*domain.ExchangeRate, error) {
+	code := strings.ToUpper(strings.TrimSpace(record.CurrencyCode))
+	countryCurrencyDesc := strings.TrimSpace(record.CountryCurrencyDesc)
+	currencyName := strings.TrimSpace(record.CurrencyName)
+
+	switch {
+	case code == "" && countryCurrencyDesc == "":
+		return nil, fmt.Errorf("%w: %s or %s is required", ErrInvalidExchangeRateFileRecord,
+			fileCurrencyCodeField, fileCountryCurrencyDescField)
+	case code != "" && !domain.IsCurrencyCode(code):
+		return nil, fmt.Errorf("%w: %w: %s", ErrInvalidExchangeRateFileRecord, domain.ErrInvalidCurrencyCode, code)
+	case countryCurrencyDesc == "":
+		countryCurrencyDesc, _ = domain.ResolveTreasuryCurrency(code)
+	case code == "":
+		code, _ = domain.TreasuryCurrencyCode(countryCurrencyDesc)
+	}
+	if currencyName == "" {
+		currencyName = code
+		if countryCurrencyDesc != "" {
+			currencyName = countryCurrencyDesc[strings.LastIndex(countryCurrencyDesc, "-")+1:]
+		}
+	}
+
+	dateOfRecord, err := time.Parse(time.DateOnly, strings.TrimSpace(record.RecordDate))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %q", ErrInvalidExchangeRateFileRecord, ErrParsingExchangeRateDateOfRecord,
+			record.RecordDate)
+	}
+	exchangeRate, errs := domain.NewExchangeRate(currencyName, record.ExchangeRate, dateOfRecord)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExchangeRateFileRecord, errors.Join(errs...))
+	}
+	exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+	exchangeRate.CurrencyCode = code
+	exchangeRate.Source = domain.ExchangeRateSourceFile
+	return exchangeRate, nil
+}
@@ -0,0 +1,18 @@
+package client
+
+import "errors"
+
+// This file defines error variables related to the ExchangeRateService port implementation using a local file of
+// exchange rates.
+
+var (
+	// ErrUnsupportedExchangeRateFile is returned when the exchange rate file is neither a CSV nor a JSON file.
+	ErrUnsupportedExchangeRateFile = errors.New("unsupported exchange rate file format, expected .csv or .json")
+
+	// ErrReadingExchangeRateFile is returned when the exchange rate file cannot be opened or read.
+	ErrReadingExchangeRateFile = errors.New("error reading the exchange rate file")
+
+	// ErrInvalidExchangeRateFileRecord is returned when a record of the exchange rate file is missing a field or has
+	// an invalid value.
+	ErrInvalidExchangeRateFileRecord = errors.New("invalid exchange rate file record")
+)
@@ -0,0 +1,196 @@
+package client_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the FileExchangeRateAdapter and the parsing of the CSV and JSON exchange rate files.
+// It uses Table Driven Tests to test different scenarios. It uses Testify for assertions, and runs the tests in
+// parallel. The files are written to a temporary directory.
+
+// writeExchangeRateFile writes an exchange rate file to a temporary directory and returns its path.
+func writeExchangeRateFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// TestNewFileExchangeRateAdapter tests the NewFileExchangeRateAdapter function. It tests the following scenarios:
+//
+// 1. CSV File With Columns In Any Order.
+// 2. JSON File.
+// 3. Unsupported Extension.
+// 4. Missing File.
+// 5. Missing Column.
+// 6. Record Without Currency.
+// 7. Invalid Currency Code.
+// 8. Invalid Rate.
+// 9. Invalid Date.
+func TestNewFileExchangeRateAdapter(t *testing.T) {
+	tests := []struct {
+		name          string
+		fileName      string
+		content       string
+		expectedRates []domain.ExchangeRate
+		expectedError error
+	}{
+		{
+			name:     "CSV File With Columns In Any Order",
+			fileName: "rates.csv",
+			content: "record_date,exchange_rate,currency_code,country_currency_desc,currency_name\n" +
+				"2024-09-30,5.43,BRL,,\n" +
+				"2024-10-01, 0.9 ,,Euro Zone-Euro,\n" +
+				"2024-10-01,3.6725,AED,,Dirham\n",
+			expectedRates: []domain.ExchangeRate{
+				{CurrencyName: "Euro", CountryCurrencyDesc: "Euro Zone-Euro", CurrencyCode: "EUR", RateText: "0.9"},
+				{CurrencyName: "Dirham", CountryCurrencyDesc: "United Arab Emirates-Dirham", CurrencyCode: "AED",
+					RateText: "3.6725"},
+				{CurrencyName: "Real", CountryCurrencyDesc: "Brazil-Real", CurrencyCode: "BRL", RateText: "5.43"},
+			},
+		},
+		{
+			name:     "JSON File",
+			fileName: "rates.JSON",
+			content: `[{"currency_code": "XYZ", "currency_name": "Internal Credit", "exchange_rate": "2",
+				"record_date": "2024-10-01"}]`,
+			expectedRates: []domain.ExchangeRate{
+				{CurrencyName: "Internal Credit", CurrencyCode: "XYZ", RateText: "2"},
+			},
+		},
+		{
+			name:          "Unsupported Extension",
+			fileName:      "rates.xml",
+			content:       "<rates/>",
+			expectedError: client.ErrUnsupportedExchangeRateFile,
+		},
+		{
+			name:          "Missing File",
+			fileName:      "",
+			expectedError: client.ErrReadingExchangeRateFile,
+		},
+		{
+			name:          "Missing Column",
+			fileName:      "rates.csv",
+			content:       "currency_code,exchange_rate\nBRL,5.43\n",
+			expectedError: client.ErrInvalidExchangeRateFileRecord,
+		},
+		{
+			name:          "Record Without Currency",
+			fileName:      "rates.json",
+			content:       `[{"exchange_rate": "5.43", "record_date": "2024-10-01"}]`,
+			expectedError: client.ErrInvalidExchangeRateFileRecord,
+		},
+		{
+			name:          "Invalid Currency Code",
+			fileName:      "rates.csv",
+			content:       "currency_code,exchange_rate,record_date\nREAL,5.43,2024-10-01\n",
+			expectedError: domain.ErrInvalidCurrencyCode,
+		},
+		{
+			name:          "Invalid Rate",
+			fileName:      "rates.csv",
+			content:       "currency_code,exchange_rate,record_date\nBRL,-5.43,2024-10-01\n",
+			expectedError: domain.ErrInvalidExchangeRate,
+		},
+		{
+			name:          "Invalid Date",
+			fileName:      "rates.json",
+			content:       `[{"currency_code": "BRL", "exchange_rate": "5.43", "record_date": "01/10/2024"}]`,
+			expectedError: client.ErrParsingExchangeRateDateOfRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "missing.csv")
+			if tt.fileName != "" {
+				path = writeExchangeRateFile(t, tt.fileName, tt.content)
+			}
+
+			adapter, err := client.NewFileExchangeRateAdapter(path)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, adapter)
+				return
+			}
+			require.NoError(t, err)
+			currencies, err := adapter.GetCurrencies(context.Background())
+			require.NoError(t, err)
+			assert.Len(t, currencies, len(tt.expectedRates))
+			exchangeRates, err := adapter.GetExchangeRatesSince(context.Background(), time.Time{})
+			require.NoError(t, err)
+			require.Len(t, exchangeRates, len(tt.expectedRates))
+			for i, expected := range tt.expectedRates {
+				assert.Equal(t, expected.CurrencyName, exchangeRates[i].CurrencyName)
+				assert.Equal(t, expected.CountryCurrencyDesc, exchangeRates[i].CountryCurrencyDesc)
+				assert.Equal(t, expected.CurrencyCode, exchangeRates[i].CurrencyCode)
+				assert.Equal(t, expected.RateText, exchangeRates[i].RateText)
+				assert.Equal(t, domain.ExchangeRateSourceFile, exchangeRates[i].Source)
+			}
+		})
+	}
+}
+
+// TestFileExchangeRateAdapter tests the lookups of the FileExchangeRateAdapter. It tests the following scenarios:
+//
+// 1. Exchange Rates By Code, Most Recent First.
+// 2. Exchange Rates By Currency Name Between Dates.
+// 3. Unknown Currency.
+// 4. Currencies With Their Latest Date.
+func TestFileExchangeRateAdapter(t *testing.T) {
+	path := writeExchangeRateFile(t, "rates.csv", "currency_code,exchange_rate,record_date\n"+
+		"BRL,5.43,2024-09-30\n"+
+		"BRL,5.45,2024-10-01\n"+
+		"BRL,5.40,2024-06-30\n"+
+		"EUR,0.9,2024-10-01\n")
+	adapter, err := client.NewFileExchangeRateAdapter(path)
+	require.NoError(t, err)
+
+	t.Run("Exchange Rates By Code, Most Recent First", func(t *testing.T) {
+		t.Parallel()
+		exchangeRates, err := adapter.GetExchangeRates(context.Background(), "BRL")
+		require.NoError(t, err)
+		require.Len(t, exchangeRates, 3)
+		assert.Equal(t, "5.45", exchangeRates[0].RateText)
+		assert.Equal(t, "5.43", exchangeRates[1].RateText)
+		assert.Equal(t, "5.40", exchangeRates[2].RateText)
+	})
+
+	t.Run("Exchange Rates By Currency Name Between Dates", func(t *testing.T) {
+		t.Parallel()
+		exchangeRates, err := adapter.GetExchangeRatesBetween(context.Background(), "real",
+			time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		require.Len(t, exchangeRates, 1)
+		assert.Equal(t, "5.43", exchangeRates[0].RateText)
+	})
+
+	t.Run("Unknown Currency", func(t *testing.T) {
+		t.Parallel()
+		exchangeRates, err := adapter.GetExchangeRates(context.Background(), "JPY")
+		assert.ErrorIs(t, err, client.ErrExchangeRateNotFound)
+		assert.Nil(t, exchangeRates)
+	})
+
+	t.Run("Currencies With Their Latest Date", func(t *testing.T) {
+		t.Parallel()
+		currencies, err := adapter.GetCurrencies(context.Background())
+		require.NoError(t, err)
+		require.Len(t, currencies, 2)
+		assert.Equal(t, "Brazil-Real", currencies[0].CountryCurrencyDesc)
+		assert.Equal(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), currencies[0].LatestDateOfRecord)
+		assert.Equal(t, "Euro Zone-Euro", currencies[1].CountryCurrencyDesc)
+	})
+}
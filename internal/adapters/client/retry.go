@@ -0,0 +1,192 @@
+package client
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains a retry policy for HTTP requests, with exponential backoff, jitter and Retry-After support.
+
+// Default settings of the retry policy. The base delay is kept well below the request timeout of the API, so a
+// request can still be retried within it.
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+	DefaultRetryMaxDelay  = 30 * time.Second
+)
+
+// Clock abstracts the passing of time, so the waits between the attempts can be skipped in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the current time once the duration has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock of the system time.
+type systemClock struct{}
+
+// Now returns the current system time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse with the system timer.
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// RetryPolicy retries HTTP requests that failed with a transport error or a transient status code (408, 429 and 5xx).
+// The delay between two attempts grows exponentially from the base delay up to the max delay, with a random jitter
+// so concurrent clients do not retry in lockstep. A Retry-After header sent with the response replaces the backoff.
+type RetryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	clock       Clock
+}
+
+// NewRetryPolicy creates a new RetryPolicy. Non-positive settings fall back to DefaultRetryAttempts,
+// DefaultRetryBaseDelay and DefaultRetryMaxDelay, and a nil clock to the system clock.
+func NewRetryPolicy(maxAttempts int, baseDelay, maxDelay time.Duration, clock Clock) *RetryPolicy {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultRetryAttempts
+	}
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &RetryPolicy{
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		maxDelay:    max(maxDelay, baseDelay),
+		clock:       clock,
+	}
+}
+
+// DefaultRetryPolicy returns the RetryPolicy with the default settings and the system clock.
+func DefaultRetryPolicy() *RetryPolicy {
+	return NewRetryPolicy(DefaultRetryAttempts, DefaultRetryBaseDelay, DefaultRetryMaxDelay, nil)
+}
+
+// Do sends a request with the client, and sends it again while it fails with a transport error or a transient status
+// code and attempts remain. The last response is returned when the attempts are exhausted, so the caller handles its
+// status code, and the error of the request context as soon as it is done. The last response is also returned when
+// the deadline of the request context would pass before the next attempt. A request with a body is only sent again
+// when its body can be rewound with GetBody.
+func (p *RetryPolicy) Do(client HTTPClient, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		if ctx.Err() != nil {
+			closeResponse(resp)
+			return nil, ctx.Err()
+		}
+		if !isRetryable(resp, err) || attempt >= p.maxAttempts || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay := p.delay(attempt, resp)
+		if deadline, ok := ctx.Deadline(); ok && deadline.Sub(p.clock.Now()) <= delay {
+			log.Warn().Int("attempt", attempt).Dur("delay", delay).Str("url", req.URL.Redacted()).
+				Msg("not retrying the request, as the deadline would pass before the next attempt")
+			return resp, err
+		}
+		logEvent := log.Warn().Int("attempt", attempt).Dur("delay", delay).Str("url", req.URL.Redacted())
+		if err != nil {
+			logEvent.Err(err).Msg("retrying the request after a transport error")
+		} else {
+			logEvent.Int("status_code", resp.StatusCode).Msg("retrying the request after a transient status code")
+		}
+		closeResponse(resp)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.clock.After(delay):
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// delay returns the time to wait after a failed attempt: the Retry-After delay of the response when it has one, and
+// the exponential backoff with jitter otherwise. Both are bounded by the max delay.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if retryAfter, ok := p.retryAfter(resp); ok {
+		return min(retryAfter, p.maxDelay)
+	}
+
+	backoff := p.baseDelay
+	for i := 1; i < attempt && backoff < p.maxDelay; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.maxDelay)
+
+	// Equal jitter: half of the backoff, plus a random part up to the other half
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
+
+// retryAfter returns the delay of the Retry-After header of a response, given either in seconds or as an HTTP date.
+func (p *RetryPolicy) retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(p.clock.Now()), 0), true
+	}
+	log.Warn().Str("retry_after", value).Msg("invalid Retry-After header, using the backoff delay")
+	return 0, false
+}
+
+// isRetryable reports whether a request failed with a transport error or a transient status code.
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// closeResponse drains and closes the body of a response that will not be processed, so its connection can be
+// reused.
+func closeResponse(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		log.Debug().Err(err).Msg("error draining response body")
+	}
+	if err := resp.Body.Close(); err != nil {
+		log.Error().Err(err).Msg("error closing response body")
+	}
+}
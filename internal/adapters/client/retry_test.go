@@ -0,0 +1,302 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the retry policy of the HTTP requests. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and mocking, and runs the tests in parallel. The waits between the
+// attempts are recorded by a fake clock instead of being slept.
+
+// fakeClock is a Clock that records the waits and elapses them at once, or never when it is blocked.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waits   []time.Duration
+	blocked bool
+}
+
+// newFakeClock creates a new fakeClock starting at the given time.
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+// Now returns the current time of the fake clock.
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After records the wait and moves the fake clock forward, unless it is blocked.
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	if !c.blocked {
+		c.now = c.now.Add(d)
+		ch <- c.now
+	}
+	return ch
+}
+
+// Advance moves the fake clock forward.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Waits returns the waits recorded by the fake clock.
+func (c *fakeClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
+
+// newNoWaitRetryPolicy creates the default retry policy with a fake clock, so the tests do not sleep.
+func newNoWaitRetryPolicy() *client.RetryPolicy {
+	return client.NewRetryPolicy(0, 0, 0, newFakeClock(time.Now()))
+}
+
+// mockAttempt is the outcome of one attempt of a request.
+type mockAttempt struct {
+	statusCode int
+	retryAfter string
+	err        error
+}
+
+// TestRetryPolicyDo tests the Do method of the RetryPolicy. It tests the following scenarios:
+//
+// 1. Transport Error Then Success.
+// 2. Transient Status Codes Then Success.
+// 3. Retry-After In Seconds.
+// 4. Retry-After As A Date.
+// 5. Retry-After Bounded By The Max Delay.
+// 6. Non-Retryable Status Code.
+// 7. Attempts Exhausted Returns The Last Response.
+// 8. Transport Errors Exhausted.
+// 9. Non-Positive Settings Fall Back To The Defaults.
+func TestRetryPolicyDo(t *testing.T) {
+	start := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		maxAttempts    int
+		baseDelay      time.Duration
+		maxDelay       time.Duration
+		attempts       []mockAttempt
+		expectedStatus int
+		expectedError  error
+		// expectedWaits are the bounds of each wait between the attempts
+		expectedWaits [][2]time.Duration
+	}{
+		{
+			name:           "Transport Error Then Success",
+			maxAttempts:    3,
+			baseDelay:      time.Second,
+			maxDelay:       10 * time.Second,
+			attempts:       []mockAttempt{{err: assert.AnError}, {statusCode: http.StatusOK}},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  [][2]time.Duration{{500 * time.Millisecond, time.Second}},
+		},
+		{
+			name:        "Transient Status Codes Then Success",
+			maxAttempts: 4,
+			baseDelay:   time.Second,
+			maxDelay:    10 * time.Second,
+			attempts: []mockAttempt{{statusCode: http.StatusRequestTimeout}, {statusCode: http.StatusTooManyRequests},
+				{statusCode: http.StatusServiceUnavailable}, {statusCode: http.StatusOK}},
+			expectedStatus: http.StatusOK,
+			expectedWaits: [][2]time.Duration{{500 * time.Millisecond, time.Second}, {time.Second, 2 * time.Second},
+				{2 * time.Second, 4 * time.Second}},
+		},
+		{
+			name:           "Retry-After In Seconds",
+			maxAttempts:    3,
+			baseDelay:      time.Second,
+			maxDelay:       10 * time.Second,
+			attempts:       []mockAttempt{{statusCode: http.StatusTooManyRequests, retryAfter: "7"}, {statusCode: http.StatusOK}},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  [][2]time.Duration{{7 * time.Second, 7 * time.Second}},
+		},
+		{
+			name:        "Retry-After As A Date",
+			maxAttempts: 3,
+			baseDelay:   time.Second,
+			maxDelay:    10 * time.Second,
+			attempts: []mockAttempt{{statusCode: http.StatusServiceUnavailable,
+				retryAfter: start.Add(5 * time.Second).Format(http.TimeFormat)}, {statusCode: http.StatusOK}},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  [][2]time.Duration{{5 * time.Second, 5 * time.Second}},
+		},
+		{
+			name:           "Retry-After Bounded By The Max Delay",
+			maxAttempts:    3,
+			baseDelay:      time.Second,
+			maxDelay:       10 * time.Second,
+			attempts:       []mockAttempt{{statusCode: http.StatusTooManyRequests, retryAfter: "120"}, {statusCode: http.StatusOK}},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  [][2]time.Duration{{10 * time.Second, 10 * time.Second}},
+		},
+		{
+			name:           "Non-Retryable Status Code",
+			maxAttempts:    3,
+			baseDelay:      time.Second,
+			maxDelay:       10 * time.Second,
+			attempts:       []mockAttempt{{statusCode: http.StatusNotFound}},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:        "Attempts Exhausted Returns The Last Response",
+			maxAttempts: 3,
+			baseDelay:   time.Second,
+			maxDelay:    10 * time.Second,
+			attempts: []mockAttempt{{statusCode: http.StatusInternalServerError},
+				{statusCode: http.StatusBadGateway}, {statusCode: http.StatusServiceUnavailable}},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedWaits:  [][2]time.Duration{{500 * time.Millisecond, time.Second}, {time.Second, 2 * time.Second}},
+		},
+		{
+			name:          "Transport Errors Exhausted",
+			maxAttempts:   2,
+			baseDelay:     time.Second,
+			maxDelay:      10 * time.Second,
+			attempts:      []mockAttempt{{err: assert.AnError}, {err: assert.AnError}},
+			expectedError: assert.AnError,
+			expectedWaits: [][2]time.Duration{{500 * time.Millisecond, time.Second}},
+		},
+		{
+			name: "Non-Positive Settings Fall Back To The Defaults",
+			attempts: []mockAttempt{{statusCode: http.StatusInternalServerError},
+				{statusCode: http.StatusInternalServerError}, {statusCode: http.StatusInternalServerError}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedWaits: [][2]time.Duration{{client.DefaultRetryBaseDelay / 2, client.DefaultRetryBaseDelay},
+				{client.DefaultRetryBaseDelay, 2 * client.DefaultRetryBaseDelay}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Creates a mock client that answers the attempts in order
+			mockClient := new(client.MockTreasuryExchangeRateAdapter)
+			for _, attempt := range tt.attempts {
+				var resp *http.Response
+				if attempt.err == nil {
+					resp = &http.Response{
+						StatusCode: attempt.statusCode,
+						Header:     http.Header{},
+						Body:       io.NopCloser(strings.NewReader("")),
+					}
+					if attempt.retryAfter != "" {
+						resp.Header.Set("Retry-After", attempt.retryAfter)
+					}
+				}
+				mockClient.On("Do", mock.Anything).Return(resp, attempt.err).Once()
+			}
+
+			clock := newFakeClock(start)
+			retryPolicy := client.NewRetryPolicy(tt.maxAttempts, tt.baseDelay, tt.maxDelay, clock)
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://example.com/rates", nil)
+			require.NoError(t, err)
+
+			resp, err := retryPolicy.Do(mockClient, req)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, resp)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			}
+			mockClient.AssertExpectations(t)
+			waits := clock.Waits()
+			require.Len(t, waits, len(tt.expectedWaits))
+			for i, bounds := range tt.expectedWaits {
+				assert.GreaterOrEqual(t, waits[i], bounds[0])
+				assert.LessOrEqual(t, waits[i], bounds[1])
+			}
+		})
+	}
+}
+
+// TestRetryPolicyContext tests that the RetryPolicy honors the context of the request. It tests the following
+// scenarios:
+//
+// 1. Canceled Context Is Not Retried.
+// 2. Context Canceled During The Wait.
+// 3. Deadline Before The Next Attempt Returns The Last Response.
+func TestRetryPolicyContext(t *testing.T) {
+	t.Run("Canceled Context Is Not Retried", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return((*http.Response)(nil), assert.AnError)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		clock := newFakeClock(time.Now())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/rates", nil)
+		require.NoError(t, err)
+
+		resp, err := client.NewRetryPolicy(3, time.Second, 10*time.Second, clock).Do(mockClient, req)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, resp)
+		assert.Empty(t, clock.Waits())
+		mockClient.AssertNumberOfCalls(t, "Do", 1)
+	})
+
+	t.Run("Context Canceled During The Wait", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		clock := newFakeClock(time.Now())
+		clock.blocked = true
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/rates", nil)
+		require.NoError(t, err)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		resp, err := client.NewRetryPolicy(3, time.Second, 10*time.Second, clock).Do(mockClient, req)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, resp)
+		assert.Len(t, clock.Waits(), 1)
+		mockClient.AssertNumberOfCalls(t, "Do", 1)
+	})
+	t.Run("Deadline Before The Next Attempt Returns The Last Response", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return(&http.Response{StatusCode: http.StatusServiceUnavailable,
+			Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		clock := newFakeClock(time.Now())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/rates", nil)
+		require.NoError(t, err)
+
+		resp, err := client.NewRetryPolicy(3, 2*time.Minute, 10*time.Minute, clock).Do(mockClient, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Empty(t, clock.Waits())
+		mockClient.AssertNumberOfCalls(t, "Do", 1)
+	})
+}
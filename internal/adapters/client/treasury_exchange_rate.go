@@ -1,11 +1,15 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
@@ -21,74 +25,348 @@ var json = jsoniter.ConfigCompatibleWithStandardLibrary
 // TreasuryExchangeRateAdapter interface defines the behavior for exchange rates fetching.
 // It allows flexibility to change the implementation of the Treasury API client for testing purposes.
 type TreasuryExchangeRateAdapter interface {
-	GetExchangeRates(currencyName string) ([]*domain.ExchangeRate, error)
+	GetExchangeRates(ctx context.Context, currencyName string) ([]*domain.ExchangeRate, error)
+	GetExchangeRatesBetween(ctx context.Context, currencyName string, from, to time.Time) ([]*domain.ExchangeRate, error)
+	GetExchangeRatesSince(ctx context.Context, since time.Time) ([]*domain.ExchangeRate, error)
+	GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error)
 }
 
-// HTTPClient just wraps te http.Client interface to make it easier to mock in tests.
+// HTTPClient just wraps te http.Client interface to make it easier to mock in tests. Requests carry their context,
+// so they are aborted when it is canceled or its deadline is exceeded.
 type HTTPClient interface {
-	Get(url string) (*http.Response, error)
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // Constants for the Treasury API. Change these if the API changes.
 const (
 	treasuryAPIEndpoint = "https://api.fiscaldata.treasury.gov/services/api/fiscal_service/v1/accounting/od/rates_of_exchange"
-	maxRetries          = 3
-	retryDelay          = 1 * time.Second
+	// exchangeRatesPageSize is the number of exchange rates requested per page for a currency.
+	exchangeRatesPageSize = 1000
+	// allExchangeRatesPageSize is the number of exchange rates requested per page for all the currencies.
+	allExchangeRatesPageSize = 10000
+	// maxPages bounds the number of pages followed for a request, in case the pagination never ends.
+	maxPages = 100
+	// currenciesLookback is how far back the supported currencies are looked up. Currencies without exchange rates
+	// since then are considered discontinued.
+	currenciesLookback = 2 * 365 * 24 * time.Hour
+	// currenciesCacheTTL is how long the supported currencies are cached. The Treasury publishes rates quarterly.
+	currenciesCacheTTL = 24 * time.Hour
 )
 
 // ConcreteTreasuryExchangeRateAdapter is the real implementation of TreasuryExchangeRateAdapter interface.
 type ConcreteTreasuryExchangeRateAdapter struct {
 	client      HTTPClient
 	apiEndpoint string
+	retryPolicy *RetryPolicy
+	// Local cache of the supported currencies, and the fetch shared by the requests while it is refreshed
+	currencies          []*domain.SupportedCurrency
+	currenciesFetchedAt time.Time
+	currenciesCall      *currenciesCall
+	currenciesMutex     sync.Mutex
+}
+
+// currenciesCall is a fetch of the supported currencies shared by all the concurrent requests for them.
+type currenciesCall struct {
+	done       chan struct{}
+	currencies []*domain.SupportedCurrency
+	err        error
 }
 
 // NewConcreteTreasuryExchangeRateAdapter creates a new ConcreteTreasuryExchangeRateAdapter with the given HTTPClient.
+// The requests are retried with the default retry policy.
 func NewConcreteTreasuryExchangeRateAdapter(client HTTPClient) *ConcreteTreasuryExchangeRateAdapter {
 	return &ConcreteTreasuryExchangeRateAdapter{
 		client:      client,
 		apiEndpoint: treasuryAPIEndpoint,
+		retryPolicy: DefaultRetryPolicy(),
 	}
 }
 
-// GetExchangeRates retrieves all the exchange rates for a currency with input and response validations.
-func (a *ConcreteTreasuryExchangeRateAdapter) GetExchangeRates(currencyName string) ([]*domain.ExchangeRate, error) {
-	apiURL := buildRequestURL(a, currencyName)
+// WithRetryPolicy replaces the retry policy of the requests to the Treasury API, and returns the adapter.
+func (a *ConcreteTreasuryExchangeRateAdapter) WithRetryPolicy(retryPolicy *RetryPolicy) *ConcreteTreasuryExchangeRateAdapter {
+	a.retryPolicy = retryPolicy
+	return a
+}
 
-	// Retry mechanism
-	var resp *http.Response
-	var err error
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		resp, err = a.client.Get(apiURL)
-		if err == nil {
-			break
+// GetExchangeRates retrieves all the exchange rates for a currency with input and response validations, across all
+// the pages of the response. The currency can be given as an ISO 4217 code (e.g. "EUR"), as a Treasury
+// country-currency description (e.g. "Euro Zone-Euro") or as a Treasury currency name (e.g. "Euro").
+func (a *ConcreteTreasuryExchangeRateAdapter) GetExchangeRates(ctx context.Context, currencyName string) ([]*domain.ExchangeRate, error) {
+	filter, err := buildCurrencyFilter(currencyName)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.getExchangeRates(ctx, currencyName, func(page int) string {
+		return buildRequestURL(a, filter, page, exchangeRatesPageSize)
+	})
+}
+
+// GetExchangeRatesBetween retrieves the exchange rates for a currency with a date of record between two dates,
+// inclusive, across all the pages of the response. The currency is given as for GetExchangeRates.
+func (a *ConcreteTreasuryExchangeRateAdapter) GetExchangeRatesBetween(ctx context.Context, currencyName string,
+	from, to time.Time) ([]*domain.ExchangeRate, error) {
+	filter, err := buildCurrencyFilter(currencyName)
+	if err != nil {
+		return nil, err
+	}
+	filter += fmt.Sprintf(",record_date:gte:%s,record_date:lte:%s", from.Format(time.DateOnly), to.Format(time.DateOnly))
+
+	return a.getExchangeRates(ctx, currencyName, func(page int) string {
+		return buildRequestURL(a, filter, page, exchangeRatesPageSize)
+	})
+}
+
+// GetExchangeRatesSince retrieves the exchange rates of all the currencies with a date of record after the given
+// date, most recent first. It returns an empty slice when no exchange rate was published since then.
+func (a *ConcreteTreasuryExchangeRateAdapter) GetExchangeRatesSince(ctx context.Context, since time.Time) ([]*domain.ExchangeRate, error) {
+	exchangeRates, err := a.getExchangeRates(ctx, "", func(page int) string {
+		return buildRequestURL(a, "record_date:gt:"+since.Format(time.DateOnly), page, allExchangeRatesPageSize)
+	})
+	if errors.Is(err, ErrExchangeRateNotFound) {
+		return []*domain.ExchangeRate{}, nil
+	}
+	return exchangeRates, err
+}
+
+// getExchangeRates retrieves the exchange rates of all the pages of a Treasury API request. It returns
+// ErrExchangeRateNotFound when there are none.
+func (a *ConcreteTreasuryExchangeRateAdapter) getExchangeRates(ctx context.Context, currencyName string,
+	buildPageURL func(page int) string) ([]*domain.ExchangeRate, error) {
+	var exchangeRates []*domain.ExchangeRate
+	err := a.getPages(ctx, buildPageURL, func(resp *http.Response) (treasuryPagination, error) {
+		pageExchangeRates, pagination, err := processResponsePage(resp, currencyName)
+		exchangeRates = append(exchangeRates, pageExchangeRates...)
+		return pagination, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exchangeRates) == 0 {
+		log.Error().Str("currency", currencyName).Msg("no data found in API response")
+		return nil, ErrExchangeRateNotFound
+	}
+	return exchangeRates, nil
+}
+
+// GetCurrencies retrieves the currencies with exchange rates published since the lookback period, with the date of
+// their latest exchange rate. The currencies are cached locally to avoid calling the Treasury API on every request.
+// Concurrent requests share one fetch, which runs in the background like the ones of the exchange rate cache, and
+// each request stops waiting for it when its own context is done.
+func (a *ConcreteTreasuryExchangeRateAdapter) GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	a.currenciesMutex.Lock()
+	if a.currencies != nil && time.Since(a.currenciesFetchedAt) < currenciesCacheTTL {
+		currencies := a.currencies
+		a.currenciesMutex.Unlock()
+		return currencies, nil
+	}
+
+	// Joins the fetch already running, or starts it
+	call := a.currenciesCall
+	if call == nil {
+		call = &currenciesCall{done: make(chan struct{})}
+		a.currenciesCall = call
+		go a.fetchCurrencies(context.WithoutCancel(ctx), call)
+	}
+	a.currenciesMutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.currencies, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchCurrencies makes the fetch of the supported currencies shared by the requests, caches its result and wakes up
+// the requests waiting for it. The lock is not held during the fetch.
+func (a *ConcreteTreasuryExchangeRateAdapter) fetchCurrencies(ctx context.Context, call *currenciesCall) {
+	ctx, cancel := context.WithTimeout(ctx, exchangeRateCacheFetchTimeout)
+	defer cancel()
+
+	call.currencies, call.err = a.requestCurrencies(ctx)
+
+	a.currenciesMutex.Lock()
+	a.currenciesCall = nil
+	if call.err == nil {
+		a.currencies = call.currencies
+		a.currenciesFetchedAt = time.Now()
+	}
+	a.currenciesMutex.Unlock()
+	close(call.done)
+}
+
+// requestCurrencies requests the currencies with exchange rates published since the lookback period from the Treasury
+// API.
+func (a *ConcreteTreasuryExchangeRateAdapter) requestCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	since := time.Now().Add(-currenciesLookback)
+	var records []treasuryCurrencyRecord
+	err := a.getPages(ctx, func(page int) string {
+		return buildCurrenciesRequestURL(a, since, page)
+	}, func(resp *http.Response) (treasuryPagination, error) {
+		pageRecords, pagination, err := processCurrenciesResponsePage(resp)
+		records = append(records, pageRecords...)
+		return pagination, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	currencies, err := newSupportedCurrencies(records)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Int("currencies", len(currencies)).Msg("supported currencies fetched from Treasury API")
+
+	return currencies, nil
+}
+
+// getPages sends the GET requests of all the pages of a Treasury API request, from the first one, and processes their
+// responses in order. The pages are followed with the next link of the responses, or with their total number of
+// pages when they have no links.
+func (a *ConcreteTreasuryExchangeRateAdapter) getPages(ctx context.Context, buildPageURL func(page int) string,
+	processPage func(resp *http.Response) (treasuryPagination, error)) error {
+	for page := 1; page != 0; {
+		if page > maxPages {
+			log.Error().Int("max_pages", maxPages).Msg("too many pages in the Treasury API response")
+			return ErrTooManyPages
 		}
-		if attempt < maxRetries-1 {
-			log.Warn().Err(err).Int("attempt", attempt+1).Msg("retrying request due to transient network issue")
-			time.Sleep(retryDelay)
+
+		resp, err := a.get(ctx, buildPageURL(page))
+		if err != nil {
+			return err
+		}
+		pagination, err := processPage(resp)
+		if err != nil {
+			return err
 		}
+
+		nextPage := pagination.nextPage(page)
+		if nextPage != 0 {
+			log.Debug().Int("page", nextPage).Int("total_pages", pagination.Meta.TotalPages).Msg("fetching the next page from Treasury API")
+		}
+		page = nextPage
 	}
+	return nil
+}
+
+// get sends a GET request to the Treasury API, retried with the retry policy on transport errors and transient status
+// codes. The retries stop as soon as the context is canceled or its deadline is exceeded, and the context error is
+// returned.
+func (a *ConcreteTreasuryExchangeRateAdapter) get(ctx context.Context, apiURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		log.Error().Err(err).Msg("error fetching exchange rates from Treasury API")
+		log.Error().Err(err).Str("url", apiURL).Msg("error building the Treasury API request")
+		return nil, err
+	}
+
+	resp, err := a.retryPolicy.Do(a.client, req)
+	if ctx.Err() != nil {
+		log.Warn().Err(ctx.Err()).Msg("Treasury API request aborted")
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("error fetching data from Treasury API")
 		return nil, ErrNetworkIssue
 	}
 
-	return ProcessResponse(resp, currencyName)
+	return resp, nil
+}
+
+// buildCurrencyFilter returns the Treasury filter of the exchange rates of a currency.
+func buildCurrencyFilter(currencyName string) (string, error) {
+	filterField, filterValue, err := resolveCurrencyFilter(currencyName)
+	if err != nil {
+		log.Warn().Err(err).Str("currency", currencyName).Msg("cannot resolve the currency code")
+		return "", err
+	}
+	return filterField + ":eq:" + url.QueryEscape(filterValue), nil
+}
+
+// resolveCurrencyFilter returns the Treasury field and value used to filter the exchange rates of a currency. ISO
+// 4217 codes and country-currency descriptions filter on "country_currency_desc", other names on "currency".
+func resolveCurrencyFilter(currencyName string) (string, string, error) {
+	currencyName = strings.TrimSpace(currencyName)
+	if domain.IsCurrencyCode(currencyName) {
+		countryCurrencyDesc, err := domain.ResolveTreasuryCurrency(currencyName)
+		if err != nil {
+			return "", "", err
+		}
+		return "country_currency_desc", countryCurrencyDesc, nil
+	}
+	if _, ok := domain.TreasuryCurrencyCode(currencyName); ok {
+		return "country_currency_desc", currencyName, nil
+	}
+	return "currency", currencyName, nil
+}
+
+// buildRequestURL constructs the URL for a page of the Treasury API request of the exchange rates matching a filter,
+// most recent first.
+func buildRequestURL(a *ConcreteTreasuryExchangeRateAdapter, filter string, page, pageSize int) string {
+	return fmt.Sprintf("%s?&sort=-record_date&format=json&page[number]=%d&page[size]=%d"+
+		"&fields=country_currency_desc,currency,exchange_rate,record_date,record_calendar_day,record_calendar_month,record_calendar_year"+
+		"&filter=%s", a.apiEndpoint, page, pageSize, filter)
+}
+
+// buildCurrenciesRequestURL constructs the URL for a page of the Treasury API request of the distinct currencies and
+// dates of record since a given date, most recent first.
+func buildCurrenciesRequestURL(a *ConcreteTreasuryExchangeRateAdapter, since time.Time, page int) string {
+	return fmt.Sprintf("%s?&sort=-record_date&format=json&page[number]=%d&page[size]=%d"+
+		"&fields=country_currency_desc,currency,record_date"+
+		"&filter=record_date:gte:%s", a.apiEndpoint, page, allExchangeRatesPageSize, since.Format(time.DateOnly))
 }
 
-// buildRequestURL constructs the URL for the Treasury API request.
-func buildRequestURL(a *ConcreteTreasuryExchangeRateAdapter, currencyName string) string {
-	return fmt.Sprintf("%s?&sort=-record_date&format=json&page[number]=1&page[size]=1000"+
-		"&fields=currency,exchange_rate,record_date,record_calendar_day,record_calendar_month,record_calendar_year"+
-		"&filter=currency:eq:%s", a.apiEndpoint, url.QueryEscape(currencyName))
+// treasuryPagination holds the pagination sections of a Treasury API response. The links are query strings relative
+// to the request (e.g. "&page%5Bnumber%5D=2&page%5Bsize%5D=1000"), and null when there is no such page.
+type treasuryPagination struct {
+	Meta struct {
+		TotalPages int `json:"total-pages"`
+	} `json:"meta"`
+	Links struct {
+		Next *string `json:"next"`
+	} `json:"links"`
+}
+
+// nextPage returns the number of the page following the given one, or 0 when it is the last page. The next link is
+// followed when there is one, and the total number of pages is used otherwise.
+func (p treasuryPagination) nextPage(page int) int {
+	if p.Links.Next != nil {
+		values, err := url.ParseQuery(strings.TrimPrefix(*p.Links.Next, "&"))
+		if err == nil {
+			if nextPage, err := strconv.Atoi(values.Get("page[number]")); err == nil && nextPage > page {
+				return nextPage
+			}
+		}
+		log.Warn().Str("next", *p.Links.Next).Msg("cannot read the next page link of the Treasury API response")
+	}
+	if page < p.Meta.TotalPages {
+		return page + 1
+	}
+	return 0
 }
 
 // ProcessResponse reads the response from the Treasury API, validates it, and returns a result.
 // An ExchangeRate slice and nil error if the response is valid. Otherwise, it returns a nil object and an error.
 func ProcessResponse(resp *http.Response, currencyName string) ([]*domain.ExchangeRate, error) {
+	exchangeRates, _, err := processResponsePage(resp, currencyName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exchangeRates) == 0 {
+		log.Error().Str("currency", currencyName).Msg("no data found in API response")
+		return nil, ErrExchangeRateNotFound
+	}
+	return exchangeRates, nil
+}
+
+// processResponsePage reads a page of exchange rates from the Treasury API, validates it, and returns its exchange
+// rates with its pagination. A page without exchange rates is valid.
+func processResponsePage(resp *http.Response, currencyName string) ([]*domain.ExchangeRate, treasuryPagination, error) {
 	// Checks the response status code after a successful request
 	if resp.StatusCode != http.StatusOK {
 		log.Error().Int("status_code", resp.StatusCode).Str("currency", currencyName).Msg("unexpected API response")
-		return nil, ErrTreasuryAPIResponse
+		return nil, treasuryPagination{}, ErrTreasuryAPIResponse
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -98,40 +376,130 @@ func ProcessResponse(resp *http.Response, currencyName string) ([]*domain.Exchan
 
 	var data struct {
 		Data []struct {
-			Currency     string `json:"currency"`
-			ExchangeRate string `json:"exchange_rate"`
-			RecordDay    string `json:"record_calendar_day"`
-			RecordMonth  string `json:"record_calendar_month"`
-			RecordYear   string `json:"record_calendar_year"`
+			CountryCurrencyDesc string `json:"country_currency_desc"`
+			Currency            string `json:"currency"`
+			ExchangeRate        string `json:"exchange_rate"`
+			RecordDay           string `json:"record_calendar_day"`
+			RecordMonth         string `json:"record_calendar_month"`
+			RecordYear          string `json:"record_calendar_year"`
 		} `json:"data"`
+		treasuryPagination
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
 		log.Error().Err(err).Msg("error decoding API response")
-		return nil, ErrDecodingResponse
-	}
-
-	if len(data.Data) == 0 {
-		log.Error().Str("currency", currencyName).Msg("no data found in API response")
-		return nil, ErrExchangeRateNotFound
+		return nil, treasuryPagination{}, ErrDecodingResponse
 	}
 
-	var exchangeRates []*domain.ExchangeRate
+	exchangeRates := make([]*domain.ExchangeRate, 0, len(data.Data))
 	// Loops through all the data and parse each exchange rate
 	for _, item := range data.Data {
 		dayOfRecord, monthOfRecord, yearOfRecord := item.RecordDay, item.RecordMonth, item.RecordYear
 		dateOfRecord, err := ParseDateFromResponse(dayOfRecord, monthOfRecord, yearOfRecord)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing exchange rate date of record (day: %s, month: %s, year: %s): %w",
+			return nil, treasuryPagination{}, fmt.Errorf("error parsing exchange rate date of record (day: %s, month: %s, year: %s): %w",
 				dayOfRecord, monthOfRecord, yearOfRecord, ErrParsingExchangeRateDateOfRecord)
 		}
 
-		rate, err := strconv.ParseFloat(item.ExchangeRate, 64)
+		// The rate is kept as the exact decimal string published by the Treasury
+		if _, err := domain.ParseDecimal(item.ExchangeRate); err != nil {
+			return nil, treasuryPagination{}, ErrInvalidExchangeRate
+		}
+
+		exchangeRate, errs := domain.NewExchangeRate(item.Currency, item.ExchangeRate, dateOfRecord)
+
+		// If there are errors, join them into one and return
+		if len(errs) > 0 {
+			var errMessages []string
+			for _, e := range errs {
+				errMessages = append(errMessages, e.Error())
+			}
+			return nil, treasuryPagination{}, fmt.Errorf("validation errors: %s", strings.Join(errMessages, ", "))
+		}
+
+		exchangeRate.Source = domain.ExchangeRateSourceTreasury
+
+		// Resolves the ISO 4217 code used to round the converted amounts, from the country-currency description
+		// or from the currency itself when it already holds one (e.g. "Brazil-Real")
+		exchangeRate.CountryCurrencyDesc = strings.TrimSpace(item.CountryCurrencyDesc)
+		if code, ok := domain.TreasuryCurrencyCode(item.CountryCurrencyDesc); ok {
+			exchangeRate.CurrencyCode = code
+		} else if code, ok := domain.TreasuryCurrencyCode(item.Currency); ok {
+			if exchangeRate.CountryCurrencyDesc == "" {
+				exchangeRate.CountryCurrencyDesc = exchangeRate.CurrencyName
+			}
+			exchangeRate.CurrencyCode = code
+		} else {
+			log.Debug().Str("currency", item.Currency).Msg("no ISO 4217 code found for the currency")
+		}
+
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+
+	return exchangeRates, data.treasuryPagination, nil
+}
+
+// ProcessCurrenciesResponse reads the distinct currencies and dates of record from the Treasury API, validates them,
+// and returns the supported currencies sorted by country-currency name, each with its latest date of record.
+func ProcessCurrenciesResponse(resp *http.Response) ([]*domain.SupportedCurrency, error) {
+	records, _, err := processCurrenciesResponsePage(resp)
+	if err != nil {
+		return nil, err
+	}
+	return newSupportedCurrencies(records)
+}
+
+// treasuryCurrencyRecord is a currency and one of its dates of record, as returned by the Treasury API.
+type treasuryCurrencyRecord struct {
+	CountryCurrencyDesc string `json:"country_currency_desc"`
+	Currency            string `json:"currency"`
+	RecordDate          string `json:"record_date"`
+}
+
+// processCurrenciesResponsePage reads a page of currencies and dates of record from the Treasury API, and returns
+// them with the pagination of the page.
+func processCurrenciesResponsePage(resp *http.Response) ([]treasuryCurrencyRecord, treasuryPagination, error) {
+	// Checks the response status code after a successful request
+	if resp.StatusCode != http.StatusOK {
+		log.Error().Int("status_code", resp.StatusCode).Msg("unexpected API response")
+		return nil, treasuryPagination{}, ErrTreasuryAPIResponse
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Error().Err(err).Msg("error closing response body")
+		}
+	}()
+
+	var data struct {
+		Data []treasuryCurrencyRecord `json:"data"`
+		treasuryPagination
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		log.Error().Err(err).Msg("error decoding API response")
+		return nil, treasuryPagination{}, ErrDecodingResponse
+	}
+	return data.Data, data.treasuryPagination, nil
+}
+
+// newSupportedCurrencies validates the currencies and dates of record from the Treasury API, and returns the supported
+// currencies sorted by country-currency name, each with its latest date of record.
+func newSupportedCurrencies(records []treasuryCurrencyRecord) ([]*domain.SupportedCurrency, error) {
+	// Keeps the latest date of record of each currency
+	currenciesByDesc := make(map[string]*domain.SupportedCurrency)
+	for _, item := range records {
+		dateOfRecord, err := time.Parse(time.DateOnly, strings.TrimSpace(item.RecordDate))
 		if err != nil {
-			return nil, ErrInvalidExchangeRate
+			return nil, fmt.Errorf("error parsing currency date of record (%s): %w", item.RecordDate,
+				ErrParsingExchangeRateDateOfRecord)
+		}
+
+		if current, ok := currenciesByDesc[item.CountryCurrencyDesc]; ok && !dateOfRecord.After(current.LatestDateOfRecord) {
+			continue
 		}
 
-		exchangeRate, errs := domain.NewExchangeRate(item.Currency, rate, dateOfRecord)
+		currencyCode, _ := domain.TreasuryCurrencyCode(item.CountryCurrencyDesc)
+		supportedCurrency, errs := domain.NewSupportedCurrency(item.CountryCurrencyDesc, item.Currency, currencyCode, dateOfRecord)
 
 		// If there are errors, join them into one and return
 		if len(errs) > 0 {
@@ -142,10 +510,18 @@ func ProcessResponse(resp *http.Response, currencyName string) ([]*domain.Exchan
 			return nil, fmt.Errorf("validation errors: %s", strings.Join(errMessages, ", "))
 		}
 
-		exchangeRates = append(exchangeRates, exchangeRate)
+		currenciesByDesc[supportedCurrency.CountryCurrencyDesc] = supportedCurrency
 	}
 
-	return exchangeRates, nil
+	currencies := make([]*domain.SupportedCurrency, 0, len(currenciesByDesc))
+	for _, supportedCurrency := range currenciesByDesc {
+		currencies = append(currencies, supportedCurrency)
+	}
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].CountryCurrencyDesc < currencies[j].CountryCurrencyDesc
+	})
+
+	return currencies, nil
 }
 
 // ParseDateFromResponse takes day, month, and year of record as strings, validates them,
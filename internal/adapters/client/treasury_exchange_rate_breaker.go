@@ -0,0 +1,264 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains a circuit breaker decorating any TreasuryExchangeRateAdapter implementation, so requests fail
+// fast while the Treasury API is down.
+
+// Default settings of the circuit breaker.
+const (
+	DefaultCircuitBreakerFailureThreshold = 5
+	DefaultCircuitBreakerSuccessThreshold = 1
+	DefaultCircuitBreakerOpenTimeout      = 30 * time.Second
+)
+
+// probeRetryAfter is the delay suggested to the requests rejected while the probe of a half-open circuit is running.
+const probeRetryAfter = 1 * time.Second
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState string
+
+// States of a circuit breaker.
+const (
+	// CircuitClosed lets the calls through and counts their consecutive failures.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects the calls until the open timeout has elapsed.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets one probe call through at a time to find out whether the upstream has recovered.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitOpenError is returned without calling the decorated adapter while the circuit is open. It wraps
+// domain.ErrExchangeRatesUnavailable and tells when to retry.
+type CircuitOpenError struct {
+	retryAfter time.Duration
+}
+
+// Error returns the message of the error.
+func (e *CircuitOpenError) Error() string {
+	return "circuit breaker open: " + domain.ErrExchangeRatesUnavailable.Error()
+}
+
+// Unwrap returns domain.ErrExchangeRatesUnavailable.
+func (e *CircuitOpenError) Unwrap() error {
+	return domain.ErrExchangeRatesUnavailable
+}
+
+// RetryAfter returns the time left before the circuit lets a call through again.
+func (e *CircuitOpenError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// CircuitBreakerStats holds the state and counters of the circuit breaker.
+type CircuitBreakerStats struct {
+	// State is the current state of the circuit.
+	State CircuitState `json:"state"`
+	// ConsecutiveFailures counts the failed calls since the last successful one while the circuit is closed.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// OpenedAt is the time the circuit last opened, while it is open or half-open.
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+	// Trips counts the times the circuit opened.
+	Trips uint64 `json:"trips"`
+	// Rejections counts the calls rejected without calling the decorated adapter.
+	Rejections uint64 `json:"rejections"`
+}
+
+// CircuitBreakerTreasuryExchangeRateAdapter guards the calls to another TreasuryExchangeRateAdapter. The circuit opens
+// after a number of consecutive upstream failures, and the calls are then rejected with a CircuitOpenError until the
+// open timeout has elapsed. The circuit is then half-open: one probe call at a time goes through, and the circuit
+// closes after enough successful probes or opens again on a failed one. A call that used up its deadline is a failure
+// of the upstream, while calls canceled by their caller and answers without data are not.
+type CircuitBreakerTreasuryExchangeRateAdapter struct {
+	next             TreasuryExchangeRateAdapter
+	failureThreshold int
+	successThreshold int
+	openTimeout      time.Duration
+	clock            Clock
+
+	mutex                sync.Mutex
+	state                CircuitState
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	openedAt             time.Time
+	probing              bool
+	trips                uint64
+	rejections           uint64
+}
+
+// NewCircuitBreakerTreasuryExchangeRateAdapter creates a new CircuitBreakerTreasuryExchangeRateAdapter decorating the
+// given adapter, with a closed circuit. Non-positive settings fall back to DefaultCircuitBreakerFailureThreshold,
+// DefaultCircuitBreakerSuccessThreshold and DefaultCircuitBreakerOpenTimeout, and a nil clock to the system clock.
+func NewCircuitBreakerTreasuryExchangeRateAdapter(next TreasuryExchangeRateAdapter, failureThreshold,
+	successThreshold int, openTimeout time.Duration, clock Clock) *CircuitBreakerTreasuryExchangeRateAdapter {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultCircuitBreakerFailureThreshold
+	}
+	if successThreshold <= 0 {
+		successThreshold = DefaultCircuitBreakerSuccessThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = DefaultCircuitBreakerOpenTimeout
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &CircuitBreakerTreasuryExchangeRateAdapter{
+		next:             next,
+		failureThreshold: failureThreshold,
+		successThreshold: successThreshold,
+		openTimeout:      openTimeout,
+		clock:            clock,
+		state:            CircuitClosed,
+	}
+}
+
+// GetExchangeRates retrieves the exchange rates of a currency from the decorated adapter, unless the circuit is open.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) GetExchangeRates(ctx context.Context,
+	currencyName string) ([]*domain.ExchangeRate, error) {
+	return guard(ctx, b, func() ([]*domain.ExchangeRate, error) {
+		return b.next.GetExchangeRates(ctx, currencyName)
+	})
+}
+
+// GetExchangeRatesBetween retrieves the exchange rates of a currency between two dates from the decorated adapter,
+// unless the circuit is open.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) GetExchangeRatesBetween(ctx context.Context, currencyName string,
+	from, to time.Time) ([]*domain.ExchangeRate, error) {
+	return guard(ctx, b, func() ([]*domain.ExchangeRate, error) {
+		return b.next.GetExchangeRatesBetween(ctx, currencyName, from, to)
+	})
+}
+
+// GetExchangeRatesSince retrieves the exchange rates published after a date from the decorated adapter, unless the
+// circuit is open.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) GetExchangeRatesSince(ctx context.Context,
+	since time.Time) ([]*domain.ExchangeRate, error) {
+	return guard(ctx, b, func() ([]*domain.ExchangeRate, error) {
+		return b.next.GetExchangeRatesSince(ctx, since)
+	})
+}
+
+// GetCurrencies retrieves the supported currencies from the decorated adapter, unless the circuit is open.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	return guard(ctx, b, func() ([]*domain.SupportedCurrency, error) {
+		return b.next.GetCurrencies(ctx)
+	})
+}
+
+// Stats returns a snapshot of the state and counters of the circuit breaker.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) Stats() CircuitBreakerStats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	stats := CircuitBreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		Trips:               b.trips,
+		Rejections:          b.rejections,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
+
+// guard calls the decorated adapter when the circuit lets the call through, and records its outcome.
+func guard[T any](ctx context.Context, b *CircuitBreakerTreasuryExchangeRateAdapter, call func() (T, error)) (T, error) {
+	probe, err := b.allow()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	result, err := call()
+	b.record(ctx, probe, err)
+	return result, err
+}
+
+// allow reports whether a call can go through, and whether it is the probe of a half-open circuit. The open circuit
+// turns half-open once the open timeout has elapsed.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) allow() (bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		elapsed := b.clock.Now().Sub(b.openedAt)
+		if elapsed < b.openTimeout {
+			b.rejections++
+			return false, &CircuitOpenError{retryAfter: b.openTimeout - elapsed}
+		}
+		b.state = CircuitHalfOpen
+		b.consecutiveSuccesses = 0
+		log.Info().Msg("exchange rate circuit breaker half-open, probing the upstream")
+		fallthrough
+	case CircuitHalfOpen:
+		if b.probing {
+			b.rejections++
+			return false, &CircuitOpenError{retryAfter: probeRetryAfter}
+		}
+		b.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record updates the circuit with the outcome of a call. Only the outcome of the probe changes a half-open circuit.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) record(ctx context.Context, probe bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		// The call was canceled by its caller, which tells nothing about the upstream
+		return
+	}
+	// A call that used up its deadline waited on a slow or hanging upstream
+	failed := isUpstreamFailure(err) || (err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded))
+
+	switch {
+	case b.state == CircuitClosed && failed:
+		b.consecutiveFailures++
+		if b.consecutiveFailures >= b.failureThreshold {
+			b.trip(err)
+		}
+	case b.state == CircuitClosed:
+		b.consecutiveFailures = 0
+	case b.state == CircuitHalfOpen && probe && failed:
+		b.trip(err)
+	case b.state == CircuitHalfOpen && probe:
+		b.consecutiveSuccesses++
+		if b.consecutiveSuccesses >= b.successThreshold {
+			b.state = CircuitClosed
+			b.consecutiveFailures = 0
+			log.Info().Msg("exchange rate circuit breaker closed, the upstream has recovered")
+		}
+	}
+}
+
+// trip opens the circuit. The caller must hold the mutex.
+func (b *CircuitBreakerTreasuryExchangeRateAdapter) trip(err error) {
+	b.state = CircuitOpen
+	b.openedAt = b.clock.Now()
+	b.trips++
+	log.Warn().Err(err).Dur("open_timeout", b.openTimeout).Msg("exchange rate circuit breaker open")
+}
+
+// isUpstreamFailure reports whether an error of the decorated adapter is caused by the Treasury API being unreachable
+// or failing, as opposed to a request without data.
+func isUpstreamFailure(err error) bool {
+	return errors.Is(err, ErrNetworkIssue) || errors.Is(err, ErrTreasuryAPIResponse) ||
+		errors.Is(err, ErrDecodingResponse)
+}
@@ -0,0 +1,268 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the circuit breaker decorating the TreasuryExchangeRateAdapter. It uses Table Driven
+// Tests to test different scenarios. It uses Testify for assertions and mocking, and runs the tests in parallel. The
+// open timeout elapses on a fake clock.
+
+// breakerStep is a call through the circuit breaker, with the outcome of the decorated adapter.
+type breakerStep struct {
+	// advance moves the clock forward before the call
+	advance time.Duration
+	// upstreamError is the error of the decorated adapter, which is not called when the call is rejected
+	upstreamError error
+	rejected      bool
+	expectedState client.CircuitState
+}
+
+// TestCircuitBreakerTreasuryExchangeRateAdapter tests the CircuitBreakerTreasuryExchangeRateAdapter. It tests the
+// following scenarios:
+//
+// 1. Opens After The Failure Threshold.
+// 2. Success Resets The Consecutive Failures.
+// 3. Answers Without Data Are Not Failures.
+// 4. Successful Probe Closes The Circuit.
+// 5. Failed Probe Opens The Circuit Again.
+// 6. Several Successful Probes Needed.
+func TestCircuitBreakerTreasuryExchangeRateAdapter(t *testing.T) {
+	tests := []struct {
+		name             string
+		failureThreshold int
+		successThreshold int
+		steps            []breakerStep
+	}{
+		{
+			name:             "Opens After The Failure Threshold",
+			failureThreshold: 2,
+			steps: []breakerStep{
+				{upstreamError: client.ErrNetworkIssue, expectedState: client.CircuitClosed},
+				{upstreamError: client.ErrTreasuryAPIResponse, expectedState: client.CircuitOpen},
+				{rejected: true, expectedState: client.CircuitOpen},
+				{advance: 29 * time.Second, rejected: true, expectedState: client.CircuitOpen},
+			},
+		},
+		{
+			name:             "Success Resets The Consecutive Failures",
+			failureThreshold: 2,
+			steps: []breakerStep{
+				{upstreamError: client.ErrNetworkIssue, expectedState: client.CircuitClosed},
+				{expectedState: client.CircuitClosed},
+				{upstreamError: client.ErrNetworkIssue, expectedState: client.CircuitClosed},
+			},
+		},
+		{
+			name:             "Answers Without Data Are Not Failures",
+			failureThreshold: 1,
+			steps: []breakerStep{
+				{upstreamError: client.ErrExchangeRateNotFound, expectedState: client.CircuitClosed},
+				{upstreamError: domain.ErrUnknownCurrencyCode, expectedState: client.CircuitClosed},
+			},
+		},
+		{
+			name:             "Successful Probe Closes The Circuit",
+			failureThreshold: 1,
+			steps: []breakerStep{
+				{upstreamError: client.ErrNetworkIssue, expectedState: client.CircuitOpen},
+				{advance: 30 * time.Second, expectedState: client.CircuitClosed},
+				{expectedState: client.CircuitClosed},
+			},
+		},
+		{
+			name:             "Failed Probe Opens The Circuit Again",
+			failureThreshold: 1,
+			steps: []breakerStep{
+				{upstreamError: client.ErrNetworkIssue, expectedState: client.CircuitOpen},
+				{advance: 30 * time.Second, upstreamError: client.ErrDecodingResponse, expectedState: client.CircuitOpen},
+				{advance: 29 * time.Second, rejected: true, expectedState: client.CircuitOpen},
+			},
+		},
+		{
+			name:             "Several Successful Probes Needed",
+			failureThreshold: 1,
+			successThreshold: 2,
+			steps: []breakerStep{
+				{upstreamError: client.ErrNetworkIssue, expectedState: client.CircuitOpen},
+				{advance: 30 * time.Second, expectedState: client.CircuitHalfOpen},
+				{expectedState: client.CircuitClosed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			clock := newFakeClock(time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC))
+			mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+			breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(mockAdapter, tt.failureThreshold,
+				tt.successThreshold, 30*time.Second, clock)
+
+			calls := 0
+			for i, step := range tt.steps {
+				clock.Advance(step.advance)
+				if !step.rejected {
+					mockAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency(nil), step.upstreamError).Once()
+					calls++
+				}
+
+				_, err := breaker.GetCurrencies(context.Background())
+
+				if step.rejected {
+					var circuitOpenError *client.CircuitOpenError
+					require.ErrorAs(t, err, &circuitOpenError, "step %d", i+1)
+					assert.ErrorIs(t, err, domain.ErrExchangeRatesUnavailable)
+					assert.Greater(t, circuitOpenError.RetryAfter(), time.Duration(0))
+				} else {
+					assert.Equal(t, step.upstreamError, err, "step %d", i+1)
+				}
+				assert.Equal(t, step.expectedState, breaker.Stats().State, "step %d", i+1)
+			}
+			mockAdapter.AssertNumberOfCalls(t, "GetCurrencies", calls)
+		})
+	}
+}
+
+// TestCircuitBreakerTreasuryExchangeRateAdapterCalls tests the calls guarded by the
+// CircuitBreakerTreasuryExchangeRateAdapter. It tests the following scenarios:
+//
+// 1. Retry After Counts Down The Open Timeout.
+// 2. Canceled Requests Are Not Failures.
+// 3. Hanging Upstream Past The Deadline Is A Failure.
+// 4. One Probe At A Time.
+// 5. Stats Report The Open Circuit.
+func TestCircuitBreakerTreasuryExchangeRateAdapterCalls(t *testing.T) {
+	t.Run("Retry After Counts Down The Open Timeout", func(t *testing.T) {
+		t.Parallel()
+		clock := newFakeClock(time.Now())
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue).Once()
+		breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(mockAdapter, 1, 1, time.Minute, clock)
+
+		_, err := breaker.GetExchangeRates(context.Background(), "Real")
+		require.ErrorIs(t, err, client.ErrNetworkIssue)
+		clock.Advance(20 * time.Second)
+		_, err = breaker.GetExchangeRatesBetween(context.Background(), "Real", time.Now(), time.Now())
+
+		var circuitOpenError *client.CircuitOpenError
+		require.ErrorAs(t, err, &circuitOpenError)
+		assert.Equal(t, 40*time.Second, circuitOpenError.RetryAfter())
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Canceled Requests Are Not Failures", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRatesSince", mock.Anything).Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue)
+		breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(mockAdapter, 1, 1, time.Minute, newFakeClock(time.Now()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := breaker.GetExchangeRatesSince(ctx, time.Now())
+
+		assert.ErrorIs(t, err, client.ErrNetworkIssue)
+		assert.Equal(t, client.CircuitClosed, breaker.Stats().State)
+	})
+
+	t.Run("Hanging Upstream Past The Deadline Is A Failure", func(t *testing.T) {
+		t.Parallel()
+		// The server holds every request until the client gives up on it
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(&serverClient{server: server})
+		breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(treasuryAdapter, 1, 1, time.Minute,
+			newFakeClock(time.Now()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := breaker.GetExchangeRates(ctx, "Real")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, client.CircuitOpen, breaker.Stats().State)
+	})
+
+	t.Run("One Probe At A Time", func(t *testing.T) {
+		t.Parallel()
+		clock := newFakeClock(time.Now())
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency(nil), client.ErrNetworkIssue).Once()
+		breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(mockAdapter, 1, 1, time.Minute, clock)
+		_, err := breaker.GetCurrencies(context.Background())
+		require.ErrorIs(t, err, client.ErrNetworkIssue)
+		clock.Advance(time.Minute)
+
+		// The probe is held by the decorated adapter until the second call is rejected
+		probeStarted := make(chan struct{})
+		releaseProbe := make(chan struct{})
+		mockAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency{}, nil).Run(func(mock.Arguments) {
+			close(probeStarted)
+			<-releaseProbe
+		}).Once()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := breaker.GetCurrencies(context.Background())
+			assert.NoError(t, err)
+		}()
+		<-probeStarted
+
+		_, err = breaker.GetCurrencies(context.Background())
+		assert.ErrorIs(t, err, domain.ErrExchangeRatesUnavailable)
+		assert.Equal(t, client.CircuitHalfOpen, breaker.Stats().State)
+		close(releaseProbe)
+		wg.Wait()
+		assert.Equal(t, client.CircuitClosed, breaker.Stats().State)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Stats Report The Open Circuit", func(t *testing.T) {
+		t.Parallel()
+		now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency(nil), client.ErrNetworkIssue)
+		breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(mockAdapter, 2, 1, time.Minute, newFakeClock(now))
+
+		for range 3 {
+			_, _ = breaker.GetCurrencies(context.Background())
+		}
+
+		assert.Equal(t, client.CircuitBreakerStats{
+			State:               client.CircuitOpen,
+			ConsecutiveFailures: 2,
+			OpenedAt:            &now,
+			Trips:               1,
+			Rejections:          1,
+		}, breaker.Stats())
+	})
+}
+
+// serverClient is an HTTPClient sending every request to a test server instead of its host.
+type serverClient struct {
+	server *httptest.Server
+}
+
+// Do sends the request to the test server.
+func (c *serverClient) Do(req *http.Request) (*http.Response, error) {
+	serverURL, err := url.Parse(c.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Scheme, req.URL.Host = serverURL.Scheme, serverURL.Host
+	return c.server.Client().Do(req)
+}
@@ -0,0 +1,260 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains a read-through in-memory cache decorating any TreasuryExchangeRateAdapter implementation.
+
+// Default settings of the exchange rate cache. The Treasury publishes new exchange rates quarterly.
+const (
+	DefaultExchangeRateCacheTTL     = 1 * time.Hour
+	DefaultExchangeRateCacheEntries = 256
+)
+
+// exchangeRateCacheFetchTimeout bounds an upstream call shared by concurrent requests, which is not canceled with the
+// request that started it. It matches the timeout of the HTTP client of the Treasury API.
+const exchangeRateCacheFetchTimeout = 10 * time.Second
+
+// CacheStats holds the counters of the exchange rate cache.
+type CacheStats struct {
+	// Hits counts the requests answered from a fresh cache entry.
+	Hits uint64 `json:"hits"`
+	// Misses counts the requests that called the decorated adapter, including the ones sharing another call.
+	Misses uint64 `json:"misses"`
+	// StaleHits counts the requests answered from an expired cache entry because the decorated adapter failed.
+	StaleHits uint64 `json:"stale_hits"`
+	// Evictions counts the entries removed to stay within the size bound.
+	Evictions uint64 `json:"evictions"`
+	// Entries is the number of currencies and ranges of dates currently cached.
+	Entries int `json:"entries"`
+}
+
+// CachingTreasuryExchangeRateAdapter caches the exchange rates of each currency returned by another
+// TreasuryExchangeRateAdapter. Entries expire after the TTL of their currency, the least recently used entry is
+// evicted when the cache is full, concurrent requests for the same currency share one upstream call, and expired
+// entries are still served when the upstream call fails. The names of a currency (e.g. "EUR" and "Euro Zone-Euro")
+// share the same entry.
+type CachingTreasuryExchangeRateAdapter struct {
+	next       TreasuryExchangeRateAdapter
+	ttl        time.Duration
+	maxEntries int
+	// currencyTTLs overrides the TTL of the entries of some currencies, by cache key of the currency
+	currencyTTLs map[string]time.Duration
+
+	mutex sync.Mutex
+	// entries indexes the elements of the recency list (most recent first) by currency, or currency and range of dates
+	entries  map[string]*list.Element
+	recency  *list.List
+	inFlight map[string]*exchangeRateCall
+	stats    CacheStats
+}
+
+// exchangeRateCacheEntry is a cached list of exchange rates with its expiration time, set from the TTL of its
+// currency.
+type exchangeRateCacheEntry struct {
+	key           string
+	exchangeRates []*domain.ExchangeRate
+	expiresAt     time.Time
+}
+
+// exchangeRateCall is an upstream call shared by all the concurrent requests for the same cache key.
+type exchangeRateCall struct {
+	done          chan struct{}
+	exchangeRates []*domain.ExchangeRate
+	err           error
+}
+
+// NewCachingTreasuryExchangeRateAdapter creates a new CachingTreasuryExchangeRateAdapter decorating the given
+// adapter. Non-positive TTL and size fall back to DefaultExchangeRateCacheTTL and DefaultExchangeRateCacheEntries.
+func NewCachingTreasuryExchangeRateAdapter(next TreasuryExchangeRateAdapter, ttl time.Duration,
+	maxEntries int) *CachingTreasuryExchangeRateAdapter {
+	if ttl <= 0 {
+		ttl = DefaultExchangeRateCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultExchangeRateCacheEntries
+	}
+	return &CachingTreasuryExchangeRateAdapter{
+		next:         next,
+		ttl:          ttl,
+		maxEntries:   maxEntries,
+		currencyTTLs: make(map[string]time.Duration),
+		entries:      make(map[string]*list.Element),
+		recency:      list.New(),
+		inFlight:     make(map[string]*exchangeRateCall),
+	}
+}
+
+// WithCurrencyTTL replaces the TTL of the entries of a currency, given by any of its names, and returns the adapter.
+// A non-positive TTL restores the TTL of the other currencies. The entries already cached keep their expiration time.
+func (c *CachingTreasuryExchangeRateAdapter) WithCurrencyTTL(currencyName string,
+	ttl time.Duration) *CachingTreasuryExchangeRateAdapter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if ttl <= 0 {
+		delete(c.currencyTTLs, exchangeRateCacheKey(currencyName))
+		return c
+	}
+	c.currencyTTLs[exchangeRateCacheKey(currencyName)] = ttl
+	return c
+}
+
+// GetExchangeRates retrieves the exchange rates of a currency from the cache, or from the decorated adapter when
+// they are not cached or expired. A request sharing the upstream call of another one stops waiting when its own
+// context is done.
+func (c *CachingTreasuryExchangeRateAdapter) GetExchangeRates(ctx context.Context, currencyName string) ([]*domain.ExchangeRate, error) {
+	currency := exchangeRateCacheKey(currencyName)
+	return c.get(ctx, currency, currency, func(ctx context.Context) ([]*domain.ExchangeRate, error) {
+		return c.next.GetExchangeRates(ctx, currencyName)
+	})
+}
+
+// GetExchangeRatesBetween retrieves the exchange rates of a currency between two dates from the cache, or from the
+// decorated adapter, like GetExchangeRates. Each range of dates is cached apart.
+func (c *CachingTreasuryExchangeRateAdapter) GetExchangeRatesBetween(ctx context.Context, currencyName string,
+	from, to time.Time) ([]*domain.ExchangeRate, error) {
+	currency := exchangeRateCacheKey(currencyName)
+	key := currency + "|" + from.Format(time.DateOnly) + "|" + to.Format(time.DateOnly)
+	return c.get(ctx, currency, key, func(ctx context.Context) ([]*domain.ExchangeRate, error) {
+		return c.next.GetExchangeRatesBetween(ctx, currencyName, from, to)
+	})
+}
+
+// get retrieves the exchange rates of a cache key of a currency from the cache, or with the upstream call when they are
+// not cached or expired. Concurrent requests for the same key share one upstream call. The upstream call runs in the
+// background, bounded by exchangeRateCacheFetchTimeout, so it is neither canceled nor cut short by the deadline of
+// the request that started it, and every request waits for it with its own context.
+func (c *CachingTreasuryExchangeRateAdapter) get(ctx context.Context, currency, key string,
+	fetch func(ctx context.Context) ([]*domain.ExchangeRate, error)) ([]*domain.ExchangeRate, error) {
+
+	c.mutex.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*exchangeRateCacheEntry)
+		if time.Now().Before(entry.expiresAt) {
+			c.recency.MoveToFront(element)
+			c.stats.Hits++
+			c.mutex.Unlock()
+			return entry.exchangeRates, nil
+		}
+	}
+	c.stats.Misses++
+
+	// Joins the upstream call already running for the currency, or starts it
+	call, ok := c.inFlight[key]
+	if !ok {
+		call = &exchangeRateCall{done: make(chan struct{})}
+		c.inFlight[key] = call
+		go c.fetch(context.WithoutCancel(ctx), currency, key, call, fetch)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return c.resultOrStale(key, call)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch makes the upstream call shared by the requests for a cache key, caches its result and wakes up the requests
+// waiting for it.
+func (c *CachingTreasuryExchangeRateAdapter) fetch(ctx context.Context, currency, key string, call *exchangeRateCall,
+	fetch func(ctx context.Context) ([]*domain.ExchangeRate, error)) {
+	ctx, cancel := context.WithTimeout(ctx, exchangeRateCacheFetchTimeout)
+	defer cancel()
+
+	call.exchangeRates, call.err = fetch(ctx)
+
+	c.mutex.Lock()
+	delete(c.inFlight, key)
+	if call.err == nil {
+		c.store(currency, key, call.exchangeRates)
+	}
+	c.mutex.Unlock()
+	close(call.done)
+}
+
+// GetExchangeRatesSince retrieves the exchange rates published after a date from the decorated adapter. They are
+// not cached since they are only used to synchronize the local store.
+func (c *CachingTreasuryExchangeRateAdapter) GetExchangeRatesSince(ctx context.Context, since time.Time) ([]*domain.ExchangeRate, error) {
+	return c.next.GetExchangeRatesSince(ctx, since)
+}
+
+// GetCurrencies retrieves the supported currencies from the decorated adapter.
+func (c *CachingTreasuryExchangeRateAdapter) GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	return c.next.GetCurrencies(ctx)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *CachingTreasuryExchangeRateAdapter) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.recency.Len()
+	return stats
+}
+
+// resultOrStale returns the result of an upstream call or, when it failed, the expired cache entry of the currency.
+func (c *CachingTreasuryExchangeRateAdapter) resultOrStale(key string, call *exchangeRateCall) ([]*domain.ExchangeRate, error) {
+	if call.err == nil {
+		return call.exchangeRates, nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.stats.StaleHits++
+		log.Warn().Err(call.err).Str("currency", key).Msg("serving stale exchange rates after an upstream error")
+		return element.Value.(*exchangeRateCacheEntry).exchangeRates, nil
+	}
+	return nil, call.err
+}
+
+// exchangeRateCacheKey returns the cache key of a currency: its Treasury country-currency description when the
+// currency is known, so its code, description and name share the same entry, or the trimmed name otherwise.
+func exchangeRateCacheKey(currencyName string) string {
+	if countryCurrencyDesc, err := domain.ResolveCountryCurrencyDesc(currencyName); err == nil && countryCurrencyDesc != "" {
+		return countryCurrencyDesc
+	}
+	return strings.TrimSpace(currencyName)
+}
+
+// store caches the exchange rates of a cache key of a currency until the TTL of the currency has elapsed, and evicts
+// the least recently used entries beyond the size bound. The caller must hold the mutex.
+func (c *CachingTreasuryExchangeRateAdapter) store(currency, key string, exchangeRates []*domain.ExchangeRate) {
+	ttl, ok := c.currencyTTLs[currency]
+	if !ok {
+		ttl = c.ttl
+	}
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*exchangeRateCacheEntry)
+		entry.exchangeRates = exchangeRates
+		entry.expiresAt = expiresAt
+		c.recency.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.recency.PushFront(&exchangeRateCacheEntry{
+		key:           key,
+		exchangeRates: exchangeRates,
+		expiresAt:     expiresAt,
+	})
+	for c.recency.Len() > c.maxEntries {
+		oldest := c.recency.Back()
+		c.recency.Remove(oldest)
+		delete(c.entries, oldest.Value.(*exchangeRateCacheEntry).key)
+		c.stats.Evictions++
+	}
+}
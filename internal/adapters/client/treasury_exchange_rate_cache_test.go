@@ -0,0 +1,267 @@
+package client_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the read-through in-memory cache of exchange rates.
+// It uses Testify for assertions and mocking, and runs the tests in parallel.
+
+// TestCachingTreasuryExchangeRateAdapter tests the CachingTreasuryExchangeRateAdapter. It tests the following
+// scenarios:
+//
+// 1. Second Request Is A Hit.
+// 2. Ranges Of Dates Are Cached Apart.
+// 3. Expired Entry Is Fetched Again.
+// 4. Currency TTL Overrides The TTL Of The Cache.
+// 5. Least Recently Used Entry Is Evicted.
+// 6. Concurrent Requests Share One Upstream Call.
+// 7. Stale Entry Is Served On Upstream Error.
+// 8. Upstream Error Without Cached Entry.
+// 9. Waiting Request Stops When Its Context Is Done.
+// 10. Shared Call Outlives The Request That Started It.
+// 11. Names Of A Currency Share One Entry.
+func TestCachingTreasuryExchangeRateAdapter(t *testing.T) {
+	exchangeRate, errs := domain.NewExchangeRate("Real", "5.434", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(t, errs)
+	exchangeRates := []*domain.ExchangeRate{exchangeRate}
+
+	t.Run("Second Request Is A Hit", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return(exchangeRates, nil).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 10)
+
+		for i := 0; i < 2; i++ {
+			actualRates, err := cache.GetExchangeRates(context.Background(), "Real")
+			require.NoError(t, err)
+			assert.Equal(t, exchangeRates, actualRates)
+		}
+
+		assert.Equal(t, client.CacheStats{Hits: 1, Misses: 1, Entries: 1}, cache.Stats())
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Ranges Of Dates Are Cached Apart", func(t *testing.T) {
+		t.Parallel()
+		from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRatesBetween", "Real", from, to).Return(exchangeRates, nil).Once()
+		mockAdapter.On("GetExchangeRatesBetween", "Real", from, to.AddDate(0, 0, 1)).Return(exchangeRates, nil).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 10)
+
+		for i := 0; i < 2; i++ {
+			actualRates, err := cache.GetExchangeRatesBetween(context.Background(), "Real", from, to)
+			require.NoError(t, err)
+			assert.Equal(t, exchangeRates, actualRates)
+		}
+		_, err := cache.GetExchangeRatesBetween(context.Background(), "Real", from, to.AddDate(0, 0, 1))
+		require.NoError(t, err)
+
+		assert.Equal(t, client.CacheStats{Hits: 1, Misses: 2, Entries: 2}, cache.Stats())
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Expired Entry Is Fetched Again", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return(exchangeRates, nil).Twice()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, 20*time.Millisecond, 10)
+
+		_, err := cache.GetExchangeRates(context.Background(), "Real")
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+		_, err = cache.GetExchangeRates(context.Background(), "Real")
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(2), cache.Stats().Misses)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Currency TTL Overrides The TTL Of The Cache", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "EUR").Return(exchangeRates, nil).Twice()
+		mockAdapter.On("GetExchangeRates", "Real").Return(exchangeRates, nil).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 10).
+			WithCurrencyTTL("Euro Zone-Euro", 20*time.Millisecond)
+
+		for _, currencyName := range []string{"EUR", "Real"} {
+			_, err := cache.GetExchangeRates(context.Background(), currencyName)
+			require.NoError(t, err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		// Only the entry of the currency with the shorter TTL has expired
+		for _, currencyName := range []string{"EUR", "Real"} {
+			_, err := cache.GetExchangeRates(context.Background(), currencyName)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, client.CacheStats{Hits: 1, Misses: 3, Entries: 2}, cache.Stats())
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Least Recently Used Entry Is Evicted", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return(exchangeRates, nil).Once()
+		mockAdapter.On("GetExchangeRates", "Yen").Return(exchangeRates, nil).Twice()
+		mockAdapter.On("GetExchangeRates", "Euro").Return(exchangeRates, nil).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 2)
+
+		// Real is used again before Euro is added, so Yen is the least recently used one
+		for _, currencyName := range []string{"Real", "Yen", "Real", "Euro", "Yen"} {
+			_, err := cache.GetExchangeRates(context.Background(), currencyName)
+			require.NoError(t, err)
+		}
+
+		stats := cache.Stats()
+		assert.Equal(t, 2, stats.Entries)
+		assert.Equal(t, uint64(2), stats.Evictions)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Concurrent Requests Share One Upstream Call", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return(exchangeRates, nil).After(50 * time.Millisecond).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 10)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				actualRates, err := cache.GetExchangeRates(context.Background(), "Real")
+				assert.NoError(t, err)
+				assert.Equal(t, exchangeRates, actualRates)
+			}()
+		}
+		wg.Wait()
+
+		stats := cache.Stats()
+		assert.Equal(t, uint64(10), stats.Hits+stats.Misses)
+		mockAdapter.AssertNumberOfCalls(t, "GetExchangeRates", 1)
+	})
+
+	t.Run("Stale Entry Is Served On Upstream Error", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return(exchangeRates, nil).Once()
+		mockAdapter.On("GetExchangeRates", "Real").Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, 20*time.Millisecond, 10)
+
+		_, err := cache.GetExchangeRates(context.Background(), "Real")
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+		actualRates, err := cache.GetExchangeRates(context.Background(), "Real")
+
+		require.NoError(t, err)
+		assert.Equal(t, exchangeRates, actualRates)
+		assert.Equal(t, uint64(1), cache.Stats().StaleHits)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Upstream Error Without Cached Entry", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue)
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 10)
+
+		actualRates, err := cache.GetExchangeRates(context.Background(), "Real")
+
+		assert.ErrorIs(t, err, client.ErrNetworkIssue)
+		assert.Nil(t, actualRates)
+		assert.Equal(t, 0, cache.Stats().Entries)
+	})
+
+	t.Run("Waiting Request Stops When Its Context Is Done", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "Real").Return(exchangeRates, nil).After(200 * time.Millisecond).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 10)
+
+		leaderDone := make(chan struct{})
+		go func() {
+			defer close(leaderDone)
+			_, err := cache.GetExchangeRates(context.Background(), "Real")
+			assert.NoError(t, err)
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		actualRates, err := cache.GetExchangeRates(ctx, "Real")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Nil(t, actualRates)
+		<-leaderDone
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Shared Call Outlives The Request That Started It", func(t *testing.T) {
+		t.Parallel()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(&slowExchangeRateAdapter{delay: 100 * time.Millisecond,
+			exchangeRates: exchangeRates}, time.Hour, 10)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		leaderDone := make(chan error)
+		go func() {
+			_, err := cache.GetExchangeRates(ctx, "Real")
+			leaderDone <- err
+		}()
+		time.Sleep(5 * time.Millisecond)
+
+		// The request joining the call gets its result, although the request that started it gave up
+		actualRates, err := cache.GetExchangeRates(context.Background(), "Real")
+
+		require.NoError(t, err)
+		assert.Equal(t, exchangeRates, actualRates)
+		assert.ErrorIs(t, <-leaderDone, context.DeadlineExceeded)
+		assert.Equal(t, 1, cache.Stats().Entries)
+	})
+
+	t.Run("Names Of A Currency Share One Entry", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRates", "EUR").Return(exchangeRates, nil).Once()
+		cache := client.NewCachingTreasuryExchangeRateAdapter(mockAdapter, time.Hour, 10)
+
+		for _, currencyName := range []string{"EUR", " EUR ", "Euro Zone-Euro"} {
+			actualRates, err := cache.GetExchangeRates(context.Background(), currencyName)
+			require.NoError(t, err)
+			assert.Equal(t, exchangeRates, actualRates)
+		}
+
+		assert.Equal(t, client.CacheStats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+		mockAdapter.AssertExpectations(t)
+	})
+}
+
+// slowExchangeRateAdapter answers with its exchange rates after a delay, or fails when the context of the call is
+// done first.
+type slowExchangeRateAdapter struct {
+	client.MockTreasuryExchangeRateAdapter
+	delay         time.Duration
+	exchangeRates []*domain.ExchangeRate
+}
+
+// GetExchangeRates waits for the delay or the end of the context.
+func (a *slowExchangeRateAdapter) GetExchangeRates(ctx context.Context, _ string) ([]*domain.ExchangeRate, error) {
+	select {
+	case <-time.After(a.delay):
+		return a.exchangeRates, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
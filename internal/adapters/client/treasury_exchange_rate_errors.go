@@ -1,6 +1,10 @@
 package client
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+)
 
 // This file defines error variables related to the ExchangeRateService port implementation using the Treasury API.
 
@@ -20,11 +24,12 @@ var (
 	// ErrParsingExchangeRateDateOfRecord is returned when the exchange rate date of record cannot be parsed.
 	ErrParsingExchangeRateDateOfRecord = errors.New("error parsing exchange rate date of record")
 
-	// ErrInvalidExchangeRate is returned when the exchange rate value is invalid and cannot be parsed into a big.Float.
+	// ErrInvalidExchangeRate is returned when the exchange rate value is invalid and cannot be parsed into a decimal number.
 	ErrInvalidExchangeRate = errors.New("invalid exchange rate value")
 
-	// ErrExchangeRateNotFound is returned when no exchange rate is found for the requested currency.
-	ErrExchangeRateNotFound = errors.New("no exchange rates found for the provided currency")
+	// ErrExchangeRateNotFound is returned when no exchange rate is found for the requested currency. It is the error
+	// of the domain, so the business logic tells it apart without depending on the adapters.
+	ErrExchangeRateNotFound = domain.ErrExchangeRateNotFound
 
 	// ErrDecodingResponse is returned when the API response cannot be decoded into the expected format.
 	ErrDecodingResponse = errors.New("error decoding response from Treasury API")
@@ -34,4 +39,7 @@ var (
 
 	// ErrTreasuryAPIResponse is returned when the Treasury API returns an error.
 	ErrTreasuryAPIResponse = errors.New("error from the Treasury API")
+
+	// ErrTooManyPages is returned when a Treasury API response has more pages than the adapter follows.
+	ErrTooManyPages = errors.New("too many pages in the Treasury API response")
 )
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
 	"github.com/stretchr/testify/mock"
@@ -13,14 +15,40 @@ type MockTreasuryExchangeRateAdapter struct {
 }
 
 // GetExchangeRates mocks the GetExchangeRates method of the TreasuryExchangeRateAdapter.
-func (m *MockTreasuryExchangeRateAdapter) GetExchangeRates(currencyName string) ([]*domain.ExchangeRate, error) {
+func (m *MockTreasuryExchangeRateAdapter) GetExchangeRates(ctx context.Context, currencyName string) ([]*domain.ExchangeRate, error) {
 	args := m.Called(currencyName)
 	// Retrieves the values from the mocked call arguments (returns a slice of ExchangeRate objects)
 	return args.Get(0).([]*domain.ExchangeRate), args.Error(1)
 }
 
-// Get is a mock method for HTTPClient interface.
-func (m *MockTreasuryExchangeRateAdapter) Get(url string) (*http.Response, error) {
-	args := m.Called(url)
+// GetExchangeRatesBetween mocks the GetExchangeRatesBetween method of the TreasuryExchangeRateAdapter.
+func (m *MockTreasuryExchangeRateAdapter) GetExchangeRatesBetween(ctx context.Context, currencyName string,
+	from, to time.Time) ([]*domain.ExchangeRate, error) {
+	args := m.Called(currencyName, from, to)
+	// Retrieves the values from the mocked call arguments (returns a slice of ExchangeRate objects)
+	return args.Get(0).([]*domain.ExchangeRate), args.Error(1)
+}
+
+// GetExchangeRatesSince mocks the GetExchangeRatesSince method of the TreasuryExchangeRateAdapter.
+func (m *MockTreasuryExchangeRateAdapter) GetExchangeRatesSince(ctx context.Context, since time.Time) ([]*domain.ExchangeRate, error) {
+	args := m.Called(since)
+	// Retrieves the values from the mocked call arguments (returns a slice of ExchangeRate objects)
+	return args.Get(0).([]*domain.ExchangeRate), args.Error(1)
+}
+
+// GetCurrencies mocks the GetCurrencies method of the TreasuryExchangeRateAdapter.
+func (m *MockTreasuryExchangeRateAdapter) GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	args := m.Called()
+	// Retrieves the values from the mocked call arguments (returns a slice of SupportedCurrency objects)
+	return args.Get(0).([]*domain.SupportedCurrency), args.Error(1)
+}
+
+// Do is a mock method for HTTPClient interface. The calls are matched on the request URL, and fail with the error
+// of the request context when it is already done.
+func (m *MockTreasuryExchangeRateAdapter) Do(req *http.Request) (*http.Response, error) {
+	args := m.Called(req.URL.String())
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
 	return args.Get(0).(*http.Response), args.Error(1)
 }
@@ -1,9 +1,11 @@
 package client_test
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -25,14 +27,19 @@ import (
 // 3. Non-200 Response.
 // 4. JSON Decoding Error.
 // 5. No Data In Response.
+// 6. Country Currency Description Resolves The ISO Code.
 func TestGetExchangeRates(t *testing.T) {
 	// Expected results
-	successfulResponseExchangeRate1, err := domain.NewExchangeRate("Real", 5.434, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	successfulResponseExchangeRate1, err := domain.NewExchangeRate("Real", "5.434", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
 	// Stops the test if the expected results are not as expected (probably the business logic changed)
 	require.Empty(t, err)
-	successfulResponseExchangeRate2, err := domain.NewExchangeRate("Real", 5.5, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
+	successfulResponseExchangeRate2, err := domain.NewExchangeRate("Real", "5.5", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
 	require.Empty(t, err)
 
+	successfulResponseExchangeRate3, err := domain.NewExchangeRate("Yen", "143.57", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(t, err)
+	successfulResponseExchangeRate3.CurrencyCode = "JPY"
+
 	successfulResponseExchangeRates := []*domain.ExchangeRate{successfulResponseExchangeRate1}
 	successfulResponseMultipleExchangeRates := []*domain.ExchangeRate{successfulResponseExchangeRate1, successfulResponseExchangeRate2}
 
@@ -87,6 +94,15 @@ func TestGetExchangeRates(t *testing.T) {
 			expectedRates: nil,
 			expectedError: client.ErrExchangeRateNotFound,
 		},
+		{
+			name: "Country Currency Description Resolves The ISO Code",
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"data":[{"country_currency_desc":"Japan-Yen","currency":"Yen","exchange_rate":"143.57","record_calendar_day":"30","record_calendar_month":"09","record_calendar_year":"2024"}]}`)),
+			},
+			expectedRates: []*domain.ExchangeRate{successfulResponseExchangeRate3},
+			expectedError: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,10 +111,10 @@ func TestGetExchangeRates(t *testing.T) {
 
 			// Creates a mock client
 			mockClient := new(client.MockTreasuryExchangeRateAdapter)
-			mockClient.On("Get", mock.Anything).Return(tt.mockResponse, tt.mockError)
+			mockClient.On("Do", mock.Anything).Return(tt.mockResponse, tt.mockError)
 
-			treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
-			actualRates, actualError := treasuryAdapter.GetExchangeRates("Real")
+			treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient).WithRetryPolicy(newNoWaitRetryPolicy())
+			actualRates, actualError := treasuryAdapter.GetExchangeRates(context.Background(), "Real")
 
 			// Asserts the results
 			if tt.expectedError != nil {
@@ -108,7 +124,9 @@ func TestGetExchangeRates(t *testing.T) {
 				assert.Equal(t, len(tt.expectedRates), len(actualRates))
 				for i, expectedRate := range tt.expectedRates {
 					assert.Equal(t, expectedRate.CurrencyName, actualRates[i].CurrencyName)
+					assert.Equal(t, expectedRate.CurrencyCode, actualRates[i].CurrencyCode)
 					assert.Equal(t, expectedRate.Rate.Cmp(actualRates[i].Rate), 0)
+					assert.Equal(t, expectedRate.RateText, actualRates[i].RateText)
 					assert.Equal(t, expectedRate.DateOfRecord, actualRates[i].DateOfRecord)
 				}
 			}
@@ -118,3 +136,516 @@ func TestGetExchangeRates(t *testing.T) {
 		})
 	}
 }
+
+// TestGetExchangeRatesCurrencyFilter tests the currency filter sent to the Treasury API by the GetExchangeRates method
+// of the TreasuryExchangeRateAdapter. It tests the following scenarios:
+//
+// 1. ISO 4217 Code.
+// 2. Country-Currency Description.
+// 3. Currency Name.
+// 4. Unknown ISO 4217 Code.
+// 5. Ambiguous ISO 4217 Code.
+func TestGetExchangeRatesCurrencyFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		currency       string
+		expectedFilter string
+		expectedError  error
+	}{
+		{
+			name:           "ISO 4217 Code",
+			currency:       "EUR",
+			expectedFilter: "&filter=country_currency_desc:eq:Euro+Zone-Euro",
+		},
+		{
+			name:           "Country-Currency Description",
+			currency:       "Canada-Dollar",
+			expectedFilter: "&filter=country_currency_desc:eq:Canada-Dollar",
+		},
+		{
+			name:           "Currency Name",
+			currency:       "Real",
+			expectedFilter: "&filter=currency:eq:Real",
+		},
+		{
+			name:          "Unknown ISO 4217 Code",
+			currency:      "XYZ",
+			expectedError: domain.ErrUnknownCurrencyCode,
+		},
+		{
+			name:          "Ambiguous ISO 4217 Code",
+			currency:      "USD",
+			expectedError: domain.ErrAmbiguousCurrencyCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Creates a mock client that only answers the expected filter
+			mockClient := new(client.MockTreasuryExchangeRateAdapter)
+			if tt.expectedError == nil {
+				mockClient.On("Do", mock.MatchedBy(func(url string) bool {
+					return strings.HasSuffix(url, tt.expectedFilter)
+				})).Return(&http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"data":[{"currency":"Any","exchange_rate":"1.5","record_calendar_day":"30","record_calendar_month":"09","record_calendar_year":"2024"}]}`)),
+				}, nil)
+			}
+
+			treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+			_, err := treasuryAdapter.GetExchangeRates(context.Background(), tt.currency)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// The API is not called when the currency cannot be resolved
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetCurrencies tests the GetCurrencies method of the TreasuryExchangeRateAdapter. It tests the following
+// scenarios:
+//
+// 1. Latest Date Of Record Of Each Currency.
+// 2. Non-200 Response.
+// 3. JSON Decoding Error.
+// 4. Invalid Date Of Record.
+func TestGetCurrencies(t *testing.T) {
+	tests := []struct {
+		name               string
+		mockResponse       *http.Response
+		expectedCurrencies []domain.SupportedCurrency
+		expectedError      error
+	}{
+		{
+			name: "Latest Date Of Record Of Each Currency",
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(strings.NewReader(`{"data":[` +
+					`{"country_currency_desc":"Japan-Yen","currency":"Yen","record_date":"2024-09-30"},` +
+					`{"country_currency_desc":"Atlantis-Drachma","currency":"Drachma","record_date":"2024-06-30"},` +
+					`{"country_currency_desc":"Japan-Yen","currency":"Yen","record_date":"2024-06-30"}]}`)),
+			},
+			expectedCurrencies: []domain.SupportedCurrency{
+				{
+					CountryCurrencyDesc: "Atlantis-Drachma",
+					CurrencyName:        "Drachma",
+					LatestDateOfRecord:  time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					CountryCurrencyDesc: "Japan-Yen",
+					CurrencyName:        "Yen",
+					CurrencyCode:        "JPY",
+					LatestDateOfRecord:  time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name: "Non-200 Response",
+			mockResponse: &http.Response{
+				StatusCode: http.StatusBadRequest,
+			},
+			expectedError: client.ErrTreasuryAPIResponse,
+		},
+		{
+			name: "JSON Decoding Error",
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`invalid json`)),
+			},
+			expectedError: client.ErrDecodingResponse,
+		},
+		{
+			name: "Invalid Date Of Record",
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"data":[{"country_currency_desc":"Japan-Yen","currency":"Yen","record_date":"30/09/2024"}]}`)),
+			},
+			expectedError: client.ErrParsingExchangeRateDateOfRecord,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Creates a mock client that only answers the distinct currencies request
+			mockClient := new(client.MockTreasuryExchangeRateAdapter)
+			mockClient.On("Do", mock.MatchedBy(func(url string) bool {
+				return strings.Contains(url, "&fields=country_currency_desc,currency,record_date&")
+			})).Return(tt.mockResponse, nil).Once()
+
+			treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+			actualCurrencies, actualError := treasuryAdapter.GetCurrencies(context.Background())
+
+			// Asserts the results
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, actualError, tt.expectedError)
+			} else {
+				assert.NoError(t, actualError)
+				require.Len(t, actualCurrencies, len(tt.expectedCurrencies))
+				for i, expectedCurrency := range tt.expectedCurrencies {
+					assert.Equal(t, expectedCurrency, *actualCurrencies[i])
+				}
+
+				// The second call is answered from the local cache
+				cachedCurrencies, err := treasuryAdapter.GetCurrencies(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, actualCurrencies, cachedCurrencies)
+			}
+
+			// Ensure that the mock client was called only once
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetCurrenciesConcurrentRequests tests the fetch of the supported currencies shared by concurrent requests. It
+// tests the following scenarios:
+//
+// 1. Concurrent Requests Share One Fetch.
+func TestGetCurrenciesConcurrentRequests(t *testing.T) {
+	t.Run("Concurrent Requests Share One Fetch", func(t *testing.T) {
+		t.Parallel()
+
+		// The fetch is held by the mock client until the request with a deadline has given up
+		fetchStarted := make(chan struct{})
+		releaseFetch := make(chan struct{})
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(
+				`{"data":[{"country_currency_desc":"Japan-Yen","currency":"Yen","record_date":"2024-09-30"}]}`)),
+		}, nil).Run(func(mock.Arguments) {
+			close(fetchStarted)
+			<-releaseFetch
+		}).Once()
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+
+		var wg sync.WaitGroup
+		getCurrencies := func() {
+			defer wg.Done()
+			currencies, err := treasuryAdapter.GetCurrencies(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, currencies, 1)
+		}
+		wg.Add(2)
+		go getCurrencies()
+		<-fetchStarted
+		go getCurrencies()
+
+		// A request does not wait for the fetch past its own deadline
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := treasuryAdapter.GetCurrencies(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(releaseFetch)
+		wg.Wait()
+		mockClient.AssertExpectations(t)
+	})
+}
+
+// TestGetExchangeRatesSince tests the GetExchangeRatesSince method of the TreasuryExchangeRateAdapter. It tests the
+// following scenarios:
+//
+// 1. New Exchange Rates Of Several Currencies.
+// 2. No New Exchange Rates.
+// 3. Network Issue.
+func TestGetExchangeRatesSince(t *testing.T) {
+	since := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expectedDescs []string
+		expectedError error
+	}{
+		{
+			name: "New Exchange Rates Of Several Currencies",
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(strings.NewReader(`{"data":[` +
+					`{"country_currency_desc":"Brazil-Real","currency":"Real","exchange_rate":"5.434","record_calendar_day":"30","record_calendar_month":"09","record_calendar_year":"2024"},` +
+					`{"country_currency_desc":"Japan-Yen","currency":"Yen","exchange_rate":"143.57","record_calendar_day":"30","record_calendar_month":"09","record_calendar_year":"2024"}]}`)),
+			},
+			expectedDescs: []string{"Brazil-Real", "Japan-Yen"},
+		},
+		{
+			name: "No New Exchange Rates",
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"data":[]}`)),
+			},
+			expectedDescs: []string{},
+		},
+		{
+			name:          "Network Issue",
+			mockResponse:  (*http.Response)(nil),
+			mockError:     assert.AnError,
+			expectedError: client.ErrNetworkIssue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Creates a mock client that only answers the incremental request
+			mockClient := new(client.MockTreasuryExchangeRateAdapter)
+			mockClient.On("Do", mock.MatchedBy(func(url string) bool {
+				return strings.HasSuffix(url, "&filter=record_date:gt:2024-06-30")
+			})).Return(tt.mockResponse, tt.mockError)
+
+			treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient).WithRetryPolicy(newNoWaitRetryPolicy())
+			actualRates, actualError := treasuryAdapter.GetExchangeRatesSince(context.Background(), since)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, actualError, tt.expectedError)
+				return
+			}
+			require.NoError(t, actualError)
+			require.Len(t, actualRates, len(tt.expectedDescs))
+			for i, expectedDesc := range tt.expectedDescs {
+				assert.Equal(t, expectedDesc, actualRates[i].CountryCurrencyDesc)
+			}
+		})
+	}
+}
+
+// TestGetExchangeRatesContext tests that the requests of the TreasuryExchangeRateAdapter honor their context. It tests
+// the following scenarios:
+//
+// 1. Canceled Context Stops The Request.
+// 2. Deadline Shorter Than The Retry Delay Returns The Error.
+func TestGetExchangeRatesContext(t *testing.T) {
+	t.Run("Canceled Context Stops The Request", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return((*http.Response)(nil), assert.AnError)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+		actualRates, actualError := treasuryAdapter.GetExchangeRates(ctx, "Real")
+
+		assert.ErrorIs(t, actualError, context.Canceled)
+		assert.Nil(t, actualRates)
+		mockClient.AssertNumberOfCalls(t, "Do", 1)
+	})
+
+	t.Run("Deadline Shorter Than The Retry Delay Returns The Error", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return((*http.Response)(nil), assert.AnError)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+		start := time.Now()
+		actualRates, actualError := treasuryAdapter.GetExchangeRates(ctx, "Real")
+
+		// The deadline would pass before the first retry, so the transport error is returned at once
+		assert.ErrorIs(t, actualError, client.ErrNetworkIssue)
+		assert.Nil(t, actualRates)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		mockClient.AssertNumberOfCalls(t, "Do", 1)
+	})
+}
+
+// TestGetExchangeRatesPagination tests that the TreasuryExchangeRateAdapter follows the pagination of the Treasury API
+// responses. It tests the following scenarios:
+//
+// 1. Follows The Next Link.
+// 2. Follows The Total Pages Without Links.
+// 3. Failure On A Later Page.
+// 4. Currencies Across Pages.
+func TestGetExchangeRatesPagination(t *testing.T) {
+	newPage := func(body string) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+	}
+	onPage := func(mockClient *client.MockTreasuryExchangeRateAdapter, page string) *mock.Call {
+		return mockClient.On("Do", mock.MatchedBy(func(url string) bool {
+			return strings.Contains(url, "&page[number]="+page+"&")
+		}))
+	}
+	realRecord := func(rate, month string) string {
+		return `{"currency":"Real","exchange_rate":"` + rate + `","record_calendar_day":"30","record_calendar_month":"` +
+			month + `","record_calendar_year":"2024"}`
+	}
+
+	t.Run("Follows The Next Link", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		onPage(mockClient, "1").Return(newPage(`{"data":[`+realRecord("5.434", "09")+`],`+
+			`"meta":{"count":1,"total-count":2,"total-pages":2},`+
+			`"links":{"self":"&page%5Bnumber%5D=1&page%5Bsize%5D=1","next":"&page%5Bnumber%5D=2&page%5Bsize%5D=1"}}`), nil).Once()
+		onPage(mockClient, "2").Return(newPage(`{"data":[`+realRecord("5.5", "06")+`],`+
+			`"meta":{"count":1,"total-count":2,"total-pages":2},`+
+			`"links":{"self":"&page%5Bnumber%5D=2&page%5Bsize%5D=1","next":null}}`), nil).Once()
+
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+		exchangeRates, err := treasuryAdapter.GetExchangeRates(context.Background(), "Real")
+
+		require.NoError(t, err)
+		require.Len(t, exchangeRates, 2)
+		assert.Equal(t, "5.434", exchangeRates[0].RateText)
+		assert.Equal(t, "5.5", exchangeRates[1].RateText)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Follows The Total Pages Without Links", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		onPage(mockClient, "1").Return(newPage(`{"data":[`+realRecord("5.434", "09")+`],"meta":{"total-pages":2}}`), nil).Once()
+		onPage(mockClient, "2").Return(newPage(`{"data":[`+realRecord("5.5", "06")+`],"meta":{"total-pages":2}}`), nil).Once()
+
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+		exchangeRates, err := treasuryAdapter.GetExchangeRates(context.Background(), "Real")
+
+		require.NoError(t, err)
+		assert.Len(t, exchangeRates, 2)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Failure On A Later Page", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		onPage(mockClient, "1").Return(newPage(`{"data":[`+realRecord("5.434", "09")+`],"meta":{"total-pages":2}}`), nil).Once()
+		onPage(mockClient, "2").Return(&http.Response{StatusCode: http.StatusInternalServerError}, nil).
+			Times(client.DefaultRetryAttempts)
+
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient).WithRetryPolicy(newNoWaitRetryPolicy())
+		exchangeRates, err := treasuryAdapter.GetExchangeRates(context.Background(), "Real")
+
+		assert.ErrorIs(t, err, client.ErrTreasuryAPIResponse)
+		assert.Nil(t, exchangeRates)
+	})
+
+	t.Run("Currencies Across Pages", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		onPage(mockClient, "1").Return(newPage(`{"data":[`+
+			`{"country_currency_desc":"Japan-Yen","currency":"Yen","record_date":"2024-09-30"}],"meta":{"total-pages":2}}`), nil).Once()
+		onPage(mockClient, "2").Return(newPage(`{"data":[`+
+			`{"country_currency_desc":"Japan-Yen","currency":"Yen","record_date":"2024-06-30"},`+
+			`{"country_currency_desc":"Brazil-Real","currency":"Real","record_date":"2024-06-30"}],"meta":{"total-pages":2}}`), nil).Once()
+
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+		currencies, err := treasuryAdapter.GetCurrencies(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, currencies, 2)
+		assert.Equal(t, "Brazil-Real", currencies[0].CountryCurrencyDesc)
+		assert.Equal(t, "Japan-Yen", currencies[1].CountryCurrencyDesc)
+		assert.Equal(t, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC), currencies[1].LatestDateOfRecord)
+	})
+}
+
+// TestGetExchangeRatesBetween tests the GetExchangeRatesBetween method of the TreasuryExchangeRateAdapter. It tests the
+// following scenarios:
+//
+// 1. Record Date Range Filter.
+// 2. No Exchange Rate In The Range.
+func TestGetExchangeRatesBetween(t *testing.T) {
+	from := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedRates int
+		expectedError error
+	}{
+		{
+			name: "Record Date Range Filter",
+			body: `{"data":[{"country_currency_desc":"Japan-Yen","currency":"Yen","exchange_rate":"143.57",` +
+				`"record_calendar_day":"30","record_calendar_month":"09","record_calendar_year":"2024"}]}`,
+			expectedRates: 1,
+		},
+		{
+			name:          "No Exchange Rate In The Range",
+			body:          `{"data":[]}`,
+			expectedError: client.ErrExchangeRateNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Creates a mock client that only answers the filter of the currency and range
+			mockClient := new(client.MockTreasuryExchangeRateAdapter)
+			mockClient.On("Do", mock.MatchedBy(func(url string) bool {
+				return strings.HasSuffix(url,
+					"&filter=country_currency_desc:eq:Japan-Yen,record_date:gte:2024-04-01,record_date:lte:2024-10-01")
+			})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tt.body))}, nil)
+
+			treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient)
+			exchangeRates, err := treasuryAdapter.GetExchangeRatesBetween(context.Background(), "JPY", from, to)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, exchangeRates, tt.expectedRates)
+		})
+	}
+}
+
+// TestGetExchangeRatesRetry tests that the TreasuryExchangeRateAdapter retries the transient failures of the Treasury
+// API. It tests the following scenarios:
+//
+// 1. Service Unavailable Then Success.
+// 2. Rate Limited Until The Attempts Are Exhausted.
+func TestGetExchangeRatesRetry(t *testing.T) {
+	body := `{"data":[{"currency":"Real","exchange_rate":"5.434","record_calendar_day":"30","record_calendar_month":"09","record_calendar_year":"2024"}]}`
+	newResponse := func(statusCode int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: statusCode, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	t.Run("Service Unavailable Then Success", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return(newResponse(http.StatusServiceUnavailable, "2"), nil).Once()
+		mockClient.On("Do", mock.Anything).Return(newResponse(http.StatusOK, ""), nil).Once()
+
+		clock := newFakeClock(time.Now())
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient).
+			WithRetryPolicy(client.NewRetryPolicy(3, time.Second, 10*time.Second, clock))
+		exchangeRates, err := treasuryAdapter.GetExchangeRates(context.Background(), "Real")
+
+		require.NoError(t, err)
+		assert.Len(t, exchangeRates, 1)
+		assert.Equal(t, []time.Duration{2 * time.Second}, clock.Waits())
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("Rate Limited Until The Attempts Are Exhausted", func(t *testing.T) {
+		t.Parallel()
+		mockClient := new(client.MockTreasuryExchangeRateAdapter)
+		mockClient.On("Do", mock.Anything).Return(newResponse(http.StatusTooManyRequests, "1"), nil).Times(2)
+
+		clock := newFakeClock(time.Now())
+		treasuryAdapter := client.NewConcreteTreasuryExchangeRateAdapter(mockClient).
+			WithRetryPolicy(client.NewRetryPolicy(2, time.Second, 10*time.Second, clock))
+		exchangeRates, err := treasuryAdapter.GetExchangeRates(context.Background(), "Real")
+
+		assert.ErrorIs(t, err, client.ErrTreasuryAPIResponse)
+		assert.Nil(t, exchangeRates)
+		assert.Equal(t, []time.Duration{time.Second}, clock.Waits())
+		mockClient.AssertExpectations(t)
+	})
+}
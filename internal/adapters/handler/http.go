@@ -2,10 +2,16 @@ package handler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,19 +30,96 @@ import (
 // Activate the jsoniter library to decode the Treasury API response.
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
+const (
+	// serverWriteTimeout is the maximum duration before timing out the writes of a response.
+	serverWriteTimeout = 1 * time.Second
+	// requestTimeout is the deadline given to the context of each request. It is shorter than the write timeout of the
+	// server, so a timeout response can still be written.
+	requestTimeout = 900 * time.Millisecond
+	// conversionWriteTimeout is the maximum duration before timing out the writes of the response of a conversion,
+	// which may wait on several Treasury API requests, their retries and the following pages of their results.
+	conversionWriteTimeout = 15 * time.Second
+	// conversionTimeout is the deadline given to the context of a conversion. It is shorter than the write timeout of
+	// the conversions, so a timeout response can still be written.
+	conversionTimeout = conversionWriteTimeout - 100*time.Millisecond
+)
+
+// Headers of the idempotent transaction creation.
+const (
+	// IdempotencyKeyHeader is the request header holding the idempotency key of a transaction creation.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is the response header set when a transaction creation is a replay.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
 // TransactionHandler holds the resources needed to handle HTTP requests for transactions.
 type TransactionHandler struct {
 	transactionService services.TransactionService
+	// conversionJobService runs the conversion jobs, nil when the conversion jobs are not enabled
+	conversionJobService *services.ConversionJobService
+	// healthChecks reports the state of the components in the health check, by component name
+	healthChecks map[string]func() interface{}
 }
 
-// TransactionDTO represents the data transfer object for transactions.
+// TransactionDTO represents the data transfer object for transactions. Amounts are encoded as decimal strings and
+// can be decoded from either decimal strings or JSON numbers. A transaction is recorded either in USD with
+// amount_in_usd, or in another currency with amount and currency. The target currency exponent is the number of
+// decimal places the converted amount is rounded to. The conversion legs are only set for transactions recorded in
+// another currency, converted through USD. The version and the revision times are only set for the revisions of a
+// transaction.
 type TransactionDTO struct {
-	ID                     string  `json:"id"`
-	Description            string  `json:"description"`
-	Timestamp              string  `json:"timestamp"`
-	AmountInUSD            float64 `json:"amount_in_usd"`
-	ExchangeRateUsed       float64 `json:"exchange_rate_used"`
-	AmountInTargetCurrency float64 `json:"amount_in_target_currency"`
+	ID                     string                  `json:"id"`
+	Description            string                  `json:"description"`
+	Timestamp              string                  `json:"timestamp"`
+	AmountInUSD            *domain.Money           `json:"amount_in_usd,omitempty"`
+	Amount                 *domain.Money           `json:"amount,omitempty"`
+	Currency               string                  `json:"currency,omitempty"`
+	ExchangeRateUsed       string                  `json:"exchange_rate_used,omitempty"`
+	ExchangeRateDate       string                  `json:"exchange_rate_date,omitempty"`
+	ExchangeRateSource     string                  `json:"exchange_rate_source,omitempty"`
+	RateSelectionPolicy    *RateSelectionPolicyDTO `json:"rate_selection_policy,omitempty"`
+	ConversionLegs         []ConversionLegDTO      `json:"conversion_legs,omitempty"`
+	AmountInTargetCurrency *domain.Money           `json:"amount_in_target_currency,omitempty"`
+	TargetCurrencyCode     string                  `json:"target_currency_code,omitempty"`
+	TargetCurrencyExponent *int                    `json:"target_currency_exponent,omitempty"`
+	LockedAt               string                  `json:"locked_at,omitempty"`
+	CurrentConversion      *CurrencyConversionDTO  `json:"current_conversion,omitempty"`
+	Version                int                     `json:"version,omitempty"`
+	UpdatedAt              string                  `json:"updated_at,omitempty"`
+	DeletedAt              string                  `json:"deleted_at,omitempty"`
+}
+
+// ConversionLegDTO represents the data transfer object for a conversion between USD and another currency, one of
+// the legs of the conversion of a transaction recorded in another currency.
+type ConversionLegDTO struct {
+	FromCurrency       string       `json:"from_currency"`
+	ToCurrency         string       `json:"to_currency"`
+	ExchangeRateUsed   string       `json:"exchange_rate_used"`
+	ExchangeRateDate   string       `json:"exchange_rate_date"`
+	ExchangeRateSource string       `json:"exchange_rate_source,omitempty"`
+	Amount             domain.Money `json:"amount"`
+}
+
+// RateSelectionPolicyDTO represents the data transfer object for the policy that selected the exchange rate of a
+// conversion.
+type RateSelectionPolicyDTO struct {
+	Name           string `json:"name"`
+	LookbackMonths int    `json:"lookback_months"`
+}
+
+// TransactionPageDTO represents the data transfer object for a page of transactions.
+type TransactionPageDTO struct {
+	Transactions []TransactionDTO `json:"transactions"`
+	NextCursor   string           `json:"next_cursor,omitempty"`
+}
+
+// CurrencyDTO represents the data transfer object for the currencies that transactions can be converted to. Any of
+// the name, the country-currency name or the code can be used as the target currency of a conversion.
+type CurrencyDTO struct {
+	CountryCurrencyName string `json:"country_currency_name"`
+	Name                string `json:"name"`
+	Code                string `json:"code,omitempty"`
+	LatestRecordDate    string `json:"latest_record_date"`
 }
 
 // SuccessResponse wraps successful responses.
@@ -51,7 +134,18 @@ type ErrorResponse struct {
 
 // NewTransactionHandler creates a new handler with injected services.
 func NewTransactionHandler(transactionService services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{transactionService: transactionService}
+	return &TransactionHandler{
+		transactionService: transactionService,
+		healthChecks:       make(map[string]func() interface{}),
+	}
+}
+
+// RegisterHealthCheck adds the state of a component to the health check response, under the given name.
+func (th *TransactionHandler) RegisterHealthCheck(name string, check func() interface{}) {
+	if th.healthChecks == nil {
+		th.healthChecks = make(map[string]func() interface{})
+	}
+	th.healthChecks[name] = check
 }
 
 // Routes sets up the Chi router with the necessary routes.
@@ -69,14 +163,47 @@ func (th *TransactionHandler) Routes() chi.Router {
 		})
 	})
 
-	r.Post("/transactions", th.SaveTransaction)
-	r.Get("/transactions/{id}/{currency}", th.FindTransactionWithCurrencyConversion)
-	r.Get("/health", th.HealthCheck)
+	// The exports stream all their transactions and the imports upload them, so they have a longer deadline than the
+	// other requests
+	r.With(RequestTimeout(exportTimeout)).Get("/transactions/export", th.ExportTransactions)
+	r.With(RequestTimeout(importTimeout)).Post("/transactions/import", th.ImportTransactions)
+	if th.conversionJobService != nil {
+		r.With(RequestTimeout(exportTimeout)).Get("/jobs/{id}/result", th.DownloadConversionJobResult)
+	}
+
+	// The conversions and the currencies are looked up on the Treasury API, so they have a longer deadline than the
+	// requests answered from the database
+	r.Group(func(r chi.Router) {
+		r.Use(WriteTimeout(conversionWriteTimeout))
+		r.Use(RequestTimeout(conversionTimeout))
+		r.Get("/transactions/{id}/conversions", th.FindTransactionConversions)
+		r.Get("/transactions/{id}/{currency}", th.FindTransactionWithCurrencyConversion)
+		r.Post("/transactions/{id}/{currency}/lock", th.LockConversion)
+		r.Get("/exchange-rates/{currency}", th.FindExchangeRates)
+		r.Get("/convert", th.Convert)
+		r.Get("/currencies", th.GetCurrencies)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(RequestTimeout(requestTimeout))
+		r.Post("/transactions", th.SaveTransaction)
+		r.Get("/transactions", th.FindTransactions)
+		r.Get("/transactions/{id}", th.FindTransaction)
+		r.Patch("/transactions/{id}", th.UpdateTransaction)
+		r.Delete("/transactions/{id}", th.DeleteTransaction)
+		r.Get("/transactions/{id}/history", th.FindTransactionHistory)
+		r.Get("/health", th.HealthCheck)
+		if th.conversionJobService != nil {
+			r.Post("/jobs/conversions", th.CreateConversionJob)
+			r.Get("/jobs/{id}", th.FindConversionJob)
+		}
+	})
 
 	return r
 }
 
-// SaveTransaction handles the POST request to save a new transaction.
+// SaveTransaction handles the POST request to save a new transaction. With an Idempotency-Key header, a retry of the
+// request returns the transaction created the first time, and another request reusing the key gets a 409 Conflict.
 func (th *TransactionHandler) SaveTransaction(w http.ResponseWriter, r *http.Request) {
 	data := TransactionDTO{}
 
@@ -99,17 +226,113 @@ func (th *TransactionHandler) SaveTransaction(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := th.transactionService.SaveTransaction(*transaction); err != nil {
+	// A request with an idempotency key creates its transaction once, however many times it is retried
+	if idempotencyKey := r.Header.Get(IdempotencyKeyHeader); idempotencyKey != "" {
+		id, replayed, err := th.transactionService.SaveTransactionWithIdempotencyKey(r.Context(), *transaction,
+			idempotencyKey, HashTransactionRequest(transaction))
+		if err != nil {
+			writeSaveTransactionErrorResponse(w, err, transaction)
+			return
+		}
+		if replayed {
+			w.Header().Set(IdempotentReplayedHeader, "true")
+		}
+		WriteSuccessResponse(w, map[string]string{"id": id.String()}, http.StatusCreated)
+		return
+	}
+
+	if err := th.transactionService.SaveTransaction(r.Context(), *transaction); err != nil {
+		writeSaveTransactionErrorResponse(w, err, transaction)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"id": transaction.ID.String()}, http.StatusCreated)
+}
+
+// writeSaveTransactionErrorResponse writes the error response of a transaction that could not be saved.
+func writeSaveTransactionErrorResponse(w http.ResponseWriter, err error, transaction *domain.Transaction) {
+	if WriteContextErrorResponse(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrUnknownCurrencyCode) || errors.Is(err, domain.ErrAmbiguousCurrencyCode):
+		log.Warn().Err(err).Str("currency", transaction.Currency()).Msg("invalid transaction currency")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyEmpty) || errors.Is(err, domain.ErrIdempotencyKeyTooLong) ||
+		errors.Is(err, domain.ErrIdempotencyKeyInvalid):
+		log.Warn().Err(err).Msg("invalid idempotency key")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
 		log.Error().Err(err).Msg("failed to save the transaction")
 		WriteErrorResponse(w, http.StatusInternalServerError, "failed to save the transaction")
+	}
+}
+
+// HashTransactionRequest returns the SHA-256 hash, in hexadecimal, of a transaction creation request. The request is
+// hashed once validated, from the fields the client sets: the trimmed description, the timestamp in UTC and the amount
+// rounded to the minor units of its currency. The same request sent with another formatting, such as "10" instead of
+// "10.00", has the same hash, and the fields assigned by the server are left out.
+func HashTransactionRequest(transaction *domain.Transaction) string {
+	request := struct {
+		Description string `json:"description"`
+		Timestamp   string `json:"timestamp"`
+		Amount      string `json:"amount"`
+		Currency    string `json:"currency"`
+	}{
+		Description: transaction.Description,
+		Timestamp:   transaction.Timestamp.UTC().Format(time.RFC3339Nano),
+		Amount:      transaction.RecordedAmount().String(),
+		Currency:    transaction.Currency(),
+	}
+	// Marshalling a struct is deterministic, and cannot fail for the fields of a request
+	payload, _ := json.Marshal(request)
+	hash := sha256.Sum256(payload)
+	return hex.EncodeToString(hash[:])
+}
+
+// FindTransactions handles the GET request to list transactions page by page with optional filters.
+func (th *TransactionHandler) FindTransactions(w http.ResponseWriter, r *http.Request) {
+	query, validationErrors := ParseTransactionQuery(r.URL.Query())
+	if len(validationErrors) > 0 {
+		validationErrorsAsStrings := make([]string, len(validationErrors))
+		for i, err := range validationErrors {
+			validationErrorsAsStrings[i] = err.Error()
+		}
+		log.Warn().Errs("validation_errors", validationErrors).Msg("transaction query validation failed")
+		WriteErrorResponse(w, http.StatusBadRequest, "validation errors: "+strings.Join(validationErrorsAsStrings, ", "))
 		return
 	}
 
-	WriteSuccessResponse(w, map[string]string{"id": transaction.ID.String()}, http.StatusCreated)
+	page, err := th.transactionService.FindTransactions(r.Context(), *query)
+	if err != nil {
+		if WriteContextErrorResponse(w, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidTransactionCursor) {
+			WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error().Err(err).Msg("failed to find the transactions")
+		WriteErrorResponse(w, http.StatusInternalServerError, "failed to find the transactions")
+		return
+	}
+
+	pageDTO := TransactionPageDTO{
+		Transactions: make([]TransactionDTO, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+	}
+	for i, transaction := range page.Transactions {
+		pageDTO.Transactions[i] = NewTransactionDTO(transaction)
+	}
+
+	WriteSuccessResponse(w, pageDTO, http.StatusOK)
 }
 
 // FindTransactionWithCurrencyConversion handles the GET request to find and return a transaction
-// converted to a target currency.
+// converted to a target currency. A locked conversion is returned with its locked figures, and with the current
+// conversion as well when include_current is true.
 func (th *TransactionHandler) FindTransactionWithCurrencyConversion(w http.ResponseWriter, r *http.Request) {
 	idString := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idString)
@@ -124,35 +347,85 @@ func (th *TransactionHandler) FindTransactionWithCurrencyConversion(w http.Respo
 		WriteErrorResponse(w, http.StatusBadRequest, "currency not provided")
 		return
 	}
-	transaction, exchangeRate, err := th.transactionService.FindTransactionAndExchangeRateFromCurrency(id, currencyName)
+	includeCurrent, err := ParseIncludeCurrent(r.URL.Query())
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid include_current parameter")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	transaction, conversions, err := th.transactionService.ConvertTransaction(r.Context(), id, []string{currencyName})
+	if WriteContextErrorResponse(w, err) {
+		return
+	}
 	if err != nil {
-		log.Warn().Err(err).Msg("transaction not found or cannot be converted to the target currency")
+		log.Warn().Err(err).Msg("transaction not found")
+		WriteErrorResponse(w, http.StatusNotFound, "the purchase cannot be converted to the target currency")
+		return
+	}
+	conversion := conversions[0]
+	if conversion.Locked != nil {
+		// The locked figures are returned even when the current conversion fails
+		th.writeLockedConversionResponse(w, transaction, conversion, includeCurrent, http.StatusOK)
+		return
+	}
+	if errors.Is(conversion.Err, domain.ErrUnknownCurrencyCode) || errors.Is(conversion.Err, domain.ErrAmbiguousCurrencyCode) {
+		log.Warn().Err(conversion.Err).Str("currency", currencyName).Msg("invalid currency code")
+		WriteErrorResponse(w, http.StatusBadRequest, conversion.Err.Error())
+		return
+	}
+	if WriteUnavailableErrorResponse(w, conversion.Err) {
+		return
+	}
+	if conversion.Err != nil {
+		log.Warn().Err(conversion.Err).Msg("transaction cannot be converted to the target currency")
 		WriteErrorResponse(w, http.StatusNotFound, "the purchase cannot be converted to the target currency")
 		return
 	}
-	transactionAmountInUSD, _ := transaction.AmountInUSD.Float64()
-	exchangeRateUsed, _ := exchangeRate.Rate.Float64()
-	transactionAmountInUSD = domain.RoundToTwoDecimalPlaces(transactionAmountInUSD)
-	exchangeRateUsed = domain.RoundToTwoDecimalPlaces(exchangeRateUsed)
+	rateSelectionPolicy := th.transactionService.RateSelectionPolicy()
 
-	transactionDTO := TransactionDTO{
-		ID:                     transaction.ID.String(),
-		Description:            transaction.Description,
-		Timestamp:              transaction.Timestamp.Format(time.DateTime),
-		AmountInUSD:            transactionAmountInUSD,
-		ExchangeRateUsed:       exchangeRateUsed,
-		AmountInTargetCurrency: domain.RoundToTwoDecimalPlaces(transactionAmountInUSD * exchangeRateUsed),
+	transactionDTO := NewConvertedTransactionDTO(transaction, conversion)
+	transactionDTO.RateSelectionPolicy = &RateSelectionPolicyDTO{
+		Name:           rateSelectionPolicy.Name(),
+		LookbackMonths: rateSelectionPolicy.LookbackMonths(),
 	}
 
 	WriteSuccessResponse(w, transactionDTO, http.StatusOK)
 }
 
+// GetCurrencies handles the GET request to list the currencies that transactions can be converted to.
+func (th *TransactionHandler) GetCurrencies(w http.ResponseWriter, r *http.Request) {
+	currencies, err := th.transactionService.GetCurrencies(r.Context())
+	if err != nil {
+		if WriteContextErrorResponse(w, err) || WriteUnavailableErrorResponse(w, err) {
+			return
+		}
+		log.Error().Err(err).Msg("failed to retrieve the supported currencies")
+		WriteErrorResponse(w, http.StatusBadGateway, "failed to retrieve the supported currencies")
+		return
+	}
+
+	currencyDTOs := make([]CurrencyDTO, len(currencies))
+	for i, currency := range currencies {
+		currencyDTOs[i] = CurrencyDTO{
+			CountryCurrencyName: currency.CountryCurrencyDesc,
+			Name:                currency.CurrencyName,
+			Code:                currency.CurrencyCode,
+			LatestRecordDate:    currency.LatestDateOfRecord.Format(time.DateOnly),
+		}
+	}
+
+	WriteSuccessResponse(w, currencyDTOs, http.StatusOK)
+}
+
 // HealthCheck handles the GET request to check the health of the server.
 func (th *TransactionHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	health := map[string]interface{}{
 		"status":    "ok",
 		"timestamp": time.Now(),
 	}
+	for name, check := range th.healthChecks {
+		health[name] = check()
+	}
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(health); err != nil {
 		log.Error().Err(err).Msg("failed to encode response")
@@ -160,13 +433,95 @@ func (th *TransactionHandler) HealthCheck(w http.ResponseWriter, r *http.Request
 	}
 }
 
-// ValidateAndCreateTransaction validates and creates a new transaction from the provided request data.
+// ValidateAndCreateTransaction validates and creates a new transaction from the provided request data. The
+// transaction is recorded in the currency of the request when one is provided, and in USD otherwise.
 func (th *TransactionHandler) ValidateAndCreateTransaction(data TransactionDTO) (*domain.Transaction, []error) {
 	timestamp, errs := ParseAndValidateTimestamp(data.Timestamp)
 	if len(errs) > 0 {
 		return nil, errs
 	}
-	return domain.NewTransaction(data.Description, timestamp, data.AmountInUSD)
+
+	if strings.TrimSpace(data.Currency) == "" {
+		if data.Amount != nil {
+			return nil, []error{ErrCurrencyNotProvided}
+		}
+		amountInUSD := domain.Money{}
+		if data.AmountInUSD != nil {
+			amountInUSD = *data.AmountInUSD
+		}
+		return domain.NewTransaction(data.Description, timestamp, amountInUSD)
+	}
+
+	// The amount of a transaction recorded in another currency is given with the currency
+	if data.AmountInUSD != nil {
+		return nil, []error{ErrConflictingAmounts}
+	}
+	amount := domain.Money{}
+	if data.Amount != nil {
+		amount = *data.Amount
+	}
+	amount, err := amount.WithCurrency(data.Currency)
+	if err != nil {
+		return nil, []error{err}
+	}
+	return domain.NewTransactionInCurrency(data.Description, timestamp, amount)
+}
+
+// NewTransactionDTO creates the data transfer object of a transaction, with its amount in the currency it was
+// recorded in.
+func NewTransactionDTO(transaction *domain.Transaction) TransactionDTO {
+	transactionDTO := TransactionDTO{
+		ID:          transaction.ID.String(),
+		Description: transaction.Description,
+		Timestamp:   transaction.Timestamp.Format(time.DateTime),
+	}
+	if transaction.Currency() == domain.CurrencyUSD {
+		amountInUSD := transaction.AmountInUSD
+		transactionDTO.AmountInUSD = &amountInUSD
+		return transactionDTO
+	}
+	amount := transaction.RecordedAmount()
+	transactionDTO.Amount = &amount
+	transactionDTO.Currency = transaction.Currency()
+	return transactionDTO
+}
+
+// NewConvertedTransactionDTO creates the data transfer object of a transaction converted to a target currency. The
+// exchange rate is set for a transaction in USD, and the conversion legs for a transaction recorded in another
+// currency.
+func NewConvertedTransactionDTO(transaction *domain.Transaction,
+	conversion *domain.TransactionConversion) TransactionDTO {
+	targetCurrencyExponent := conversion.AmountInTargetCurrency.Exponent()
+	amountInTargetCurrency := conversion.AmountInTargetCurrency
+
+	transactionDTO := NewTransactionDTO(transaction)
+	transactionDTO.AmountInTargetCurrency = &amountInTargetCurrency
+	transactionDTO.TargetCurrencyCode = amountInTargetCurrency.Currency()
+	transactionDTO.TargetCurrencyExponent = &targetCurrencyExponent
+	if transaction.Currency() == domain.CurrencyUSD && conversion.ExchangeRate != nil {
+		transactionDTO.ExchangeRateUsed = conversion.ExchangeRate.RateText
+		transactionDTO.ExchangeRateDate = conversion.ExchangeRate.DateOfRecord.Format(time.DateOnly)
+		transactionDTO.ExchangeRateSource = conversion.ExchangeRate.Source
+	} else {
+		transactionDTO.ConversionLegs = NewConversionLegDTOs(conversion.Legs)
+	}
+	return transactionDTO
+}
+
+// NewConversionLegDTOs creates the data transfer objects of the legs of a conversion through USD.
+func NewConversionLegDTOs(legs []domain.ConversionLeg) []ConversionLegDTO {
+	legDTOs := make([]ConversionLegDTO, len(legs))
+	for i, leg := range legs {
+		legDTOs[i] = ConversionLegDTO{
+			FromCurrency:       leg.FromCurrency,
+			ToCurrency:         leg.ToCurrency,
+			ExchangeRateUsed:   leg.ExchangeRate.RateText,
+			ExchangeRateDate:   leg.ExchangeRate.DateOfRecord.Format(time.DateOnly),
+			ExchangeRateSource: leg.ExchangeRate.Source,
+			Amount:             leg.Amount,
+		}
+	}
+	return legDTOs
 }
 
 // StartServer starts the HTTP server on the provided port.
@@ -176,7 +531,7 @@ func (th *TransactionHandler) StartServer(port string) {
 		Addr:         ":" + port,
 		Handler:      router,
 		ReadTimeout:  1 * time.Second,
-		WriteTimeout: 1 * time.Second,
+		WriteTimeout: serverWriteTimeout,
 		IdleTimeout:  0 * time.Second,
 	}
 
@@ -223,6 +578,32 @@ func (th *TransactionHandler) ShutdownServer(server *http.Server) {
 	log.Info().Msg("server exited")
 }
 
+// RequestTimeout is a middleware giving the context of each request the provided deadline, so the work done on its
+// behalf (e.g. the Treasury API requests) is aborted once the response cannot be written anymore.
+func RequestTimeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WriteTimeout is a middleware extending the write deadline of each response past the write timeout of the server to
+// the provided timeout, for the requests given a longer deadline than the other ones.
+func WriteTimeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+			if err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Warn().Err(err).Msg("failed to extend the write deadline of the request")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // ParseAndValidateTimestamp checks if the provided timestamp string is not empty, parses it,
 // and ensures that the timestamp is not in the future.
 func ParseAndValidateTimestamp(timestampString string) (time.Time, []error) {
@@ -249,6 +630,51 @@ func ParseAndValidateTimestamp(timestampString string) (time.Time, []error) {
 	return timestamp, errs
 }
 
+// ParseTransactionQuery parses the URL query parameters of the transaction listing into a TransactionQuery.
+// Supported parameters are from, to (ISO 8601), min_amount, max_amount, description, cursor and limit.
+func ParseTransactionQuery(values url.Values) (*domain.TransactionQuery, []error) {
+	errs := make([]error, 0, 5)
+
+	parseTimestamp := func(key string) *time.Time {
+		if values.Get(key) == "" {
+			return nil
+		}
+		timestamp, err := ParseISO8601Timestamp(values.Get(key))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			return nil
+		}
+		return &timestamp
+	}
+	parseAmount := func(key string) *domain.Money {
+		if values.Get(key) == "" {
+			return nil
+		}
+		amount, err := domain.ParseMoney(values.Get(key), domain.CurrencyUSD)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, ErrInvalidAmountFormat))
+			return nil
+		}
+		return &amount
+	}
+
+	from, to := parseTimestamp("from"), parseTimestamp("to")
+	minAmount, maxAmount := parseAmount("min_amount"), parseAmount("max_amount")
+	limit := 0
+	if values.Get("limit") != "" {
+		parsedLimit, err := strconv.Atoi(strings.TrimSpace(values.Get("limit")))
+		if err != nil {
+			errs = append(errs, ErrInvalidLimitFormat)
+		}
+		limit = parsedLimit
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return domain.NewTransactionQuery(from, to, minAmount, maxAmount, values.Get("description"), values.Get("cursor"), limit)
+}
+
 // ParseISO8601Timestamp validates if the provided timestamp string is in ISO 8601 format
 // and converts it to a time.Time instance.
 func ParseISO8601Timestamp(timestampString string) (time.Time, error) {
@@ -287,6 +713,39 @@ func WriteSuccessResponse(w http.ResponseWriter, data interface{}, statusCode in
 	}
 }
 
+// WriteContextErrorResponse writes the error response of a request whose context is done, and reports whether the
+// error was a context error. A request past its deadline gets a gateway timeout, while nothing is written to a
+// client that went away.
+func WriteContextErrorResponse(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Warn().Err(err).Msg("request timed out")
+		WriteErrorResponse(w, http.StatusGatewayTimeout, "the request timed out")
+		return true
+	case errors.Is(err, context.Canceled):
+		log.Warn().Err(err).Msg("request canceled by the client")
+		return true
+	default:
+		return false
+	}
+}
+
+// WriteUnavailableErrorResponse writes a service unavailable response when the exchange rate provider is temporarily
+// unavailable, and reports whether it was. The Retry-After header is set when the error tells when to retry.
+func WriteUnavailableErrorResponse(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, domain.ErrExchangeRatesUnavailable) {
+		return false
+	}
+	var retryAfter interface{ RetryAfter() time.Duration }
+	if errors.As(err, &retryAfter) {
+		seconds := int(math.Ceil(retryAfter.RetryAfter().Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	}
+	log.Warn().Err(err).Msg("exchange rate provider unavailable")
+	WriteErrorResponse(w, http.StatusServiceUnavailable, domain.ErrExchangeRatesUnavailable.Error())
+	return true
+}
+
 // WriteErrorResponse writes an error response with the provided status code and message.
 func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the conversions without a stored transaction.
+
+// ConversionDTO represents the data transfer object for a conversion. The target currency exponent is the number of
+// decimal places the converted amount is rounded to.
+type ConversionDTO struct {
+	AmountInUSD            domain.Money            `json:"amount_in_usd"`
+	Date                   string                  `json:"date"`
+	ExchangeRateUsed       string                  `json:"exchange_rate_used"`
+	ExchangeRateDate       string                  `json:"exchange_rate_date"`
+	ExchangeRateSource     string                  `json:"exchange_rate_source,omitempty"`
+	RateSelectionPolicy    *RateSelectionPolicyDTO `json:"rate_selection_policy"`
+	AmountInTargetCurrency domain.Money            `json:"amount_in_target_currency"`
+	TargetCurrencyCode     string                  `json:"target_currency_code,omitempty"`
+	TargetCurrencyExponent int                     `json:"target_currency_exponent"`
+}
+
+// ConversionRequest holds the parsed query parameters of a conversion.
+type ConversionRequest struct {
+	AmountInUSD  domain.Money
+	CurrencyName string
+	Date         time.Time
+}
+
+// Convert handles the GET request to convert an amount in USD to a target currency on a date, without storing a
+// transaction. The date defaults to today.
+func (th *TransactionHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	request, validationErrors := ParseConversionRequest(r.URL.Query(), time.Now())
+	if len(validationErrors) > 0 {
+		validationErrorsAsStrings := make([]string, len(validationErrors))
+		for i, err := range validationErrors {
+			validationErrorsAsStrings[i] = err.Error()
+		}
+		log.Warn().Errs("validation_errors", validationErrors).Msg("conversion validation failed")
+		WriteErrorResponse(w, http.StatusBadRequest, "validation errors: "+strings.Join(validationErrorsAsStrings, ", "))
+		return
+	}
+
+	conversion, err := th.transactionService.ConvertAmount(r.Context(), request.AmountInUSD, request.CurrencyName,
+		request.Date)
+	if err != nil {
+		writeExchangeRateErrorResponse(w, err, request.CurrencyName)
+		return
+	}
+	rateSelectionPolicy := th.transactionService.RateSelectionPolicy()
+
+	WriteSuccessResponse(w, ConversionDTO{
+		AmountInUSD:        conversion.AmountInUSD,
+		Date:               conversion.Date.Format(time.DateOnly),
+		ExchangeRateUsed:   conversion.ExchangeRate.RateText,
+		ExchangeRateDate:   conversion.ExchangeRate.DateOfRecord.Format(time.DateOnly),
+		ExchangeRateSource: conversion.ExchangeRate.Source,
+		RateSelectionPolicy: &RateSelectionPolicyDTO{
+			Name:           rateSelectionPolicy.Name(),
+			LookbackMonths: rateSelectionPolicy.LookbackMonths(),
+		},
+		AmountInTargetCurrency: conversion.AmountInTargetCurrency,
+		TargetCurrencyCode:     conversion.ExchangeRate.CurrencyCode,
+		TargetCurrencyExponent: conversion.AmountInTargetCurrency.Exponent(),
+	}, http.StatusOK)
+}
+
+// ParseConversionRequest parses the URL query parameters of a conversion and validates them with the domain rules of
+// a transaction. Supported parameters are amount, currency and date (YYYY-MM-DD or ISO 8601, defaults to now).
+func ParseConversionRequest(values url.Values, now time.Time) (*ConversionRequest, []error) {
+	errs := make([]error, 0, 3)
+
+	request := &ConversionRequest{
+		CurrencyName: strings.TrimSpace(values.Get("currency")),
+		Date:         now,
+	}
+	if request.CurrencyName == "" {
+		errs = append(errs, ErrCurrencyNotProvided)
+	}
+
+	amountInUSD, err := domain.ParseMoney(values.Get("amount"), domain.CurrencyUSD)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("amount: %w", ErrInvalidAmountFormat))
+	}
+	request.AmountInUSD = amountInUSD
+
+	if dateString := values.Get("date"); dateString != "" {
+		date, err := ParseDate(dateString)
+		if err != nil {
+			// Accepts a full timestamp as well, like the transactions
+			date, err = ParseISO8601Timestamp(dateString)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("date: %w", ErrInvalidDateFormat))
+		}
+		request.Date = date
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if validationErrors := domain.ValidateConversion(request.AmountInUSD, request.Date); len(validationErrors) > 0 {
+		return nil, validationErrors
+	}
+	return request, nil
+}
+
+// isConversionValidationError reports whether the error comes from the domain validation of a conversion.
+func isConversionValidationError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidAmountInUSD) || errors.Is(err, domain.ErrAmountNotInUSD) ||
+		errors.Is(err, domain.ErrInvalidConversionDate)
+}
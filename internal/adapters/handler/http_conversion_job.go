@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the conversion jobs, converting the stored transactions in the background.
+
+// ConversionJobRequestDTO represents the data transfer object for the creation of a conversion job. The transactions
+// that occurred between from and to, when set, are converted to the currency.
+type ConversionJobRequestDTO struct {
+	Currency string `json:"currency"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+// ConversionJobDTO represents the data transfer object for a conversion job and its progress, between 0 and 1. The
+// URL of the result is only set once the job completed.
+type ConversionJobDTO struct {
+	ID          string  `json:"id"`
+	Status      string  `json:"status"`
+	Currency    string  `json:"currency"`
+	From        string  `json:"from,omitempty"`
+	To          string  `json:"to,omitempty"`
+	Total       int     `json:"total"`
+	Processed   int     `json:"processed"`
+	Converted   int     `json:"converted"`
+	Failed      int     `json:"failed"`
+	Progress    float64 `json:"progress"`
+	Error       string  `json:"error,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	StartedAt   string  `json:"started_at,omitempty"`
+	CompletedAt string  `json:"completed_at,omitempty"`
+	ResultURL   string  `json:"result_url,omitempty"`
+}
+
+// WithConversionJobService adds the conversion jobs to the routes of the handler, and returns the handler.
+func (th *TransactionHandler) WithConversionJobService(conversionJobService *services.ConversionJobService) *TransactionHandler {
+	th.conversionJobService = conversionJobService
+	return th
+}
+
+// CreateConversionJob handles the POST request to create a job converting the stored transactions to a currency in
+// the background. The job is accepted with a 202 Accepted, and its progress can be followed at its Location.
+func (th *TransactionHandler) CreateConversionJob(w http.ResponseWriter, r *http.Request) {
+	data := ConversionJobRequestDTO{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		log.Warn().Err(err).Msg("invalid request payload")
+		WriteErrorResponse(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+
+	job, validationErrors := NewConversionJob(data, time.Now())
+	if len(validationErrors) > 0 {
+		validationErrorsAsStrings := make([]string, len(validationErrors))
+		for i, err := range validationErrors {
+			validationErrorsAsStrings[i] = err.Error()
+		}
+		log.Warn().Errs("validation_errors", validationErrors).Msg("conversion job validation failed")
+		WriteErrorResponse(w, http.StatusBadRequest, "validation errors: "+strings.Join(validationErrorsAsStrings, ", "))
+		return
+	}
+
+	if err := th.conversionJobService.CreateConversionJob(r.Context(), *job); err != nil {
+		writeConversionJobErrorResponse(w, err, job.ID)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.ID.String())
+	WriteSuccessResponse(w, NewConversionJobDTO(job), http.StatusAccepted)
+}
+
+// FindConversionJob handles the GET request to find a conversion job and report its progress.
+func (th *TransactionHandler) FindConversionJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseConversionJobID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := th.conversionJobService.FindConversionJob(r.Context(), id)
+	if err != nil {
+		writeConversionJobErrorResponse(w, err, id)
+		return
+	}
+
+	WriteSuccessResponse(w, NewConversionJobDTO(job), http.StatusOK)
+}
+
+// DownloadConversionJobResult handles the GET request to download the result of a completed conversion job, in the
+// formats of the export of transactions: CSV by default, JSON lines or OFX. The result of a job that has not
+// completed gets a 409 Conflict.
+func (th *TransactionHandler) DownloadConversionJobResult(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseConversionJobID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := th.conversionJobService.FindConversionJob(r.Context(), id)
+	if err != nil {
+		writeConversionJobErrorResponse(w, err, id)
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	exportWriter, contentType, format, err := newTransactionExportWriter(w, format, job.CurrencyName, job.Query())
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("conversion-job-%s.%s", id, format)
+	exported, opened, err := writeTransactionExport(w, filename, contentType, exportWriter,
+		func(export func(*domain.Transaction, *domain.TransactionConversion) error) error {
+			return th.conversionJobService.StreamConversionJobResults(r.Context(), id,
+				func(result *domain.ConversionJobResult) error {
+					conversion, err := result.TransactionConversion(job.CurrencyName)
+					if err != nil {
+						return err
+					}
+					return export(&result.Transaction, conversion)
+				})
+		})
+	if err != nil && !opened {
+		writeConversionJobErrorResponse(w, err, id)
+		return
+	}
+	if err != nil {
+		// The response is already on its way, so the download can only be cut short
+		log.Error().Err(err).Str("job_id", id.String()).Int("transactions", exported).Msg("failed to download the conversion job result")
+		return
+	}
+	log.Info().Str("job_id", id.String()).Str("format", format).Int("transactions", exported).Msg("conversion job result downloaded")
+}
+
+// writeConversionJobErrorResponse writes the error response of a request on a conversion job.
+func writeConversionJobErrorResponse(w http.ResponseWriter, err error, id uuid.UUID) {
+	if WriteContextErrorResponse(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrUnknownCurrencyCode) || errors.Is(err, domain.ErrAmbiguousCurrencyCode):
+		log.Warn().Err(err).Str("job_id", id.String()).Msg("invalid conversion job currency")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrConversionJobNotCompleted):
+		WriteErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		log.Warn().Err(err).Str("job_id", id.String()).Msg("conversion job not found")
+		WriteErrorResponse(w, http.StatusNotFound, "conversion job not found")
+	}
+}
+
+// parseConversionJobID parses the conversion job ID of the request path, and writes the error response when it is
+// invalid.
+func parseConversionJobID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	idString := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		log.Warn().Err(err).Str("id", idString).Msg("invalid conversion job ID format")
+		WriteErrorResponse(w, http.StatusBadRequest, "invalid conversion job ID format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// NewConversionJob creates a queued conversion job from the provided request data. The dates are ISO 8601
+// timestamps.
+func NewConversionJob(data ConversionJobRequestDTO, createdAt time.Time) (*domain.ConversionJob, []error) {
+	errs := make([]error, 0, 2)
+	parseTimestamp := func(key, value string) *time.Time {
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		timestamp, err := ParseISO8601Timestamp(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			return nil
+		}
+		return &timestamp
+	}
+
+	from, to := parseTimestamp("from", data.From), parseTimestamp("to", data.To)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return domain.NewConversionJob(data.Currency, from, to, createdAt)
+}
+
+// NewConversionJobDTO creates the data transfer object of a conversion job.
+func NewConversionJobDTO(job *domain.ConversionJob) ConversionJobDTO {
+	jobDTO := ConversionJobDTO{
+		ID:        job.ID.String(),
+		Status:    job.Status,
+		Currency:  job.CurrencyName,
+		Total:     job.Total,
+		Processed: job.Processed,
+		Converted: job.Converted,
+		Failed:    job.Failed,
+		// The progress is rounded to a tenth of a percent
+		Progress:  math.Round(job.Progress()*1000) / 1000,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt.Format(time.RFC3339),
+	}
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	jobDTO.From, jobDTO.To = formatTime(job.From), formatTime(job.To)
+	jobDTO.StartedAt, jobDTO.CompletedAt = formatTime(job.StartedAt), formatTime(job.CompletedAt)
+	if job.Status == domain.ConversionJobCompleted {
+		jobDTO.ResultURL = "/jobs/" + job.ID.String() + "/result"
+	}
+	return jobDTO
+}
@@ -0,0 +1,258 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/google/uuid"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the conversion jobs. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the independent tests in parallel.
+
+// TestConversionJob tests the CreateConversionJob, FindConversionJob and DownloadConversionJobResult handlers. It
+// tests the following scenarios:
+//
+// 1. Job Created, Completed And Downloaded.
+// 2. Invalid Request Payload.
+// 3. Invalid Date Range.
+// 4. Invalid Date Format.
+// 5. Unknown Currency.
+// 6. Invalid Job ID.
+// 7. Unknown Job.
+// 8. Result Of A Queued Job.
+// 9. Invalid Result Format.
+func TestConversionJob(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/conversion_job_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	exchangeRates := make([]*domain.ExchangeRate, 0, 2)
+	for _, values := range [][]string{{"Euro", "0.897", "Euro Zone-Euro", "EUR"}, {"Peso", "19.6", "Mexico-Peso", "MXN"}} {
+		exchangeRate, errs := domain.NewExchangeRate(values[0], values[1], time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = values[2]
+		exchangeRate.CurrencyCode = values[3]
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+	require.NoError(t, exchangeRateRepo.SaveExchangeRates(context.Background(), exchangeRates))
+	// The exchange rates are stored as synchronized over 2024, so the adapter is never called
+	require.NoError(t, exchangeRateRepo.SaveExchangeRateCoverage(context.Background(), "",
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)))
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+
+	ids := make([]string, 0, 2)
+	for _, purchase := range []struct {
+		description string
+		timestamp   time.Time
+		amount      domain.Money
+	}{
+		{"Coffee", time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC), domain.MustParseMoney("10.00", domain.CurrencyUSD)},
+		{"Hotel & Spa", time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC), domain.MustParseMoney("25.79", "EUR")},
+	} {
+		transaction, errs := domain.NewTransactionInCurrency(purchase.description, purchase.timestamp, purchase.amount)
+		require.Empty(t, errs)
+		require.NoError(t, transactionRepo.SaveTransaction(context.Background(), *transaction))
+		ids = append(ids, transaction.ID.String())
+	}
+
+	// newRouter creates the routes of a handler with its own conversion jobs, run in the background when started
+	newRouter := func(t *testing.T, started bool) (http.Handler, *services.ConversionJobService) {
+		conversionJobRepo, err := repository.NewConversionJobRepositoryBoltDB(transactionRepo.GetBoltDB(), "conversion_jobs_"+uuid.New().String())
+		require.NoError(t, err)
+		conversionJobService := services.NewConversionJobService(conversionJobRepo, transactionService, 1)
+		if started {
+			conversionJobService.Start(context.Background())
+			t.Cleanup(conversionJobService.Stop)
+		}
+		return handler.NewTransactionHandler(*transactionService).WithConversionJobService(conversionJobService).Routes(),
+			conversionJobService
+	}
+	// readConversionJob reads the conversion job of a response
+	readConversionJob := func(t *testing.T, rr *httptest.ResponseRecorder) handler.ConversionJobDTO {
+		var body struct {
+			Data handler.ConversionJobDTO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return body.Data
+	}
+
+	t.Run("Job Created, Completed And Downloaded", func(t *testing.T) {
+		t.Parallel()
+		router, conversionJobService := newRouter(t, true)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/jobs/conversions",
+			strings.NewReader(`{"currency":"MXN","from":"2024-10-01T00:00:00Z"}`)))
+
+		require.Equal(t, http.StatusAccepted, rr.Code, rr.Body.String())
+		createdJob := readConversionJob(t, rr)
+		assert.Equal(t, "/jobs/"+createdJob.ID, rr.Header().Get("Location"))
+		assert.Equal(t, "MXN", createdJob.Currency)
+		assert.Equal(t, "2024-10-01T00:00:00Z", createdJob.From)
+		assert.Empty(t, createdJob.ResultURL)
+
+		// The progress is followed through the service, as the routes are rate limited
+		id, err := uuid.Parse(createdJob.ID)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			job, err := conversionJobService.FindConversionJob(context.Background(), id)
+			return err == nil && job.Done()
+		}, 5*time.Second, 10*time.Millisecond)
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/jobs/"+createdJob.ID, nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		completedJob := readConversionJob(t, rr)
+		assert.Equal(t, domain.ConversionJobCompleted, completedJob.Status)
+		assert.Equal(t, 2, completedJob.Total)
+		assert.Equal(t, 2, completedJob.Converted)
+		assert.Equal(t, float64(1), completedJob.Progress)
+		assert.NotEmpty(t, completedJob.CompletedAt)
+		assert.Equal(t, "/jobs/"+createdJob.ID+"/result", completedJob.ResultURL)
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, completedJob.ResultURL, nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "conversion-job-"+createdJob.ID+".csv")
+		records, err := csv.NewReader(strings.NewReader(rr.Body.String())).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, ids[0], records[1][0])
+		assert.Equal(t, []string{"MXN", "196.00", "MXN", "", ""}, records[1][7:])
+		assert.Equal(t, []string{"MXN", "563.53", "MXN", "", ""}, records[2][7:])
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, completedJob.ResultURL+"?format=ndjson", nil))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+		lines := make([]handler.TransactionExportDTO, 0)
+		scanner := bufio.NewScanner(strings.NewReader(rr.Body.String()))
+		for scanner.Scan() {
+			var line handler.TransactionExportDTO
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		require.Len(t, lines, 2)
+		assert.Equal(t, ids[1], lines[1].ID)
+		require.NotNil(t, lines[1].Conversion)
+		assert.Equal(t, "563.53", lines[1].Conversion.AmountInTargetCurrency.String())
+		assert.Len(t, lines[1].Conversion.ConversionLegs, 2)
+	})
+
+	// The workers are not started, so the jobs stay queued
+	router, conversionJobService := newRouter(t, false)
+	queuedJob, errs := domain.NewConversionJob("EUR", nil, nil, time.Now())
+	require.Empty(t, errs)
+	require.NoError(t, conversionJobService.CreateConversionJob(context.Background(), *queuedJob))
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "Invalid Request Payload",
+			method:          http.MethodPost,
+			path:            "/jobs/conversions",
+			body:            `{"currency":`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid request payload",
+		},
+		{
+			name:            "Invalid Date Range",
+			method:          http.MethodPost,
+			path:            "/jobs/conversions",
+			body:            `{"currency":"EUR","from":"2024-10-02T00:00:00Z","to":"2024-10-01T00:00:00Z"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "validation errors: " + domain.ErrInvalidConversionJobDateRange.Error(),
+		},
+		{
+			name:           "Invalid Date Format",
+			method:         http.MethodPost,
+			path:           "/jobs/conversions",
+			body:           `{"currency":"EUR","from":"yesterday"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "Unknown Currency",
+			method:          http.MethodPost,
+			path:            "/jobs/conversions",
+			body:            `{"currency":"XYZ"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: domain.ErrUnknownCurrencyCode.Error() + ": XYZ",
+		},
+		{
+			name:            "Invalid Job ID",
+			method:          http.MethodGet,
+			path:            "/jobs/not-a-uuid",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid conversion job ID format",
+		},
+		{
+			name:            "Unknown Job",
+			method:          http.MethodGet,
+			path:            "/jobs/" + uuid.New().String(),
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "conversion job not found",
+		},
+		{
+			name:            "Result Of A Queued Job",
+			method:          http.MethodGet,
+			path:            "/jobs/" + queuedJob.ID.String() + "/result",
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: domain.ErrConversionJobNotCompleted.Error(),
+		},
+		{
+			name:           "Invalid Result Format",
+			method:         http.MethodGet,
+			path:           "/jobs/" + queuedJob.ID.String() + "/result?format=xlsx",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			if tt.expectedMessage == "" {
+				return
+			}
+			var body handler.ErrorResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body.Error)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the conversions without a stored transaction. It uses Table
+// Driven Tests to test different scenarios. It uses Testify for assertions and mocking, and runs the tests in
+// parallel.
+
+// TestParseConversionRequest tests the ParseConversionRequest function. It tests the following scenarios:
+//
+// 1. All Parameters With A Date.
+// 2. Date Defaults To Now.
+// 3. Timestamp As Date.
+// 4. Missing Currency And Invalid Amount.
+// 5. Invalid Date Format.
+// 6. Negative Amount.
+// 7. Future Date.
+func TestParseConversionRequest(t *testing.T) {
+	now := time.Date(2024, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedAmount string
+		expectedDate   time.Time
+		expectedErrors []error
+	}{
+		{
+			name:           "All Parameters With A Date",
+			query:          "amount=28.745&currency=JPY&date=2024-10-01",
+			expectedAmount: "28.745",
+			expectedDate:   time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "Date Defaults To Now",
+			query:          "amount=10&currency=EUR",
+			expectedAmount: "10",
+			expectedDate:   now,
+		},
+		{
+			name:           "Timestamp As Date",
+			query:          "amount=10&currency=EUR&date=2024-10-01T15:04:05Z",
+			expectedAmount: "10",
+			expectedDate:   time.Date(2024, 10, 1, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:           "Missing Currency And Invalid Amount",
+			query:          "amount=ten",
+			expectedErrors: []error{handler.ErrCurrencyNotProvided, handler.ErrInvalidAmountFormat},
+		},
+		{
+			name:           "Invalid Date Format",
+			query:          "amount=10&currency=EUR&date=01/10/2024",
+			expectedErrors: []error{handler.ErrInvalidDateFormat},
+		},
+		{
+			name:           "Negative Amount",
+			query:          "amount=-10&currency=EUR",
+			expectedErrors: []error{domain.ErrInvalidAmountInUSD},
+		},
+		{
+			name:           "Future Date",
+			query:          "amount=10&currency=EUR&date=2999-01-01",
+			expectedErrors: []error{domain.ErrInvalidConversionDate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			request, errs := handler.ParseConversionRequest(values, now)
+
+			if len(tt.expectedErrors) > 0 {
+				require.Len(t, errs, len(tt.expectedErrors))
+				for i, expectedError := range tt.expectedErrors {
+					assert.ErrorIs(t, errs[i], expectedError)
+				}
+				assert.Nil(t, request)
+				return
+			}
+			require.Empty(t, errs)
+			assert.Equal(t, tt.expectedAmount, request.AmountInUSD.String())
+			assert.Equal(t, tt.expectedDate, request.Date)
+		})
+	}
+}
+
+// TestConvert tests the Convert handler. It tests the following scenarios:
+//
+// 1. Conversion On A Date.
+// 2. Invalid Amount.
+// 3. No Applicable Exchange Rate.
+func TestConvert(t *testing.T) {
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/conversion_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	exchangeRate, errs := domain.NewExchangeRate("Yen", "143.57", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(t, errs)
+	exchangeRate.CountryCurrencyDesc = "Japan-Yen"
+	exchangeRate.CurrencyCode = "JPY"
+	exchangeRate.Source = domain.ExchangeRateSourceECB
+	mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+	mockAdapter.On("GetExchangeRatesBetween", mock.Anything, mock.Anything, mock.Anything).Return([]*domain.ExchangeRate{exchangeRate}, nil)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo, mockAdapter, nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Conversion On A Date",
+			query:          "?amount=28.745&currency=JPY&date=2024-10-01",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":{"amount_in_usd":"28.75","date":"2024-10-01","exchange_rate_used":"143.57",` +
+				`"exchange_rate_date":"2024-09-30","exchange_rate_source":"ecb",` +
+				`"rate_selection_policy":{"name":"latest_on_or_before","lookback_months":6},` +
+				`"amount_in_target_currency":"4128","target_currency_code":"JPY","target_currency_exponent":0}}`,
+		},
+		{
+			name:           "Invalid Amount",
+			query:          "?amount=0&currency=JPY",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "No Applicable Exchange Rate",
+			query:          "?amount=10&currency=JPY&date=2024-01-01",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/convert"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+// TestConvertExchangeRatesUnavailable tests the responses of the handlers while the circuit breaker of the exchange
+// rate adapter is open. The steps depend on each other, so they run in order. It tests the following scenarios:
+//
+// 1. Treasury API Failure Opens The Circuit.
+// 2. Open Circuit Fails Fast With Retry-After.
+// 3. Currencies Fail Fast.
+// 4. Health Reports The Open Circuit.
+func TestConvertExchangeRatesUnavailable(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/unavailable_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+	mockAdapter.On("GetExchangeRatesBetween", mock.Anything, mock.Anything, mock.Anything).
+		Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue).Once()
+	breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(mockAdapter, 1, 1, time.Minute, nil)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo, breaker, nil)
+	transactionHandler := handler.NewTransactionHandler(*transactionService)
+	transactionHandler.RegisterHealthCheck("exchange_rate_circuit_breaker", func() interface{} {
+		return breaker.Stats()
+	})
+	router := transactionHandler.Routes()
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{
+			name:           "Treasury API Failure Opens The Circuit",
+			path:           "/convert?amount=10&currency=JPY&date=2024-10-01",
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name:               "Open Circuit Fails Fast With Retry-After",
+			path:               "/convert?amount=10&currency=JPY&date=2024-10-01",
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "60",
+		},
+		{
+			name:               "Currencies Fail Fast",
+			path:               "/currencies",
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "60",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequestWithContext(context.Background(), http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedRetryAfter, rr.Header().Get("Retry-After"))
+		})
+	}
+
+	// Health Reports The Open Circuit
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var health struct {
+		CircuitBreaker client.CircuitBreakerStats `json:"exchange_rate_circuit_breaker"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &health))
+	assert.Equal(t, client.CircuitOpen, health.CircuitBreaker.State)
+	assert.Equal(t, uint64(2), health.CircuitBreaker.Rejections)
+	mockAdapter.AssertExpectations(t)
+}
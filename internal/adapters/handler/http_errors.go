@@ -13,4 +13,64 @@ var (
 
 	// ErrInvalidTimestamp is returned when the timestamp is in the future.
 	ErrInvalidTimestamp = errors.New("transaction timestamp cannot be in the future")
+
+	// ErrInvalidAmountFormat is returned when an amount query parameter is not a valid number.
+	ErrInvalidAmountFormat = errors.New("amount must be a valid number")
+
+	// ErrInvalidLimitFormat is returned when the limit query parameter is not a valid integer.
+	ErrInvalidLimitFormat = errors.New("limit must be a valid integer")
+
+	// ErrConflictingAmounts is returned when a transaction is given both an amount in USD and an amount in a currency.
+	ErrConflictingAmounts = errors.New("either amount_in_usd or amount with currency must be provided, not both")
+
+	// ErrCurrencyNotProvided is returned when the target currency of a conversion is not provided.
+	ErrCurrencyNotProvided = errors.New("currency not provided")
+
+	// ErrInvalidDateFormat is returned when a date query parameter is not in the YYYY-MM-DD format.
+	ErrInvalidDateFormat = errors.New("date must be in the YYYY-MM-DD format")
+
+	// ErrInvalidResponseFormat is returned when the requested response format is not supported.
+	ErrInvalidResponseFormat = errors.New("format must be json or csv")
+
+	// ErrInvalidIncludeCurrentFormat is returned when the include_current query parameter is not a valid boolean.
+	ErrInvalidIncludeCurrentFormat = errors.New("include_current must be true or false")
+
+	// ErrIfMatchRequired is returned when a transaction is updated or deleted without the If-Match header.
+	ErrIfMatchRequired = errors.New("the If-Match header with the ETag of the transaction is required")
+
+	// ErrInvalidIfMatch is returned when the If-Match header is not the ETag of a version of a transaction.
+	ErrInvalidIfMatch = errors.New("the If-Match header must be the ETag of a version of the transaction")
+
+	// ErrEmptyTransactionPatch is returned when the update of a transaction changes none of its fields.
+	ErrEmptyTransactionPatch = errors.New("at least one of description, timestamp, amount_in_usd, amount or " +
+		"currency must be provided")
+
+	// ErrCurrencyWithoutAmount is returned when the currency of a transaction is updated without its amount.
+	ErrCurrencyWithoutAmount = errors.New("the amount must be provided with the currency")
+
+	// ErrUnsupportedImportContentType is returned when an import of transactions is neither CSV nor JSON lines.
+	ErrUnsupportedImportContentType = errors.New("the import must be sent as text/csv or application/x-ndjson")
+
+	// ErrImportTooLarge is returned when the body of an import of transactions exceeds its maximum size.
+	ErrImportTooLarge = errors.New("the import cannot exceed 10 MB")
+
+	// ErrTooManyImportRows is returned when an import of transactions exceeds its maximum number of rows.
+	ErrTooManyImportRows = errors.New("the import cannot exceed 10000 rows")
+
+	// ErrEmptyImport is returned when an import of transactions has no row.
+	ErrEmptyImport = errors.New("the import has no transaction")
+
+	// ErrInvalidImportHeader is returned when the header row of an import in CSV does not name the columns of a
+	// transaction.
+	ErrInvalidImportHeader = errors.New("the CSV header must name the description and timestamp columns, and " +
+		"amount_in_usd, amount or currency")
+
+	// ErrInvalidImportRow is returned for a row of an import that cannot be decoded.
+	ErrInvalidImportRow = errors.New("invalid row")
+
+	// ErrInvalidAllOrNothingFormat is returned when the all_or_nothing query parameter is not a valid boolean.
+	ErrInvalidAllOrNothingFormat = errors.New("all_or_nothing must be true or false")
+
+	// ErrInvalidExportFormat is returned when the requested format of an export of transactions is not supported.
+	ErrInvalidExportFormat = errors.New("format must be csv, ndjson or ofx")
 )
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the exchange rate history.
+
+// Response formats of the exchange rate history.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// NextCursorHeader is the response header holding the cursor of the next page of a CSV response.
+const NextCursorHeader = "X-Next-Cursor"
+
+// exchangeRateCSVHeader is the header row of the exchange rates in CSV.
+var exchangeRateCSVHeader = []string{"date_of_record", "currency_name", "country_currency_name", "currency_code", "rate"}
+
+// ExchangeRateDTO represents the data transfer object for exchange rates. The rate is the decimal string published
+// by the source.
+type ExchangeRateDTO struct {
+	CurrencyName        string `json:"currency_name"`
+	CountryCurrencyName string `json:"country_currency_name,omitempty"`
+	CurrencyCode        string `json:"currency_code,omitempty"`
+	Rate                string `json:"rate"`
+	DateOfRecord        string `json:"date_of_record"`
+	Source              string `json:"source,omitempty"`
+}
+
+// ExchangeRatePageDTO represents the data transfer object for a page of the exchange rate history.
+type ExchangeRatePageDTO struct {
+	ExchangeRates []ExchangeRateDTO `json:"exchange_rates"`
+	NextCursor    string            `json:"next_cursor,omitempty"`
+}
+
+// ExchangeRateAsOfDTO represents the data transfer object for the exchange rate applicable on a date, with the
+// policy that selected it.
+type ExchangeRateAsOfDTO struct {
+	AsOf                string                  `json:"as_of"`
+	ExchangeRate        ExchangeRateDTO         `json:"exchange_rate"`
+	RateSelectionPolicy *RateSelectionPolicyDTO `json:"rate_selection_policy"`
+}
+
+// FindExchangeRates handles the GET request to list the exchange rate history of a currency page by page, most
+// recent first. When the as_of parameter is set, only the exchange rate applicable on that date is returned, as
+// selected for the conversions, and the other filters are ignored. The format parameter selects JSON (default) or
+// CSV output.
+func (th *TransactionHandler) FindExchangeRates(w http.ResponseWriter, r *http.Request) {
+	currencyName := chi.URLParam(r, "currency")
+	values := r.URL.Query()
+
+	format := strings.ToLower(strings.TrimSpace(values.Get("format")))
+	if format == "" {
+		format = formatJSON
+	}
+	if format != formatJSON && format != formatCSV {
+		log.Warn().Str("format", format).Msg("invalid exchange rate response format")
+		WriteErrorResponse(w, http.StatusBadRequest, ErrInvalidResponseFormat.Error())
+		return
+	}
+
+	if values.Get("as_of") != "" {
+		th.findExchangeRateAsOf(w, r, currencyName, values.Get("as_of"), format)
+		return
+	}
+
+	query, validationErrors := ParseExchangeRateQuery(currencyName, values)
+	if len(validationErrors) > 0 {
+		validationErrorsAsStrings := make([]string, len(validationErrors))
+		for i, err := range validationErrors {
+			validationErrorsAsStrings[i] = err.Error()
+		}
+		log.Warn().Errs("validation_errors", validationErrors).Msg("exchange rate query validation failed")
+		WriteErrorResponse(w, http.StatusBadRequest, "validation errors: "+strings.Join(validationErrorsAsStrings, ", "))
+		return
+	}
+
+	page, err := th.transactionService.FindExchangeRateHistory(r.Context(), *query)
+	if err != nil {
+		writeExchangeRateErrorResponse(w, err, currencyName)
+		return
+	}
+
+	if format == formatCSV {
+		if page.NextCursor != "" {
+			w.Header().Set(NextCursorHeader, page.NextCursor)
+		}
+		WriteCSVResponse(w, exchangeRateCSVRecords(page.ExchangeRates...), http.StatusOK)
+		return
+	}
+
+	pageDTO := ExchangeRatePageDTO{
+		ExchangeRates: make([]ExchangeRateDTO, len(page.ExchangeRates)),
+		NextCursor:    page.NextCursor,
+	}
+	for i, exchangeRate := range page.ExchangeRates {
+		pageDTO.ExchangeRates[i] = NewExchangeRateDTO(exchangeRate)
+	}
+
+	WriteSuccessResponse(w, pageDTO, http.StatusOK)
+}
+
+// findExchangeRateAsOf writes the exchange rate of a currency applicable on a date.
+func (th *TransactionHandler) findExchangeRateAsOf(w http.ResponseWriter, r *http.Request, currencyName string,
+	asOfString string, format string) {
+	asOf, err := ParseDate(asOfString)
+	if err != nil {
+		log.Warn().Err(err).Str("as_of", asOfString).Msg("invalid as of date")
+		WriteErrorResponse(w, http.StatusBadRequest, "as_of: "+err.Error())
+		return
+	}
+
+	exchangeRate, err := th.transactionService.FindExchangeRateAsOf(r.Context(), currencyName, asOf)
+	if err != nil {
+		writeExchangeRateErrorResponse(w, err, currencyName)
+		return
+	}
+
+	if format == formatCSV {
+		WriteCSVResponse(w, exchangeRateCSVRecords(exchangeRate), http.StatusOK)
+		return
+	}
+
+	rateSelectionPolicy := th.transactionService.RateSelectionPolicy()
+	WriteSuccessResponse(w, ExchangeRateAsOfDTO{
+		AsOf:         asOf.Format(time.DateOnly),
+		ExchangeRate: NewExchangeRateDTO(exchangeRate),
+		RateSelectionPolicy: &RateSelectionPolicyDTO{
+			Name:           rateSelectionPolicy.Name(),
+			LookbackMonths: rateSelectionPolicy.LookbackMonths(),
+		},
+	}, http.StatusOK)
+}
+
+// writeExchangeRateErrorResponse maps the errors of the exchange rate lookups to error responses.
+func writeExchangeRateErrorResponse(w http.ResponseWriter, err error, currencyName string) {
+	if WriteContextErrorResponse(w, err) || WriteUnavailableErrorResponse(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrUnknownCurrencyCode), errors.Is(err, domain.ErrAmbiguousCurrencyCode),
+		errors.Is(err, domain.ErrInvalidExchangeRateCursor), isConversionValidationError(err):
+		log.Warn().Err(err).Str("currency", currencyName).Msg("invalid exchange rate request")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrNoApplicableExchangeRate):
+		log.Warn().Err(err).Str("currency", currencyName).Msg("no applicable exchange rate")
+		WriteErrorResponse(w, http.StatusNotFound, "no exchange rate applies to the requested date")
+	default:
+		log.Error().Err(err).Str("currency", currencyName).Msg("failed to retrieve the exchange rates")
+		WriteErrorResponse(w, http.StatusBadGateway, "failed to retrieve the exchange rates")
+	}
+}
+
+// NewExchangeRateDTO creates the data transfer object of an exchange rate.
+func NewExchangeRateDTO(exchangeRate *domain.ExchangeRate) ExchangeRateDTO {
+	return ExchangeRateDTO{
+		CurrencyName:        exchangeRate.CurrencyName,
+		CountryCurrencyName: exchangeRate.CountryCurrencyDesc,
+		CurrencyCode:        exchangeRate.CurrencyCode,
+		Rate:                exchangeRate.RateText,
+		DateOfRecord:        exchangeRate.DateOfRecord.Format(time.DateOnly),
+		Source:              exchangeRate.Source,
+	}
+}
+
+// ParseExchangeRateQuery parses the URL query parameters of the exchange rate history into an ExchangeRateQuery.
+// Supported parameters are from, to (YYYY-MM-DD), cursor and limit.
+func ParseExchangeRateQuery(currencyName string, values url.Values) (*domain.ExchangeRateQuery, []error) {
+	errs := make([]error, 0, 3)
+
+	parseDate := func(key string) *time.Time {
+		if values.Get(key) == "" {
+			return nil
+		}
+		date, err := ParseDate(values.Get(key))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			return nil
+		}
+		return &date
+	}
+
+	from, to := parseDate("from"), parseDate("to")
+	limit := 0
+	if values.Get("limit") != "" {
+		parsedLimit, err := strconv.Atoi(strings.TrimSpace(values.Get("limit")))
+		if err != nil {
+			errs = append(errs, ErrInvalidLimitFormat)
+		}
+		limit = parsedLimit
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return domain.NewExchangeRateQuery(currencyName, from, to, values.Get("cursor"), limit)
+}
+
+// ParseDate parses a date in the YYYY-MM-DD format, like the dates of record of the exchange rates.
+func ParseDate(dateString string) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, strings.TrimSpace(dateString))
+	if err != nil {
+		return time.Time{}, ErrInvalidDateFormat
+	}
+	return date, nil
+}
+
+// exchangeRateCSVRecords turns exchange rates into CSV records, header included.
+func exchangeRateCSVRecords(exchangeRates ...*domain.ExchangeRate) [][]string {
+	records := make([][]string, 0, len(exchangeRates)+1)
+	records = append(records, exchangeRateCSVHeader)
+	for _, exchangeRate := range exchangeRates {
+		records = append(records, []string{
+			exchangeRate.DateOfRecord.Format(time.DateOnly),
+			exchangeRate.CurrencyName,
+			exchangeRate.CountryCurrencyDesc,
+			exchangeRate.CurrencyCode,
+			exchangeRate.RateText,
+		})
+	}
+	return records
+}
+
+// WriteCSVResponse writes a CSV response with the provided records and status code.
+func WriteCSVResponse(w http.ResponseWriter, records [][]string, statusCode int) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	if err := csv.NewWriter(w).WriteAll(records); err != nil {
+		log.Error().Err(err).Msg("failed to encode response")
+	}
+}
@@ -0,0 +1,192 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/google/uuid"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the exchange rate history. It uses Table Driven Tests to test
+// different scenarios. It uses Testify for assertions and mocking, and runs the tests in parallel.
+
+// TestFindExchangeRates tests the FindExchangeRates handler. It tests the following scenarios:
+//
+// 1. History Page In JSON.
+// 2. History Page In CSV.
+// 3. As Of Lookup.
+// 4. As Of Lookup Without Applicable Exchange Rate.
+// 5. Invalid Format.
+// 6. Invalid Date.
+// 7. Unknown Currency Code.
+// 8. Adapter Failure.
+func TestFindExchangeRates(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/exchange_rate_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	newExchangeRate := func(rate string, dateOfRecord time.Time) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Real", rate, dateOfRecord)
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = "Brazil-Real"
+		exchangeRate.CurrencyCode = "BRL"
+		return exchangeRate
+	}
+	exchangeRates := []*domain.ExchangeRate{
+		newExchangeRate("5.434", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)),
+		newExchangeRate("5.56", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}
+
+	tests := []struct {
+		name              string
+		currency          string
+		query             string
+		mockExchangeRates []*domain.ExchangeRate
+		mockError         error
+		expectedStatus    int
+		expectedBody      string
+		expectedCSV       string
+		expectedCursor    bool
+	}{
+		{
+			name:              "History Page In JSON",
+			currency:          "BRL",
+			query:             "?from=2024-01-01&to=2024-12-31&limit=1",
+			mockExchangeRates: exchangeRates,
+			expectedStatus:    http.StatusOK,
+			expectedBody: `[{"currency_name":"Real","country_currency_name":"Brazil-Real","currency_code":"BRL",` +
+				`"rate":"5.434","date_of_record":"2024-09-30"}]`,
+			expectedCursor: true,
+		},
+		{
+			name:              "History Page In CSV",
+			currency:          "BRL",
+			query:             "?format=csv",
+			mockExchangeRates: exchangeRates,
+			expectedStatus:    http.StatusOK,
+			expectedCSV: "date_of_record,currency_name,country_currency_name,currency_code,rate\n" +
+				"2024-09-30,Real,Brazil-Real,BRL,5.434\n" +
+				"2024-06-30,Real,Brazil-Real,BRL,5.56\n",
+		},
+		{
+			name:              "As Of Lookup",
+			currency:          "BRL",
+			query:             "?as_of=2024-08-15",
+			mockExchangeRates: exchangeRates,
+			expectedStatus:    http.StatusOK,
+			expectedBody: `{"as_of":"2024-08-15","exchange_rate":{"currency_name":"Real",` +
+				`"country_currency_name":"Brazil-Real","currency_code":"BRL","rate":"5.56","date_of_record":"2024-06-30"},` +
+				`"rate_selection_policy":{"name":"latest_on_or_before","lookback_months":6}}`,
+		},
+		{
+			name:              "As Of Lookup Without Applicable Exchange Rate",
+			currency:          "BRL",
+			query:             "?as_of=2023-01-01",
+			mockExchangeRates: exchangeRates,
+			expectedStatus:    http.StatusNotFound,
+		},
+		{
+			name:           "Invalid Format",
+			currency:       "BRL",
+			query:          "?format=xml",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Date",
+			currency:       "BRL",
+			query:          "?from=2024-13-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown Currency Code",
+			currency:       "XYZ",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:              "Adapter Failure",
+			currency:          "Real",
+			mockExchangeRates: []*domain.ExchangeRate(nil),
+			mockError:         client.ErrNetworkIssue,
+			expectedStatus:    http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Each test uses its own exchange rate bucket and adapter
+			exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates_"+uuid.New().String())
+			require.NoError(t, err)
+			mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+			mockAdapter.On("GetExchangeRates", tt.currency).Return(tt.mockExchangeRates, tt.mockError)
+			mockAdapter.On("GetExchangeRatesBetween", tt.currency, mock.Anything, mock.Anything).
+				Return(tt.mockExchangeRates, tt.mockError)
+			transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo, mockAdapter, nil)
+			router := handler.NewTransactionHandler(*transactionService).Routes()
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/exchange-rates/"+tt.currency+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedCSV != "" {
+				assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+				assert.Equal(t, tt.expectedCSV, rr.Body.String())
+			}
+			if tt.expectedBody != "" {
+				var response struct {
+					Data jsoniter.RawMessage `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				actualBody := string(response.Data)
+				if tt.expectedCursor {
+					var page handler.ExchangeRatePageDTO
+					require.NoError(t, json.Unmarshal(response.Data, &page))
+					assert.NotEmpty(t, page.NextCursor)
+					exchangeRatesJSON, err := json.Marshal(page.ExchangeRates)
+					require.NoError(t, err)
+					actualBody = string(exchangeRatesJSON)
+				}
+				assert.JSONEq(t, tt.expectedBody, actualBody)
+			}
+		})
+	}
+}
+
+// TestParseDate tests the ParseDate function. It tests the following scenarios:
+//
+// 1. Valid Date.
+// 2. Timestamp Instead Of A Date.
+func TestParseDate(t *testing.T) {
+	t.Run("Valid Date", func(t *testing.T) {
+		t.Parallel()
+		date, err := handler.ParseDate(" 2024-09-30 ")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC), date)
+	})
+
+	t.Run("Timestamp Instead Of A Date", func(t *testing.T) {
+		t.Parallel()
+		_, err := handler.ParseDate("2024-09-30T00:00:00Z")
+		assert.ErrorIs(t, err, handler.ErrInvalidDateFormat)
+	})
+}
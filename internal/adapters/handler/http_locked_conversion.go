@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the locking of the conversion of a transaction, and the responses of the
+// locked conversions.
+
+// LockConversion handles the POST request to convert a transaction to a target currency and lock the conversion on
+// the transaction. Later reads of the conversion return the locked figures.
+func (th *TransactionHandler) LockConversion(w http.ResponseWriter, r *http.Request) {
+	idString := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		log.Warn().Err(err).Str("id", idString).Msg("invalid transaction ID format")
+		WriteErrorResponse(w, http.StatusBadRequest, "invalid transaction ID format")
+		return
+	}
+	currencyName := chi.URLParam(r, "currency")
+
+	transaction, lockedConversion, err := th.transactionService.LockConversion(r.Context(), id, currencyName)
+	if WriteContextErrorResponse(w, err) || WriteUnavailableErrorResponse(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrConversionAlreadyLocked):
+		log.Warn().Err(err).Str("transaction_id", id.String()).Str("currency", currencyName).Msg("conversion already locked")
+		WriteErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, domain.ErrUnknownCurrencyCode), errors.Is(err, domain.ErrAmbiguousCurrencyCode):
+		log.Warn().Err(err).Str("currency", currencyName).Msg("invalid currency code")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		log.Warn().Err(err).Str("transaction_id", id.String()).Msg("transaction conversion cannot be locked")
+		WriteErrorResponse(w, http.StatusNotFound, "the purchase cannot be converted to the target currency")
+		return
+	}
+
+	th.writeLockedConversionResponse(w, transaction, &domain.TransactionConversion{
+		CurrencyName: currencyName,
+		Locked:       lockedConversion,
+	}, false, http.StatusCreated)
+}
+
+// writeLockedConversionResponse writes the response of a transaction with a locked conversion. The current
+// conversion is added when includeCurrent is true.
+func (th *TransactionHandler) writeLockedConversionResponse(w http.ResponseWriter, transaction *domain.Transaction,
+	conversion *domain.TransactionConversion, includeCurrent bool, statusCode int) {
+	lockedConversion, err := conversion.Locked.TransactionConversion(conversion.CurrencyName)
+	if err != nil {
+		log.Error().Err(err).Str("transaction_id", transaction.ID.String()).Msg("invalid locked conversion")
+		WriteErrorResponse(w, http.StatusInternalServerError, "failed to read the locked conversion")
+		return
+	}
+
+	transactionDTO := NewConvertedTransactionDTO(transaction, lockedConversion)
+	transactionDTO.RateSelectionPolicy = NewLockedRateSelectionPolicyDTO(conversion.Locked)
+	transactionDTO.LockedAt = conversion.Locked.LockedAt.Format(time.RFC3339)
+	if includeCurrent {
+		currentConversion := NewCurrencyConversionDTO(transaction, conversion)
+		transactionDTO.CurrentConversion = &currentConversion
+	}
+
+	WriteSuccessResponse(w, transactionDTO, statusCode)
+}
+
+// NewLockedCurrencyConversionDTO creates the data transfer object of a locked conversion of a transaction to one
+// currency. The current conversion is added when includeCurrent is true.
+func NewLockedCurrencyConversionDTO(transaction *domain.Transaction, conversion *domain.TransactionConversion,
+	includeCurrent bool) CurrencyConversionDTO {
+	lockedConversion, err := conversion.Locked.TransactionConversion(conversion.CurrencyName)
+	if err != nil {
+		log.Error().Err(err).Str("transaction_id", transaction.ID.String()).Msg("invalid locked conversion")
+		return CurrencyConversionDTO{
+			Currency: conversion.CurrencyName,
+			Error:    "failed to read the locked conversion",
+		}
+	}
+
+	conversionDTO := NewCurrencyConversionDTO(transaction, lockedConversion)
+	conversionDTO.RateSelectionPolicy = NewLockedRateSelectionPolicyDTO(conversion.Locked)
+	conversionDTO.LockedAt = conversion.Locked.LockedAt.Format(time.RFC3339)
+	if includeCurrent {
+		currentConversion := NewCurrencyConversionDTO(transaction, conversion)
+		conversionDTO.CurrentConversion = &currentConversion
+	}
+	return conversionDTO
+}
+
+// NewLockedRateSelectionPolicyDTO creates the data transfer object of the policy a conversion was locked with.
+func NewLockedRateSelectionPolicyDTO(lockedConversion *domain.LockedConversion) *RateSelectionPolicyDTO {
+	return &RateSelectionPolicyDTO{
+		Name:           lockedConversion.RateSelectionPolicy,
+		LookbackMonths: lockedConversion.LookbackMonths,
+	}
+}
+
+// ParseIncludeCurrent parses the include_current query parameter, which adds the current conversion to a locked
+// conversion. It defaults to false.
+func ParseIncludeCurrent(values url.Values) (bool, error) {
+	includeCurrentString := values.Get("include_current")
+	if includeCurrentString == "" {
+		return false, nil
+	}
+	includeCurrent, err := strconv.ParseBool(includeCurrentString)
+	if err != nil {
+		return false, ErrInvalidIncludeCurrentFormat
+	}
+	return includeCurrent, nil
+}
@@ -0,0 +1,227 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the locked conversions. It uses Table Driven Tests to test
+// different scenarios. It uses Testify for assertions and runs the independent tests in parallel.
+
+// TestParseIncludeCurrent tests the ParseIncludeCurrent function. It tests the following scenarios:
+//
+// 1. Defaults To False.
+// 2. True.
+// 3. Invalid Value.
+func TestParseIncludeCurrent(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expected      bool
+		expectedError error
+	}{
+		{
+			name:     "Defaults To False",
+			query:    "",
+			expected: false,
+		},
+		{
+			name:     "True",
+			query:    "include_current=true",
+			expected: true,
+		},
+		{
+			name:          "Invalid Value",
+			query:         "include_current=maybe",
+			expectedError: handler.ErrInvalidIncludeCurrentFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			includeCurrent, err := handler.ParseIncludeCurrent(values)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expected, includeCurrent)
+		})
+	}
+}
+
+// TestLockConversion tests the locking of a conversion and the reads of the locked conversion through the HTTP
+// handlers. The steps depend on each other, so they run in order. It tests the following scenarios:
+//
+// 1. Lock A Conversion.
+// 2. Conversion Locked Twice.
+// 3. Unknown Currency.
+// 4. Transaction Not Found.
+// 5. Locked Figures Survive A Rate Revision.
+// 6. Current Conversion Included.
+// 7. Several Currencies With A Locked One.
+// 8. Invalid Include Current.
+func TestLockConversion(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/locked_conversion_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	saveExchangeRate := func(rate string) {
+		exchangeRate, errs := domain.NewExchangeRate("Peso", rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = "Mexico-Peso"
+		exchangeRate.CurrencyCode = "MXN"
+		require.NoError(t, exchangeRateRepo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{exchangeRate}))
+	}
+	saveExchangeRate("19.6")
+	// The exchange rates are stored as synchronized over 2024, so the adapter is never called
+	require.NoError(t, exchangeRateRepo.SaveExchangeRateCoverage(context.Background(), "",
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)))
+	transaction, errs := domain.NewTransaction("Hotel in Mexico City", time.Date(2024, 10, 1, 20, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("10.00", domain.CurrencyUSD))
+	require.Empty(t, errs)
+	require.NoError(t, transactionRepo.SaveTransaction(context.Background(), *transaction))
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	id := transaction.ID.String()
+	transactionBody := `"id":"` + id + `","description":"Hotel in Mexico City","timestamp":"2024-10-01 20:00:00",` +
+		`"amount_in_usd":"10.00","rate_selection_policy":{"name":"latest_on_or_before","lookback_months":6}`
+	lockedBody := `"exchange_rate_used":"19.6","exchange_rate_date":"2024-09-30","amount_in_target_currency":"196.00",` +
+		`"target_currency_code":"MXN","target_currency_exponent":2`
+	currentBody := `{"currency":"MXN","exchange_rate_used":"20.0","exchange_rate_date":"2024-09-30",` +
+		`"amount_in_target_currency":"200.00","target_currency_code":"MXN","target_currency_exponent":2}`
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		revisedRate    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Lock A Conversion",
+			method:         http.MethodPost,
+			path:           "/transactions/" + id + "/MXN/lock",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"data":{` + transactionBody + `,` + lockedBody + `}}`,
+		},
+		{
+			name:           "Conversion Locked Twice",
+			method:         http.MethodPost,
+			path:           "/transactions/" + id + "/Mexico-Peso/lock",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Unknown Currency",
+			method:         http.MethodPost,
+			path:           "/transactions/" + id + "/XYZ/lock",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Transaction Not Found",
+			method:         http.MethodPost,
+			path:           "/transactions/6f1b7f6e-1f2a-4c1e-9d59-3f8a2b1c0d4e/MXN/lock",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Locked Figures Survive A Rate Revision",
+			method:         http.MethodGet,
+			path:           "/transactions/" + id + "/MXN",
+			revisedRate:    "20.0",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{` + transactionBody + `,` + lockedBody + `}}`,
+		},
+		{
+			name:           "Current Conversion Included",
+			method:         http.MethodGet,
+			path:           "/transactions/" + id + "/MXN?include_current=true",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{` + transactionBody + `,` + lockedBody + `,"current_conversion":` + currentBody + `}}`,
+		},
+		{
+			name:           "Several Currencies With A Locked One",
+			method:         http.MethodGet,
+			path:           "/transactions/" + id + "/conversions?currencies=MXN,USD&include_current=true",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":{` + transactionBody + `,"conversions":[{"currency":"MXN",` + lockedBody + `,` +
+				`"rate_selection_policy":{"name":"latest_on_or_before","lookback_months":6},` +
+				`"current_conversion":` + currentBody + `},` +
+				`{"currency":"USD","amount_in_target_currency":"10.00","target_currency_code":"USD",` +
+				`"target_currency_exponent":2}]}}`,
+		},
+		{
+			name:           "Invalid Include Current",
+			method:         http.MethodGet,
+			path:           "/transactions/" + id + "/MXN?include_current=maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.revisedRate != "" {
+				// The Treasury revises the exchange rate after the conversion was locked
+				saveExchangeRate(tt.revisedRate)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody == "" {
+				return
+			}
+			// The lock time is checked apart, since it is the time the test ran
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			removeLockedAt(t, body["data"])
+			actualBody, err := json.Marshal(body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedBody, string(actualBody))
+		})
+	}
+}
+
+// removeLockedAt checks and removes the lock times of a response data, and of its conversions.
+func removeLockedAt(t *testing.T, data interface{}) {
+	t.Helper()
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if lockedAt, ok := fields["locked_at"].(string); ok {
+		_, err := time.Parse(time.RFC3339, lockedAt)
+		assert.NoError(t, err)
+		delete(fields, "locked_at")
+	}
+	if conversions, ok := fields["conversions"].([]interface{}); ok {
+		for _, conversion := range conversions {
+			removeLockedAt(t, conversion)
+		}
+	}
+}
@@ -1,13 +1,22 @@
 package handler_test
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
 	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,11 +29,18 @@ import (
 // 1. Valid Transaction Data.
 // 2. Invalid Timestamp.
 // 3. Negative AmountInUSD.
+// 4. Amount In Another Currency.
+// 5. Amount Without Currency.
+// 6. Conflicting Amounts.
 func TestValidateAndCreateTransaction(t *testing.T) {
 	// Expected values
-	transactionValidTransactionData, err := domain.NewTransaction("Valid Description", time.Now().UTC(), 100.0)
+	transactionValidTransactionData, err := domain.NewTransaction("Valid Description", time.Now().UTC(), domain.MustParseMoney("100.00", domain.CurrencyUSD))
 	// Stops the test if the expected results are not as expected (probably the business logic changed)
 	require.Empty(t, err)
+	validAmount := domain.MustParseMoney("100.0", "")
+	negativeAmount := domain.MustParseMoney("-10.0", "")
+	transactionInEuros, err := domain.NewTransactionInCurrency("Valid Description", time.Now().UTC(), domain.MustParseMoney("100.00", "EUR"))
+	require.Empty(t, err)
 
 	tests := []struct {
 		name           string
@@ -37,7 +53,7 @@ func TestValidateAndCreateTransaction(t *testing.T) {
 			inputData: handler.TransactionDTO{
 				Description: "Valid Description",
 				Timestamp:   time.Now().UTC().Format(time.RFC3339),
-				AmountInUSD: 100.0,
+				AmountInUSD: &validAmount,
 			},
 			expectedErrors: []error{},
 			expectedResult: transactionValidTransactionData,
@@ -47,7 +63,7 @@ func TestValidateAndCreateTransaction(t *testing.T) {
 			inputData: handler.TransactionDTO{
 				Description: "Test Description",
 				Timestamp:   "invalid-timestamp",
-				AmountInUSD: 100.0,
+				AmountInUSD: &validAmount,
 			},
 			expectedErrors: []error{handler.ErrInvalidTimestampFormat},
 			expectedResult: nil,
@@ -57,11 +73,44 @@ func TestValidateAndCreateTransaction(t *testing.T) {
 			inputData: handler.TransactionDTO{
 				Description: "Test Description",
 				Timestamp:   time.Now().UTC().Format(time.RFC3339),
-				AmountInUSD: -10.0,
+				AmountInUSD: &negativeAmount,
 			},
 			expectedErrors: []error{domain.ErrInvalidAmountInUSD},
 			expectedResult: nil,
 		},
+		{
+			name: "Amount In Another Currency",
+			inputData: handler.TransactionDTO{
+				Description: "Valid Description",
+				Timestamp:   time.Now().UTC().Format(time.RFC3339),
+				Amount:      &validAmount,
+				Currency:    "eur",
+			},
+			expectedErrors: []error{},
+			expectedResult: transactionInEuros,
+		},
+		{
+			name: "Amount Without Currency",
+			inputData: handler.TransactionDTO{
+				Description: "Test Description",
+				Timestamp:   time.Now().UTC().Format(time.RFC3339),
+				Amount:      &validAmount,
+			},
+			expectedErrors: []error{handler.ErrCurrencyNotProvided},
+			expectedResult: nil,
+		},
+		{
+			name: "Conflicting Amounts",
+			inputData: handler.TransactionDTO{
+				Description: "Test Description",
+				Timestamp:   time.Now().UTC().Format(time.RFC3339),
+				AmountInUSD: &validAmount,
+				Amount:      &validAmount,
+				Currency:    "EUR",
+			},
+			expectedErrors: []error{handler.ErrConflictingAmounts},
+			expectedResult: nil,
+		},
 	}
 
 	transactionHandler := handler.TransactionHandler{}
@@ -83,6 +132,8 @@ func TestValidateAndCreateTransaction(t *testing.T) {
 			if tt.expectedResult != nil {
 				assert.Equal(t, tt.expectedResult.Description, result.Description)
 				assert.Equal(t, tt.expectedResult.AmountInUSD, result.AmountInUSD)
+				assert.Equal(t, tt.expectedResult.Currency(), result.Currency())
+				assert.Equal(t, tt.expectedResult.RecordedAmount(), result.RecordedAmount())
 			}
 		})
 	}
@@ -91,7 +142,13 @@ func TestValidateAndCreateTransaction(t *testing.T) {
 // TestWriteSuccessResponse tests the WriteSuccessResponse function. It tests the following scenarios:
 //
 // 1. Success Response with Data.
+// 2. Success Response With A Currency Without Minor Units.
+// 3. Success Response With The Rate Selection Policy.
 func TestWriteSuccessResponse(t *testing.T) {
+	amountInUSD := domain.MustParseMoney("28.75", domain.CurrencyUSD)
+	amountInYen := domain.MustParseMoney("4128", "JPY")
+	yenExponent := 0
+
 	tests := []struct {
 		name           string
 		data           interface{}
@@ -104,6 +161,44 @@ func TestWriteSuccessResponse(t *testing.T) {
 			statusCode:     http.StatusOK,
 			expectedOutput: "{\"data\":{\"id\":\"12345\"}}",
 		},
+		{
+			name: "Success Response With A Currency Without Minor Units",
+			data: handler.TransactionDTO{
+				ID:                     "12345",
+				Description:            "Sushi",
+				Timestamp:              "2024-10-01 12:00:00",
+				AmountInUSD:            &amountInUSD,
+				ExchangeRateUsed:       "143.57",
+				AmountInTargetCurrency: &amountInYen,
+				TargetCurrencyCode:     "JPY",
+				TargetCurrencyExponent: &yenExponent,
+			},
+			statusCode: http.StatusOK,
+			expectedOutput: `{"data":{"id":"12345","description":"Sushi","timestamp":"2024-10-01 12:00:00",` +
+				`"amount_in_usd":"28.75","exchange_rate_used":"143.57","amount_in_target_currency":"4128",` +
+				`"target_currency_code":"JPY","target_currency_exponent":0}}`,
+		},
+		{
+			name: "Success Response With The Rate Selection Policy",
+			data: handler.TransactionDTO{
+				ID:               "12345",
+				Description:      "Sushi",
+				Timestamp:        "2024-10-01 12:00:00",
+				AmountInUSD:      &amountInUSD,
+				ExchangeRateUsed: "143.57",
+				ExchangeRateDate: "2024-09-30",
+				RateSelectionPolicy: &handler.RateSelectionPolicyDTO{
+					Name:           domain.RateSelectionLatestOnOrBefore,
+					LookbackMonths: 6,
+				},
+				AmountInTargetCurrency: &amountInYen,
+			},
+			statusCode: http.StatusOK,
+			expectedOutput: `{"data":{"id":"12345","description":"Sushi","timestamp":"2024-10-01 12:00:00",` +
+				`"amount_in_usd":"28.75","exchange_rate_used":"143.57","exchange_rate_date":"2024-09-30",` +
+				`"rate_selection_policy":{"name":"latest_on_or_before","lookback_months":6},` +
+				`"amount_in_target_currency":"4128"}}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -247,3 +342,365 @@ func TestParseISO8601Timestamp(t *testing.T) {
 		})
 	}
 }
+
+// TestParseTransactionQuery tests the ParseTransactionQuery function. It tests the following scenarios:
+//
+// 1. No Parameters.
+// 2. All Parameters.
+// 3. Invalid Date Format.
+// 4. Invalid Amount Format.
+// 5. Invalid Limit Format.
+// 6. Inverted Date Range.
+func TestParseTransactionQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		rawQuery       string
+		expectedLimit  int
+		expectedErrors []error
+	}{
+		{
+			name:           "No Parameters",
+			rawQuery:       "",
+			expectedLimit:  domain.DefaultTransactionQueryLimit,
+			expectedErrors: []error{},
+		},
+		{
+			name: "All Parameters",
+			rawQuery: "from=2024-01-01T00:00:00Z&to=2024-12-31T00:00:00Z&min_amount=1.5&max_amount=100" +
+				"&description=coffee&cursor=abc&limit=10",
+			expectedLimit:  10,
+			expectedErrors: []error{},
+		},
+		{
+			name:           "Invalid Date Format",
+			rawQuery:       "from=yesterday",
+			expectedErrors: []error{handler.ErrInvalidTimestampFormat},
+		},
+		{
+			name:           "Invalid Amount Format",
+			rawQuery:       "min_amount=ten",
+			expectedErrors: []error{handler.ErrInvalidAmountFormat},
+		},
+		{
+			name:           "Invalid Limit Format",
+			rawQuery:       "limit=many",
+			expectedErrors: []error{handler.ErrInvalidLimitFormat},
+		},
+		{
+			name:           "Inverted Date Range",
+			rawQuery:       "from=2024-12-31T00:00:00Z&to=2024-01-01T00:00:00Z",
+			expectedErrors: []error{domain.ErrInvalidTransactionQueryDateRange},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values, err := url.ParseQuery(tt.rawQuery)
+			require.NoError(t, err)
+
+			query, errs := handler.ParseTransactionQuery(values)
+
+			if len(tt.expectedErrors) > 0 {
+				require.Len(t, errs, len(tt.expectedErrors))
+				for i, expectedError := range tt.expectedErrors {
+					assert.ErrorIs(t, errs[i], expectedError)
+				}
+				assert.Nil(t, query)
+			} else {
+				assert.Empty(t, errs)
+				require.NotNil(t, query)
+				assert.Equal(t, tt.expectedLimit, query.Limit)
+			}
+		})
+	}
+}
+
+// TestHealthCheck tests the HealthCheck handler. It tests the following scenarios:
+//
+// 1. Registered Health Checks Are Reported.
+func TestHealthCheck(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	t.Run("Registered Health Checks Are Reported", func(t *testing.T) {
+		t.Parallel()
+		transactionHandler := handler.TransactionHandler{}
+		transactionHandler.RegisterHealthCheck("exchange_rate_cache", func() interface{} {
+			return map[string]int{"hits": 3}
+		})
+
+		rr := httptest.NewRecorder()
+		transactionHandler.HealthCheck(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var health map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &health))
+		assert.Equal(t, "ok", health["status"])
+		assert.Equal(t, map[string]interface{}{"hits": float64(3)}, health["exchange_rate_cache"])
+	})
+}
+
+// TestRequestTimeout tests the RequestTimeout middleware. It tests the following scenarios:
+//
+// 1. Request Context Gets The Deadline.
+func TestRequestTimeout(t *testing.T) {
+	t.Run("Request Context Gets The Deadline", func(t *testing.T) {
+		t.Parallel()
+		var deadline time.Time
+		var hasDeadline bool
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline, hasDeadline = r.Context().Deadline()
+		})
+
+		start := time.Now()
+		handler.RequestTimeout(time.Minute)(next).ServeHTTP(httptest.NewRecorder(),
+			httptest.NewRequest(http.MethodGet, "/transactions", nil))
+
+		require.True(t, hasDeadline)
+		assert.WithinDuration(t, start.Add(time.Minute), deadline, time.Second)
+	})
+}
+
+// TestWriteTimeout tests the WriteTimeout middleware. It tests the following scenarios:
+//
+// 1. Response Written After The Write Timeout Of The Server.
+func TestWriteTimeout(t *testing.T) {
+	t.Run("Response Written After The Write Timeout Of The Server", func(t *testing.T) {
+		t.Parallel()
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(300 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		})
+		server := httptest.NewUnstartedServer(handler.WriteTimeout(time.Minute)(next))
+		server.Config.WriteTimeout = 100 * time.Millisecond
+		server.Start()
+		defer server.Close()
+
+		resp, err := server.Client().Get(server.URL)
+
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+// TestWriteContextErrorResponse tests the WriteContextErrorResponse function. It tests the following scenarios:
+//
+// 1. Deadline Exceeded.
+// 2. Canceled Request.
+// 3. Other Error.
+func TestWriteContextErrorResponse(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedHandled bool
+		expectedStatus  int
+	}{
+		{
+			name:            "Deadline Exceeded",
+			err:             fmt.Errorf("treasury request: %w", context.DeadlineExceeded),
+			expectedHandled: true,
+			expectedStatus:  http.StatusGatewayTimeout,
+		},
+		{
+			name:            "Canceled Request",
+			err:             context.Canceled,
+			expectedHandled: true,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "Other Error",
+			err:             assert.AnError,
+			expectedHandled: false,
+			expectedStatus:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := httptest.NewRecorder()
+
+			assert.Equal(t, tt.expectedHandled, handler.WriteContextErrorResponse(rr, tt.err))
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+// TestWriteUnavailableErrorResponse tests the WriteUnavailableErrorResponse function. It tests the following
+// scenarios:
+//
+// 1. Open Circuit Sets Retry-After.
+// 2. Unavailable Without Retry-After.
+// 3. Other Error.
+func TestWriteUnavailableErrorResponse(t *testing.T) {
+	// Opens a circuit breaker to get its error
+	mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+	mockAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency(nil), client.ErrNetworkIssue).Once()
+	breaker := client.NewCircuitBreakerTreasuryExchangeRateAdapter(mockAdapter, 1, 1, 30*time.Second, nil)
+	_, err := breaker.GetCurrencies(context.Background())
+	require.ErrorIs(t, err, client.ErrNetworkIssue)
+	_, circuitOpenErr := breaker.GetCurrencies(context.Background())
+	require.ErrorIs(t, circuitOpenErr, domain.ErrExchangeRatesUnavailable)
+
+	tests := []struct {
+		name               string
+		err                error
+		expectedHandled    bool
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{
+			name:               "Open Circuit Sets Retry-After",
+			err:                fmt.Errorf("exchange rates: %w", circuitOpenErr),
+			expectedHandled:    true,
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "30",
+		},
+		{
+			name:            "Unavailable Without Retry-After",
+			err:             domain.ErrExchangeRatesUnavailable,
+			expectedHandled: true,
+			expectedStatus:  http.StatusServiceUnavailable,
+		},
+		{
+			name:            "Other Error",
+			err:             client.ErrNetworkIssue,
+			expectedHandled: false,
+			expectedStatus:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := httptest.NewRecorder()
+
+			assert.Equal(t, tt.expectedHandled, handler.WriteUnavailableErrorResponse(rr, tt.err))
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedRetryAfter, rr.Header().Get("Retry-After"))
+		})
+	}
+}
+
+// TestSaveTransactionIdempotencyKey tests the transaction creation with an Idempotency-Key header. The steps depend
+// on each other, so they run in order. It tests the following scenarios:
+//
+// 1. First Request Creates The Transaction.
+// 2. Retry Returns The Original Response.
+// 3. Retry With Another Formatting Returns The Original Response.
+// 4. Retry With The Same Values Written Differently Returns The Original Response.
+// 5. Another Request Reusing The Key Conflicts.
+// 6. Another Key Creates Another Transaction.
+// 7. Invalid Key.
+func TestSaveTransactionIdempotencyKey(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/idempotency_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	body := `{"description":"Sample Transaction","timestamp":"2023-11-06T15:04:05Z","amount_in_usd":"28.745"}`
+	tests := []struct {
+		name             string
+		idempotencyKey   string
+		body             string
+		expectedStatus   int
+		expectedReplayed bool
+		// expectedSameID tells whether the transaction ID is the one of the first request
+		expectedSameID bool
+	}{
+		{
+			name:           "First Request Creates The Transaction",
+			idempotencyKey: "key-1",
+			body:           body,
+			expectedStatus: http.StatusCreated,
+			expectedSameID: true,
+		},
+		{
+			name:             "Retry Returns The Original Response",
+			idempotencyKey:   "key-1",
+			body:             body,
+			expectedStatus:   http.StatusCreated,
+			expectedReplayed: true,
+			expectedSameID:   true,
+		},
+		{
+			name:           "Retry With Another Formatting Returns The Original Response",
+			idempotencyKey: "key-1",
+			body: `{ "amount_in_usd": 28.745, "timestamp": "2023-11-06T15:04:05Z",
+				"description": "Sample Transaction" }`,
+			expectedStatus:   http.StatusCreated,
+			expectedReplayed: true,
+			expectedSameID:   true,
+		},
+		{
+			name:           "Retry With The Same Values Written Differently Returns The Original Response",
+			idempotencyKey: "key-1",
+			body: `{"id":"00000000-0000-0000-0000-000000000001","description":"  Sample Transaction ",
+				"timestamp":"2023-11-06T10:04:05-05:00","amount_in_usd":"28.75"}`,
+			expectedStatus:   http.StatusCreated,
+			expectedReplayed: true,
+			expectedSameID:   true,
+		},
+		{
+			name:           "Another Request Reusing The Key Conflicts",
+			idempotencyKey: "key-1",
+			body:           strings.Replace(body, "28.745", "30", 1),
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Another Key Creates Another Transaction",
+			idempotencyKey: "key-2",
+			body:           body,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Invalid Key",
+			idempotencyKey: strings.Repeat("k", 256),
+			body:           body,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	var firstID string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(tt.body))
+			req.Header.Set(handler.IdempotencyKeyHeader, tt.idempotencyKey)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			if tt.expectedReplayed {
+				assert.Equal(t, "true", rr.Header().Get(handler.IdempotentReplayedHeader))
+			} else {
+				assert.Empty(t, rr.Header().Get(handler.IdempotentReplayedHeader))
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var response struct {
+				Data struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			if firstID == "" {
+				firstID = response.Data.ID
+			}
+			assert.Equal(t, tt.expectedSameID, response.Data.ID == firstID)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the conversion of a transaction to several currencies in a single request.
+
+// TransactionConversionsDTO represents the data transfer object for a transaction converted to several currencies.
+// Its amount is either in USD, or in the currency it was recorded in.
+type TransactionConversionsDTO struct {
+	ID                  string                  `json:"id"`
+	Description         string                  `json:"description"`
+	Timestamp           string                  `json:"timestamp"`
+	AmountInUSD         *domain.Money           `json:"amount_in_usd,omitempty"`
+	Amount              *domain.Money           `json:"amount,omitempty"`
+	Currency            string                  `json:"currency,omitempty"`
+	RateSelectionPolicy *RateSelectionPolicyDTO `json:"rate_selection_policy"`
+	Conversions         []CurrencyConversionDTO `json:"conversions"`
+}
+
+// CurrencyConversionDTO represents the data transfer object for the conversion of a transaction to one currency.
+// A failed conversion only has the requested currency and the error. The conversion legs are only set for
+// transactions recorded in another currency, converted through USD. A locked conversion has the policy it was locked
+// with, since it may differ from the current one.
+type CurrencyConversionDTO struct {
+	Currency               string                  `json:"currency"`
+	ExchangeRateUsed       string                  `json:"exchange_rate_used,omitempty"`
+	ExchangeRateDate       string                  `json:"exchange_rate_date,omitempty"`
+	ExchangeRateSource     string                  `json:"exchange_rate_source,omitempty"`
+	ConversionLegs         []ConversionLegDTO      `json:"conversion_legs,omitempty"`
+	AmountInTargetCurrency *domain.Money           `json:"amount_in_target_currency,omitempty"`
+	TargetCurrencyCode     string                  `json:"target_currency_code,omitempty"`
+	TargetCurrencyExponent *int                    `json:"target_currency_exponent,omitempty"`
+	RateSelectionPolicy    *RateSelectionPolicyDTO `json:"rate_selection_policy,omitempty"`
+	LockedAt               string                  `json:"locked_at,omitempty"`
+	CurrentConversion      *CurrencyConversionDTO  `json:"current_conversion,omitempty"`
+	Error                  string                  `json:"error,omitempty"`
+}
+
+// FindTransactionConversions handles the GET request to find a transaction and convert it to several currencies.
+// The response succeeds when the transaction is found, even if some of the currencies cannot be converted. The locked
+// conversions are returned with their locked figures, and with the current conversions as well when include_current
+// is true.
+func (th *TransactionHandler) FindTransactionConversions(w http.ResponseWriter, r *http.Request) {
+	idString := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		log.Warn().Err(err).Str("id", idString).Msg("invalid transaction ID format")
+		WriteErrorResponse(w, http.StatusBadRequest, "invalid transaction ID format")
+		return
+	}
+	currencyNames, validationErrors := ParseConversionCurrencies(r.URL.Query())
+	if len(validationErrors) > 0 {
+		validationErrorsAsStrings := make([]string, len(validationErrors))
+		for i, err := range validationErrors {
+			validationErrorsAsStrings[i] = err.Error()
+		}
+		log.Warn().Errs("validation_errors", validationErrors).Msg("conversion currencies validation failed")
+		WriteErrorResponse(w, http.StatusBadRequest, "validation errors: "+strings.Join(validationErrorsAsStrings, ", "))
+		return
+	}
+	includeCurrent, err := ParseIncludeCurrent(r.URL.Query())
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid include_current parameter")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	transaction, conversions, err := th.transactionService.ConvertTransaction(r.Context(), id, currencyNames)
+	if WriteContextErrorResponse(w, err) {
+		return
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("transaction_id", id.String()).Msg("transaction not found")
+		WriteErrorResponse(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	rateSelectionPolicy := th.transactionService.RateSelectionPolicy()
+
+	transactionDTO := NewTransactionDTO(transaction)
+	transactionConversionsDTO := TransactionConversionsDTO{
+		ID:          transactionDTO.ID,
+		Description: transactionDTO.Description,
+		Timestamp:   transactionDTO.Timestamp,
+		AmountInUSD: transactionDTO.AmountInUSD,
+		Amount:      transactionDTO.Amount,
+		Currency:    transactionDTO.Currency,
+		RateSelectionPolicy: &RateSelectionPolicyDTO{
+			Name:           rateSelectionPolicy.Name(),
+			LookbackMonths: rateSelectionPolicy.LookbackMonths(),
+		},
+		Conversions: make([]CurrencyConversionDTO, len(conversions)),
+	}
+	for i, conversion := range conversions {
+		if conversion.Locked != nil {
+			transactionConversionsDTO.Conversions[i] = NewLockedCurrencyConversionDTO(transaction, conversion,
+				includeCurrent)
+			continue
+		}
+		transactionConversionsDTO.Conversions[i] = NewCurrencyConversionDTO(transaction, conversion)
+	}
+
+	WriteSuccessResponse(w, transactionConversionsDTO, http.StatusOK)
+}
+
+// NewCurrencyConversionDTO creates the data transfer object of the conversion of a transaction to one currency.
+func NewCurrencyConversionDTO(transaction *domain.Transaction,
+	conversion *domain.TransactionConversion) CurrencyConversionDTO {
+	if conversion.Err != nil {
+		return CurrencyConversionDTO{
+			Currency: conversion.CurrencyName,
+			Error:    conversionErrorMessage(conversion.Err),
+		}
+	}
+
+	amountInTargetCurrency := conversion.AmountInTargetCurrency
+	targetCurrencyExponent := amountInTargetCurrency.Exponent()
+	conversionDTO := CurrencyConversionDTO{
+		Currency:               conversion.CurrencyName,
+		AmountInTargetCurrency: &amountInTargetCurrency,
+		TargetCurrencyCode:     amountInTargetCurrency.Currency(),
+		TargetCurrencyExponent: &targetCurrencyExponent,
+	}
+	if transaction.Currency() == domain.CurrencyUSD && conversion.ExchangeRate != nil {
+		conversionDTO.ExchangeRateUsed = conversion.ExchangeRate.RateText
+		conversionDTO.ExchangeRateDate = conversion.ExchangeRate.DateOfRecord.Format(time.DateOnly)
+		conversionDTO.ExchangeRateSource = conversion.ExchangeRate.Source
+	} else {
+		conversionDTO.ConversionLegs = NewConversionLegDTOs(conversion.Legs)
+	}
+	return conversionDTO
+}
+
+// ParseConversionCurrencies parses the comma-separated currencies query parameter of a multi-currency conversion.
+// The parameter can be repeated, and the duplicates are removed.
+func ParseConversionCurrencies(values url.Values) ([]string, []error) {
+	currencyNames := make([]string, 0, len(values["currencies"]))
+	for _, value := range values["currencies"] {
+		currencyNames = append(currencyNames, strings.Split(value, ",")...)
+	}
+	return domain.NewConversionCurrencies(currencyNames)
+}
+
+// conversionErrorMessage returns the message reported for a currency that cannot be converted. Only the errors
+// caused by the requested currency are detailed.
+func conversionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrUnknownCurrencyCode), errors.Is(err, domain.ErrAmbiguousCurrencyCode):
+		return err.Error()
+	case errors.Is(err, domain.ErrNoApplicableExchangeRate):
+		return "no exchange rate applies to the purchase date"
+	case errors.Is(err, domain.ErrExchangeRatesUnavailable):
+		return domain.ErrExchangeRatesUnavailable.Error()
+	default:
+		return "failed to retrieve the exchange rates"
+	}
+}
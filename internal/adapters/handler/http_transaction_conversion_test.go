@@ -0,0 +1,266 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/google/uuid"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the conversion of a transaction to several currencies. It uses
+// Table Driven Tests to test different scenarios. It uses Testify for assertions and mocking, and runs the tests in
+// parallel.
+
+// TestParseConversionCurrencies tests the ParseConversionCurrencies function. It tests the following scenarios:
+//
+// 1. Comma-Separated Currencies.
+// 2. Repeated Parameter.
+// 3. Missing Parameter.
+func TestParseConversionCurrencies(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		expectedCurrencies []string
+		expectedErrors     []error
+	}{
+		{
+			name:               "Comma-Separated Currencies",
+			query:              "currencies=EUR,GBP, JPY,EUR",
+			expectedCurrencies: []string{"EUR", "GBP", "JPY"},
+		},
+		{
+			name:               "Repeated Parameter",
+			query:              "currencies=EUR&currencies=Canada-Dollar",
+			expectedCurrencies: []string{"EUR", "Canada-Dollar"},
+		},
+		{
+			name:           "Missing Parameter",
+			query:          "",
+			expectedErrors: []error{domain.ErrConversionCurrenciesEmpty},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			currencyNames, errs := handler.ParseConversionCurrencies(values)
+
+			if len(tt.expectedErrors) > 0 {
+				assert.Equal(t, tt.expectedErrors, errs)
+				return
+			}
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.expectedCurrencies, currencyNames)
+		})
+	}
+}
+
+// TestFindTransactionConversions tests the FindTransactionConversions handler. It tests the following scenarios:
+//
+// 1. Failures Reported Per Currency.
+// 2. Invalid Transaction ID.
+// 3. Missing Currencies.
+// 4. Transaction Not Found.
+func TestFindTransactionConversions(t *testing.T) {
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/transaction_conversion_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	transaction, errs := domain.NewTransaction("Sample Transaction", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("28.75", domain.CurrencyUSD))
+	require.Empty(t, errs)
+	require.NoError(t, transactionRepo.SaveTransaction(context.Background(), *transaction))
+
+	yenExchangeRate, errs := domain.NewExchangeRate("Yen", "143.57", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(t, errs)
+	yenExchangeRate.CountryCurrencyDesc = "Japan-Yen"
+	yenExchangeRate.CurrencyCode = "JPY"
+	realExchangeRate, errs := domain.NewExchangeRate("Real", "5.434", time.Date(2020, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(t, errs)
+	realExchangeRate.CountryCurrencyDesc = "Brazil-Real"
+	realExchangeRate.CurrencyCode = "BRL"
+	mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+	mockAdapter.On("GetExchangeRatesBetween", "JPY", mock.Anything, mock.Anything).Return([]*domain.ExchangeRate{yenExchangeRate}, nil)
+	mockAdapter.On("GetExchangeRatesBetween", "Real", mock.Anything, mock.Anything).Return([]*domain.ExchangeRate{realExchangeRate}, nil)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo, mockAdapter, nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Failures Reported Per Currency",
+			path:           "/transactions/" + transaction.ID.String() + "/conversions?currencies=JPY,XYZ,Real",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":{"id":"` + transaction.ID.String() + `","description":"Sample Transaction",` +
+				`"timestamp":"2024-10-01 00:00:00","amount_in_usd":"28.75",` +
+				`"rate_selection_policy":{"name":"latest_on_or_before","lookback_months":6},"conversions":[` +
+				`{"currency":"JPY","exchange_rate_used":"143.57","exchange_rate_date":"2024-09-30",` +
+				`"amount_in_target_currency":"4128","target_currency_code":"JPY","target_currency_exponent":0},` +
+				`{"currency":"XYZ","error":"currency code is unknown; it must be the ISO 4217 code of a currency with published exchange rates: XYZ"},` +
+				`{"currency":"Real","error":"no exchange rate applies to the purchase date"}]}}`,
+		},
+		{
+			name:           "Invalid Transaction ID",
+			path:           "/transactions/not-a-uuid/conversions?currencies=JPY",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing Currencies",
+			path:           "/transactions/" + transaction.ID.String() + "/conversions",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Transaction Not Found",
+			path:           "/transactions/" + uuid.New().String() + "/conversions?currencies=JPY",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+// TestCrossCurrencyConversion tests the conversion of a transaction recorded in another currency than USD through
+// the HTTP handlers. It tests the following scenarios:
+//
+// 1. Single Currency Reports Both Legs.
+// 2. Several Currencies Report Their Legs.
+// 3. Currency Without Exchange Rates Is Rejected.
+func TestCrossCurrencyConversion(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/cross_currency_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	newExchangeRate := func(currencyName, rate, countryCurrencyDesc, currencyCode string) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate(currencyName, rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+		exchangeRate.CurrencyCode = currencyCode
+		return exchangeRate
+	}
+	require.NoError(t, exchangeRateRepo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{
+		newExchangeRate("Euro", "0.897", "Euro Zone-Euro", "EUR"),
+		newExchangeRate("Peso", "19.6", "Mexico-Peso", "MXN"),
+	}))
+	// The exchange rates are stored as synchronized over 2024, so the adapter is never called
+	require.NoError(t, exchangeRateRepo.SaveExchangeRateCoverage(context.Background(), "",
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)))
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	// Saves the transaction through the handler
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(
+		`{"description":"Dinner in Madrid","timestamp":"2024-10-01T20:00:00Z","amount":"25.79","currency":"EUR"}`)))
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	id := created.Data.ID
+	transactionBody := `"id":"` + id + `","description":"Dinner in Madrid","timestamp":"2024-10-01 20:00:00",` +
+		`"amount":"25.79","currency":"EUR","rate_selection_policy":{"name":"latest_on_or_before","lookback_months":6}`
+	euroLeg := `{"from_currency":"EUR","to_currency":"USD","exchange_rate_used":"0.897","exchange_rate_date":"2024-09-30",` +
+		`"amount":"28.75"}`
+	pesoLeg := `{"from_currency":"USD","to_currency":"MXN","exchange_rate_used":"19.6","exchange_rate_date":"2024-09-30",` +
+		`"amount":"563.53"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Single Currency Reports Both Legs",
+			method:         http.MethodGet,
+			path:           "/transactions/" + id + "/MXN",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":{` + transactionBody + `,"conversion_legs":[` + euroLeg + `,` + pesoLeg + `],` +
+				`"amount_in_target_currency":"563.53","target_currency_code":"MXN","target_currency_exponent":2}}`,
+		},
+		{
+			name:           "Several Currencies Report Their Legs",
+			method:         http.MethodGet,
+			path:           "/transactions/" + id + "/conversions?currencies=MXN,USD",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"data":{` + transactionBody + `,"conversions":[` +
+				`{"currency":"MXN","conversion_legs":[` + euroLeg + `,` + pesoLeg + `],"amount_in_target_currency":"563.53",` +
+				`"target_currency_code":"MXN","target_currency_exponent":2},` +
+				`{"currency":"USD","conversion_legs":[` + euroLeg + `],"amount_in_target_currency":"28.75",` +
+				`"target_currency_code":"USD","target_currency_exponent":2}]}}`,
+		},
+		{
+			name:           "Currency Without Exchange Rates Is Rejected",
+			method:         http.MethodPost,
+			path:           "/transactions",
+			body:           `{"description":"Souvenir","timestamp":"2024-10-01T20:00:00Z","amount":"10","currency":"ABC"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the export of transactions.
+
+// Formats of an export of transactions.
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFormatOFX    = "ofx"
+)
+
+// exportTimeout is the deadline of an export. It is longer than the deadline of the other requests, as an export
+// streams all the matching transactions.
+const exportTimeout = 5 * time.Minute
+
+// Fields of the OFX statement of an export.
+const (
+	// ofxAccountID is the account of the statement, as the transactions are not attached to an account.
+	ofxAccountID = "transactions"
+	// ofxNameMaxLength is the maximum length of the name of an OFX transaction. The full description is in the memo.
+	ofxNameMaxLength = 32
+	// ofxDateLayout is the layout of the OFX dates, in UTC.
+	ofxDateLayout = "20060102150405.000[0:GMT]"
+)
+
+// transactionExportCSVHeader is the header row of the transactions in CSV.
+var transactionExportCSVHeader = []string{"id", "description", "timestamp", "amount_in_usd", "amount", "currency",
+	"version"}
+
+// transactionExportConversionCSVHeader are the columns added to the header row of an export with a target currency.
+var transactionExportConversionCSVHeader = []string{"target_currency", "amount_in_target_currency",
+	"target_currency_code", "locked_at", "conversion_error"}
+
+// TransactionExportDTO represents the data transfer object for an exported transaction, a line of an export in JSON
+// lines. The conversion is only set for an export with a target currency.
+type TransactionExportDTO struct {
+	ID          string                 `json:"id"`
+	Description string                 `json:"description"`
+	Timestamp   string                 `json:"timestamp"`
+	AmountInUSD *domain.Money          `json:"amount_in_usd,omitempty"`
+	Amount      *domain.Money          `json:"amount,omitempty"`
+	Currency    string                 `json:"currency,omitempty"`
+	Version     int                    `json:"version"`
+	Conversion  *CurrencyConversionDTO `json:"conversion,omitempty"`
+}
+
+// transactionExportWriter writes the transactions of an export in a format, as they are read.
+type transactionExportWriter interface {
+	// Open writes what comes before the transactions.
+	Open() error
+	// Write writes a transaction, with its conversion when the export has a target currency.
+	Write(transaction *domain.Transaction, conversion *domain.TransactionConversion) error
+	// Close writes what comes after the transactions and flushes the export.
+	Close() error
+}
+
+// ExportTransactions handles the GET request to export the transactions matching the filters of the listing, in
+// CSV, JSON lines or OFX. The transactions are streamed in chronological order as they are read, converted to the
+// currency query parameter when one is given. An OFX statement is in a single currency, so its transactions are
+// converted to USD by default. The pagination parameters are ignored, and not validated.
+func (th *TransactionHandler) ExportTransactions(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	// An export is not paginated, so the limit and cursor of a listing URL reused for the export are left out
+	filterValues := maps.Clone(values)
+	delete(filterValues, "limit")
+	delete(filterValues, "cursor")
+	query, validationErrors := ParseTransactionQuery(filterValues)
+	if len(validationErrors) > 0 {
+		validationErrorsAsStrings := make([]string, len(validationErrors))
+		for i, err := range validationErrors {
+			validationErrorsAsStrings[i] = err.Error()
+		}
+		WriteErrorResponse(w, http.StatusBadRequest, "validation errors: "+strings.Join(validationErrorsAsStrings, ", "))
+		return
+	}
+
+	currencyName := strings.TrimSpace(values.Get("currency"))
+	format := strings.ToLower(strings.TrimSpace(values.Get("format")))
+	if format == exportFormatOFX && currencyName == "" {
+		currencyName = domain.CurrencyUSD
+	}
+	exportWriter, contentType, format, err := newTransactionExportWriter(w, format, currencyName, *query)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	exported, opened, err := writeTransactionExport(w, "transactions."+format, contentType, exportWriter,
+		func(export func(*domain.Transaction, *domain.TransactionConversion) error) error {
+			return th.transactionService.ExportTransactions(r.Context(), *query, currencyName, export)
+		})
+	if err != nil && !opened {
+		writeExportErrorResponse(w, err, currencyName)
+		return
+	}
+	if err != nil {
+		// The response is already on its way, so the export can only be cut short
+		log.Error().Err(err).Int("transactions", exported).Msg("failed to export the transactions")
+		return
+	}
+	log.Info().Str("format", format).Int("transactions", exported).Msg("transactions exported")
+}
+
+// newTransactionExportWriter creates the writer of an export in a format, csv by default, with the conversions to
+// the currency when one is given. It returns the writer, the content type and the format of the export.
+func newTransactionExportWriter(w io.Writer, format, currencyName string,
+	query domain.TransactionQuery) (transactionExportWriter, string, string, error) {
+	switch format {
+	case exportFormatCSV, "":
+		return &csvExportWriter{writer: csv.NewWriter(w), withConversion: currencyName != ""},
+			"text/csv; charset=utf-8", exportFormatCSV, nil
+	case exportFormatNDJSON:
+		return &ndjsonExportWriter{writer: bufio.NewWriter(w)}, contentTypeNDJSON, format, nil
+	case exportFormatOFX:
+		return &ofxExportWriter{writer: bufio.NewWriter(w), query: query, currencyName: currencyName,
+			exportedAt: time.Now().UTC(), balance: new(big.Rat), exponent: 2}, "application/x-ofx", format, nil
+	default:
+		return nil, "", "", ErrInvalidExportFormat
+	}
+}
+
+// writeTransactionExport writes the transactions passed by the stream function to the response as an attachment.
+// The response is only started with the first transaction, so an export failing before it can still get an error
+// response. It returns the number of transactions written, whether the response was started and the error that cut
+// the export short.
+func writeTransactionExport(w http.ResponseWriter, filename, contentType string,
+	exportWriter transactionExportWriter,
+	stream func(export func(*domain.Transaction, *domain.TransactionConversion) error) error) (int, bool, error) {
+	// The export may outlast the write timeout of the server
+	err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warn().Err(err).Msg("failed to extend the write deadline of the export")
+	}
+
+	opened, exported := false, 0
+	open := func() error {
+		opened = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+		w.WriteHeader(http.StatusOK)
+		return exportWriter.Open()
+	}
+	err = stream(func(transaction *domain.Transaction, conversion *domain.TransactionConversion) error {
+		if !opened {
+			if err := open(); err != nil {
+				return err
+			}
+		}
+		exported++
+		return exportWriter.Write(transaction, conversion)
+	})
+	if err == nil && !opened {
+		err = open()
+	}
+	if err == nil {
+		err = exportWriter.Close()
+	}
+	return exported, opened, err
+}
+
+// writeExportErrorResponse writes the error response of an export that failed before any transaction was written.
+func writeExportErrorResponse(w http.ResponseWriter, err error, currencyName string) {
+	if WriteContextErrorResponse(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrUnknownCurrencyCode) || errors.Is(err, domain.ErrAmbiguousCurrencyCode):
+		log.Warn().Err(err).Str("currency", currencyName).Msg("invalid export currency")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Error().Err(err).Msg("failed to export the transactions")
+		WriteErrorResponse(w, http.StatusInternalServerError, "failed to export the transactions")
+	}
+}
+
+// NewTransactionExportDTO creates the data transfer object of an exported transaction, with its conversion when the
+// export has a target currency.
+func NewTransactionExportDTO(transaction *domain.Transaction,
+	conversion *domain.TransactionConversion) TransactionExportDTO {
+	transactionDTO := NewTransactionDTO(transaction)
+	exportDTO := TransactionExportDTO{
+		ID:          transactionDTO.ID,
+		Description: transactionDTO.Description,
+		Timestamp:   transactionDTO.Timestamp,
+		AmountInUSD: transactionDTO.AmountInUSD,
+		Amount:      transactionDTO.Amount,
+		Currency:    transactionDTO.Currency,
+		Version:     transaction.Version,
+	}
+	if conversion != nil {
+		conversionDTO := NewExportedConversionDTO(transaction, conversion)
+		exportDTO.Conversion = &conversionDTO
+	}
+	return exportDTO
+}
+
+// NewExportedConversionDTO creates the data transfer object of the conversion of an exported transaction. The locked
+// conversion is exported when the conversion is locked, as for a single conversion.
+func NewExportedConversionDTO(transaction *domain.Transaction,
+	conversion *domain.TransactionConversion) CurrencyConversionDTO {
+	if conversion.Locked != nil {
+		return NewLockedCurrencyConversionDTO(transaction, conversion, false)
+	}
+	return NewCurrencyConversionDTO(transaction, conversion)
+}
+
+// csvExportWriter writes the transactions of an export in CSV, with the conversion columns when the export has a
+// target currency.
+type csvExportWriter struct {
+	writer         *csv.Writer
+	withConversion bool
+}
+
+// Open writes the header row.
+func (e *csvExportWriter) Open() error {
+	header := transactionExportCSVHeader
+	if e.withConversion {
+		header = append(slices.Clone(header), transactionExportConversionCSVHeader...)
+	}
+	return e.writer.Write(header)
+}
+
+// Write writes the row of a transaction.
+func (e *csvExportWriter) Write(transaction *domain.Transaction, conversion *domain.TransactionConversion) error {
+	exportDTO := NewTransactionExportDTO(transaction, conversion)
+	record := []string{exportDTO.ID, exportDTO.Description, exportDTO.Timestamp, moneyString(exportDTO.AmountInUSD),
+		moneyString(exportDTO.Amount), exportDTO.Currency, fmt.Sprint(exportDTO.Version)}
+	if exportDTO.Conversion != nil {
+		record = append(record, exportDTO.Conversion.Currency,
+			moneyString(exportDTO.Conversion.AmountInTargetCurrency), exportDTO.Conversion.TargetCurrencyCode,
+			exportDTO.Conversion.LockedAt, exportDTO.Conversion.Error)
+	}
+	return e.writer.Write(record)
+}
+
+// Close flushes the rows.
+func (e *csvExportWriter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// ndjsonExportWriter writes the transactions of an export in JSON lines, a transaction per line.
+type ndjsonExportWriter struct {
+	writer *bufio.Writer
+}
+
+// Open writes nothing, as JSON lines have no header.
+func (e *ndjsonExportWriter) Open() error {
+	return nil
+}
+
+// Write writes the line of a transaction.
+func (e *ndjsonExportWriter) Write(transaction *domain.Transaction, conversion *domain.TransactionConversion) error {
+	// The encoder ends each transaction with a newline
+	return json.NewEncoder(e.writer).Encode(NewTransactionExportDTO(transaction, conversion))
+}
+
+// Close flushes the lines.
+func (e *ndjsonExportWriter) Close() error {
+	return e.writer.Flush()
+}
+
+// ofxExportWriter writes the transactions of an export as an OFX 2.2 credit card statement in the target currency.
+// The purchases are debits, and the ledger balance is their total. The statement starts at the beginning of the date
+// range, or at the first transaction, and ends at the end of the date range, or when the export is made. The
+// transactions that cannot be converted to the target currency are left out of the statement.
+type ofxExportWriter struct {
+	writer       *bufio.Writer
+	query        domain.TransactionQuery
+	currencyName string
+	exportedAt   time.Time
+	// started is set once the statement has its currency and its first transaction
+	started bool
+	// balance is the exact total of the amounts of the statement, with the largest exponent of the amounts
+	balance  *big.Rat
+	exponent int
+	// skipped counts the transactions left out of the statement
+	skipped int
+}
+
+// Open writes the OFX headers and the sign-on response.
+func (e *ofxExportWriter) Open() error {
+	_, err := fmt.Fprintf(e.writer, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1>
+<SONRS>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<DTSERVER>%s</DTSERVER>
+<LANGUAGE>ENG</LANGUAGE>
+</SONRS>
+</SIGNONMSGSRSV1>
+<CREDITCARDMSGSRSV1>
+<CCSTMTTRNRS>
+<TRNUID>0</TRNUID>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<CCSTMTRS>
+`, e.exportedAt.Format(ofxDateLayout))
+	return err
+}
+
+// start writes the currency, the account and the start of the transaction list of the statement. The currency is
+// the ISO 4217 code of the first converted amount, when there is one.
+func (e *ofxExportWriter) start(currencyCode string, firstTimestamp time.Time) error {
+	e.started = true
+	startDate := firstTimestamp
+	if e.query.From != nil {
+		startDate = *e.query.From
+	}
+	_, err := fmt.Fprintf(e.writer, `<CURDEF>%s</CURDEF>
+<CCACCTFROM><ACCTID>%s</ACCTID></CCACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s</DTSTART>
+<DTEND>%s</DTEND>
+`, ofxEscape(currencyCode), ofxAccountID, startDate.UTC().Format(ofxDateLayout), e.endDate().Format(ofxDateLayout))
+	return err
+}
+
+// Write writes a transaction converted to the currency of the statement as a debit.
+func (e *ofxExportWriter) Write(transaction *domain.Transaction, conversion *domain.TransactionConversion) error {
+	conversionDTO := NewExportedConversionDTO(transaction, conversion)
+	if conversionDTO.Error != "" {
+		e.skipped++
+		log.Warn().Str("transaction_id", transaction.ID.String()).Str("error", conversionDTO.Error).Msg("transaction left out of the OFX statement")
+		return nil
+	}
+	amount := *conversionDTO.AmountInTargetCurrency
+	if !e.started {
+		if err := e.start(amount.Currency(), transaction.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	e.balance.Sub(e.balance, amount.Rat())
+	e.exponent = max(e.exponent, amount.Exponent())
+	name := []rune(transaction.Description)
+	if len(name) > ofxNameMaxLength {
+		name = name[:ofxNameMaxLength]
+	}
+	_, err := fmt.Fprintf(e.writer, `<STMTTRN>
+<TRNTYPE>DEBIT</TRNTYPE>
+<DTPOSTED>%s</DTPOSTED>
+<TRNAMT>-%s</TRNAMT>
+<FITID>%s</FITID>
+<NAME>%s</NAME>
+<MEMO>%s</MEMO>
+</STMTTRN>
+`, transaction.Timestamp.UTC().Format(ofxDateLayout), amount.String(), transaction.ID.String(), ofxEscape(string(name)),
+		ofxEscape(transaction.Description))
+	return err
+}
+
+// Close writes the ledger balance and the end of the statement, and flushes it.
+func (e *ofxExportWriter) Close() error {
+	if !e.started {
+		// Without a converted amount, the currency of the statement is the requested one
+		if err := e.start(strings.ToUpper(e.currencyName), e.endDate()); err != nil {
+			return err
+		}
+	}
+	if e.skipped > 0 {
+		log.Warn().Int("transactions", e.skipped).Msg("transactions left out of the OFX statement")
+	}
+	_, err := fmt.Fprintf(e.writer, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</CCSTMTRS>
+</CCSTMTTRNRS>
+</CREDITCARDMSGSRSV1>
+</OFX>
+`, e.balance.FloatString(e.exponent), e.endDate().Format(ofxDateLayout))
+	if err != nil {
+		return err
+	}
+	return e.writer.Flush()
+}
+
+// endDate returns the end of the statement, in UTC.
+func (e *ofxExportWriter) endDate() time.Time {
+	if e.query.To != nil {
+		return e.query.To.UTC()
+	}
+	return e.exportedAt
+}
+
+// ofxEscape escapes the text of an OFX element.
+func ofxEscape(text string) string {
+	var escaped strings.Builder
+	// Writing to a strings.Builder cannot fail
+	_ = xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
+
+// moneyString returns the decimal string of an optional amount, empty when it is not set.
+func moneyString(amount *domain.Money) string {
+	if amount == nil {
+		return ""
+	}
+	return amount.String()
+}
@@ -0,0 +1,216 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the export of transactions. It uses Table Driven Tests to test
+// different scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestExportTransactions tests the ExportTransactions handler. It tests the following scenarios:
+//
+// 1. CSV By Default.
+// 2. CSV With A Target Currency.
+// 3. JSON Lines With Date Filters.
+// 4. OFX Statement In USD.
+// 5. Empty Export.
+// 6. Pagination Parameters Ignored.
+// 7. Invalid Format.
+// 8. Invalid Date Filter.
+// 9. Unknown Target Currency.
+func TestExportTransactions(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/export_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	exchangeRates := make([]*domain.ExchangeRate, 0, 2)
+	for _, values := range [][]string{{"Euro", "0.897", "Euro Zone-Euro", "EUR"}, {"Peso", "19.6", "Mexico-Peso", "MXN"}} {
+		exchangeRate, errs := domain.NewExchangeRate(values[0], values[1], time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = values[2]
+		exchangeRate.CurrencyCode = values[3]
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+	require.NoError(t, exchangeRateRepo.SaveExchangeRates(context.Background(), exchangeRates))
+	// The exchange rates are stored as synchronized over 2024, so the adapter is never called
+	require.NoError(t, exchangeRateRepo.SaveExchangeRateCoverage(context.Background(), "",
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)))
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	ids := make([]string, 0, 3)
+	for _, purchase := range []struct {
+		description string
+		timestamp   time.Time
+		amount      domain.Money
+	}{
+		{"Coffee", time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC), domain.MustParseMoney("10.00", domain.CurrencyUSD)},
+		{"Hotel & Spa", time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC), domain.MustParseMoney("25.79", "EUR")},
+		{"Taxi", time.Date(2024, 10, 2, 9, 0, 0, 0, time.UTC), domain.MustParseMoney("5.00", domain.CurrencyUSD)},
+	} {
+		transaction, errs := domain.NewTransactionInCurrency(purchase.description, purchase.timestamp, purchase.amount)
+		require.Empty(t, errs)
+		require.NoError(t, transactionRepo.SaveTransaction(context.Background(), *transaction))
+		ids = append(ids, transaction.ID.String())
+	}
+
+	// readCSV reads the records of a CSV export
+	readCSV := func(t *testing.T, body string) [][]string {
+		records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		expectedStatus      int
+		expectedContentType string
+		// check asserts the body of a successful export
+		check func(t *testing.T, body string)
+	}{
+		{
+			name:                "CSV By Default",
+			query:               "",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				records := readCSV(t, body)
+				require.Len(t, records, 4)
+				assert.Equal(t, []string{"id", "description", "timestamp", "amount_in_usd", "amount", "currency",
+					"version"}, records[0])
+				assert.Equal(t, []string{ids[0], "Coffee", "2024-10-01 09:00:00", "10.00", "", "", "1"}, records[1])
+				assert.Equal(t, []string{ids[1], "Hotel & Spa", "2024-10-01 12:00:00", "", "25.79", "EUR", "1"},
+					records[2])
+			},
+		},
+		{
+			name:                "CSV With A Target Currency",
+			query:               "?format=csv&currency=MXN",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				records := readCSV(t, body)
+				require.Len(t, records, 4)
+				assert.Equal(t, []string{"target_currency", "amount_in_target_currency", "target_currency_code",
+					"locked_at", "conversion_error"}, records[0][7:])
+				assert.Equal(t, []string{"MXN", "196.00", "MXN", "", ""}, records[1][7:])
+				assert.Equal(t, []string{"MXN", "563.53", "MXN", "", ""}, records[2][7:])
+			},
+		},
+		{
+			name:                "JSON Lines With Date Filters",
+			query:               "?format=ndjson&from=2024-10-01T10:00:00Z&to=2024-10-02T00:00:00Z&currency=USD",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			check: func(t *testing.T, body string) {
+				lines := make([]handler.TransactionExportDTO, 0)
+				scanner := bufio.NewScanner(strings.NewReader(body))
+				for scanner.Scan() {
+					var line handler.TransactionExportDTO
+					require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+					lines = append(lines, line)
+				}
+				require.Len(t, lines, 1)
+				assert.Equal(t, ids[1], lines[0].ID)
+				assert.Equal(t, "25.79", lines[0].Amount.String())
+				require.NotNil(t, lines[0].Conversion)
+				assert.Equal(t, "28.75", lines[0].Conversion.AmountInTargetCurrency.String())
+				assert.Len(t, lines[0].Conversion.ConversionLegs, 1)
+			},
+		},
+		{
+			name:                "OFX Statement In USD",
+			query:               "?format=ofx",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ofx",
+			check: func(t *testing.T, body string) {
+				assert.True(t, strings.HasPrefix(body, "<?xml"))
+				assert.Contains(t, body, "<CURDEF>USD</CURDEF>")
+				assert.Contains(t, body, "<DTSTART>20241001090000.000[0:GMT]</DTSTART>")
+				assert.Equal(t, 3, strings.Count(body, "<STMTTRN>"))
+				assert.Contains(t, body, "<TRNAMT>-28.75</TRNAMT>\n<FITID>"+ids[1]+"</FITID>")
+				assert.Contains(t, body, "<NAME>Hotel &amp; Spa</NAME>")
+				assert.Contains(t, body, "<BALAMT>-43.75</BALAMT>")
+				assert.True(t, strings.HasSuffix(body, "</OFX>\n"))
+			},
+		},
+		{
+			name:                "Empty Export",
+			query:               "?from=2030-01-01T00:00:00Z",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				assert.Len(t, readCSV(t, body), 1)
+			},
+		},
+		{
+			name:                "Pagination Parameters Ignored",
+			query:               "?limit=none&cursor=invalid",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			check: func(t *testing.T, body string) {
+				assert.Len(t, readCSV(t, body), 4)
+			},
+		},
+		{
+			name:           "Invalid Format",
+			query:          "?format=xlsx",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid Date Filter",
+			query:          "?from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown Target Currency",
+			query:          "?format=ndjson&currency=XYZ",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, "/transactions/export"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			if tt.check == nil {
+				return
+			}
+			assert.Equal(t, tt.expectedContentType, rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+			tt.check(t, rr.Body.String())
+		})
+	}
+}
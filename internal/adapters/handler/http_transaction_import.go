@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the bulk import of transactions.
+
+// Content types of a bulk import of transactions.
+const (
+	contentTypeCSV    = "text/csv"
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+const (
+	// maxImportBytes is the maximum size of the body of a bulk import of transactions.
+	maxImportBytes = 10 << 20
+	// maxImportRows is the maximum number of rows of a bulk import of transactions.
+	maxImportRows = 10000
+	// importTimeout is the deadline of a bulk import of transactions. It is longer than the deadline of the other
+	// requests, as an import uploads up to maxImportBytes and saves up to maxImportRows transactions.
+	importTimeout = 2 * time.Minute
+)
+
+// Columns of the transactions in CSV. The header row names the columns of the file, in any order.
+const (
+	columnDescription = "description"
+	columnTimestamp   = "timestamp"
+	columnAmountInUSD = "amount_in_usd"
+	columnAmount      = "amount"
+	columnCurrency    = "currency"
+)
+
+// transactionImportColumns are the columns that the header row of an import in CSV can name.
+var transactionImportColumns = []string{columnDescription, columnTimestamp, columnAmountInUSD, columnAmount,
+	columnCurrency}
+
+// TransactionImportDTO represents the data transfer object for the report of a bulk import of transactions, with a
+// row per imported row, in the order of the file.
+type TransactionImportDTO struct {
+	Accepted int                       `json:"accepted"`
+	Rejected int                       `json:"rejected"`
+	Rows     []TransactionImportRowDTO `json:"rows"`
+}
+
+// TransactionImportRowDTO represents the data transfer object for a row of a bulk import of transactions, with the ID
+// of the saved transaction or the errors that rejected the row.
+type TransactionImportRowDTO struct {
+	Line   int      `json:"line"`
+	ID     string   `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportTransactions handles the POST request to import transactions in bulk, from CSV or JSON lines. Each row is
+// validated on its own, and the valid rows are saved even when other rows are invalid, unless the all_or_nothing query
+// parameter is set. The response reports the ID of the transaction of each accepted row and the errors of each
+// rejected row.
+func (th *TransactionHandler) ImportTransactions(w http.ResponseWriter, r *http.Request) {
+	allOrNothing, err := ParseAllOrNothing(r.URL.Query())
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var parseRows func(body io.Reader) ([]*domain.TransactionImportRow, error)
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case err == nil && mediaType == contentTypeCSV:
+		parseRows = th.ParseTransactionImportCSV
+	case err == nil && mediaType == contentTypeNDJSON:
+		parseRows = th.ParseTransactionImportNDJSON
+	default:
+		WriteErrorResponse(w, http.StatusUnsupportedMediaType, ErrUnsupportedImportContentType.Error())
+		return
+	}
+
+	// The upload of the import and its report may outlast the read and write timeouts of the server
+	responseController := http.NewResponseController(w)
+	deadline := time.Now().Add(importTimeout)
+	if err := responseController.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warn().Err(err).Msg("failed to extend the read deadline of the import")
+	}
+	if err := responseController.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warn().Err(err).Msg("failed to extend the write deadline of the import")
+	}
+
+	rows, err := parseRows(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		writeImportParseErrorResponse(w, err)
+		return
+	}
+
+	err = th.transactionService.ImportTransactions(r.Context(), rows, allOrNothing)
+	if err != nil && !errors.Is(err, domain.ErrTransactionImportRejected) {
+		if WriteContextErrorResponse(w, err) {
+			return
+		}
+		log.Error().Err(err).Msg("failed to import the transactions")
+		WriteErrorResponse(w, http.StatusInternalServerError, "failed to import the transactions")
+		return
+	}
+
+	report := NewTransactionImportDTO(rows)
+	log.Info().Int("accepted", report.Accepted).Int("rejected", report.Rejected).Bool("all_or_nothing",
+		allOrNothing).Msg("transactions imported")
+	if err != nil {
+		WriteSuccessResponse(w, report, http.StatusUnprocessableEntity)
+		return
+	}
+	WriteSuccessResponse(w, report, http.StatusOK)
+}
+
+// writeImportParseErrorResponse writes the error response of an import whose body could not be read.
+func writeImportParseErrorResponse(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		WriteErrorResponse(w, http.StatusRequestEntityTooLarge, ErrImportTooLarge.Error())
+	case errors.Is(err, ErrTooManyImportRows):
+		WriteErrorResponse(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, ErrEmptyImport) || errors.Is(err, ErrInvalidImportHeader):
+		log.Warn().Err(err).Msg("invalid import")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Warn().Err(err).Msg("failed to read the import")
+		WriteErrorResponse(w, http.StatusBadRequest, "invalid request payload")
+	}
+}
+
+// ParseTransactionImportCSV parses the rows of an import in CSV. The header row names the columns, and a row that is
+// not valid CSV is rejected without stopping the import. The lines of the rows are the lines of the file.
+func (th *TransactionHandler) ParseTransactionImportCSV(body io.Reader) ([]*domain.TransactionImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyImport
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns, err := ParseTransactionImportHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*domain.TransactionImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, err
+		}
+		if len(rows) == maxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		if parseErr != nil {
+			rows = append(rows, &domain.TransactionImportRow{Line: parseErr.StartLine, Errs: []error{
+				fmt.Errorf("%w: %w", ErrInvalidImportRow, parseErr.Err)}})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		data, errs := NewTransactionImportCSVRecord(columns, record)
+		if len(errs) > 0 {
+			rows = append(rows, &domain.TransactionImportRow{Line: line, Errs: errs})
+			continue
+		}
+		rows = append(rows, th.NewTransactionImportRow(line, data))
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return rows, nil
+}
+
+// ParseTransactionImportHeader parses the header row of an import in CSV, and returns the index of each column by
+// name. The description and timestamp columns are required, and a column cannot be repeated.
+func ParseTransactionImportHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheets may start the file with a byte order mark
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%w: column %q is repeated", ErrInvalidImportHeader, name)
+		}
+		if !slices.Contains(transactionImportColumns, name) {
+			return nil, fmt.Errorf("%w: column %q is unknown", ErrInvalidImportHeader, name)
+		}
+		columns[name] = i
+	}
+
+	for _, column := range []string{columnDescription, columnTimestamp} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%w: column %q is missing", ErrInvalidImportHeader, column)
+		}
+	}
+	return columns, nil
+}
+
+// NewTransactionImportCSVRecord creates the request data of a transaction from a record of an import in CSV. An
+// empty amount is left out, as an absent field of a JSON request.
+func NewTransactionImportCSVRecord(columns map[string]int, record []string) (TransactionDTO, []error) {
+	value := func(column string) string {
+		if i, ok := columns[column]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	errs := make([]error, 0, 2)
+	parseAmount := func(column string) *domain.Money {
+		if value(column) == "" {
+			return nil
+		}
+		amount, err := domain.ParseMoney(value(column), "")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", column, err))
+			return nil
+		}
+		return &amount
+	}
+
+	data := TransactionDTO{
+		Description: value(columnDescription),
+		Timestamp:   value(columnTimestamp),
+		AmountInUSD: parseAmount(columnAmountInUSD),
+		Amount:      parseAmount(columnAmount),
+		Currency:    value(columnCurrency),
+	}
+	return data, errs
+}
+
+// ParseTransactionImportNDJSON parses the rows of an import in JSON lines, a transaction request per line. Blank lines
+// are skipped, and a line that is not a valid transaction request is rejected without stopping the import.
+func (th *TransactionHandler) ParseTransactionImportNDJSON(body io.Reader) ([]*domain.TransactionImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, maxImportBytes)
+
+	rows := make([]*domain.TransactionImportRow, 0)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		data := TransactionDTO{}
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			rows = append(rows, &domain.TransactionImportRow{Line: line, Errs: []error{
+				fmt.Errorf("%w: %w", ErrInvalidImportRow, err)}})
+			continue
+		}
+		rows = append(rows, th.NewTransactionImportRow(line, data))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return rows, nil
+}
+
+// NewTransactionImportRow validates the request data of a row of an import, and creates the row with its
+// transaction or its validation errors.
+func (th *TransactionHandler) NewTransactionImportRow(line int, data TransactionDTO) *domain.TransactionImportRow {
+	transaction, validationErrors := th.ValidateAndCreateTransaction(data)
+	if len(validationErrors) > 0 {
+		return &domain.TransactionImportRow{Line: line, Errs: validationErrors}
+	}
+	return &domain.TransactionImportRow{Line: line, Transaction: transaction}
+}
+
+// NewTransactionImportDTO creates the data transfer object of the report of a bulk import of transactions.
+func NewTransactionImportDTO(rows []*domain.TransactionImportRow) TransactionImportDTO {
+	report := TransactionImportDTO{Rows: make([]TransactionImportRowDTO, len(rows))}
+	for i, row := range rows {
+		report.Rows[i].Line = row.Line
+		if row.Accepted() {
+			report.Accepted++
+			report.Rows[i].ID = row.Transaction.ID.String()
+			continue
+		}
+		report.Rejected++
+		report.Rows[i].Errors = make([]string, len(row.Errs))
+		for j, err := range row.Errs {
+			report.Rows[i].Errors[j] = err.Error()
+		}
+	}
+	return report
+}
+
+// ParseAllOrNothing parses the all_or_nothing query parameter, which saves the transactions of an import only when
+// all its rows are valid. It defaults to false.
+func ParseAllOrNothing(values url.Values) (bool, error) {
+	allOrNothingString := values.Get("all_or_nothing")
+	if allOrNothingString == "" {
+		return false, nil
+	}
+	allOrNothing, err := strconv.ParseBool(allOrNothingString)
+	if err != nil {
+		return false, ErrInvalidAllOrNothingFormat
+	}
+	return allOrNothing, nil
+}
@@ -0,0 +1,233 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the bulk import of transactions. It uses Table Driven Tests to test
+// different scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestImportTransactions tests the ImportTransactions handler. It tests the following scenarios:
+//
+// 1. CSV With Valid And Invalid Rows.
+// 2. CSV Columns In Any Order.
+// 3. CSV All Or Nothing With An Invalid Row.
+// 4. JSON Lines With Valid And Invalid Rows.
+// 5. JSON Lines All Or Nothing With Valid Rows.
+// 6. Unsupported Content Type.
+// 7. Invalid All Or Nothing.
+// 8. Unknown CSV Column.
+// 9. Empty Import.
+// 10. Too Many Rows.
+func TestImportTransactions(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/import_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	tooManyRows := "description,timestamp,amount_in_usd\n" +
+		strings.Repeat("Coffee,2024-10-01T08:00:00Z,3.50\n", 10001)
+
+	tests := []struct {
+		name                  string
+		contentType           string
+		query                 string
+		body                  string
+		expectedStatus        int
+		expectedAccepted      int
+		expectedRejectedLines []int
+	}{
+		{
+			name:        "CSV With Valid And Invalid Rows",
+			contentType: "text/csv; charset=utf-8",
+			body: "description,timestamp,amount_in_usd,amount,currency\n" +
+				"Coffee,2024-10-01T08:00:00Z,3.50,,\n" +
+				",2024-10-01T08:00:00Z,3.50,,\n" +
+				"Hotel,2024-10-02T20:00:00Z,,120.00,EUR\n" +
+				"Taxi,2024-10-03T09:00:00Z,abc,,\n" +
+				"Lunch,2024-10-03T12:00:00Z\n",
+			expectedStatus:        http.StatusOK,
+			expectedAccepted:      2,
+			expectedRejectedLines: []int{3, 5, 6},
+		},
+		{
+			name:             "CSV Columns In Any Order",
+			contentType:      "text/csv",
+			body:             "\ufeffAmount, Currency, Timestamp, Description\n15.00,GBP,2024-10-01T08:00:00Z,Museum\n",
+			expectedStatus:   http.StatusOK,
+			expectedAccepted: 1,
+		},
+		{
+			name:        "CSV All Or Nothing With An Invalid Row",
+			contentType: "text/csv",
+			query:       "?all_or_nothing=true",
+			body: "description,timestamp,amount_in_usd\n" +
+				"Coffee,2024-10-01T08:00:00Z,3.50\n" +
+				"Tea,2999-10-01T08:00:00Z,2.50\n",
+			expectedStatus:        http.StatusUnprocessableEntity,
+			expectedRejectedLines: []int{2, 3},
+		},
+		{
+			name:        "JSON Lines With Valid And Invalid Rows",
+			contentType: "application/x-ndjson",
+			body: `{"description":"Coffee","timestamp":"2024-10-01T08:00:00Z","amount_in_usd":"3.50"}` + "\n" +
+				"\n" +
+				`{"description":"Hotel","timestamp":"2024-10-02T20:00:00Z","amount":120,"currency":"EUR"}` + "\n" +
+				`{"description":"Taxi",` + "\n" +
+				`{"description":"Lunch","timestamp":"2024-10-03T12:00:00Z","amount_in_usd":"9.00","amount":"8.00",` +
+				`"currency":"EUR"}`,
+			expectedStatus:        http.StatusOK,
+			expectedAccepted:      2,
+			expectedRejectedLines: []int{4, 5},
+		},
+		{
+			name:        "JSON Lines All Or Nothing With Valid Rows",
+			contentType: "application/x-ndjson",
+			query:       "?all_or_nothing=1",
+			body: `{"description":"Coffee","timestamp":"2024-10-01T08:00:00Z","amount_in_usd":3.5}` + "\n" +
+				`{"description":"Tea","timestamp":"2024-10-01T09:00:00Z","amount_in_usd":"2.50"}` + "\n",
+			expectedStatus:   http.StatusOK,
+			expectedAccepted: 2,
+		},
+		{
+			name:           "Unsupported Content Type",
+			contentType:    "application/json",
+			body:           `{"description":"Coffee","timestamp":"2024-10-01T08:00:00Z","amount_in_usd":"3.50"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "Invalid All Or Nothing",
+			contentType:    "text/csv",
+			query:          "?all_or_nothing=maybe",
+			body:           "description,timestamp,amount_in_usd\nCoffee,2024-10-01T08:00:00Z,3.50\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown CSV Column",
+			contentType:    "text/csv",
+			body:           "description,timestamp,amount_in_usd,category\nCoffee,2024-10-01T08:00:00Z,3.50,Food\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Empty Import",
+			contentType:    "application/x-ndjson",
+			body:           "\n\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Too Many Rows",
+			contentType:    "text/csv",
+			body:           tooManyRows,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/transactions/import"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			if tt.expectedStatus != http.StatusOK && tt.expectedStatus != http.StatusUnprocessableEntity {
+				return
+			}
+			var response struct {
+				Data handler.TransactionImportDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			report := response.Data
+			assert.Equal(t, tt.expectedAccepted, report.Accepted)
+			assert.Equal(t, len(tt.expectedRejectedLines), report.Rejected)
+
+			rejectedLines := make([]int, 0)
+			for _, row := range report.Rows {
+				if len(row.Errors) > 0 {
+					assert.Empty(t, row.ID)
+					rejectedLines = append(rejectedLines, row.Line)
+					continue
+				}
+				// The transaction of an accepted row is saved
+				findReq := httptest.NewRequest(http.MethodGet, "/transactions/"+row.ID, nil)
+				findRR := httptest.NewRecorder()
+				router.ServeHTTP(findRR, findReq)
+				assert.Equal(t, http.StatusOK, findRR.Code, findRR.Body.String())
+			}
+			assert.ElementsMatch(t, tt.expectedRejectedLines, rejectedLines)
+		})
+	}
+}
+
+// TestParseTransactionImportHeader tests the ParseTransactionImportHeader function. It tests the following
+// scenarios:
+//
+// 1. Columns In Any Order.
+// 2. Repeated Column.
+// 3. Unknown Column.
+// 4. Missing Timestamp Column.
+func TestParseTransactionImportHeader(t *testing.T) {
+	tests := []struct {
+		name            string
+		header          []string
+		expectedColumns map[string]int
+		expectedError   error
+	}{
+		{
+			name:            "Columns In Any Order",
+			header:          []string{"\ufeffTimestamp", " description ", "amount_in_usd"},
+			expectedColumns: map[string]int{"timestamp": 0, "description": 1, "amount_in_usd": 2},
+		},
+		{
+			name:          "Repeated Column",
+			header:        []string{"description", "timestamp", "Description"},
+			expectedError: handler.ErrInvalidImportHeader,
+		},
+		{
+			name:          "Unknown Column",
+			header:        []string{"description", "timestamp", "category"},
+			expectedError: handler.ErrInvalidImportHeader,
+		},
+		{
+			name:          "Missing Timestamp Column",
+			header:        []string{"description", "amount_in_usd"},
+			expectedError: handler.ErrInvalidImportHeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			columns, err := handler.ParseTransactionImportHeader(tt.header)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedColumns, columns)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// This file contains the HTTP handler for the revisions of a transaction: its reading, update, deletion and history.
+// The version of a transaction is its entity tag, so updates and deletions are made with optimistic concurrency.
+
+// Headers of the optimistic concurrency of the revisions.
+const (
+	// ETagHeader is the response header holding the entity tag of the current version of a transaction.
+	ETagHeader = "ETag"
+	// IfMatchHeader is the request header holding the entity tag of the version an update or a deletion is made from.
+	IfMatchHeader = "If-Match"
+)
+
+// TransactionPatchDTO represents the data transfer object for the update of a transaction. Only the provided fields
+// are changed. An amount without currency keeps the currency of the transaction.
+type TransactionPatchDTO struct {
+	Description *string       `json:"description,omitempty"`
+	Timestamp   *string       `json:"timestamp,omitempty"`
+	AmountInUSD *domain.Money `json:"amount_in_usd,omitempty"`
+	Amount      *domain.Money `json:"amount,omitempty"`
+	Currency    *string       `json:"currency,omitempty"`
+}
+
+// FindTransaction handles the GET request to find the current revision of a transaction. Its version is returned as
+// the ETag header, to be sent back in the If-Match header of an update or a deletion.
+func (th *TransactionHandler) FindTransaction(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := th.transactionService.FindTransaction(r.Context(), id)
+	if err != nil {
+		writeTransactionRevisionErrorResponse(w, err, id)
+		return
+	}
+
+	w.Header().Set(ETagHeader, TransactionETag(transaction.Version))
+	WriteSuccessResponse(w, NewTransactionRevisionDTO(transaction), http.StatusOK)
+}
+
+// UpdateTransaction handles the PATCH request to update the description, timestamp or amount of a transaction. The
+// If-Match header must hold the ETag of the current version: a missing header gets a 428 Precondition Required, and
+// the ETag of an older version a 412 Precondition Failed.
+func (th *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := parseIfMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	patch := TransactionPatchDTO{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		log.Warn().Err(err).Msg("invalid request payload")
+		WriteErrorResponse(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+
+	transaction, err := th.transactionService.FindTransaction(r.Context(), id)
+	if err != nil {
+		writeTransactionRevisionErrorResponse(w, err, id)
+		return
+	}
+	data, err := ApplyTransactionPatch(transaction, patch)
+	if err != nil {
+		log.Warn().Err(err).Str("transaction_id", id.String()).Msg("invalid transaction patch")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	revised, validationErrors := th.ValidateAndCreateTransaction(data)
+	if len(validationErrors) > 0 {
+		validationErrorsAsStrings := make([]string, len(validationErrors))
+		for i, err := range validationErrors {
+			validationErrorsAsStrings[i] = err.Error()
+		}
+		log.Warn().Errs("validation_errors", validationErrors).Str("transaction_id",
+			id.String()).Msg("transaction validation failed")
+		WriteErrorResponse(w, http.StatusBadRequest, "validation errors: "+strings.Join(validationErrorsAsStrings, ", "))
+		return
+	}
+
+	revision, err := th.transactionService.UpdateTransaction(r.Context(), id, version, *revised)
+	if err != nil {
+		writeTransactionRevisionErrorResponse(w, err, id)
+		return
+	}
+
+	w.Header().Set(ETagHeader, TransactionETag(revision.Version))
+	WriteSuccessResponse(w, NewTransactionRevisionDTO(revision), http.StatusOK)
+}
+
+// DeleteTransaction handles the DELETE request to soft delete a transaction. The transaction is no longer found, but
+// its history is kept. The If-Match header is required like for an update.
+func (th *TransactionHandler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := parseIfMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := th.transactionService.DeleteTransaction(r.Context(), id, version); err != nil {
+		writeTransactionRevisionErrorResponse(w, err, id)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FindTransactionHistory handles the GET request to list all the revisions of a transaction, oldest first. The
+// history of a deleted transaction ends with its tombstone.
+func (th *TransactionHandler) FindTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTransactionID(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := th.transactionService.FindTransactionHistory(r.Context(), id)
+	if err != nil {
+		writeTransactionRevisionErrorResponse(w, err, id)
+		return
+	}
+
+	revisionDTOs := make([]TransactionDTO, len(revisions))
+	for i, revision := range revisions {
+		revisionDTOs[i] = NewTransactionRevisionDTO(revision)
+	}
+
+	WriteSuccessResponse(w, revisionDTOs, http.StatusOK)
+}
+
+// parseTransactionID parses the transaction ID of the request path, and writes a bad request response when it is
+// invalid.
+func parseTransactionID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	idString := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		log.Warn().Err(err).Str("id", idString).Msg("invalid transaction ID format")
+		WriteErrorResponse(w, http.StatusBadRequest, "invalid transaction ID format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseIfMatchVersion parses the version of the If-Match header of the request, and writes the error response when
+// it is missing or invalid.
+func parseIfMatchVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	version, err := ParseTransactionETag(r.Header.Get(IfMatchHeader))
+	switch {
+	case errors.Is(err, ErrIfMatchRequired):
+		log.Warn().Err(err).Msg("missing If-Match header")
+		WriteErrorResponse(w, http.StatusPreconditionRequired, err.Error())
+		return 0, false
+	case err != nil:
+		log.Warn().Err(err).Str("if_match", r.Header.Get(IfMatchHeader)).Msg("invalid If-Match header")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	return version, true
+}
+
+// writeTransactionRevisionErrorResponse writes the error response of a transaction that could not be read or revised.
+func writeTransactionRevisionErrorResponse(w http.ResponseWriter, err error, id uuid.UUID) {
+	if WriteContextErrorResponse(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrTransactionVersionMismatch):
+		log.Warn().Err(err).Str("transaction_id", id.String()).Msg("transaction revised concurrently")
+		WriteErrorResponse(w, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, domain.ErrTransactionConversionsLocked):
+		log.Warn().Err(err).Str("transaction_id", id.String()).Msg("transaction with locked conversions")
+		WriteErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrUnknownCurrencyCode) || errors.Is(err, domain.ErrAmbiguousCurrencyCode):
+		log.Warn().Err(err).Str("transaction_id", id.String()).Msg("invalid transaction currency")
+		WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Warn().Err(err).Str("transaction_id", id.String()).Msg("transaction not found")
+		WriteErrorResponse(w, http.StatusNotFound, "transaction not found")
+	}
+}
+
+// TransactionETag returns the entity tag of a version of a transaction.
+func TransactionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseTransactionETag parses the version of a transaction from its entity tag. A weak entity tag is accepted, as the
+// version identifies the revision whatever its representation.
+func ParseTransactionETag(etag string) (int, error) {
+	etag = strings.TrimSpace(etag)
+	if etag == "" {
+		return 0, ErrIfMatchRequired
+	}
+
+	unquoted, err := strconv.Unquote(strings.TrimPrefix(etag, "W/"))
+	if err != nil {
+		return 0, ErrInvalidIfMatch
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, ErrInvalidIfMatch
+	}
+	return version, nil
+}
+
+// ApplyTransactionPatch applies the update of a transaction to its current revision, and returns the resulting
+// transaction creation request, to be validated like a new transaction. An empty update is rejected.
+func ApplyTransactionPatch(transaction *domain.Transaction, patch TransactionPatchDTO) (TransactionDTO, error) {
+	if patch == (TransactionPatchDTO{}) {
+		return TransactionDTO{}, ErrEmptyTransactionPatch
+	}
+
+	data := NewTransactionDTO(transaction)
+	// The fractional seconds are kept, so an update that does not change the timestamp keeps it as it is
+	data.Timestamp = transaction.Timestamp.Format(time.RFC3339Nano)
+	if patch.Description != nil {
+		data.Description = *patch.Description
+	}
+	if patch.Timestamp != nil {
+		data.Timestamp = *patch.Timestamp
+	}
+
+	switch {
+	case patch.AmountInUSD != nil:
+		// The amount in USD records the transaction in USD, the other amount fields conflict with it
+		data.AmountInUSD = patch.AmountInUSD
+		data.Amount = patch.Amount
+		data.Currency = ""
+		if patch.Currency != nil {
+			data.Currency = *patch.Currency
+		}
+	case patch.Amount != nil:
+		data.AmountInUSD = nil
+		data.Amount = patch.Amount
+		data.Currency = transaction.Currency()
+		if patch.Currency != nil {
+			data.Currency = *patch.Currency
+		}
+	case patch.Currency != nil:
+		return TransactionDTO{}, ErrCurrencyWithoutAmount
+	}
+	return data, nil
+}
+
+// NewTransactionRevisionDTO creates the data transfer object of a revision of a transaction, with its version and the
+// time it was made.
+func NewTransactionRevisionDTO(transaction *domain.Transaction) TransactionDTO {
+	transactionDTO := NewTransactionDTO(transaction)
+	transactionDTO.Version = transaction.Version
+	if transaction.UpdatedAt != nil {
+		transactionDTO.UpdatedAt = transaction.UpdatedAt.Format(time.RFC3339)
+	}
+	if transaction.DeletedAt != nil {
+		transactionDTO.DeletedAt = transaction.DeletedAt.Format(time.RFC3339)
+	}
+	return transactionDTO
+}
@@ -0,0 +1,338 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/handler"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the HTTP handler of the revisions of a transaction. It uses Table Driven Tests to test
+// different scenarios. It uses Testify for assertions.
+
+// TestTransactionRevisions tests the reading, update, deletion and history of a transaction. The steps depend on each
+// other, so they run in order. It tests the following scenarios:
+//
+// 1. Read Returns The ETag.
+// 2. Update Without If-Match.
+// 3. Update With An Invalid If-Match.
+// 4. Empty Update.
+// 5. Update From The Current Version.
+// 6. Update From A Stale Version.
+// 7. Update Of The Amount Keeps The Currency.
+// 8. Update Of The Currency Without Amount.
+// 9. Delete From A Stale Version.
+// 10. Delete From The Current Version.
+// 11. Deleted Transaction Not Found.
+// 12. History Ends With The Tombstone.
+// 13. Unknown Transaction History.
+func TestTransactionRevisions(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/revision_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	transaction, errs := domain.NewTransactionInCurrency("Sample Transaction", time.Date(2023, 11, 6, 15, 4, 5, 0, time.UTC),
+		domain.MustParseMoney("28.75", "EUR"))
+	require.Empty(t, errs)
+	require.NoError(t, transactionRepo.SaveTransaction(context.Background(), *transaction))
+	path := "/transactions/" + transaction.ID.String()
+
+	// decodeRevisions decodes the revisions of a response, a single one or a list
+	decodeRevisions := func(t *testing.T, body []byte) []handler.TransactionDTO {
+		var response struct {
+			Data jsoniter.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &response))
+		var revisions []handler.TransactionDTO
+		if strings.HasPrefix(string(response.Data), "[") {
+			require.NoError(t, json.Unmarshal(response.Data, &revisions))
+			return revisions
+		}
+		var revision handler.TransactionDTO
+		require.NoError(t, json.Unmarshal(response.Data, &revision))
+		return []handler.TransactionDTO{revision}
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		ifMatch        string
+		body           string
+		expectedStatus int
+		expectedETag   string
+		// check asserts the revisions of a successful response
+		check func(t *testing.T, revisions []handler.TransactionDTO)
+	}{
+		{
+			name:           "Read Returns The ETag",
+			method:         http.MethodGet,
+			path:           path,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"1"`,
+			check: func(t *testing.T, revisions []handler.TransactionDTO) {
+				assert.Equal(t, 1, revisions[0].Version)
+				assert.Equal(t, "Sample Transaction", revisions[0].Description)
+				assert.Empty(t, revisions[0].UpdatedAt)
+			},
+		},
+		{
+			name:           "Update Without If-Match",
+			method:         http.MethodPatch,
+			path:           path,
+			body:           `{"description":"Corrected Transaction"}`,
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "Update With An Invalid If-Match",
+			method:         http.MethodPatch,
+			path:           path,
+			ifMatch:        "version-1",
+			body:           `{"description":"Corrected Transaction"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Empty Update",
+			method:         http.MethodPatch,
+			path:           path,
+			ifMatch:        `"1"`,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Update From The Current Version",
+			method:         http.MethodPatch,
+			path:           path,
+			ifMatch:        `"1"`,
+			body:           `{"description":"Corrected Transaction"}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"2"`,
+			check: func(t *testing.T, revisions []handler.TransactionDTO) {
+				assert.Equal(t, 2, revisions[0].Version)
+				assert.Equal(t, "Corrected Transaction", revisions[0].Description)
+				assert.Equal(t, "28.75", revisions[0].Amount.String())
+				assert.NotEmpty(t, revisions[0].UpdatedAt)
+			},
+		},
+		{
+			name:           "Update From A Stale Version",
+			method:         http.MethodPatch,
+			path:           path,
+			ifMatch:        `"1"`,
+			body:           `{"description":"Lost Update"}`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "Update Of The Amount Keeps The Currency",
+			method:         http.MethodPatch,
+			path:           path,
+			ifMatch:        `W/"2"`,
+			body:           `{"amount":"30.15"}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"3"`,
+			check: func(t *testing.T, revisions []handler.TransactionDTO) {
+				assert.Equal(t, "30.15", revisions[0].Amount.String())
+				assert.Equal(t, "EUR", revisions[0].Currency)
+				assert.Equal(t, "Corrected Transaction", revisions[0].Description)
+			},
+		},
+		{
+			name:           "Update Of The Currency Without Amount",
+			method:         http.MethodPatch,
+			path:           path,
+			ifMatch:        `"3"`,
+			body:           `{"currency":"JPY"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Delete From A Stale Version",
+			method:         http.MethodDelete,
+			path:           path,
+			ifMatch:        `"2"`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "Delete From The Current Version",
+			method:         http.MethodDelete,
+			path:           path,
+			ifMatch:        `"3"`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Deleted Transaction Not Found",
+			method:         http.MethodGet,
+			path:           path,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "History Ends With The Tombstone",
+			method:         http.MethodGet,
+			path:           path + "/history",
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, revisions []handler.TransactionDTO) {
+				require.Len(t, revisions, 4)
+				for i, revision := range revisions {
+					assert.Equal(t, i+1, revision.Version)
+				}
+				assert.Equal(t, "Sample Transaction", revisions[0].Description)
+				assert.Empty(t, revisions[2].DeletedAt)
+				assert.NotEmpty(t, revisions[3].DeletedAt)
+			},
+		},
+		{
+			name:           "Unknown Transaction History",
+			method:         http.MethodGet,
+			path:           "/transactions/8d1b5b8e-2f5a-4a53-9b5e-3f1c2a8e9d4b/history",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.ifMatch != "" {
+				req.Header.Set(handler.IfMatchHeader, tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			assert.Equal(t, tt.expectedETag, rr.Header().Get(handler.ETagHeader))
+			if tt.check != nil {
+				tt.check(t, decodeRevisions(t, rr.Body.Bytes()))
+			}
+		})
+	}
+}
+
+// TestUpdateTransactionWithLockedConversions tests the update of a transaction with locked conversions and a
+// timestamp with fractional seconds. It tests the following scenarios:
+//
+// 1. Update Of The Description Keeps The Timestamp.
+// 2. Update Of The Timestamp Rejected.
+func TestUpdateTransactionWithLockedConversions(t *testing.T) {
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/revision_locked_handler_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo,
+		new(client.MockTreasuryExchangeRateAdapter), nil)
+	router := handler.NewTransactionHandler(*transactionService).Routes()
+
+	timestamp := time.Date(2024, 10, 1, 20, 0, 0, 123456789, time.UTC)
+	transaction, errs := domain.NewTransaction("Hotel in Mexico City", timestamp, domain.MustParseMoney("10.00", domain.CurrencyUSD))
+	require.Empty(t, errs)
+	transaction.LockedConversions = []domain.LockedConversion{{Currency: "MXN", CountryCurrencyDesc: "Mexico-Peso",
+		AmountInTargetCurrency: domain.MustParseMoney("196.00", "MXN")}}
+	require.NoError(t, transactionRepo.SaveTransaction(context.Background(), *transaction))
+	path := "/transactions/" + transaction.ID.String()
+
+	tests := []struct {
+		name                string
+		ifMatch             string
+		body                string
+		expectedStatus      int
+		expectedDescription string
+	}{
+		{
+			name:                "Update Of The Description Keeps The Timestamp",
+			ifMatch:             `"1"`,
+			body:                `{"description":"Hotel in Monterrey"}`,
+			expectedStatus:      http.StatusOK,
+			expectedDescription: "Hotel in Monterrey",
+		},
+		{
+			name:                "Update Of The Timestamp Rejected",
+			ifMatch:             `"2"`,
+			body:                `{"timestamp":"2024-10-01T20:00:00Z"}`,
+			expectedStatus:      http.StatusConflict,
+			expectedDescription: "Hotel in Monterrey",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(tt.body))
+			req.Header.Set(handler.IfMatchHeader, tt.ifMatch)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			storedTransaction, err := transactionRepo.FindTransaction(context.Background(), transaction.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDescription, storedTransaction.Description)
+			assert.True(t, timestamp.Equal(storedTransaction.Timestamp))
+			assert.Len(t, storedTransaction.LockedConversions, 1)
+		})
+	}
+}
+
+// TestParseTransactionETag tests the ParseTransactionETag function. It tests the following scenarios:
+//
+// 1. Strong ETag.
+// 2. Weak ETag.
+// 3. Missing ETag.
+// 4. Unquoted ETag.
+// 5. Non-Numeric Version.
+// 6. Non-Positive Version.
+func TestParseTransactionETag(t *testing.T) {
+	tests := []struct {
+		name            string
+		etag            string
+		expectedVersion int
+		expectedError   error
+	}{
+		{name: "Strong ETag", etag: `"3"`, expectedVersion: 3},
+		{name: "Weak ETag", etag: ` W/"12" `, expectedVersion: 12},
+		{name: "Missing ETag", etag: "", expectedError: handler.ErrIfMatchRequired},
+		{name: "Unquoted ETag", etag: "3", expectedError: handler.ErrInvalidIfMatch},
+		{name: "Non-Numeric Version", etag: `"abc"`, expectedError: handler.ErrInvalidIfMatch},
+		{name: "Non-Positive Version", etag: `"0"`, expectedError: handler.ErrInvalidIfMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			version, err := handler.ParseTransactionETag(tt.etag)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedVersion, version)
+			if tt.expectedError == nil {
+				assert.Equal(t, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tt.etag), "W/")),
+					handler.TransactionETag(version))
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,12 +22,17 @@ import (
 // Activate the jsoniter library to decode the Treasury API response.
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
-// TransactionRepositoryBoltDB represents a BoltDB database with a bucket name to store transactions
-// and a mutex to manage concurrent access to the database.
+// TransactionRepositoryBoltDB represents a BoltDB database with a bucket name to store transactions, a secondary
+// bucket indexing them by timestamp, a bucket holding their prior revisions, buckets for the idempotency keys of
+// their creation and a mutex to manage concurrent access to the database.
 type TransactionRepositoryBoltDB struct {
-	boltDB     *bbolt.DB
-	bucketName string
-	rwMutex    sync.RWMutex
+	boltDB                    *bbolt.DB
+	bucketName                string
+	timestampIndexBucket      string
+	historyBucket             string
+	idempotencyKeyBucket      string
+	idempotencyKeyIndexBucket string
+	rwMutex                   sync.RWMutex
 }
 
 // NewTransactionRepositoryBoltDB creates a new TransactionRepositoryBoltDB instance with input validation.
@@ -49,24 +58,58 @@ func NewTransactionRepositoryBoltDB(pathToDB string, bucketName string) (*Transa
 		return nil, ErrCreateOpenDatabaseFile
 	}
 
-	// Ensures the bucket exists, or create it if it doesn't
+	repository := &TransactionRepositoryBoltDB{
+		boltDB:                    boltDB,
+		bucketName:                bucketName,
+		timestampIndexBucket:      bucketName + timestampIndexBucketSuffix,
+		historyBucket:             bucketName + historyBucketSuffix,
+		idempotencyKeyBucket:      bucketName + idempotencyKeyBucketSuffix,
+		idempotencyKeyIndexBucket: bucketName + idempotencyKeyIndexBucketSuffix,
+	}
+
+	// Ensures the buckets exist, or create them if they don't. Databases created before the timestamp index existed
+	// need it to be built from the stored transactions, in the same write transaction so the index is never left
+	// empty
 	err = boltDB.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(bucketName))
-		return err
+		for _, name := range []string{bucketName, repository.historyBucket, repository.idempotencyKeyBucket,
+			repository.idempotencyKeyIndexBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				log.Error().Err(err).Str("bucket", name).Msg("failed to create the bucket")
+				return ErrCreateBucket
+			}
+		}
+		if !repository.timestampIndexOutdated(tx) {
+			return nil
+		}
+		indexed, err := repository.rebuildTimestampIndex(tx)
+		if err != nil {
+			log.Error().Err(err).Str("bucket", repository.timestampIndexBucket).Msg("failed to rebuild the timestamp index")
+			return ErrRebuildIndex
+		}
+		log.Info().Str("bucket", repository.timestampIndexBucket).Int("transactions", indexed).Msg("timestamp index rebuilt")
+		return nil
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to create the bucket")
-		return nil, ErrCreateBucket
+		// The database file can only be opened once, so it is closed for the next attempt
+		if closeErr := boltDB.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close the database file")
+		}
+		if !errors.Is(err, ErrRebuildIndex) {
+			return nil, ErrCreateBucket
+		}
+		return nil, err
 	}
 
-	return &TransactionRepositoryBoltDB{
-		boltDB:     boltDB,
-		bucketName: bucketName,
-	}, nil
+	return repository, nil
 }
 
 // SaveTransaction implements the SaveTransaction method of the TransactionRepository interface for BoltDB.
-func (r *TransactionRepositoryBoltDB) SaveTransaction(transaction domain.Transaction) error {
+// The transaction is not saved when the context is already done, or when a transaction with the same ID exists.
+func (r *TransactionRepositoryBoltDB) SaveTransaction(ctx context.Context, transaction domain.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// Get a write lock to ensure exclusive access to the database
 	// Only one transaction can be saved at a time to prevent deadlocks
 	r.rwMutex.Lock()
@@ -74,43 +117,236 @@ func (r *TransactionRepositoryBoltDB) SaveTransaction(transaction domain.Transac
 	defer r.rwMutex.Unlock()
 
 	return r.boltDB.Update(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte(r.bucketName))
-		if bucket == nil {
-			log.Error().
-				Str("bucket", r.bucketName).
-				Msg("bucket not found in BoltDB")
-			return ErrBucketNotFound
+		return r.insertTransaction(tx, transaction)
+	})
+}
+
+// SaveTransactions implements the SaveTransactions method of the TransactionRepository interface for BoltDB. The
+// transactions are saved in a single write transaction, so either all of them or none are saved. None is saved when
+// the context is done before the end of the batch, or when one of them has the ID of an existing transaction.
+func (r *TransactionRepositoryBoltDB) SaveTransactions(ctx context.Context, transactions []domain.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	return r.boltDB.Update(func(tx *bbolt.Tx) error {
+		for _, transaction := range transactions {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := r.insertTransaction(tx, transaction); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
+
+// insertTransaction saves a new transaction in a write transaction. An existing transaction with the same ID is
+// never overwritten.
+func (r *TransactionRepositoryBoltDB) insertTransaction(tx *bbolt.Tx, transaction domain.Transaction) error {
+	bucket := tx.Bucket([]byte(r.bucketName))
+	if bucket == nil {
+		log.Error().
+			Str("bucket", r.bucketName).
+			Msg("bucket not found in BoltDB")
+		return ErrBucketNotFound
+	}
+
+	if bucket.Get([]byte(transaction.ID.String())) != nil {
+		log.Error().
+			Str("transaction_id", transaction.ID.String()).
+			Msg("transaction already exists in BoltDB")
+		return ErrTransactionAlreadyExists
+	}
+	return r.putTransaction(tx, transaction)
+}
+
+// putTransaction saves a transaction and its timestamp index entry in a write transaction. The tombstone of a
+// deleted transaction is not indexed, so it is left out of the listings.
+func (r *TransactionRepositoryBoltDB) putTransaction(tx *bbolt.Tx, transaction domain.Transaction) error {
+	bucket := tx.Bucket([]byte(r.bucketName))
+	if bucket == nil {
+		log.Error().
+			Str("bucket", r.bucketName).
+			Msg("bucket not found in BoltDB")
+		return ErrBucketNotFound
+	}
 
-		transactionJSONData, err := json.Marshal(transaction)
+	indexBucket := tx.Bucket([]byte(r.timestampIndexBucket))
+	if indexBucket == nil {
+		log.Error().
+			Str("bucket", r.timestampIndexBucket).
+			Msg("bucket not found in BoltDB")
+		return ErrBucketNotFound
+	}
+
+	// Removes the index entry of the previous version, its timestamp may have changed
+	if previousJSONData := bucket.Get([]byte(transaction.ID.String())); previousJSONData != nil {
+		var previous domain.Transaction
+		if err := json.Unmarshal(previousJSONData, &previous); err == nil {
+			if err := indexBucket.Delete(timestampIndexKey(previous.Timestamp, previous.ID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	transactionJSONData, err := json.Marshal(transaction)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("transaction_id", transaction.ID.String()).
+			Msg("failed to marshal transaction data")
+		return err
+	}
+
+	err = bucket.Put([]byte(transaction.ID.String()), transactionJSONData)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("transaction_id", transaction.ID.String()).
+			Msg("failed to save the transaction")
+		return err
+	}
+
+	if transaction.Deleted() {
+		return nil
+	}
+
+	// The index is updated in the same write transaction, so both buckets are always consistent
+	err = indexBucket.Put(timestampIndexKey(transaction.Timestamp, transaction.ID), []byte(transaction.ID.String()))
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("transaction_id", transaction.ID.String()).
+			Msg("failed to index the transaction")
+	}
+	return err
+}
+
+// FindTransaction implements the FindTransaction method of the TransactionRepository interface for BoltDB. A
+// deleted transaction is not found.
+func (r *TransactionRepositoryBoltDB) FindTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get a read lock to ensure shared read access to the database
+	r.rwMutex.RLock()
+	// Release the read lock after the function execution
+	defer r.rwMutex.RUnlock()
+
+	var transaction *domain.Transaction
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		var err error
+		transaction, err = r.getTransaction(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// getTransaction reads a transaction within a BoltDB transaction. A deleted transaction is not found, as it is only
+// kept as a tombstone for its history.
+func (r *TransactionRepositoryBoltDB) getTransaction(tx *bbolt.Tx, id uuid.UUID) (*domain.Transaction, error) {
+	bucket := tx.Bucket([]byte(r.bucketName))
+	if bucket == nil {
+		log.Error().
+			Str("bucket", r.bucketName).
+			Msg("bucket not found in BoltDB")
+		return nil, ErrBucketNotFound
+	}
+
+	transactionJSONData := bucket.Get([]byte(id.String()))
+	if transactionJSONData == nil {
+		log.Warn().
+			Str("transaction_id", id.String()).
+			Msg("transaction not found in BoltDB")
+		return nil, ErrTransactionNotFound
+	}
+
+	var transaction domain.Transaction
+	if err := json.Unmarshal(transactionJSONData, &transaction); err != nil {
+		log.Error().
+			Err(err).
+			Str("transaction_id", id.String()).
+			Msg("failed to unmarshal transaction data")
+		return nil, err
+	}
+
+	if transaction.Deleted() {
+		log.Warn().
+			Str("transaction_id", id.String()).
+			Msg("transaction deleted in BoltDB")
+		return nil, ErrTransactionNotFound
+	}
+	return &transaction, nil
+}
+
+// SaveLockedConversion implements the SaveLockedConversion method of the TransactionRepository interface for BoltDB.
+// The transaction is read and updated in the same write transaction, so a conversion to a currency is only locked
+// once even by concurrent requests. The locked conversion makes a new revision of the transaction, and the prior
+// revision is kept in its history. It returns the updated transaction.
+func (r *TransactionRepositoryBoltDB) SaveLockedConversion(ctx context.Context, id uuid.UUID,
+	lockedConversion domain.LockedConversion) (*domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	var revision *domain.Transaction
+	err := r.boltDB.Update(func(tx *bbolt.Tx) error {
+		transaction, err := r.getTransaction(tx, id)
 		if err != nil {
-			log.Error().
-				Err(err).
-				Str("transaction_id", transaction.ID.String()).
-				Msg("failed to marshal transaction data")
 			return err
 		}
 
-		err = bucket.Put([]byte(transaction.ID.String()), transactionJSONData)
+		revision, err = transaction.WithLockedConversion(lockedConversion, lockedConversion.LockedAt)
 		if err != nil {
-			log.Error().
-				Err(err).
-				Str("transaction_id", transaction.ID.String()).
-				Msg("failed to save the transaction")
+			return err
 		}
-		return err
+		return r.putRevision(tx, *transaction, *revision)
 	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
 }
 
-// FindTransaction implements the FindTransaction method of the TransactionRepository interface for BoltDB.
-func (r *TransactionRepositoryBoltDB) FindTransaction(id uuid.UUID) (*domain.Transaction, error) {
+// FindTransactions implements the FindTransactions method of the TransactionRepository interface for BoltDB.
+// Transactions are walked in chronological order through the timestamp index, seeking directly to the start of
+// the date range. The cursor holds the index key of the last transaction of the previous page. The walk stops as soon
+// as the context is done.
+func (r *TransactionRepositoryBoltDB) FindTransactions(ctx context.Context,
+	query domain.TransactionQuery) (*domain.TransactionPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Get a read lock to ensure shared read access to the database
 	r.rwMutex.RLock()
 	// Release the read lock after the function execution
 	defer r.rwMutex.RUnlock()
 
-	var transaction domain.Transaction
-	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+	lastKey, err := decodeCursor(query.Cursor)
+	if err != nil || (lastKey != nil && len(lastKey) != timestampIndexKeySize) {
+		log.Warn().Err(err).Str("cursor", query.Cursor).Msg("invalid transaction cursor")
+		return nil, domain.ErrInvalidTransactionCursor
+	}
+
+	page := &domain.TransactionPage{Transactions: make([]*domain.Transaction, 0, query.Limit)}
+	err = r.boltDB.View(func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket([]byte(r.bucketName))
 		if bucket == nil {
 			log.Error().
@@ -118,28 +354,73 @@ func (r *TransactionRepositoryBoltDB) FindTransaction(id uuid.UUID) (*domain.Tra
 				Msg("bucket not found in BoltDB")
 			return ErrBucketNotFound
 		}
+		indexBucket := tx.Bucket([]byte(r.timestampIndexBucket))
+		if indexBucket == nil {
+			log.Error().
+				Str("bucket", r.timestampIndexBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
 
-		transactionJSONData := bucket.Get([]byte(id.String()))
-		if transactionJSONData == nil {
-			log.Warn().
-				Str("transaction_id", id.String()).
-				Msg("transaction not found in BoltDB")
-			return ErrTransactionNotFound
+		cursor := indexBucket.Cursor()
+		var key, value []byte
+		switch {
+		case lastKey != nil:
+			// Resume right after the last key of the previous page
+			key, value = cursor.Seek(lastKey)
+			if key != nil && bytes.Equal(key, lastKey) {
+				key, value = cursor.Next()
+			}
+		case query.From != nil:
+			key, value = cursor.Seek(timestampIndexKey(*query.From, uuid.Nil))
+		default:
+			key, value = cursor.First()
 		}
 
-		err := json.Unmarshal(transactionJSONData, &transaction)
-		if err != nil {
-			log.Error().
-				Err(err).
-				Str("transaction_id", id.String()).
-				Msg("failed to unmarshal transaction data")
+		var lastIndexKey []byte
+		for ; key != nil; key, value = cursor.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// The index is sorted by timestamp, so nothing after the end of the date range can match
+			if query.To != nil && timestampFromIndexKey(key).After(*query.To) {
+				break
+			}
+
+			transactionJSONData := bucket.Get(value)
+			if transactionJSONData == nil {
+				log.Warn().
+					Str("transaction_id", string(value)).
+					Msg("indexed transaction not found in BoltDB")
+				continue
+			}
+			var transaction domain.Transaction
+			if err := json.Unmarshal(transactionJSONData, &transaction); err != nil {
+				log.Error().
+					Err(err).
+					Str("transaction_id", string(value)).
+					Msg("failed to unmarshal transaction data")
+				return err
+			}
+			if !query.Matches(transaction) {
+				continue
+			}
+
+			// A full page was already collected, so there is at least one more page to fetch
+			if len(page.Transactions) == query.Limit {
+				page.NextCursor = encodeCursor(lastIndexKey)
+				return nil
+			}
+			page.Transactions = append(page.Transactions, &transaction)
+			lastIndexKey = append(lastIndexKey[:0], key...)
 		}
-		return err
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &transaction, nil
+	return page, nil
 }
 
 // GetBoltDB returns the BoltDB instance.
@@ -161,3 +442,16 @@ func ValidateTransactionRepositoryBoltDB(pathToDB string, bucketName string) err
 	}
 	return nil
 }
+
+// encodeCursor turns a bucket key into an opaque cursor safe to be used in URLs.
+func encodeCursor(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// decodeCursor turns an opaque cursor back into a bucket key. An empty cursor returns a nil key.
+func decodeCursor(cursor string) ([]byte, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	return base64.RawURLEncoding.DecodeString(cursor)
+}
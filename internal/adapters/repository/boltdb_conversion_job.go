@@ -0,0 +1,448 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.etcd.io/bbolt"
+)
+
+// This file contains the implementation of the ConversionJobRepository interface using BoltDB.
+
+// conversionJobResultBucketSuffix is the suffix of the bucket holding the results of the conversion jobs. It holds
+// one nested bucket per job, where the results are keyed by their sequence number, in the order they were saved.
+const conversionJobResultBucketSuffix = "_results"
+
+// ConversionJobRepositoryBoltDB represents a bucket of a BoltDB database storing conversion jobs by ID, a bucket
+// holding their results and a mutex to manage concurrent access to the database.
+type ConversionJobRepositoryBoltDB struct {
+	boltDB       *bbolt.DB
+	bucketName   string
+	resultBucket string
+	rwMutex      sync.RWMutex
+}
+
+// NewConversionJobRepositoryBoltDB creates a new ConversionJobRepositoryBoltDB instance with input validation. It
+// shares an already opened BoltDB database (e.g. the one of the transaction repository), since a database file can
+// only be opened once.
+func NewConversionJobRepositoryBoltDB(boltDB *bbolt.DB, bucketName string) (*ConversionJobRepositoryBoltDB, error) {
+	bucketName = strings.TrimSpace(bucketName)
+
+	if err := ValidateConversionJobRepositoryBoltDB(boltDB, bucketName); err != nil {
+		return nil, err
+	}
+
+	repository := &ConversionJobRepositoryBoltDB{
+		boltDB:       boltDB,
+		bucketName:   bucketName,
+		resultBucket: bucketName + conversionJobResultBucketSuffix,
+	}
+
+	// Ensures the buckets exist, or create them if they don't
+	err := boltDB.Update(func(tx *bbolt.Tx) error {
+		for _, name := range []string{repository.bucketName, repository.resultBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create the bucket")
+		return nil, ErrCreateBucket
+	}
+
+	return repository, nil
+}
+
+// SaveConversionJob implements the SaveConversionJob method of the ConversionJobRepository interface for BoltDB. A
+// job already stored with the same ID is replaced.
+func (r *ConversionJobRepositoryBoltDB) SaveConversionJob(ctx context.Context, job domain.ConversionJob) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	return r.boltDB.Update(func(tx *bbolt.Tx) error {
+		return r.putConversionJob(tx, job)
+	})
+}
+
+// FindConversionJob implements the FindConversionJob method of the ConversionJobRepository interface for BoltDB.
+func (r *ConversionJobRepositoryBoltDB) FindConversionJob(ctx context.Context, id uuid.UUID) (*domain.ConversionJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get a read lock to ensure shared read access to the database
+	r.rwMutex.RLock()
+	// Release the read lock after the function execution
+	defer r.rwMutex.RUnlock()
+
+	var job *domain.ConversionJob
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.bucketName).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		jobJSONData := bucket.Get([]byte(id.String()))
+		if jobJSONData == nil {
+			log.Warn().
+				Str("job_id", id.String()).
+				Msg("conversion job not found in BoltDB")
+			return ErrConversionJobNotFound
+		}
+		var err error
+		job, err = unmarshalConversionJob(jobJSONData)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ClaimConversionJob implements the ClaimConversionJob method of the ConversionJobRepository interface for BoltDB.
+// The oldest queued job is marked as running and returned, in a single write transaction so a job is claimed by a
+// single worker. The results left by an interrupted run of the job are deleted. It returns nil when no job is
+// queued.
+func (r *ConversionJobRepositoryBoltDB) ClaimConversionJob(ctx context.Context,
+	startedAt time.Time) (*domain.ConversionJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	var claimedJob *domain.ConversionJob
+	err := r.boltDB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.bucketName).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		// Jobs are keyed by ID, so finding the oldest queued one walks all of them
+		err := bucket.ForEach(func(key, jobJSONData []byte) error {
+			job, err := unmarshalConversionJob(jobJSONData)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("job_id", string(key)).
+					Msg("failed to unmarshal conversion job data")
+				return err
+			}
+			if job.Status == domain.ConversionJobQueued &&
+				(claimedJob == nil || job.CreatedAt.Before(claimedJob.CreatedAt)) {
+				claimedJob = job
+			}
+			return nil
+		})
+		if err != nil || claimedJob == nil {
+			return err
+		}
+
+		if err := r.deleteConversionJobResults(tx, claimedJob.ID); err != nil {
+			return err
+		}
+		claimedJob.Start(startedAt, 0)
+		return r.putConversionJob(tx, *claimedJob)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimedJob, nil
+}
+
+// RequeueConversionJobs implements the RequeueConversionJobs method of the ConversionJobRepository interface for
+// BoltDB. The running jobs are queued again, as they were interrupted when their worker stopped. It returns the
+// number of jobs queued again.
+func (r *ConversionJobRepositoryBoltDB) RequeueConversionJobs(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	requeued := 0
+	err := r.boltDB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.bucketName).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		interruptedJobs := make([]*domain.ConversionJob, 0)
+		err := bucket.ForEach(func(key, jobJSONData []byte) error {
+			job, err := unmarshalConversionJob(jobJSONData)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("job_id", string(key)).
+					Msg("failed to unmarshal conversion job data")
+				return err
+			}
+			if job.Status == domain.ConversionJobRunning {
+				interruptedJobs = append(interruptedJobs, job)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// The bucket cannot be written while it is walked, so the jobs are saved afterwards
+		for _, job := range interruptedJobs {
+			job.Requeue()
+			if err := r.putConversionJob(tx, *job); err != nil {
+				return err
+			}
+		}
+		requeued = len(interruptedJobs)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return requeued, nil
+}
+
+// SaveConversionJobResults implements the SaveConversionJobResults method of the ConversionJobRepository interface
+// for BoltDB. The results are appended to the results of the job, and the job is saved with its progress, in the
+// same write transaction so the progress of a job always matches its stored results.
+func (r *ConversionJobRepositoryBoltDB) SaveConversionJobResults(ctx context.Context, job domain.ConversionJob,
+	results []*domain.ConversionJobResult) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	return r.boltDB.Update(func(tx *bbolt.Tx) error {
+		resultBucket := tx.Bucket([]byte(r.resultBucket))
+		if resultBucket == nil {
+			log.Error().
+				Str("bucket", r.resultBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		jobResultBucket, err := resultBucket.CreateBucketIfNotExists([]byte(job.ID.String()))
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("job_id", job.ID.String()).
+				Msg("failed to create the conversion job result bucket")
+			return err
+		}
+
+		for _, result := range results {
+			sequence, err := jobResultBucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			resultJSONData, err := json.Marshal(result)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("job_id", job.ID.String()).
+					Str("transaction_id", result.Transaction.ID.String()).
+					Msg("failed to marshal conversion job result data")
+				return err
+			}
+			if err := jobResultBucket.Put(conversionJobResultKey(sequence), resultJSONData); err != nil {
+				log.Error().
+					Err(err).
+					Str("job_id", job.ID.String()).
+					Msg("failed to save the conversion job result")
+				return err
+			}
+		}
+
+		return r.putConversionJob(tx, job)
+	})
+}
+
+// StreamConversionJobResults implements the StreamConversionJobResults method of the ConversionJobRepository
+// interface for BoltDB. The results of the job are passed to the export function one at a time, in the order they
+// were saved. Like the streaming of the transactions, the results are read a page at a time, each page in its own
+// short read transaction resuming after the key of the previous page, so the pace of the client never keeps a read
+// transaction open.
+func (r *ConversionJobRepositoryBoltDB) StreamConversionJobResults(ctx context.Context, id uuid.UUID,
+	export func(result *domain.ConversionJobResult) error) error {
+	var after []byte
+	for {
+		results, lastKey, err := r.findConversionJobResults(ctx, id, after)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := export(result); err != nil {
+				return err
+			}
+		}
+		if len(results) < streamPageSize {
+			return nil
+		}
+		after = lastKey
+	}
+}
+
+// findConversionJobResults reads a page of the results of a job, starting after the given key, or from the first
+// result when it is nil. It returns the results and the key of the last one.
+func (r *ConversionJobRepositoryBoltDB) findConversionJobResults(ctx context.Context, id uuid.UUID,
+	after []byte) ([]*domain.ConversionJobResult, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Get a read lock to ensure shared read access to the database
+	r.rwMutex.RLock()
+	// Release the read lock after the function execution
+	defer r.rwMutex.RUnlock()
+
+	results := make([]*domain.ConversionJobResult, 0, streamPageSize)
+	var lastKey []byte
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		resultBucket := tx.Bucket([]byte(r.resultBucket))
+		if resultBucket == nil {
+			log.Error().
+				Str("bucket", r.resultBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		// A job without results has no result bucket
+		jobResultBucket := resultBucket.Bucket([]byte(id.String()))
+		if jobResultBucket == nil {
+			return nil
+		}
+
+		cursor := jobResultBucket.Cursor()
+		key, resultJSONData := cursor.First()
+		if after != nil {
+			key, resultJSONData = cursor.Seek(after)
+			if key != nil && bytes.Equal(key, after) {
+				key, resultJSONData = cursor.Next()
+			}
+		}
+		for ; key != nil && len(results) < streamPageSize; key, resultJSONData = cursor.Next() {
+			var result domain.ConversionJobResult
+			if err := json.Unmarshal(resultJSONData, &result); err != nil {
+				log.Error().
+					Err(err).
+					Str("job_id", id.String()).
+					Msg("failed to unmarshal conversion job result data")
+				return err
+			}
+			results = append(results, &result)
+			// The key is only valid during the read transaction
+			lastKey = append(lastKey[:0], key...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, lastKey, nil
+}
+
+// putConversionJob saves a job in a write transaction.
+func (r *ConversionJobRepositoryBoltDB) putConversionJob(tx *bbolt.Tx, job domain.ConversionJob) error {
+	bucket := tx.Bucket([]byte(r.bucketName))
+	if bucket == nil {
+		log.Error().
+			Str("bucket", r.bucketName).
+			Msg("bucket not found in BoltDB")
+		return ErrBucketNotFound
+	}
+
+	jobJSONData, err := json.Marshal(job)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("job_id", job.ID.String()).
+			Msg("failed to marshal conversion job data")
+		return err
+	}
+	if err := bucket.Put([]byte(job.ID.String()), jobJSONData); err != nil {
+		log.Error().
+			Err(err).
+			Str("job_id", job.ID.String()).
+			Msg("failed to save the conversion job")
+		return err
+	}
+	return nil
+}
+
+// deleteConversionJobResults deletes the results of a job in a write transaction.
+func (r *ConversionJobRepositoryBoltDB) deleteConversionJobResults(tx *bbolt.Tx, id uuid.UUID) error {
+	resultBucket := tx.Bucket([]byte(r.resultBucket))
+	if resultBucket == nil {
+		log.Error().
+			Str("bucket", r.resultBucket).
+			Msg("bucket not found in BoltDB")
+		return ErrBucketNotFound
+	}
+	if resultBucket.Bucket([]byte(id.String())) == nil {
+		return nil
+	}
+	return resultBucket.DeleteBucket([]byte(id.String()))
+}
+
+// ValidateConversionJobRepositoryBoltDB validates the BoltDB database and the bucket name for the
+// ConversionJobRepositoryBoltDB struct.
+func ValidateConversionJobRepositoryBoltDB(boltDB *bbolt.DB, bucketName string) error {
+	// Validate the database and bucket name emptiness: must not be empty
+	if boltDB == nil || bucketName == "" {
+		return ErrBoltDBAndBucketNameIsMandatory
+	}
+	return nil
+}
+
+// conversionJobResultKey builds the sortable key of the result of a job from its sequence number.
+func conversionJobResultKey(sequence uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, sequence)
+	return key
+}
+
+// unmarshalConversionJob turns a stored conversion job back into a ConversionJob.
+func unmarshalConversionJob(jobJSONData []byte) (*domain.ConversionJob, error) {
+	var job domain.ConversionJob
+	if err := json.Unmarshal(jobJSONData, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
@@ -0,0 +1,233 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the BoltDB implementation of the ConversionJobRepository interface.
+// It uses Testify for assertions and runs the tests in parallel.
+
+// TestConversionJobBoltDBRepository tests the BoltDB implementation of the ConversionJobRepository interface.
+// It tests the following scenarios:
+//
+// 1. Repository Initialization Without Database.
+// 2. Save And Find A Conversion Job.
+// 3. Find An Unknown Conversion Job.
+// 4. Claim The Oldest Queued Job.
+// 5. Nothing To Claim.
+// 6. Requeue The Running Jobs.
+// 7. Save And Stream Results Across Batches.
+// 8. Stream The Results Of A Job Without Results.
+// 9. Canceled Context.
+func TestConversionJobBoltDBRepository(t *testing.T) {
+	// The conversion jobs share the database of the transactions
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/conversion_job_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	// Each test uses its own bucket of the shared database
+	newRepository := func(t *testing.T) *repository.ConversionJobRepositoryBoltDB {
+		repo, err := repository.NewConversionJobRepositoryBoltDB(transactionRepo.GetBoltDB(), "conversion_jobs_"+uuid.New().String())
+		require.NoError(t, err)
+		return repo
+	}
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	newConversionJob := func(t *testing.T, createdAt time.Time) *domain.ConversionJob {
+		job, errs := domain.NewConversionJob("MXN", nil, nil, createdAt)
+		require.Empty(t, errs)
+		return job
+	}
+	newResults := func(t *testing.T, count int) []*domain.ConversionJobResult {
+		results := make([]*domain.ConversionJobResult, count)
+		for i := range results {
+			transaction, errs := domain.NewTransaction("converted", createdAt.Add(time.Duration(i)*time.Hour),
+				domain.MustParseMoney("1.25", domain.CurrencyUSD))
+			require.Empty(t, errs)
+			results[i] = &domain.ConversionJobResult{Transaction: *transaction, Error: domain.ErrNoApplicableExchangeRate.Error()}
+		}
+		return results
+	}
+
+	t.Run("Repository Initialization Without Database", func(t *testing.T) {
+		t.Parallel()
+		repo, err := repository.NewConversionJobRepositoryBoltDB(nil, "conversion_jobs")
+		assert.ErrorIs(t, err, repository.ErrBoltDBAndBucketNameIsMandatory)
+		assert.Nil(t, repo)
+	})
+
+	t.Run("Save And Find A Conversion Job", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		from := createdAt.AddDate(0, -6, 0)
+		job, errs := domain.NewConversionJob("Mexico-Peso", &from, nil, createdAt)
+		require.Empty(t, errs)
+		require.NoError(t, repo.SaveConversionJob(context.Background(), *job))
+
+		foundJob, err := repo.FindConversionJob(context.Background(), job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, job, foundJob)
+	})
+
+	t.Run("Find An Unknown Conversion Job", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+
+		job, err := repo.FindConversionJob(context.Background(), uuid.New())
+		assert.ErrorIs(t, err, repository.ErrConversionJobNotFound)
+		assert.Nil(t, job)
+	})
+
+	t.Run("Claim The Oldest Queued Job", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		newerJob := newConversionJob(t, createdAt.Add(time.Hour))
+		olderJob := newConversionJob(t, createdAt)
+		completedJob := newConversionJob(t, createdAt.Add(-time.Hour))
+		completedJob.Start(createdAt, 0)
+		completedJob.Complete(createdAt)
+		for _, job := range []*domain.ConversionJob{newerJob, olderJob, completedJob} {
+			require.NoError(t, repo.SaveConversionJob(context.Background(), *job))
+		}
+		// Results left by an interrupted run of the job
+		require.NoError(t, repo.SaveConversionJobResults(context.Background(), *olderJob, newResults(t, 3)))
+
+		startedAt := createdAt.Add(2 * time.Hour)
+		claimedJob, err := repo.ClaimConversionJob(context.Background(), startedAt)
+		require.NoError(t, err)
+		require.NotNil(t, claimedJob)
+		assert.Equal(t, olderJob.ID, claimedJob.ID)
+		assert.Equal(t, domain.ConversionJobRunning, claimedJob.Status)
+		assert.Equal(t, startedAt, *claimedJob.StartedAt)
+
+		storedJob, err := repo.FindConversionJob(context.Background(), olderJob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, claimedJob, storedJob)
+		streamed := 0
+		require.NoError(t, repo.StreamConversionJobResults(context.Background(), olderJob.ID,
+			func(*domain.ConversionJobResult) error {
+				streamed++
+				return nil
+			}))
+		assert.Zero(t, streamed)
+
+		// The next claim gets the newer job
+		claimedJob, err = repo.ClaimConversionJob(context.Background(), startedAt)
+		require.NoError(t, err)
+		require.NotNil(t, claimedJob)
+		assert.Equal(t, newerJob.ID, claimedJob.ID)
+	})
+
+	t.Run("Nothing To Claim", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		runningJob := newConversionJob(t, createdAt)
+		runningJob.Start(createdAt, 10)
+		require.NoError(t, repo.SaveConversionJob(context.Background(), *runningJob))
+
+		claimedJob, err := repo.ClaimConversionJob(context.Background(), createdAt)
+		require.NoError(t, err)
+		assert.Nil(t, claimedJob)
+	})
+
+	t.Run("Requeue The Running Jobs", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		runningJob := newConversionJob(t, createdAt)
+		runningJob.Start(createdAt, 10)
+		runningJob.Record(&domain.ConversionJobResult{})
+		queuedJob := newConversionJob(t, createdAt.Add(time.Hour))
+		for _, job := range []*domain.ConversionJob{runningJob, queuedJob} {
+			require.NoError(t, repo.SaveConversionJob(context.Background(), *job))
+		}
+
+		requeued, err := repo.RequeueConversionJobs(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, requeued)
+
+		storedJob, err := repo.FindConversionJob(context.Background(), runningJob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ConversionJobQueued, storedJob.Status)
+		assert.Nil(t, storedJob.StartedAt)
+		assert.Zero(t, storedJob.Processed)
+	})
+
+	t.Run("Save And Stream Results Across Batches", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		job := newConversionJob(t, createdAt)
+		job.Start(createdAt, 250)
+		results := newResults(t, 250)
+		for i := 0; i < len(results); i += 100 {
+			batch := results[i:min(i+100, len(results))]
+			for _, result := range batch {
+				job.Record(result)
+			}
+			require.NoError(t, repo.SaveConversionJobResults(context.Background(), *job, batch))
+		}
+
+		// The job is saved with its progress along with the results
+		storedJob, err := repo.FindConversionJob(context.Background(), job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 250, storedJob.Processed)
+		assert.Equal(t, 250, storedJob.Failed)
+
+		streamed := make([]*domain.ConversionJobResult, 0, len(results))
+		require.NoError(t, repo.StreamConversionJobResults(context.Background(), job.ID,
+			func(result *domain.ConversionJobResult) error {
+				streamed = append(streamed, result)
+				return nil
+			}))
+		require.Len(t, streamed, len(results))
+		for i, result := range streamed {
+			assert.Equal(t, results[i].Transaction.ID, result.Transaction.ID)
+			assert.ErrorIs(t, result.Err(), domain.ErrNoApplicableExchangeRate)
+		}
+	})
+
+	t.Run("Stream The Results Of A Job Without Results", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+
+		streamed := 0
+		err := repo.StreamConversionJobResults(context.Background(), uuid.New(), func(*domain.ConversionJobResult) error {
+			streamed++
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Zero(t, streamed)
+	})
+
+	t.Run("Canceled Context", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		job := newConversionJob(t, createdAt)
+
+		assert.ErrorIs(t, repo.SaveConversionJob(ctx, *job), context.Canceled)
+		_, err := repo.FindConversionJob(ctx, job.ID)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = repo.ClaimConversionJob(ctx, createdAt)
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = repo.RequeueConversionJobs(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, repo.SaveConversionJobResults(ctx, *job, nil), context.Canceled)
+		assert.ErrorIs(t, repo.StreamConversionJobResults(ctx, job.ID, func(*domain.ConversionJobResult) error {
+			return nil
+		}), context.Canceled)
+	})
+}
@@ -8,6 +8,9 @@ var (
 	// ErrPathToDBAndBucketNameIsMandatory is returned when the database file path and/or the bucket name are empty.
 	ErrPathToDBAndBucketNameIsMandatory = errors.New("the database file path and the bucket name are mandatory")
 
+	// ErrBoltDBAndBucketNameIsMandatory is returned when the database and/or the bucket name are empty.
+	ErrBoltDBAndBucketNameIsMandatory = errors.New("the database and the bucket name are mandatory")
+
 	// ErrDatabaseDirectoryCouldNotBeCreated is returned when the database file directory could not be created.
 	ErrDatabaseDirectoryCouldNotBeCreated = errors.New("the database file directory could not be created")
 
@@ -22,4 +25,16 @@ var (
 
 	// ErrTransactionNotFound is returned when the transaction is not found.
 	ErrTransactionNotFound = errors.New("transaction not found")
+
+	// ErrTransactionAlreadyExists is returned when a new transaction has the ID of an existing transaction.
+	ErrTransactionAlreadyExists = errors.New("a transaction with the same ID already exists")
+
+	// ErrExchangeRateCountryCurrencyMissing is returned when an exchange rate without country-currency is saved.
+	ErrExchangeRateCountryCurrencyMissing = errors.New("the exchange rate country-currency is mandatory to save it")
+
+	// ErrConversionJobNotFound is returned when the conversion job is not found.
+	ErrConversionJobNotFound = errors.New("conversion job not found")
+
+	// ErrRebuildIndex is returned when the timestamp index could not be rebuilt.
+	ErrRebuildIndex = errors.New("the timestamp index could not be rebuilt")
 )
@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+	"go.etcd.io/bbolt"
+)
+
+// This file contains the implementation of the ExchangeRateRepository interface using BoltDB.
+
+const (
+	// exchangeRateCoverageBucketSuffix is appended to the bucket name to get the bucket holding the periods over which
+	// all the published exchange rates are stored, keyed by country-currency.
+	exchangeRateCoverageBucketSuffix = "_coverage"
+	// allCurrenciesCoverageKey is the key of the coverage of all the currencies. Country-currencies never contain it.
+	allCurrenciesCoverageKey = "*"
+)
+
+// ExchangeRateRepositoryBoltDB represents a bucket of a BoltDB database storing exchange rates and a mutex to manage
+// concurrent access to the database. The bucket holds one nested bucket per country-currency, where the exchange
+// rates are keyed by their date of record, and a bucket holding the coverage of the stored exchange rates.
+type ExchangeRateRepositoryBoltDB struct {
+	boltDB         *bbolt.DB
+	bucketName     string
+	coverageBucket string
+	rwMutex        sync.RWMutex
+}
+
+// exchangeRateRecord is the stored representation of an exchange rate. The rate is kept as published.
+type exchangeRateRecord struct {
+	CurrencyName        string    `json:"currency_name"`
+	CountryCurrencyDesc string    `json:"country_currency_desc"`
+	CurrencyCode        string    `json:"currency_code,omitempty"`
+	Rate                string    `json:"rate"`
+	DateOfRecord        time.Time `json:"date_of_record"`
+	Source              string    `json:"source,omitempty"`
+}
+
+// NewExchangeRateRepositoryBoltDB creates a new ExchangeRateRepositoryBoltDB instance with input validation. It
+// shares an already opened BoltDB database (e.g. the one of the transaction repository), since a database file can
+// only be opened once.
+func NewExchangeRateRepositoryBoltDB(boltDB *bbolt.DB, bucketName string) (*ExchangeRateRepositoryBoltDB, error) {
+	bucketName = strings.TrimSpace(bucketName)
+
+	if err := ValidateExchangeRateRepositoryBoltDB(boltDB, bucketName); err != nil {
+		return nil, err
+	}
+
+	repository := &ExchangeRateRepositoryBoltDB{
+		boltDB:         boltDB,
+		bucketName:     bucketName,
+		coverageBucket: bucketName + exchangeRateCoverageBucketSuffix,
+	}
+
+	// Ensures the buckets exist, or create them if they don't
+	err := boltDB.Update(func(tx *bbolt.Tx) error {
+		for _, name := range []string{repository.bucketName, repository.coverageBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to create the bucket")
+		return nil, ErrCreateBucket
+	}
+
+	return repository, nil
+}
+
+// SaveExchangeRates implements the SaveExchangeRates method of the ExchangeRateRepository interface for BoltDB.
+// All the exchange rates are saved in the same write transaction. An exchange rate already stored for the same
+// country-currency and date of record is replaced. Nothing is saved when the context is done before the end.
+func (r *ExchangeRateRepositoryBoltDB) SaveExchangeRates(ctx context.Context, exchangeRates []*domain.ExchangeRate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Exchange rates are stored by country-currency, so they cannot be saved without it
+	for _, exchangeRate := range exchangeRates {
+		if exchangeRate.CountryCurrencyDesc == "" {
+			log.Warn().
+				Str("currency", exchangeRate.CurrencyName).
+				Msg("exchange rate without country-currency cannot be saved")
+			return ErrExchangeRateCountryCurrencyMissing
+		}
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	return r.boltDB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.bucketName).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		for _, exchangeRate := range exchangeRates {
+			// Returning an error rolls back the whole write transaction
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			currencyBucket, err := bucket.CreateBucketIfNotExists([]byte(exchangeRate.CountryCurrencyDesc))
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("currency", exchangeRate.CountryCurrencyDesc).
+					Msg("failed to create the currency bucket")
+				return err
+			}
+
+			exchangeRateJSONData, err := json.Marshal(exchangeRateRecord{
+				CurrencyName:        exchangeRate.CurrencyName,
+				CountryCurrencyDesc: exchangeRate.CountryCurrencyDesc,
+				CurrencyCode:        exchangeRate.CurrencyCode,
+				Rate:                exchangeRate.RateText,
+				DateOfRecord:        exchangeRate.DateOfRecord,
+				Source:              exchangeRate.Source,
+			})
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("currency", exchangeRate.CountryCurrencyDesc).
+					Msg("failed to marshal exchange rate data")
+				return err
+			}
+
+			err = currencyBucket.Put([]byte(exchangeRate.DateOfRecord.Format(time.DateOnly)), exchangeRateJSONData)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("currency", exchangeRate.CountryCurrencyDesc).
+					Msg("failed to save the exchange rate")
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindExchangeRates implements the FindExchangeRates method of the ExchangeRateRepository interface for BoltDB.
+// The exchange rates of the country-currency are returned most recent first, like the Treasury API does. An empty
+// slice is returned when no exchange rate is stored for it.
+func (r *ExchangeRateRepositoryBoltDB) FindExchangeRates(ctx context.Context,
+	countryCurrencyDesc string) ([]*domain.ExchangeRate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get a read lock to ensure shared read access to the database
+	r.rwMutex.RLock()
+	// Release the read lock after the function execution
+	defer r.rwMutex.RUnlock()
+
+	exchangeRates := make([]*domain.ExchangeRate, 0)
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.bucketName).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		currencyBucket := bucket.Bucket([]byte(countryCurrencyDesc))
+		if currencyBucket == nil {
+			return nil
+		}
+
+		// Dates of record are sortable keys, so walking backwards gives the most recent exchange rates first
+		cursor := currencyBucket.Cursor()
+		for key, value := cursor.Last(); key != nil; key, value = cursor.Prev() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			exchangeRate, err := unmarshalExchangeRate(value)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("currency", countryCurrencyDesc).
+					Str("date_of_record", string(key)).
+					Msg("failed to unmarshal exchange rate data")
+				return err
+			}
+			exchangeRates = append(exchangeRates, exchangeRate)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exchangeRates, nil
+}
+
+// SaveExchangeRateCoverage implements the SaveExchangeRateCoverage method of the ExchangeRateRepository interface for
+// BoltDB. The days from and to, included, are merged into the coverage already stored for the country-currency. An
+// empty country-currency records the coverage of all the currencies, as left by a synchronization.
+func (r *ExchangeRateRepositoryBoltDB) SaveExchangeRateCoverage(ctx context.Context, countryCurrencyDesc string,
+	from, to time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	return r.boltDB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.coverageBucket))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.coverageBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		coverage, err := unmarshalExchangeRateCoverage(bucket.Get(coverageKey(countryCurrencyDesc)))
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("currency", countryCurrencyDesc).
+				Msg("failed to unmarshal exchange rate coverage data")
+			return err
+		}
+		coverage.Add(from, to)
+
+		coverageJSONData, err := json.Marshal(coverage)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("currency", countryCurrencyDesc).
+				Msg("failed to marshal exchange rate coverage data")
+			return err
+		}
+		return bucket.Put(coverageKey(countryCurrencyDesc), coverageJSONData)
+	})
+}
+
+// FindExchangeRateCoverage implements the FindExchangeRateCoverage method of the ExchangeRateRepository interface
+// for BoltDB. The coverage of the country-currency includes the coverage of all the currencies. An empty
+// country-currency returns the coverage of all the currencies only.
+func (r *ExchangeRateRepositoryBoltDB) FindExchangeRateCoverage(ctx context.Context,
+	countryCurrencyDesc string) (domain.ExchangeRateCoverage, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ExchangeRateCoverage{}, err
+	}
+
+	// Get a read lock to ensure shared read access to the database
+	r.rwMutex.RLock()
+	// Release the read lock after the function execution
+	defer r.rwMutex.RUnlock()
+
+	var coverage domain.ExchangeRateCoverage
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.coverageBucket))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.coverageBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		for _, key := range [][]byte{coverageKey(""), coverageKey(countryCurrencyDesc)} {
+			storedCoverage, err := unmarshalExchangeRateCoverage(bucket.Get(key))
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("currency", string(key)).
+					Msg("failed to unmarshal exchange rate coverage data")
+				return err
+			}
+			coverage.Merge(storedCoverage)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.ExchangeRateCoverage{}, err
+	}
+	return coverage, nil
+}
+
+// ValidateExchangeRateRepositoryBoltDB validates the BoltDB database and the bucket name for the
+// ExchangeRateRepositoryBoltDB struct.
+func ValidateExchangeRateRepositoryBoltDB(boltDB *bbolt.DB, bucketName string) error {
+	// Validate the database and bucket name emptiness: must not be empty
+	if boltDB == nil || bucketName == "" {
+		return ErrBoltDBAndBucketNameIsMandatory
+	}
+	return nil
+}
+
+// coverageKey returns the key of the coverage of a country-currency, or of all the currencies when it is empty.
+func coverageKey(countryCurrencyDesc string) []byte {
+	if countryCurrencyDesc == "" {
+		return []byte(allCurrenciesCoverageKey)
+	}
+	return []byte(countryCurrencyDesc)
+}
+
+// unmarshalExchangeRateCoverage turns a stored coverage back into an ExchangeRateCoverage. A missing coverage is
+// empty.
+func unmarshalExchangeRateCoverage(coverageJSONData []byte) (domain.ExchangeRateCoverage, error) {
+	var coverage domain.ExchangeRateCoverage
+	if coverageJSONData == nil {
+		return coverage, nil
+	}
+	err := json.Unmarshal(coverageJSONData, &coverage)
+	return coverage, err
+}
+
+// unmarshalExchangeRate turns a stored exchange rate back into a validated ExchangeRate.
+func unmarshalExchangeRate(exchangeRateJSONData []byte) (*domain.ExchangeRate, error) {
+	var record exchangeRateRecord
+	if err := json.Unmarshal(exchangeRateJSONData, &record); err != nil {
+		return nil, err
+	}
+
+	exchangeRate, errs := domain.NewExchangeRate(record.CurrencyName, record.Rate, record.DateOfRecord)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid stored exchange rate: %v", errs)
+	}
+	exchangeRate.CountryCurrencyDesc = record.CountryCurrencyDesc
+	exchangeRate.CurrencyCode = record.CurrencyCode
+	exchangeRate.Source = record.Source
+
+	return exchangeRate, nil
+}
@@ -0,0 +1,163 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the BoltDB implementation of the ExchangeRateRepository interface.
+// It uses Testify for assertions and runs the tests in parallel.
+
+// TestExchangeRateBoltDBRepository tests the BoltDB implementation of the ExchangeRateRepository interface.
+// It tests the following scenarios:
+//
+// 1. Repository Initialization Without Database.
+// 2. Save And Find Exchange Rates Most Recent First.
+// 3. Saving Again Replaces The Exchange Rate.
+// 4. Find Exchange Rates Of An Unknown Currency.
+// 5. Save An Exchange Rate Without Country-Currency.
+// 6. Save And Find The Coverage.
+// 7. Canceled Context.
+func TestExchangeRateBoltDBRepository(t *testing.T) {
+	// The exchange rates share the database of the transactions
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB("testdata/exchange_rate_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	// Each test uses its own bucket of the shared database
+	newRepository := func(t *testing.T) *repository.ExchangeRateRepositoryBoltDB {
+		repo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates_"+uuid.New().String())
+		require.NoError(t, err)
+		return repo
+	}
+	newExchangeRate := func(t *testing.T, countryCurrencyDesc, rate string, dateOfRecord time.Time) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Any", rate, dateOfRecord)
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+		exchangeRate.CurrencyCode = "BRL"
+		exchangeRate.Source = domain.ExchangeRateSourceTreasury
+		return exchangeRate
+	}
+	march := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	september := time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Repository Initialization Without Database", func(t *testing.T) {
+		t.Parallel()
+		repo, err := repository.NewExchangeRateRepositoryBoltDB(nil, "exchange_rates")
+		assert.ErrorIs(t, err, repository.ErrBoltDBAndBucketNameIsMandatory)
+		assert.Nil(t, repo)
+	})
+
+	t.Run("Save And Find Exchange Rates Most Recent First", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		require.NoError(t, repo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{
+			newExchangeRate(t, "Brazil-Real", "5.5", june),
+			newExchangeRate(t, "Brazil-Real", "5.434", september),
+			newExchangeRate(t, "Brazil-Real", "4.9", march),
+		}))
+
+		exchangeRates, err := repo.FindExchangeRates(context.Background(), "Brazil-Real")
+		require.NoError(t, err)
+		require.Len(t, exchangeRates, 3)
+		assert.Equal(t, september, exchangeRates[0].DateOfRecord)
+		assert.Equal(t, june, exchangeRates[1].DateOfRecord)
+		assert.Equal(t, march, exchangeRates[2].DateOfRecord)
+		assert.Equal(t, "5.434", exchangeRates[0].RateText)
+		assert.Equal(t, "Brazil-Real", exchangeRates[0].CountryCurrencyDesc)
+		assert.Equal(t, "BRL", exchangeRates[0].CurrencyCode)
+		assert.Equal(t, domain.ExchangeRateSourceTreasury, exchangeRates[0].Source)
+	})
+
+	t.Run("Saving Again Replaces The Exchange Rate", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		require.NoError(t, repo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{newExchangeRate(t, "Brazil-Real", "5.5", june)}))
+		require.NoError(t, repo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{newExchangeRate(t, "Brazil-Real", "5.6", june)}))
+
+		exchangeRates, err := repo.FindExchangeRates(context.Background(), "Brazil-Real")
+		require.NoError(t, err)
+		require.Len(t, exchangeRates, 1)
+		assert.Equal(t, "5.6", exchangeRates[0].RateText)
+	})
+
+	t.Run("Find Exchange Rates Of An Unknown Currency", func(t *testing.T) {
+		t.Parallel()
+		exchangeRates, err := newRepository(t).FindExchangeRates(context.Background(), "Atlantis-Drachma")
+		require.NoError(t, err)
+		assert.Empty(t, exchangeRates)
+	})
+
+	t.Run("Save An Exchange Rate Without Country-Currency", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		err := repo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{
+			newExchangeRate(t, "Brazil-Real", "5.5", june),
+			newExchangeRate(t, "", "5.434", september),
+		})
+		assert.ErrorIs(t, err, repository.ErrExchangeRateCountryCurrencyMissing)
+
+		// Nothing is saved
+		exchangeRates, err := repo.FindExchangeRates(context.Background(), "Brazil-Real")
+		require.NoError(t, err)
+		assert.Empty(t, exchangeRates)
+	})
+
+	t.Run("Save And Find The Coverage", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		coverage, err := repo.FindExchangeRateCoverage(context.Background(), "Brazil-Real")
+		require.NoError(t, err)
+		assert.Empty(t, coverage.Periods)
+
+		require.NoError(t, repo.SaveExchangeRateCoverage(context.Background(), "", march, june))
+		require.NoError(t, repo.SaveExchangeRateCoverage(context.Background(), "Brazil-Real", june.AddDate(0, 0, 1), september))
+		require.NoError(t, repo.SaveExchangeRateCoverage(context.Background(), "Japan-Yen", september, september))
+
+		// The coverage of a currency includes the coverage of all the currencies
+		coverage, err = repo.FindExchangeRateCoverage(context.Background(), "Brazil-Real")
+		require.NoError(t, err)
+		assert.True(t, coverage.Covers(march, september))
+		coverage, err = repo.FindExchangeRateCoverage(context.Background(), "Japan-Yen")
+		require.NoError(t, err)
+		assert.Len(t, coverage.Periods, 2)
+		assert.False(t, coverage.Covers(march, september))
+		coverage, err = repo.FindExchangeRateCoverage(context.Background(), "")
+		require.NoError(t, err)
+		assert.Equal(t, june, coverage.End())
+	})
+
+	t.Run("Canceled Context", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := repo.SaveExchangeRates(ctx, []*domain.ExchangeRate{newExchangeRate(t, "Brazil-Real", "5.5", june)})
+		assert.ErrorIs(t, err, context.Canceled)
+		_, err = repo.FindExchangeRates(ctx, "Brazil-Real")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, repo.SaveExchangeRateCoverage(ctx, "Brazil-Real", march, june), context.Canceled)
+		_, err = repo.FindExchangeRateCoverage(ctx, "Brazil-Real")
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// Nothing is saved
+		exchangeRates, err := repo.FindExchangeRates(context.Background(), "Brazil-Real")
+		require.NoError(t, err)
+		assert.Empty(t, exchangeRates)
+	})
+}
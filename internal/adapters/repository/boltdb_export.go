@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.etcd.io/bbolt"
+)
+
+// This file contains the streaming of the transactions of the BoltDB transaction repository, used by the exports.
+
+// streamPageSize is the maximum number of transactions read in a single read transaction when streaming
+// transactions.
+const streamPageSize = 100
+
+// StreamTransactions implements the StreamTransactions method of the TransactionRepository interface for BoltDB.
+// The transactions matching the filters of the query are passed to the export function one at a time, in
+// chronological order, so only a page of them is kept in memory. The pagination of the query is ignored. The walk
+// stops at the first error of the export function, or as soon as the context is done.
+//
+// Each page is read in its own short read transaction, resuming after the index key of the previous page, and the
+// read transaction is closed before the page is exported: the pace of the client never keeps a read transaction
+// open, which would stall the writes growing the database file. A transaction saved or deleted during the walk is
+// therefore exported or skipped depending on where the walk is.
+func (r *TransactionRepositoryBoltDB) StreamTransactions(ctx context.Context, query domain.TransactionQuery,
+	export func(transaction *domain.Transaction) error) error {
+	var after []byte
+	for {
+		transactions, lastKey, err := r.findStreamPage(ctx, query, after)
+		if err != nil {
+			return err
+		}
+		for _, transaction := range transactions {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := export(transaction); err != nil {
+				return err
+			}
+		}
+		if lastKey == nil {
+			return nil
+		}
+		after = lastKey
+	}
+}
+
+// findStreamPage reads a page of the transactions matching the filters of a query, walking the timestamp index after
+// the given key, or from the start of the date range when it is nil. It returns the transactions and the last index
+// key walked, which is nil when the walk reached the end of the date range.
+func (r *TransactionRepositoryBoltDB) findStreamPage(ctx context.Context, query domain.TransactionQuery,
+	after []byte) ([]*domain.Transaction, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Get a read lock to ensure shared read access to the database
+	r.rwMutex.RLock()
+	// Release the read lock after the function execution
+	defer r.rwMutex.RUnlock()
+
+	transactions := make([]*domain.Transaction, 0, streamPageSize)
+	var lastKey []byte
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.bucketName).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+		indexBucket := tx.Bucket([]byte(r.timestampIndexBucket))
+		if indexBucket == nil {
+			log.Error().
+				Str("bucket", r.timestampIndexBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		cursor := indexBucket.Cursor()
+		var key, value []byte
+		switch {
+		case after != nil:
+			// Resume right after the last key of the previous page
+			key, value = cursor.Seek(after)
+			if key != nil && bytes.Equal(key, after) {
+				key, value = cursor.Next()
+			}
+		case query.From != nil:
+			key, value = cursor.Seek(timestampIndexKey(*query.From, uuid.Nil))
+		default:
+			key, value = cursor.First()
+		}
+
+		for ; key != nil; key, value = cursor.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// The index is sorted by timestamp, so nothing after the end of the date range can match
+			if query.To != nil && timestampFromIndexKey(key).After(*query.To) {
+				lastKey = nil
+				return nil
+			}
+			// A full page was already read, the next page resumes after its last key
+			if len(transactions) == streamPageSize {
+				return nil
+			}
+			// The key is only valid during the read transaction
+			lastKey = append(lastKey[:0], key...)
+
+			transactionJSONData := bucket.Get(value)
+			if transactionJSONData == nil {
+				log.Warn().
+					Str("transaction_id", string(value)).
+					Msg("indexed transaction not found in BoltDB")
+				continue
+			}
+			var transaction domain.Transaction
+			if err := json.Unmarshal(transactionJSONData, &transaction); err != nil {
+				log.Error().
+					Err(err).
+					Str("transaction_id", string(value)).
+					Msg("failed to unmarshal transaction data")
+				return err
+			}
+			if query.Matches(transaction) {
+				transactions = append(transactions, &transaction)
+			}
+		}
+		lastKey = nil
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return transactions, lastKey, nil
+}
@@ -0,0 +1,116 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the streaming of the transactions of the BoltDB transaction repository. It uses Table
+// Driven Tests to test different scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestTransactionBoltDBRepositoryStream tests the StreamTransactions method of the BoltDB transaction repository. It
+// tests the following scenarios:
+//
+// 1. All Transactions In Chronological Order.
+// 2. Date Range Across Pages.
+// 3. Filters Without Pagination.
+// 4. Export Error Stops The Walk.
+// 5. Canceled Context.
+func TestTransactionBoltDBRepositoryStream(t *testing.T) {
+	repo, err := repository.NewTransactionRepositoryBoltDB("testdata/transaction_stream_test.db", "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, repo.Close(), "failed to close the repository")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	// Saves more transactions than a page of the stream, an hour apart, in reverse order
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	transactions := make([]domain.Transaction, 250)
+	for i := len(transactions) - 1; i >= 0; i-- {
+		transaction, errs := domain.NewTransaction("streamed", start.Add(time.Duration(i)*time.Hour),
+			domain.MustParseMoney("1.25", domain.CurrencyUSD))
+		require.Empty(t, errs)
+		transactions[i] = *transaction
+	}
+	transactions[10].Description = "coffee"
+	require.NoError(t, repo.SaveTransactions(context.Background(), transactions))
+
+	errExport := errors.New("export failed")
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	from, to := start.Add(90*time.Hour), start.Add(210*time.Hour)
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		query         domain.TransactionQuery
+		failAt        int
+		expectedFirst int
+		expectedCount int
+		expectedError error
+	}{
+		{
+			name:          "All Transactions In Chronological Order",
+			ctx:           context.Background(),
+			expectedCount: 250,
+		},
+		{
+			name:          "Date Range Across Pages",
+			ctx:           context.Background(),
+			query:         domain.TransactionQuery{From: &from, To: &to},
+			expectedFirst: 90,
+			expectedCount: 121,
+		},
+		{
+			name:          "Filters Without Pagination",
+			ctx:           context.Background(),
+			query:         domain.TransactionQuery{DescriptionContains: "COFFEE", Cursor: "ignored", Limit: 1},
+			expectedFirst: 10,
+			expectedCount: 1,
+		},
+		{
+			name:          "Export Error Stops The Walk",
+			ctx:           context.Background(),
+			failAt:        120,
+			expectedCount: 120,
+			expectedError: errExport,
+		},
+		{
+			name:          "Canceled Context",
+			ctx:           canceledCtx,
+			expectedError: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			streamed := make([]*domain.Transaction, 0)
+
+			err := repo.StreamTransactions(tt.ctx, tt.query, func(transaction *domain.Transaction) error {
+				if tt.failAt > 0 && len(streamed) == tt.failAt {
+					return errExport
+				}
+				streamed = append(streamed, transaction)
+				return nil
+			})
+
+			assert.ErrorIs(t, err, tt.expectedError)
+			require.Len(t, streamed, tt.expectedCount)
+			for i, transaction := range streamed {
+				assert.Equal(t, transactions[tt.expectedFirst+i].ID, transaction.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.etcd.io/bbolt"
+)
+
+// This file contains the revisions of the transactions in the BoltDB transaction repository, and their history.
+
+// historyBucketSuffix is appended to the bucket name to name the bucket holding the prior revisions of the
+// transactions. The key of a revision is the ID of its transaction followed by its version, so the revisions of a
+// transaction are stored together, oldest first.
+const historyBucketSuffix = "_history"
+
+// historyKey builds the key of a revision of a transaction in the history bucket.
+func historyKey(id uuid.UUID, version int) []byte {
+	key := make([]byte, 0, len(id)+8)
+	key = append(key, id[:]...)
+	return binary.BigEndian.AppendUint64(key, uint64(version))
+}
+
+// SaveTransactionRevision implements the SaveTransactionRevision method of the TransactionRepository interface for
+// BoltDB. The revision replaces the current revision of its transaction, which is kept in the history, only when it
+// follows it: a revision made from an older version is rejected with domain.ErrTransactionVersionMismatch, since the
+// transaction was revised concurrently. The tombstone of a deleted transaction is saved as its last revision.
+func (r *TransactionRepositoryBoltDB) SaveTransactionRevision(ctx context.Context, revision domain.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	return r.boltDB.Update(func(tx *bbolt.Tx) error {
+		transaction, err := r.getTransaction(tx, revision.ID)
+		if err != nil {
+			return err
+		}
+
+		if revision.Version != transaction.Version+1 {
+			log.Warn().
+				Str("transaction_id", revision.ID.String()).
+				Int("version", transaction.Version).
+				Int("revision_version", revision.Version).
+				Msg("transaction revised from another version")
+			return domain.ErrTransactionVersionMismatch
+		}
+		return r.putRevision(tx, *transaction, revision)
+	})
+}
+
+// putRevision keeps the current revision of a transaction in the history and saves its next revision, in a write
+// transaction.
+func (r *TransactionRepositoryBoltDB) putRevision(tx *bbolt.Tx, transaction, revision domain.Transaction) error {
+	historyBucket := tx.Bucket([]byte(r.historyBucket))
+	if historyBucket == nil {
+		log.Error().
+			Str("bucket", r.historyBucket).
+			Msg("bucket not found in BoltDB")
+		return ErrBucketNotFound
+	}
+
+	transactionJSONData, err := json.Marshal(transaction)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("transaction_id", transaction.ID.String()).
+			Msg("failed to marshal transaction data")
+		return err
+	}
+
+	err = historyBucket.Put(historyKey(transaction.ID, transaction.Version), transactionJSONData)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("transaction_id", transaction.ID.String()).
+			Msg("failed to save the transaction revision")
+		return err
+	}
+	return r.putTransaction(tx, revision)
+}
+
+// FindTransactionHistory implements the FindTransactionHistory method of the TransactionRepository interface for
+// BoltDB. It returns all the revisions of a transaction, oldest first, ending with its current revision. The history
+// of a deleted transaction ends with its tombstone.
+func (r *TransactionRepositoryBoltDB) FindTransactionHistory(ctx context.Context,
+	id uuid.UUID) ([]*domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get a read lock to ensure shared read access to the database
+	r.rwMutex.RLock()
+	// Release the read lock after the function execution
+	defer r.rwMutex.RUnlock()
+
+	var revisions []*domain.Transaction
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			log.Error().
+				Str("bucket", r.bucketName).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+		historyBucket := tx.Bucket([]byte(r.historyBucket))
+		if historyBucket == nil {
+			log.Error().
+				Str("bucket", r.historyBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		transactionJSONData := bucket.Get([]byte(id.String()))
+		if transactionJSONData == nil {
+			log.Warn().
+				Str("transaction_id", id.String()).
+				Msg("transaction not found in BoltDB")
+			return ErrTransactionNotFound
+		}
+
+		// The prior revisions are stored by version after the ID of their transaction
+		cursor := historyBucket.Cursor()
+		for key, value := cursor.Seek(id[:]); key != nil && bytes.HasPrefix(key, id[:]); key, value = cursor.Next() {
+			revision, err := unmarshalRevision(id, value)
+			if err != nil {
+				return err
+			}
+			revisions = append(revisions, revision)
+		}
+
+		revision, err := unmarshalRevision(id, transactionJSONData)
+		if err != nil {
+			return err
+		}
+		revisions = append(revisions, revision)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// unmarshalRevision decodes a revision of a transaction.
+func unmarshalRevision(id uuid.UUID, transactionJSONData []byte) (*domain.Transaction, error) {
+	var revision domain.Transaction
+	if err := json.Unmarshal(transactionJSONData, &revision); err != nil {
+		log.Error().
+			Err(err).
+			Str("transaction_id", id.String()).
+			Msg("failed to unmarshal transaction data")
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,167 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the revisions of the transactions in the BoltDB transaction repository, and their
+// history. It uses Testify for assertions and runs the tests in parallel.
+
+// TestTransactionBoltDBRepositoryHistory tests the SaveTransactionRevision and FindTransactionHistory methods of the
+// BoltDB transaction repository. It tests the following scenarios:
+//
+// 1. Revisions Kept In The History.
+// 2. Revision From Another Version.
+// 3. Deleted Transaction Keeps Its History.
+// 4. Locked Conversion Makes A Revision.
+// 5. Unknown Transaction.
+// 6. Canceled Context.
+func TestTransactionBoltDBRepositoryHistory(t *testing.T) {
+	// Create a temporary BoltDB database file for testing
+	tempDBPath := "testdata/transaction_history_test.db"
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		err := os.RemoveAll("testdata")
+		require.NoError(t, err, "failed to clean up test data directory")
+	})
+
+	revisedAt := time.Date(2024, 10, 2, 9, 0, 0, 0, time.UTC)
+
+	// Opens the repository on a unique bucket name for each test, with a saved transaction
+	newRepository := func(t *testing.T) (*repository.TransactionRepositoryBoltDB, *domain.Transaction) {
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, "transactions_"+uuid.New().String())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, repo.Close(), "failed to close the repository")
+		})
+		transaction, errs := domain.NewTransaction("giberish", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC),
+			domain.MustParseMoney("25.70", domain.CurrencyUSD))
+		require.Empty(t, errs)
+		require.NoError(t, repo.SaveTransaction(context.Background(), *transaction))
+		return repo, transaction
+	}
+
+	// Revises the description of a transaction
+	revise := func(t *testing.T, transaction *domain.Transaction, description string) *domain.Transaction {
+		revised := *transaction
+		revised.Description = description
+		revision, err := transaction.Revise(revised, revisedAt)
+		require.NoError(t, err)
+		return revision
+	}
+
+	t.Run("Revisions Kept In The History", func(t *testing.T) {
+		t.Parallel()
+		repo, transaction := newRepository(t)
+		secondRevision := revise(t, transaction, "second")
+		require.NoError(t, repo.SaveTransactionRevision(context.Background(), *secondRevision))
+		thirdRevision := revise(t, secondRevision, "third")
+		require.NoError(t, repo.SaveTransactionRevision(context.Background(), *thirdRevision))
+
+		currentTransaction, err := repo.FindTransaction(context.Background(), transaction.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "third", currentTransaction.Description)
+		assert.Equal(t, 3, currentTransaction.Version)
+
+		revisions, err := repo.FindTransactionHistory(context.Background(), transaction.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 3)
+		for i, description := range []string{"giberish", "second", "third"} {
+			assert.Equal(t, i+1, revisions[i].Version)
+			assert.Equal(t, description, revisions[i].Description)
+		}
+	})
+
+	t.Run("Revision From Another Version", func(t *testing.T) {
+		t.Parallel()
+		repo, transaction := newRepository(t)
+		require.NoError(t, repo.SaveTransactionRevision(context.Background(), *revise(t, transaction, "first writer")))
+
+		err := repo.SaveTransactionRevision(context.Background(), *revise(t, transaction, "second writer"))
+
+		assert.ErrorIs(t, err, domain.ErrTransactionVersionMismatch)
+		currentTransaction, err := repo.FindTransaction(context.Background(), transaction.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "first writer", currentTransaction.Description)
+	})
+
+	t.Run("Deleted Transaction Keeps Its History", func(t *testing.T) {
+		t.Parallel()
+		repo, transaction := newRepository(t)
+		tombstone, err := transaction.Delete(revisedAt)
+		require.NoError(t, err)
+		require.NoError(t, repo.SaveTransactionRevision(context.Background(), *tombstone))
+
+		_, err = repo.FindTransaction(context.Background(), transaction.ID)
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+		page, err := repo.FindTransactions(context.Background(), domain.TransactionQuery{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, page.Transactions)
+		err = repo.SaveTransactionRevision(context.Background(), *revise(t, transaction, "revived"))
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+
+		revisions, err := repo.FindTransactionHistory(context.Background(), transaction.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.False(t, revisions[0].Deleted())
+		assert.True(t, revisions[1].Deleted())
+	})
+
+	t.Run("Locked Conversion Makes A Revision", func(t *testing.T) {
+		t.Parallel()
+		repo, transaction := newRepository(t)
+		lockedConversion := domain.LockedConversion{
+			Currency:               "JPY",
+			CountryCurrencyDesc:    "Japan-Yen",
+			AmountInTargetCurrency: domain.MustParseMoney("3682", "JPY"),
+			RateSelectionPolicy:    domain.RateSelectionLatestOnOrBefore,
+			LockedAt:               revisedAt,
+		}
+
+		lockedTransaction, err := repo.SaveLockedConversion(context.Background(), transaction.ID, lockedConversion)
+		require.NoError(t, err)
+		assert.Equal(t, 2, lockedTransaction.Version)
+
+		revisions, err := repo.FindTransactionHistory(context.Background(), transaction.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Empty(t, revisions[0].LockedConversions)
+		assert.Len(t, revisions[1].LockedConversions, 1)
+	})
+
+	t.Run("Unknown Transaction", func(t *testing.T) {
+		t.Parallel()
+		repo, transaction := newRepository(t)
+		revision := revise(t, transaction, "unknown")
+		revision.ID = uuid.New()
+
+		err := repo.SaveTransactionRevision(context.Background(), *revision)
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+		revisions, err := repo.FindTransactionHistory(context.Background(), revision.ID)
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+		assert.Nil(t, revisions)
+	})
+
+	t.Run("Canceled Context", func(t *testing.T) {
+		t.Parallel()
+		repo, transaction := newRepository(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := repo.SaveTransactionRevision(ctx, *revise(t, transaction, "canceled"))
+		assert.ErrorIs(t, err, context.Canceled)
+		revisions, err := repo.FindTransactionHistory(ctx, transaction.ID)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, revisions)
+	})
+}
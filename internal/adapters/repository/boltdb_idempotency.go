@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/rs/zerolog/log"
+	"go.etcd.io/bbolt"
+)
+
+// This file contains the idempotency keys of the creation of transactions in the BoltDB transaction repository.
+
+// Constants for the idempotency keys. The keys are stored by key in one bucket, and indexed by expiry in another one
+// so the expired keys are purged without walking all the keys. The key of the expiry index is the expiry time in
+// nanoseconds followed by the idempotency key.
+const (
+	idempotencyKeyBucketSuffix      = "_idempotency_keys"
+	idempotencyKeyIndexBucketSuffix = "_idempotency_keys_by_expiry"
+)
+
+// idempotencyKeyIndexKey builds the sortable expiry index key of an idempotency key.
+func idempotencyKeyIndexKey(expiresAt time.Time, key string) []byte {
+	indexKey := make([]byte, 8, 8+len(key))
+	// Flips the sign bit so negative times (before 1970) are sorted before the positive ones
+	binary.BigEndian.PutUint64(indexKey, uint64(expiresAt.UnixNano())^(1<<63))
+	return append(indexKey, key...)
+}
+
+// SaveTransactionWithIdempotencyKey implements the SaveTransactionWithIdempotencyKey method of the
+// TransactionRepository interface for BoltDB. The idempotency key is looked up and the transaction saved in the same
+// write transaction, so concurrent retries of a request create a single transaction. When the key was already used
+// and has not expired, nothing is saved and the stored key is returned, otherwise the new key is returned. The
+// expired keys are purged on the way.
+func (r *TransactionRepositoryBoltDB) SaveTransactionWithIdempotencyKey(ctx context.Context,
+	transaction domain.Transaction, idempotencyKey domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	storedKey := idempotencyKey
+	err := r.boltDB.Update(func(tx *bbolt.Tx) error {
+		keyBucket := tx.Bucket([]byte(r.idempotencyKeyBucket))
+		if keyBucket == nil {
+			log.Error().
+				Str("bucket", r.idempotencyKeyBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		indexBucket := tx.Bucket([]byte(r.idempotencyKeyIndexBucket))
+		if indexBucket == nil {
+			log.Error().
+				Str("bucket", r.idempotencyKeyIndexBucket).
+				Msg("bucket not found in BoltDB")
+			return ErrBucketNotFound
+		}
+
+		if err := purgeExpiredIdempotencyKeys(keyBucket, indexBucket, idempotencyKey.CreatedAt); err != nil {
+			return err
+		}
+
+		// A key still stored after the purge has not expired, so the request is a replay
+		if keyJSONData := keyBucket.Get([]byte(idempotencyKey.Key)); keyJSONData != nil {
+			if err := json.Unmarshal(keyJSONData, &storedKey); err != nil {
+				log.Error().
+					Err(err).
+					Str("idempotency_key", idempotencyKey.Key).
+					Msg("failed to unmarshal idempotency key data")
+				return err
+			}
+			return nil
+		}
+
+		if err := r.insertTransaction(tx, transaction); err != nil {
+			return err
+		}
+
+		keyJSONData, err := json.Marshal(idempotencyKey)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("idempotency_key", idempotencyKey.Key).
+				Msg("failed to marshal idempotency key data")
+			return err
+		}
+		if err := keyBucket.Put([]byte(idempotencyKey.Key), keyJSONData); err != nil {
+			log.Error().
+				Err(err).
+				Str("idempotency_key", idempotencyKey.Key).
+				Msg("failed to save the idempotency key")
+			return err
+		}
+		return indexBucket.Put(idempotencyKeyIndexKey(idempotencyKey.ExpiresAt, idempotencyKey.Key),
+			[]byte(idempotencyKey.Key))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &storedKey, nil
+}
+
+// purgeExpiredIdempotencyKeys deletes the idempotency keys expired at the given time, walking the expiry index from
+// the oldest expiry.
+func purgeExpiredIdempotencyKeys(keyBucket, indexBucket *bbolt.Bucket, now time.Time) error {
+	// Keys expiring exactly now are expired, so the bound is the first key after them
+	bound := idempotencyKeyIndexKey(now.Add(1), "")
+
+	purged := 0
+	cursor := indexBucket.Cursor()
+	for indexKey, key := cursor.First(); indexKey != nil && bytes.Compare(indexKey, bound) < 0; indexKey, key = cursor.First() {
+		if err := keyBucket.Delete(key); err != nil {
+			return err
+		}
+		if err := indexBucket.Delete(indexKey); err != nil {
+			return err
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		log.Debug().Int("idempotency_keys", purged).Msg("expired idempotency keys purged")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the idempotency keys of the BoltDB transaction repository.
+// It uses Testify for assertions and runs the tests in parallel.
+
+// TestTransactionBoltDBRepositoryIdempotencyKeys tests the SaveTransactionWithIdempotencyKey method of the BoltDB
+// transaction repository. It tests the following scenarios:
+//
+// 1. First Use Saves The Transaction.
+// 2. Replay Returns The Stored Key.
+// 3. Expired Key Is Purged And Reused.
+// 4. Canceled Context.
+func TestTransactionBoltDBRepositoryIdempotencyKeys(t *testing.T) {
+	// Create a temporary BoltDB database file for testing
+	tempDBPath := "testdata/transaction_idempotency_test.db"
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		err := os.RemoveAll("testdata")
+		require.NoError(t, err, "failed to clean up test data directory")
+	})
+
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// Creates a transaction and an idempotency key for it, created at the given time
+	newTransactionWithKey := func(t *testing.T, key, requestHash string,
+		createdAt time.Time) (*domain.Transaction, *domain.IdempotencyKey) {
+		transaction, errs := domain.NewTransaction("giberish", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC),
+			domain.MustParseMoney("25.70", domain.CurrencyUSD))
+		require.Empty(t, errs)
+		idempotencyKey, errs := domain.NewIdempotencyKey(key, requestHash, transaction.ID, createdAt, time.Hour)
+		require.Empty(t, errs)
+		return transaction, idempotencyKey
+	}
+
+	// Opens the repository on a unique bucket name for each test
+	newRepository := func(t *testing.T) *repository.TransactionRepositoryBoltDB {
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, "transactions_"+uuid.New().String())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, repo.Close(), "failed to close the repository")
+		})
+		return repo
+	}
+
+	t.Run("First Use Saves The Transaction", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		transaction, idempotencyKey := newTransactionWithKey(t, "key-1", "hash-1", createdAt)
+
+		storedKey, err := repo.SaveTransactionWithIdempotencyKey(context.Background(), *transaction, *idempotencyKey)
+
+		require.NoError(t, err)
+		assert.Equal(t, idempotencyKey, storedKey)
+		savedTransaction, err := repo.FindTransaction(context.Background(), transaction.ID)
+		require.NoError(t, err)
+		assert.Equal(t, transaction.ID, savedTransaction.ID)
+	})
+
+	t.Run("Replay Returns The Stored Key", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		transaction, idempotencyKey := newTransactionWithKey(t, "key-1", "hash-1", createdAt)
+		_, err := repo.SaveTransactionWithIdempotencyKey(context.Background(), *transaction, *idempotencyKey)
+		require.NoError(t, err)
+
+		retriedTransaction, retriedKey := newTransactionWithKey(t, "key-1", "hash-2", createdAt.Add(59*time.Minute))
+		storedKey, err := repo.SaveTransactionWithIdempotencyKey(context.Background(), *retriedTransaction, *retriedKey)
+
+		require.NoError(t, err)
+		assert.Equal(t, transaction.ID, storedKey.TransactionID)
+		assert.Equal(t, "hash-1", storedKey.RequestHash)
+		_, err = repo.FindTransaction(context.Background(), retriedTransaction.ID)
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+	})
+
+	t.Run("Expired Key Is Purged And Reused", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		transaction, idempotencyKey := newTransactionWithKey(t, "key-1", "hash-1", createdAt)
+		_, err := repo.SaveTransactionWithIdempotencyKey(context.Background(), *transaction, *idempotencyKey)
+		require.NoError(t, err)
+
+		// The key expires exactly one hour after its creation
+		laterTransaction, laterKey := newTransactionWithKey(t, "key-1", "hash-2", createdAt.Add(time.Hour))
+		storedKey, err := repo.SaveTransactionWithIdempotencyKey(context.Background(), *laterTransaction, *laterKey)
+
+		require.NoError(t, err)
+		assert.Equal(t, laterKey, storedKey)
+		_, err = repo.FindTransaction(context.Background(), laterTransaction.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Canceled Context", func(t *testing.T) {
+		t.Parallel()
+		repo := newRepository(t)
+		transaction, idempotencyKey := newTransactionWithKey(t, "key-1", "hash-1", createdAt)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		storedKey, err := repo.SaveTransactionWithIdempotencyKey(ctx, *transaction, *idempotencyKey)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, storedKey)
+		_, err = repo.FindTransaction(context.Background(), transaction.ID)
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+	})
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.etcd.io/bbolt"
+)
+
+// This file contains the secondary timestamp index of the BoltDB transaction repository.
+
+// Constants for the timestamp index. The key is the timestamp in seconds and its nanoseconds, followed by the
+// transaction ID, so the keys are sorted chronologically and unique even when two transactions share the same
+// timestamp. The seconds cover any year a timestamp can be parsed with, unlike a count of nanoseconds.
+const (
+	timestampIndexBucketSuffix = "_by_timestamp"
+	timestampIndexKeySize      = 8 + 4 + 16
+)
+
+// timestampIndexKey builds the sortable timestamp index key of a transaction.
+func timestampIndexKey(timestamp time.Time, id uuid.UUID) []byte {
+	key := make([]byte, timestampIndexKeySize)
+	// Flips the sign bit so negative timestamps (before 1970) are sorted before the positive ones
+	binary.BigEndian.PutUint64(key[:8], uint64(timestamp.Unix())^(1<<63))
+	binary.BigEndian.PutUint32(key[8:12], uint32(timestamp.Nanosecond()))
+	copy(key[12:], id[:])
+	return key
+}
+
+// timestampFromIndexKey extracts the timestamp from a timestamp index key.
+func timestampFromIndexKey(key []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint64(key[:8]) ^ (1 << 63))
+	return time.Unix(seconds, int64(binary.BigEndian.Uint32(key[8:12]))).UTC()
+}
+
+// timestampIndexOutdated reports whether the timestamp index has to be rebuilt: when it is missing, when it is empty
+// while transactions are stored, or when it was built with keys of another size by a previous version.
+func (r *TransactionRepositoryBoltDB) timestampIndexOutdated(tx *bbolt.Tx) bool {
+	indexBucket := tx.Bucket([]byte(r.timestampIndexBucket))
+	if indexBucket == nil {
+		return true
+	}
+	key, _ := indexBucket.Cursor().First()
+	if key == nil {
+		bucket := tx.Bucket([]byte(r.bucketName))
+		if bucket == nil {
+			return false
+		}
+		transactionKey, _ := bucket.Cursor().First()
+		return transactionKey != nil
+	}
+	return len(key) != timestampIndexKeySize
+}
+
+// RebuildTimestampIndex drops the timestamp index and rebuilds it from all the stored transactions in a single
+// write transaction. It is used to cover databases created before the index existed.
+func (r *TransactionRepositoryBoltDB) RebuildTimestampIndex() error {
+	// Get a write lock to ensure exclusive access to the database
+	r.rwMutex.Lock()
+	// Release the write lock after the function execution
+	defer r.rwMutex.Unlock()
+
+	indexed := 0
+	err := r.boltDB.Update(func(tx *bbolt.Tx) error {
+		var err error
+		indexed, err = r.rebuildTimestampIndex(tx)
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Str("bucket", r.timestampIndexBucket).Msg("failed to rebuild the timestamp index")
+		return ErrRebuildIndex
+	}
+
+	log.Info().Str("bucket", r.timestampIndexBucket).Int("transactions", indexed).Msg("timestamp index rebuilt")
+	return nil
+}
+
+// rebuildTimestampIndex drops the timestamp index and rebuilds it from all the stored transactions in a write
+// transaction. It returns the number of transactions indexed.
+func (r *TransactionRepositoryBoltDB) rebuildTimestampIndex(tx *bbolt.Tx) (int, error) {
+	bucket := tx.Bucket([]byte(r.bucketName))
+	if bucket == nil {
+		log.Error().
+			Str("bucket", r.bucketName).
+			Msg("bucket not found in BoltDB")
+		return 0, ErrBucketNotFound
+	}
+
+	if tx.Bucket([]byte(r.timestampIndexBucket)) != nil {
+		if err := tx.DeleteBucket([]byte(r.timestampIndexBucket)); err != nil {
+			return 0, err
+		}
+	}
+	indexBucket, err := tx.CreateBucket([]byte(r.timestampIndexBucket))
+	if err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	err = bucket.ForEach(func(key, value []byte) error {
+		var transaction domain.Transaction
+		if err := json.Unmarshal(value, &transaction); err != nil {
+			log.Error().
+				Err(err).
+				Str("transaction_id", string(key)).
+				Msg("failed to unmarshal transaction data")
+			return err
+		}
+		// The tombstones of the deleted transactions are left out of the index
+		if transaction.Deleted() {
+			return nil
+		}
+		indexed++
+		return indexBucket.Put(timestampIndexKey(transaction.Timestamp, transaction.ID), key)
+	})
+	return indexed, err
+}
@@ -0,0 +1,220 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+// This file contains tests for the timestamp index of the BoltDB transaction repository.
+// It uses Testify for assertions and runs the tests in parallel.
+
+// TestTransactionBoltDBRepositoryTimestampIndex tests the timestamp index of the BoltDB transaction repository.
+// It tests the following scenarios:
+//
+// 1. Chronological Order And Date Range.
+// 2. Revising Moves The Index Entry.
+// 3. Rebuild For Databases Without Index.
+// 4. Rebuild For Databases With An Empty Index.
+// 5. Timestamps Outside The Nanosecond Range.
+func TestTransactionBoltDBRepositoryTimestampIndex(t *testing.T) {
+	// Create a temporary BoltDB database file for testing
+	tempDBPath := "testdata/transaction_index_test.db"
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		err := os.RemoveAll("testdata")
+		require.NoError(t, err, "failed to clean up test data directory")
+	})
+
+	// Creates one transaction per day of January 2024, saved in reverse order
+	newTransactions := func(t *testing.T) []*domain.Transaction {
+		transactions := make([]*domain.Transaction, 0, 31)
+		for day := 31; day >= 1; day-- {
+			amountInUSD, err := domain.NewMoney(int64(day)*100, 2, domain.CurrencyUSD)
+			require.NoError(t, err)
+			transaction, errs := domain.NewTransaction("giberish", time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC), amountInUSD)
+			require.Empty(t, errs)
+			transactions = append(transactions, transaction)
+		}
+		return transactions
+	}
+
+	t.Run("Chronological Order And Date Range", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+
+		for _, transaction := range newTransactions(t) {
+			require.NoError(t, repo.SaveTransaction(context.Background(), *transaction))
+		}
+
+		from := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+		page, err := repo.FindTransactions(context.Background(), domain.TransactionQuery{From: &from, To: &to, Limit: 100})
+		require.NoError(t, err)
+
+		// Days 10 to 19 are inside the range (day 20 is at noon, after the end of the range)
+		require.Len(t, page.Transactions, 10)
+		for i, transaction := range page.Transactions {
+			assert.Equal(t, 10+i, transaction.Timestamp.Day())
+		}
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Revising Moves The Index Entry", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+
+		transaction, errs := domain.NewTransaction("giberish", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			domain.MustParseMoney("10.00", domain.CurrencyUSD))
+		require.Empty(t, errs)
+		require.NoError(t, repo.SaveTransaction(context.Background(), *transaction))
+
+		revised := *transaction
+		revised.Timestamp = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		revision, err := transaction.Revise(revised, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, repo.SaveTransactionRevision(context.Background(), *revision))
+
+		page, err := repo.FindTransactions(context.Background(), domain.TransactionQuery{Limit: 100})
+		require.NoError(t, err)
+		require.Len(t, page.Transactions, 1)
+		assert.Equal(t, revision.Timestamp, page.Transactions[0].Timestamp)
+	})
+
+	t.Run("Rebuild For Databases Without Index", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repoFirstSession, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		for _, transaction := range newTransactions(t) {
+			require.NoError(t, repoFirstSession.SaveTransaction(context.Background(), *transaction))
+		}
+
+		// Drops the index to simulate a database created before the index existed
+		err = repoFirstSession.GetBoltDB().Update(func(tx *bbolt.Tx) error {
+			return tx.DeleteBucket([]byte(bucketName + "_by_timestamp"))
+		})
+		require.NoError(t, err)
+		require.NoError(t, repoFirstSession.Close(), "failed to close the first repository")
+
+		// Second session: the index is rebuilt when the repository is opened
+		repoSecondSession, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repoSecondSession.Close()
+			require.NoError(t, err, "failed to close the second repository")
+		}()
+
+		page, err := repoSecondSession.FindTransactions(context.Background(), domain.TransactionQuery{Limit: 100})
+		require.NoError(t, err)
+		require.Len(t, page.Transactions, 31)
+		assert.Equal(t, 1, page.Transactions[0].Timestamp.Day())
+		assert.Equal(t, 31, page.Transactions[30].Timestamp.Day())
+	})
+
+	t.Run("Rebuild For Databases With An Empty Index", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repoFirstSession, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		for _, transaction := range newTransactions(t) {
+			require.NoError(t, repoFirstSession.SaveTransaction(context.Background(), *transaction))
+		}
+
+		// Empties the index to simulate an index created but never filled
+		err = repoFirstSession.GetBoltDB().Update(func(tx *bbolt.Tx) error {
+			if err := tx.DeleteBucket([]byte(bucketName + "_by_timestamp")); err != nil {
+				return err
+			}
+			_, err := tx.CreateBucket([]byte(bucketName + "_by_timestamp"))
+			return err
+		})
+		require.NoError(t, err)
+		require.NoError(t, repoFirstSession.Close(), "failed to close the first repository")
+
+		// Second session: the index is rebuilt when the repository is opened
+		repoSecondSession, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repoSecondSession.Close()
+			require.NoError(t, err, "failed to close the second repository")
+		}()
+
+		page, err := repoSecondSession.FindTransactions(context.Background(), domain.TransactionQuery{Limit: 100})
+		require.NoError(t, err)
+		assert.Len(t, page.Transactions, 31)
+	})
+
+	t.Run("Timestamps Outside The Nanosecond Range", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+
+		// The nanoseconds since 1970 only cover the years 1678 to 2262
+		timestamps := []time.Time{
+			time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC),
+			time.Date(1500, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		for _, timestamp := range timestamps {
+			transaction, errs := domain.NewTransaction("giberish", timestamp, domain.MustParseMoney("10.00", domain.CurrencyUSD))
+			require.Empty(t, errs)
+			require.NoError(t, repo.SaveTransaction(context.Background(), *transaction))
+		}
+
+		from := time.Date(1400, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+		page, err := repo.FindTransactions(context.Background(), domain.TransactionQuery{From: &from, To: &to, Limit: 100})
+		require.NoError(t, err)
+		require.Len(t, page.Transactions, 3)
+		assert.Equal(t, timestamps[1], page.Transactions[0].Timestamp)
+		assert.Equal(t, timestamps[2], page.Transactions[1].Timestamp)
+		assert.Equal(t, timestamps[0], page.Transactions[2].Timestamp)
+
+		from = time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC)
+		page, err = repo.FindTransactions(context.Background(), domain.TransactionQuery{From: &from, Limit: 100})
+		require.NoError(t, err)
+		assert.Len(t, page.Transactions, 2)
+	})
+}
@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"context"
 	"math/rand"
 	"os"
 	"sync"
@@ -28,9 +29,14 @@ import (
 // 5. Persistence Across Sessions.
 // 6. Heavy Write Scenario.
 // 7. Heavy Read/Write Scenario.
+// 8. Find Transactions With Filters And Pagination.
+// 9. Find Transactions With Invalid Cursor.
+// 10. Lock A Conversion Once.
+// 11. Existing Transaction Not Overwritten.
+// 12. Save A Batch Of Transactions Atomically.
 func TestTransactionBoltDBRepository(t *testing.T) {
 	// Reusable test Transaction
-	testTransaction, err := domain.NewTransaction("giberish", time.Now(), 100.50)
+	testTransaction, err := domain.NewTransaction("giberish", time.Now(), domain.MustParseMoney("100.50", domain.CurrencyUSD))
 	// Stops the test if the expected results are not as expected (probably the business logic changed)
 	require.Empty(t, err)
 
@@ -68,10 +74,10 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 			require.NoError(t, err, "failed to close the repository")
 		}()
 
-		err = repo.SaveTransaction(*testTransaction)
+		err = repo.SaveTransaction(context.Background(), *testTransaction)
 		require.NoError(t, err)
 
-		retrievedTransaction, err := repo.FindTransaction(testTransaction.ID)
+		retrievedTransaction, err := repo.FindTransaction(context.Background(), testTransaction.ID)
 		require.NoError(t, err)
 		require.NotNil(t, retrievedTransaction)
 		assert.Equal(t, testTransaction.ID, retrievedTransaction.ID)
@@ -92,7 +98,7 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 			require.NoError(t, err, "failed to close the repository")
 		}()
 
-		_, err = repo.FindTransaction(uuid.New())
+		_, err = repo.FindTransaction(context.Background(), uuid.New())
 		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
 	})
 
@@ -116,7 +122,7 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		err = repo.SaveTransaction(*testTransaction)
+		err = repo.SaveTransaction(context.Background(), *testTransaction)
 		assert.ErrorIs(t, err, repository.ErrBucketNotFound)
 	})
 
@@ -129,7 +135,7 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 		repoFirstSession, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
 		require.NoError(t, err)
 
-		err = repoFirstSession.SaveTransaction(*testTransaction)
+		err = repoFirstSession.SaveTransaction(context.Background(), *testTransaction)
 		require.NoError(t, err)
 		err = repoFirstSession.Close()
 		require.NoError(t, err, "failed to close the first repository")
@@ -142,7 +148,7 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 			require.NoError(t, err, "failed to close the second repository")
 		}()
 
-		retrievedTransaction, err := repoSecondSession.FindTransaction(testTransaction.ID)
+		retrievedTransaction, err := repoSecondSession.FindTransaction(context.Background(), testTransaction.ID)
 		require.NoError(t, err)
 		assert.Equal(t, testTransaction.AmountInUSD, retrievedTransaction.AmountInUSD)
 	})
@@ -170,7 +176,9 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 		// Write transactions concurrently
 		for i := 0; i < iterations; i++ {
 			// Creates a test transaction
-			transaction, err := domain.NewTransaction("giberish", time.Now(), float64(i)+(rand.Float64()*100))
+			amountInUSD, moneyErr := domain.NewMoney(int64(i*100+rand.Intn(10000)), 2, domain.CurrencyUSD)
+			require.NoError(t, moneyErr)
+			transaction, err := domain.NewTransaction("giberish", time.Now(), amountInUSD)
 			// Stops the test if the expected results are not as expected (probably the business logic changed)
 			require.Empty(t, err)
 
@@ -179,7 +187,7 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 			wg.Add(1)
 			go func(transaction domain.Transaction) {
 				defer wg.Done()
-				err := repo.SaveTransaction(transaction)
+				err := repo.SaveTransaction(context.Background(), transaction)
 				if err != nil {
 					writeErrChan <- err
 				}
@@ -198,7 +206,7 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 
 		// Verify if all transactions were saved
 		for _, transaction := range transactions {
-			retrievedTransaction, err := repo.FindTransaction(transaction.ID)
+			retrievedTransaction, err := repo.FindTransaction(context.Background(), transaction.ID)
 			require.NoError(t, err)
 			assert.Equal(t, 0, transaction.AmountInUSD.Cmp(retrievedTransaction.AmountInUSD))
 		}
@@ -229,19 +237,21 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 				defer wg.Done()
 
 				// Creates a test transaction
-				transaction, err := domain.NewTransaction("giberish", time.Now(), float64(i)+(rand.Float64()*100))
+				amountInUSD, moneyErr := domain.NewMoney(int64(i*100+rand.Intn(10000)), 2, domain.CurrencyUSD)
+				require.NoError(t, moneyErr)
+				transaction, err := domain.NewTransaction("giberish", time.Now(), amountInUSD)
 				// Stops the test if the expected results are not as expected (probably the business logic changed)
 				require.Empty(t, err)
 
 				// Random write
-				if err := repo.SaveTransaction(*transaction); err != nil {
+				if err := repo.SaveTransaction(context.Background(), *transaction); err != nil {
 					writeErrChan <- err
 					return
 				}
 
 				// Random read
 				if i%2 == 0 {
-					if _, err := repo.FindTransaction(transaction.ID); err != nil {
+					if _, err := repo.FindTransaction(context.Background(), transaction.ID); err != nil {
 						writeErrChan <- err
 					}
 				}
@@ -258,6 +268,169 @@ func TestTransactionBoltDBRepository(t *testing.T) {
 			assert.NoError(t, err, "error during heavy read/write load operation")
 		}
 	})
+
+	t.Run("Find Transactions With Filters And Pagination", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+
+		// Saves 5 coffees and 5 books with increasing amounts
+		for i := 1; i <= 10; i++ {
+			description := "Coffee"
+			if i%2 == 0 {
+				description = "Book"
+			}
+			amountInUSD, err := domain.NewMoney(int64(i)*100, 2, domain.CurrencyUSD)
+			require.NoError(t, err)
+			transaction, errs := domain.NewTransaction(description, time.Date(2024, 1, i, 0, 0, 0, 0, time.UTC), amountInUSD)
+			require.Empty(t, errs)
+			require.NoError(t, repo.SaveTransaction(context.Background(), *transaction))
+		}
+
+		minAmountInUSD := domain.MustParseMoney("2", domain.CurrencyUSD)
+		query, errs := domain.NewTransactionQuery(nil, nil, &minAmountInUSD, nil, "coffee", "", 2)
+		require.Empty(t, errs)
+
+		// Walks through all the pages until there is no next cursor
+		found := make([]*domain.Transaction, 0)
+		pages := 0
+		for {
+			page, err := repo.FindTransactions(context.Background(), *query)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Transactions), 2)
+			found = append(found, page.Transactions...)
+			pages++
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+
+		// Coffees are the odd amounts and the first one is filtered out by the minimum amount
+		assert.Equal(t, 2, pages)
+		require.Len(t, found, 4)
+		for _, transaction := range found {
+			assert.Equal(t, "Coffee", transaction.Description)
+			assert.Equal(t, 1, transaction.AmountInUSD.Cmp(minAmountInUSD))
+		}
+	})
+
+	t.Run("Find Transactions With Invalid Cursor", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+
+		_, err = repo.FindTransactions(context.Background(), domain.TransactionQuery{Cursor: "not base64!", Limit: 10})
+		assert.ErrorIs(t, err, domain.ErrInvalidTransactionCursor)
+	})
+
+	t.Run("Lock A Conversion Once", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+		require.NoError(t, repo.SaveTransaction(context.Background(), *testTransaction))
+		lockedConversion := domain.LockedConversion{
+			Currency:               "JPY",
+			CountryCurrencyDesc:    "Japan-Yen",
+			AmountInTargetCurrency: domain.MustParseMoney("4128", "JPY"),
+			RateSelectionPolicy:    domain.RateSelectionLatestOnOrBefore,
+			LockedAt:               time.Date(2024, 10, 2, 0, 0, 0, 0, time.UTC),
+		}
+
+		updatedTransaction, err := repo.SaveLockedConversion(context.Background(), testTransaction.ID, lockedConversion)
+		require.NoError(t, err)
+		require.Len(t, updatedTransaction.LockedConversions, 1)
+
+		retrievedTransaction, err := repo.FindTransaction(context.Background(), testTransaction.ID)
+		require.NoError(t, err)
+		require.Len(t, retrievedTransaction.LockedConversions, 1)
+		assert.Equal(t, lockedConversion.AmountInTargetCurrency, retrievedTransaction.LockedConversions[0].AmountInTargetCurrency)
+
+		_, err = repo.SaveLockedConversion(context.Background(), testTransaction.ID, lockedConversion)
+		assert.ErrorIs(t, err, domain.ErrConversionAlreadyLocked)
+		_, err = repo.SaveLockedConversion(context.Background(), uuid.New(), lockedConversion)
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+	})
+
+	t.Run("Existing Transaction Not Overwritten", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+		require.NoError(t, repo.SaveTransaction(context.Background(), *testTransaction))
+
+		// A new transaction colliding with the ID of the saved one
+		collidingTransaction := *testTransaction
+		collidingTransaction.Description = "colliding"
+		err = repo.SaveTransaction(context.Background(), collidingTransaction)
+		assert.ErrorIs(t, err, repository.ErrTransactionAlreadyExists)
+
+		retrievedTransaction, err := repo.FindTransaction(context.Background(), testTransaction.ID)
+		require.NoError(t, err)
+		assert.Equal(t, testTransaction.Description, retrievedTransaction.Description)
+	})
+
+	t.Run("Save A Batch Of Transactions Atomically", func(t *testing.T) {
+		t.Parallel()
+
+		// Create a unique bucket name for this test
+		bucketName := "transactions_" + uuid.New().String()
+
+		repo, err := repository.NewTransactionRepositoryBoltDB(tempDBPath, bucketName)
+		require.NoError(t, err)
+		defer func() {
+			err := repo.Close()
+			require.NoError(t, err, "failed to close the repository")
+		}()
+		batch := make([]domain.Transaction, 3)
+		for i := range batch {
+			transaction, errs := domain.NewTransaction("batch", time.Now(), domain.MustParseMoney("1.25", domain.CurrencyUSD))
+			require.Empty(t, errs)
+			batch[i] = *transaction
+		}
+		require.NoError(t, repo.SaveTransactions(context.Background(), batch[:2]))
+
+		// A batch with a saved transaction saves none of its transactions
+		err = repo.SaveTransactions(context.Background(), batch[1:])
+		assert.ErrorIs(t, err, repository.ErrTransactionAlreadyExists)
+		_, err = repo.FindTransaction(context.Background(), batch[2].ID)
+		assert.ErrorIs(t, err, repository.ErrTransactionNotFound)
+		for _, transaction := range batch[:2] {
+			_, err = repo.FindTransaction(context.Background(), transaction.ID)
+			assert.NoError(t, err)
+		}
+	})
 }
 
 // TestValidateTransactionRepositoryBoltDB tests the ValidateTransactionRepositoryBoltDB function.
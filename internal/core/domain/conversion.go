@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// This file contains the Conversion struct, its constructor and validation functions.
+
+// Conversion represents an amount in USD converted to another currency on a date, without a stored transaction.
+type Conversion struct {
+	// AmountInUSD is the converted amount, rounded half up to two decimal places like the transaction amounts.
+	AmountInUSD Money
+	// Date is the date the exchange rate was selected for, stored in UTC.
+	Date time.Time
+	// ExchangeRate is the exchange rate applied to the amount.
+	ExchangeRate *ExchangeRate
+	// AmountInTargetCurrency is the amount rounded to the minor units of the target currency.
+	AmountInTargetCurrency Money
+}
+
+// NewConversion creates a new Conversion instance with input validation. The amount is validated and rounded like
+// the amount of a transaction before being converted.
+func NewConversion(amountInUSD Money, date time.Time, exchangeRate *ExchangeRate) (*Conversion, []error) {
+	// Validate the inputs before constructing the object
+	if errs := ValidateConversion(amountInUSD, date); len(errs) > 0 {
+		return nil, errs
+	}
+
+	roundedAmountInUSD, err := NewMoneyFromRat(amountInUSD.Rat(), CurrencyUSD, amountInUSDExponent, RoundHalfUp)
+	if err != nil {
+		return nil, []error{err}
+	}
+	amountInTargetCurrency, err := exchangeRate.Convert(roundedAmountInUSD)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	return &Conversion{
+		AmountInUSD:            roundedAmountInUSD,
+		Date:                   date.UTC(),
+		ExchangeRate:           exchangeRate,
+		AmountInTargetCurrency: amountInTargetCurrency,
+	}, nil
+}
+
+// ValidateConversion validates the amount in USD and the date of a Conversion, with the same rules as a transaction.
+func ValidateConversion(amountInUSD Money, date time.Time) []error {
+	errors := make([]error, 0, 3)
+
+	// Aggregate the validation errors
+	errors = append(errors, ValidateAmountInUSD(amountInUSD)...)
+
+	// Validate the date: cannot be in the future
+	if date.After(time.Now()) {
+		errors = append(errors, ErrInvalidConversionDate)
+	}
+
+	return errors
+}
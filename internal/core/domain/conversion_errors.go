@@ -0,0 +1,10 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to conversion validation in the domain layer.
+
+var (
+	// ErrInvalidConversionDate is returned when the date of a conversion is invalid.
+	ErrInvalidConversionDate = errors.New("conversion date is invalid; it cannot be in the future")
+)
@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// This file contains the ConversionJob and ConversionJobResult structs, their constructors and validation functions.
+
+// Statuses of a conversion job. A job is queued until a worker picks it up, and running until all its transactions
+// are converted. A job that could not go through all its transactions fails.
+const (
+	ConversionJobQueued    = "queued"
+	ConversionJobRunning   = "running"
+	ConversionJobCompleted = "completed"
+	ConversionJobFailed    = "failed"
+)
+
+// ConversionJob represents the conversion of the stored transactions to a target currency, run in the background.
+// The counters report the progress of the job: the total is the number of transactions found when the job started,
+// and every processed transaction is either converted or failed.
+type ConversionJob struct {
+	// ID is the unique identifier for the job.
+	ID uuid.UUID `json:"id"`
+	// CurrencyName is the target currency as requested (ISO 4217 code, country-currency or currency name).
+	CurrencyName string `json:"currency_name"`
+	// From keeps only the transactions that occurred at or after it, when set.
+	From *time.Time `json:"from,omitempty"`
+	// To keeps only the transactions that occurred at or before it, when set.
+	To        *time.Time `json:"to,omitempty"`
+	Status    string     `json:"status"`
+	Total     int        `json:"total"`
+	Processed int        `json:"processed"`
+	Converted int        `json:"converted"`
+	Failed    int        `json:"failed"`
+	// Error is the reason the job failed. Empty unless the job failed.
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ConversionJobResult represents the conversion of a transaction by a conversion job, with the transaction as it was
+// converted. The conversion is frozen as a locked conversion, so the result keeps the exchange rates it used. A
+// transaction that could not be converted keeps the error instead.
+type ConversionJobResult struct {
+	Transaction Transaction       `json:"transaction"`
+	Conversion  *LockedConversion `json:"conversion,omitempty"`
+	// Locked reports whether the conversion is the one locked on the transaction for the target currency.
+	Locked bool   `json:"locked,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// conversionJobResultErrors are the errors of a failed conversion restored by the results, so they can still be told
+// apart once stored. The other errors are only kept as messages.
+var conversionJobResultErrors = []error{ErrNoApplicableExchangeRate, ErrExchangeRatesUnavailable}
+
+// NewConversionJob creates a new ConversionJob instance with input validation. The job is queued.
+func NewConversionJob(currencyName string, from, to *time.Time, createdAt time.Time) (*ConversionJob, []error) {
+	currencyName = strings.TrimSpace(currencyName)
+
+	// Validate the inputs before constructing the object
+	if errs := ValidateConversionJob(currencyName, from, to); len(errs) > 0 {
+		return nil, errs
+	}
+
+	job := &ConversionJob{
+		ID:           uuid.New(),
+		CurrencyName: currencyName,
+		Status:       ConversionJobQueued,
+		CreatedAt:    createdAt.UTC(),
+	}
+	if from != nil {
+		utcFrom := from.UTC()
+		job.From = &utcFrom
+	}
+	if to != nil {
+		utcTo := to.UTC()
+		job.To = &utcTo
+	}
+	return job, nil
+}
+
+// ValidateConversionJob validates the target currency and the date range for the ConversionJob struct.
+func ValidateConversionJob(currencyName string, from, to *time.Time) []error {
+	errors := make([]error, 0, 2)
+
+	// Validate the target currency: cannot be empty
+	if currencyName == "" {
+		errors = append(errors, ErrConversionJobCurrencyEmpty)
+	}
+
+	// Validate the date range: the start cannot be after the end
+	if from != nil && to != nil && from.After(*to) {
+		errors = append(errors, ErrInvalidConversionJobDateRange)
+	}
+
+	return errors
+}
+
+// Query returns the query of the transactions converted by the job.
+func (j *ConversionJob) Query() TransactionQuery {
+	return TransactionQuery{From: j.From, To: j.To}
+}
+
+// Done reports whether the job is completed or failed.
+func (j *ConversionJob) Done() bool {
+	return j.Status == ConversionJobCompleted || j.Status == ConversionJobFailed
+}
+
+// Start marks the job as running, with the number of transactions it converts. The counters of a job started again
+// after an interruption are reset.
+func (j *ConversionJob) Start(startedAt time.Time, total int) {
+	utcStartedAt := startedAt.UTC()
+	j.Status = ConversionJobRunning
+	j.StartedAt = &utcStartedAt
+	j.Total = total
+	j.Processed, j.Converted, j.Failed = 0, 0, 0
+	j.Error = ""
+	j.CompletedAt = nil
+}
+
+// Requeue marks an interrupted job as queued again, so it is started over.
+func (j *ConversionJob) Requeue() {
+	j.Status = ConversionJobQueued
+	j.StartedAt = nil
+	j.Total, j.Processed, j.Converted, j.Failed = 0, 0, 0, 0
+}
+
+// Record counts the result of the conversion of a transaction in the progress of the job. The total grows with the
+// transactions created since the job started, so the processed transactions never outnumber it.
+func (j *ConversionJob) Record(result *ConversionJobResult) {
+	j.Processed++
+	j.Total = max(j.Total, j.Processed)
+	if result.Error != "" {
+		j.Failed++
+		return
+	}
+	j.Converted++
+}
+
+// Complete marks the job as completed. The total is the number of transactions processed, as transactions may have
+// been created or deleted since the job started.
+func (j *ConversionJob) Complete(completedAt time.Time) {
+	utcCompletedAt := completedAt.UTC()
+	j.Status = ConversionJobCompleted
+	j.Total = j.Processed
+	j.CompletedAt = &utcCompletedAt
+}
+
+// Fail marks the job as failed with the error that stopped it.
+func (j *ConversionJob) Fail(completedAt time.Time, err error) {
+	utcCompletedAt := completedAt.UTC()
+	j.Status = ConversionJobFailed
+	j.Error = err.Error()
+	j.CompletedAt = &utcCompletedAt
+}
+
+// Progress returns the share of the transactions of the job already processed, between 0 and 1.
+func (j *ConversionJob) Progress() float64 {
+	switch {
+	case j.Status == ConversionJobCompleted:
+		return 1
+	case j.Total == 0:
+		return 0
+	case j.Processed >= j.Total:
+		return 1
+	}
+	return float64(j.Processed) / float64(j.Total)
+}
+
+// NewConversionJobResult creates the result of the conversion of a transaction by a job. A successful conversion is
+// frozen with the policy that selected its exchange rates, unless a conversion is locked on the transaction for the
+// target currency, which is kept as is. The locked conversions of the transaction are not kept with it.
+func NewConversionJobResult(transaction *Transaction, conversion *TransactionConversion,
+	rateSelectionPolicy RateSelectionPolicy, convertedAt time.Time) *ConversionJobResult {
+	result := &ConversionJobResult{Transaction: *transaction}
+	result.Transaction.LockedConversions = nil
+
+	if conversion.Locked != nil {
+		result.Conversion = conversion.Locked
+		result.Locked = true
+		return result
+	}
+	frozenConversion, err := NewLockedConversion(conversion, rateSelectionPolicy, convertedAt)
+	if err != nil {
+		result.Error = err.Error()
+		// The known errors are stored without the details wrapped around them, so they can be restored
+		for _, knownErr := range conversionJobResultErrors {
+			if errors.Is(err, knownErr) {
+				result.Error = knownErr.Error()
+			}
+		}
+		return result
+	}
+	result.Conversion = frozenConversion
+	return result
+}
+
+// Err returns the error of a failed conversion, nil when the conversion succeeded.
+func (r *ConversionJobResult) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	for _, knownErr := range conversionJobResultErrors {
+		if r.Error == knownErr.Error() {
+			return knownErr
+		}
+	}
+	return errors.New(r.Error)
+}
+
+// TransactionConversion returns the conversion of the result as a TransactionConversion to the requested currency
+// name of the job, with the exchange rates it used. A failed conversion keeps its error.
+func (r *ConversionJobResult) TransactionConversion(currencyName string) (*TransactionConversion, error) {
+	if r.Conversion == nil {
+		return &TransactionConversion{CurrencyName: currencyName, Err: r.Err()}, nil
+	}
+
+	conversion, err := r.Conversion.TransactionConversion(currencyName)
+	if err != nil {
+		return nil, err
+	}
+	if r.Locked {
+		conversion.Locked = r.Conversion
+	}
+	return conversion, nil
+}
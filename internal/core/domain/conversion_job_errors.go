@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to conversion jobs in the domain layer.
+
+var (
+	// ErrConversionJobCurrencyEmpty is returned when the target currency of a conversion job is empty.
+	ErrConversionJobCurrencyEmpty = errors.New("conversion job currency is required; it cannot be empty")
+
+	// ErrInvalidConversionJobDateRange is returned when the start date of a conversion job is after its end date.
+	ErrInvalidConversionJobDateRange = errors.New("conversion job date range is invalid; from cannot be after to")
+
+	// ErrConversionJobNotCompleted is returned when the result of a conversion job is requested before it completed.
+	ErrConversionJobNotCompleted = errors.New("the conversion job has not completed")
+)
@@ -0,0 +1,287 @@
+package domain_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the ConversionJob and ConversionJobResult domain models. It uses Table Driven Tests to
+// test different scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestNewConversionJob tests the NewConversionJob constructor function. It tests the following scenarios:
+//
+// 1. Valid Conversion Job.
+// 2. Without Date Range.
+// 3. Empty Currency.
+// 4. Invalid Date Range.
+func TestNewConversionJob(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		currencyName   string
+		from           *time.Time
+		to             *time.Time
+		expectedErrors []error
+	}{
+		{
+			name:         "Valid Conversion Job",
+			currencyName: " MXN ",
+			from:         &from,
+			to:           &to,
+		},
+		{
+			name:         "Without Date Range",
+			currencyName: "Mexico-Peso",
+		},
+		{
+			name:           "Empty Currency",
+			currencyName:   "  ",
+			expectedErrors: []error{domain.ErrConversionJobCurrencyEmpty},
+		},
+		{
+			name:           "Invalid Date Range",
+			currencyName:   "MXN",
+			from:           &to,
+			to:             &from,
+			expectedErrors: []error{domain.ErrInvalidConversionJobDateRange},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			job, errs := domain.NewConversionJob(tt.currencyName, tt.from, tt.to, createdAt)
+
+			if len(tt.expectedErrors) > 0 {
+				assert.Nil(t, job)
+				assert.Equal(t, tt.expectedErrors, errs)
+				return
+			}
+			require.Empty(t, errs)
+			assert.NotZero(t, job.ID)
+			assert.Equal(t, domain.ConversionJobQueued, job.Status)
+			assert.Equal(t, createdAt.UTC(), job.CreatedAt)
+			assert.Equal(t, domain.TransactionQuery{From: tt.from, To: tt.to}, job.Query())
+			assert.False(t, job.Done())
+		})
+	}
+}
+
+// TestConversionJobProgress tests the progress of a conversion job through its statuses. It tests the following
+// scenarios:
+//
+// 1. Queued Job.
+// 2. Running Job Halfway.
+// 3. More Transactions Than The Total.
+// 4. Completed Job.
+// 5. Failed Job.
+// 6. Requeued Job.
+func TestConversionJobProgress(t *testing.T) {
+	startedAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	converted := &domain.ConversionJobResult{}
+	failed := &domain.ConversionJobResult{Error: "failed"}
+
+	tests := []struct {
+		name              string
+		update            func(job *domain.ConversionJob)
+		expectedStatus    string
+		expectedTotal     int
+		expectedConverted int
+		expectedFailed    int
+		expectedProgress  float64
+	}{
+		{
+			name:           "Queued Job",
+			update:         func(*domain.ConversionJob) {},
+			expectedStatus: domain.ConversionJobQueued,
+		},
+		{
+			name: "Running Job Halfway",
+			update: func(job *domain.ConversionJob) {
+				job.Start(startedAt, 4)
+				job.Record(converted)
+				job.Record(failed)
+			},
+			expectedStatus:    domain.ConversionJobRunning,
+			expectedTotal:     4,
+			expectedConverted: 1,
+			expectedFailed:    1,
+			expectedProgress:  0.5,
+		},
+		{
+			name: "More Transactions Than The Total",
+			update: func(job *domain.ConversionJob) {
+				job.Start(startedAt, 1)
+				job.Record(converted)
+				job.Record(converted)
+			},
+			expectedStatus:    domain.ConversionJobRunning,
+			expectedTotal:     2,
+			expectedConverted: 2,
+			expectedProgress:  1,
+		},
+		{
+			name: "Completed Job",
+			update: func(job *domain.ConversionJob) {
+				job.Start(startedAt, 3)
+				job.Record(converted)
+				job.Record(converted)
+				job.Complete(startedAt.Add(time.Minute))
+			},
+			expectedStatus:    domain.ConversionJobCompleted,
+			expectedTotal:     2,
+			expectedConverted: 2,
+			expectedProgress:  1,
+		},
+		{
+			name: "Failed Job",
+			update: func(job *domain.ConversionJob) {
+				job.Start(startedAt, 4)
+				job.Record(failed)
+				job.Fail(startedAt.Add(time.Minute), domain.ErrExchangeRatesUnavailable)
+			},
+			expectedStatus:   domain.ConversionJobFailed,
+			expectedTotal:    4,
+			expectedFailed:   1,
+			expectedProgress: 0.25,
+		},
+		{
+			name: "Requeued Job",
+			update: func(job *domain.ConversionJob) {
+				job.Start(startedAt, 4)
+				job.Record(converted)
+				job.Requeue()
+			},
+			expectedStatus: domain.ConversionJobQueued,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			job, errs := domain.NewConversionJob("MXN", nil, nil, startedAt)
+			require.Empty(t, errs)
+
+			tt.update(job)
+
+			assert.Equal(t, tt.expectedStatus, job.Status)
+			assert.Equal(t, tt.expectedTotal, job.Total)
+			assert.Equal(t, tt.expectedConverted, job.Converted)
+			assert.Equal(t, tt.expectedFailed, job.Failed)
+			assert.Equal(t, tt.expectedConverted+tt.expectedFailed, job.Processed)
+			assert.InDelta(t, tt.expectedProgress, job.Progress(), 0.0001)
+			assert.Equal(t, tt.expectedStatus == domain.ConversionJobCompleted ||
+				tt.expectedStatus == domain.ConversionJobFailed, job.Done())
+		})
+	}
+}
+
+// TestNewConversionJobResult tests the NewConversionJobResult constructor function and the restoration of the
+// conversion of a stored result. It tests the following scenarios:
+//
+// 1. Converted Transaction.
+// 2. Locked Conversion Kept.
+// 3. Known Conversion Error Restored.
+// 4. Other Conversion Error Kept As A Message.
+func TestNewConversionJobResult(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	convertedAt := time.Date(2024, 10, 3, 9, 0, 0, 0, time.UTC)
+	transaction, errs := domain.NewTransactionInCurrency("Purchase", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.79", "EUR"))
+	require.Empty(t, errs)
+
+	tests := []struct {
+		name string
+		// conversion returns the conversion of the transaction to MXN
+		conversion       func(t *testing.T) *domain.TransactionConversion
+		expectedLocked   bool
+		expectedLockedAt time.Time
+		expectedError    error
+	}{
+		{
+			name: "Converted Transaction",
+			conversion: func(t *testing.T) *domain.TransactionConversion {
+				conversion, _ := newLockedConversionFixture(t)
+				return conversion
+			},
+			expectedLockedAt: convertedAt,
+		},
+		{
+			name: "Locked Conversion Kept",
+			conversion: func(t *testing.T) *domain.TransactionConversion {
+				conversion, lockedConversion := newLockedConversionFixture(t)
+				conversion.Locked = lockedConversion
+				return conversion
+			},
+			expectedLocked:   true,
+			expectedLockedAt: time.Date(2024, 10, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Known Conversion Error Restored",
+			conversion: func(*testing.T) *domain.TransactionConversion {
+				return &domain.TransactionConversion{CurrencyName: "MXN",
+					Err: fmt.Errorf("Mexico-Peso: %w", domain.ErrNoApplicableExchangeRate)}
+			},
+			expectedError: domain.ErrNoApplicableExchangeRate,
+		},
+		{
+			name: "Other Conversion Error Kept As A Message",
+			conversion: func(*testing.T) *domain.TransactionConversion {
+				return &domain.TransactionConversion{CurrencyName: "MXN", Err: domain.ErrSourceExchangeRateMissing}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			conversion := tt.conversion(t)
+			withLockedConversions := *transaction
+			withLockedConversions.LockedConversions = []domain.LockedConversion{{Currency: "CAD"}}
+
+			result := domain.NewConversionJobResult(&withLockedConversions, conversion, domain.DefaultRateSelectionPolicy(),
+				convertedAt)
+
+			// The result is restored as stored
+			resultJSONData, err := json.Marshal(result)
+			require.NoError(t, err)
+			var storedResult domain.ConversionJobResult
+			require.NoError(t, json.Unmarshal(resultJSONData, &storedResult))
+			assert.Equal(t, transaction.ID, storedResult.Transaction.ID)
+			assert.Equal(t, "25.79", storedResult.Transaction.RecordedAmount().String())
+			assert.Equal(t, "EUR", storedResult.Transaction.Currency())
+			assert.Empty(t, storedResult.Transaction.LockedConversions)
+			assert.Equal(t, tt.expectedLocked, storedResult.Locked)
+
+			restoredConversion, err := storedResult.TransactionConversion("MXN")
+			require.NoError(t, err)
+			assert.Equal(t, "MXN", restoredConversion.CurrencyName)
+			if conversion.Err != nil {
+				require.Error(t, restoredConversion.Err)
+				assert.Nil(t, storedResult.Conversion)
+				if tt.expectedError != nil {
+					assert.ErrorIs(t, restoredConversion.Err, tt.expectedError)
+				} else {
+					assert.Equal(t, conversion.Err.Error(), restoredConversion.Err.Error())
+				}
+				return
+			}
+			require.NoError(t, restoredConversion.Err)
+			assert.Equal(t, "563.53", restoredConversion.AmountInTargetCurrency.String())
+			assert.Equal(t, "MXN", restoredConversion.AmountInTargetCurrency.Currency())
+			assert.Len(t, restoredConversion.Legs, 2)
+			assert.Equal(t, tt.expectedLockedAt, storedResult.Conversion.LockedAt)
+			assert.Equal(t, tt.expectedLocked, restoredConversion.Locked != nil)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the Conversion domain model. It uses Table Driven Tests to test different scenarios.
+// It uses Testify for assertions and runs the tests in parallel.
+
+// TestNewConversion tests the NewConversion constructor function. It tests the following scenarios:
+//
+// 1. Valid Conversion.
+// 2. Amount Rounded Like A Transaction.
+// 3. Zero Amount.
+// 4. Amount Not In USD.
+// 5. Future Date.
+func TestNewConversion(t *testing.T) {
+	exchangeRate, errs := domain.NewExchangeRate("Yen", "143.57", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(t, errs)
+	exchangeRate.CurrencyCode = "JPY"
+	date := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                   string
+		amountInUSD            domain.Money
+		date                   time.Time
+		expectedAmountInUSD    string
+		expectedAmountInTarget string
+		expectedErrors         []error
+	}{
+		{
+			name:                   "Valid Conversion",
+			amountInUSD:            domain.MustParseMoney("28.75", domain.CurrencyUSD),
+			date:                   date,
+			expectedAmountInUSD:    "28.75",
+			expectedAmountInTarget: "4128",
+		},
+		{
+			name:                   "Amount Rounded Like A Transaction",
+			amountInUSD:            domain.MustParseMoney("28.745", domain.CurrencyUSD),
+			date:                   date,
+			expectedAmountInUSD:    "28.75",
+			expectedAmountInTarget: "4128",
+		},
+		{
+			name:           "Zero Amount",
+			amountInUSD:    domain.MustParseMoney("0.004", domain.CurrencyUSD),
+			date:           date,
+			expectedErrors: []error{domain.ErrInvalidAmountInUSD},
+		},
+		{
+			name:           "Amount Not In USD",
+			amountInUSD:    domain.MustParseMoney("10", "EUR"),
+			date:           date,
+			expectedErrors: []error{domain.ErrAmountNotInUSD},
+		},
+		{
+			name:           "Future Date",
+			amountInUSD:    domain.MustParseMoney("10", domain.CurrencyUSD),
+			date:           time.Now().Add(24 * time.Hour),
+			expectedErrors: []error{domain.ErrInvalidConversionDate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			conversion, errs := domain.NewConversion(tt.amountInUSD, tt.date, exchangeRate)
+
+			if len(tt.expectedErrors) > 0 {
+				assert.Equal(t, tt.expectedErrors, errs)
+				assert.Nil(t, conversion)
+				return
+			}
+			require.Empty(t, errs)
+			assert.Equal(t, tt.expectedAmountInUSD, conversion.AmountInUSD.String())
+			assert.Equal(t, tt.expectedAmountInTarget, conversion.AmountInTargetCurrency.String())
+			assert.Equal(t, "JPY", conversion.AmountInTargetCurrency.Currency())
+			assert.Equal(t, exchangeRate, conversion.ExchangeRate)
+		})
+	}
+}
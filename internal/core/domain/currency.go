@@ -0,0 +1,183 @@
+package domain
+
+import "strings"
+
+// This file contains the Currency struct and the ISO 4217 table of currencies with their minor units.
+
+// defaultCurrencyExponent is the number of minor units used for currencies missing from the ISO 4217 table.
+const defaultCurrencyExponent = 2
+
+// Currency represents an ISO 4217 currency.
+type Currency struct {
+	// Code is the ISO 4217 alphabetic code (e.g. "EUR").
+	Code string
+	// Exponent is the number of minor units of the currency (e.g. 2 for EUR, 0 for JPY and 3 for KWD).
+	Exponent int
+	// Name is the English name of the currency.
+	Name string
+}
+
+// iso4217Currencies holds the ISO 4217 currencies that can be converted to, indexed by their code.
+var iso4217Currencies = map[string]Currency{
+	"AED": {Code: "AED", Exponent: 2, Name: "UAE Dirham"},
+	"AFN": {Code: "AFN", Exponent: 2, Name: "Afghani"},
+	"ALL": {Code: "ALL", Exponent: 2, Name: "Lek"},
+	"AMD": {Code: "AMD", Exponent: 2, Name: "Armenian Dram"},
+	"ANG": {Code: "ANG", Exponent: 2, Name: "Netherlands Antillean Guilder"},
+	"AOA": {Code: "AOA", Exponent: 2, Name: "Kwanza"},
+	"ARS": {Code: "ARS", Exponent: 2, Name: "Argentine Peso"},
+	"AUD": {Code: "AUD", Exponent: 2, Name: "Australian Dollar"},
+	"AZN": {Code: "AZN", Exponent: 2, Name: "Azerbaijan Manat"},
+	"BAM": {Code: "BAM", Exponent: 2, Name: "Convertible Mark"},
+	"BBD": {Code: "BBD", Exponent: 2, Name: "Barbados Dollar"},
+	"BDT": {Code: "BDT", Exponent: 2, Name: "Taka"},
+	"BGN": {Code: "BGN", Exponent: 2, Name: "Bulgarian Lev"},
+	"BHD": {Code: "BHD", Exponent: 3, Name: "Bahraini Dinar"},
+	"BIF": {Code: "BIF", Exponent: 0, Name: "Burundi Franc"},
+	"BMD": {Code: "BMD", Exponent: 2, Name: "Bermudian Dollar"},
+	"BND": {Code: "BND", Exponent: 2, Name: "Brunei Dollar"},
+	"BOB": {Code: "BOB", Exponent: 2, Name: "Boliviano"},
+	"BRL": {Code: "BRL", Exponent: 2, Name: "Brazilian Real"},
+	"BSD": {Code: "BSD", Exponent: 2, Name: "Bahamian Dollar"},
+	"BWP": {Code: "BWP", Exponent: 2, Name: "Pula"},
+	"BYN": {Code: "BYN", Exponent: 2, Name: "Belarusian Ruble"},
+	"BZD": {Code: "BZD", Exponent: 2, Name: "Belize Dollar"},
+	"CAD": {Code: "CAD", Exponent: 2, Name: "Canadian Dollar"},
+	"CDF": {Code: "CDF", Exponent: 2, Name: "Congolese Franc"},
+	"CHF": {Code: "CHF", Exponent: 2, Name: "Swiss Franc"},
+	"CLP": {Code: "CLP", Exponent: 0, Name: "Chilean Peso"},
+	"CNY": {Code: "CNY", Exponent: 2, Name: "Yuan Renminbi"},
+	"COP": {Code: "COP", Exponent: 2, Name: "Colombian Peso"},
+	"CRC": {Code: "CRC", Exponent: 2, Name: "Costa Rican Colon"},
+	"CUP": {Code: "CUP", Exponent: 2, Name: "Cuban Peso"},
+	"CVE": {Code: "CVE", Exponent: 2, Name: "Cabo Verde Escudo"},
+	"CZK": {Code: "CZK", Exponent: 2, Name: "Czech Koruna"},
+	"DJF": {Code: "DJF", Exponent: 0, Name: "Djibouti Franc"},
+	"DKK": {Code: "DKK", Exponent: 2, Name: "Danish Krone"},
+	"DOP": {Code: "DOP", Exponent: 2, Name: "Dominican Peso"},
+	"DZD": {Code: "DZD", Exponent: 2, Name: "Algerian Dinar"},
+	"EGP": {Code: "EGP", Exponent: 2, Name: "Egyptian Pound"},
+	"ERN": {Code: "ERN", Exponent: 2, Name: "Nakfa"},
+	"ETB": {Code: "ETB", Exponent: 2, Name: "Ethiopian Birr"},
+	"EUR": {Code: "EUR", Exponent: 2, Name: "Euro"},
+	"FJD": {Code: "FJD", Exponent: 2, Name: "Fiji Dollar"},
+	"GBP": {Code: "GBP", Exponent: 2, Name: "Pound Sterling"},
+	"GEL": {Code: "GEL", Exponent: 2, Name: "Lari"},
+	"GHS": {Code: "GHS", Exponent: 2, Name: "Ghana Cedi"},
+	"GMD": {Code: "GMD", Exponent: 2, Name: "Dalasi"},
+	"GNF": {Code: "GNF", Exponent: 0, Name: "Guinean Franc"},
+	"GTQ": {Code: "GTQ", Exponent: 2, Name: "Quetzal"},
+	"GYD": {Code: "GYD", Exponent: 2, Name: "Guyana Dollar"},
+	"HKD": {Code: "HKD", Exponent: 2, Name: "Hong Kong Dollar"},
+	"HNL": {Code: "HNL", Exponent: 2, Name: "Lempira"},
+	"HTG": {Code: "HTG", Exponent: 2, Name: "Gourde"},
+	"HUF": {Code: "HUF", Exponent: 2, Name: "Forint"},
+	"IDR": {Code: "IDR", Exponent: 2, Name: "Rupiah"},
+	"ILS": {Code: "ILS", Exponent: 2, Name: "New Israeli Sheqel"},
+	"INR": {Code: "INR", Exponent: 2, Name: "Indian Rupee"},
+	"IQD": {Code: "IQD", Exponent: 3, Name: "Iraqi Dinar"},
+	"IRR": {Code: "IRR", Exponent: 2, Name: "Iranian Rial"},
+	"ISK": {Code: "ISK", Exponent: 0, Name: "Iceland Krona"},
+	"JMD": {Code: "JMD", Exponent: 2, Name: "Jamaican Dollar"},
+	"JOD": {Code: "JOD", Exponent: 3, Name: "Jordanian Dinar"},
+	"JPY": {Code: "JPY", Exponent: 0, Name: "Yen"},
+	"KES": {Code: "KES", Exponent: 2, Name: "Kenyan Shilling"},
+	"KGS": {Code: "KGS", Exponent: 2, Name: "Som"},
+	"KHR": {Code: "KHR", Exponent: 2, Name: "Riel"},
+	"KMF": {Code: "KMF", Exponent: 0, Name: "Comorian Franc"},
+	"KRW": {Code: "KRW", Exponent: 0, Name: "Won"},
+	"KWD": {Code: "KWD", Exponent: 3, Name: "Kuwaiti Dinar"},
+	"KYD": {Code: "KYD", Exponent: 2, Name: "Cayman Islands Dollar"},
+	"KZT": {Code: "KZT", Exponent: 2, Name: "Tenge"},
+	"LAK": {Code: "LAK", Exponent: 2, Name: "Lao Kip"},
+	"LBP": {Code: "LBP", Exponent: 2, Name: "Lebanese Pound"},
+	"LKR": {Code: "LKR", Exponent: 2, Name: "Sri Lanka Rupee"},
+	"LRD": {Code: "LRD", Exponent: 2, Name: "Liberian Dollar"},
+	"LSL": {Code: "LSL", Exponent: 2, Name: "Loti"},
+	"LYD": {Code: "LYD", Exponent: 3, Name: "Libyan Dinar"},
+	"MAD": {Code: "MAD", Exponent: 2, Name: "Moroccan Dirham"},
+	"MDL": {Code: "MDL", Exponent: 2, Name: "Moldovan Leu"},
+	"MGA": {Code: "MGA", Exponent: 2, Name: "Malagasy Ariary"},
+	"MKD": {Code: "MKD", Exponent: 2, Name: "Denar"},
+	"MMK": {Code: "MMK", Exponent: 2, Name: "Kyat"},
+	"MNT": {Code: "MNT", Exponent: 2, Name: "Tugrik"},
+	"MOP": {Code: "MOP", Exponent: 2, Name: "Pataca"},
+	"MRU": {Code: "MRU", Exponent: 2, Name: "Ouguiya"},
+	"MUR": {Code: "MUR", Exponent: 2, Name: "Mauritius Rupee"},
+	"MVR": {Code: "MVR", Exponent: 2, Name: "Rufiyaa"},
+	"MWK": {Code: "MWK", Exponent: 2, Name: "Malawi Kwacha"},
+	"MXN": {Code: "MXN", Exponent: 2, Name: "Mexican Peso"},
+	"MYR": {Code: "MYR", Exponent: 2, Name: "Malaysian Ringgit"},
+	"MZN": {Code: "MZN", Exponent: 2, Name: "Mozambique Metical"},
+	"NAD": {Code: "NAD", Exponent: 2, Name: "Namibia Dollar"},
+	"NGN": {Code: "NGN", Exponent: 2, Name: "Naira"},
+	"NIO": {Code: "NIO", Exponent: 2, Name: "Cordoba Oro"},
+	"NOK": {Code: "NOK", Exponent: 2, Name: "Norwegian Krone"},
+	"NPR": {Code: "NPR", Exponent: 2, Name: "Nepalese Rupee"},
+	"NZD": {Code: "NZD", Exponent: 2, Name: "New Zealand Dollar"},
+	"OMR": {Code: "OMR", Exponent: 3, Name: "Rial Omani"},
+	"PEN": {Code: "PEN", Exponent: 2, Name: "Sol"},
+	"PGK": {Code: "PGK", Exponent: 2, Name: "Kina"},
+	"PHP": {Code: "PHP", Exponent: 2, Name: "Philippine Peso"},
+	"PKR": {Code: "PKR", Exponent: 2, Name: "Pakistan Rupee"},
+	"PLN": {Code: "PLN", Exponent: 2, Name: "Zloty"},
+	"PYG": {Code: "PYG", Exponent: 0, Name: "Guarani"},
+	"QAR": {Code: "QAR", Exponent: 2, Name: "Qatari Rial"},
+	"RON": {Code: "RON", Exponent: 2, Name: "Romanian Leu"},
+	"RSD": {Code: "RSD", Exponent: 2, Name: "Serbian Dinar"},
+	"RUB": {Code: "RUB", Exponent: 2, Name: "Russian Ruble"},
+	"RWF": {Code: "RWF", Exponent: 0, Name: "Rwanda Franc"},
+	"SAR": {Code: "SAR", Exponent: 2, Name: "Saudi Riyal"},
+	"SBD": {Code: "SBD", Exponent: 2, Name: "Solomon Islands Dollar"},
+	"SCR": {Code: "SCR", Exponent: 2, Name: "Seychelles Rupee"},
+	"SDG": {Code: "SDG", Exponent: 2, Name: "Sudanese Pound"},
+	"SEK": {Code: "SEK", Exponent: 2, Name: "Swedish Krona"},
+	"SGD": {Code: "SGD", Exponent: 2, Name: "Singapore Dollar"},
+	"SLE": {Code: "SLE", Exponent: 2, Name: "Leone"},
+	"SOS": {Code: "SOS", Exponent: 2, Name: "Somali Shilling"},
+	"SRD": {Code: "SRD", Exponent: 2, Name: "Surinam Dollar"},
+	"SSP": {Code: "SSP", Exponent: 2, Name: "South Sudanese Pound"},
+	"STN": {Code: "STN", Exponent: 2, Name: "Dobra"},
+	"SYP": {Code: "SYP", Exponent: 2, Name: "Syrian Pound"},
+	"SZL": {Code: "SZL", Exponent: 2, Name: "Lilangeni"},
+	"THB": {Code: "THB", Exponent: 2, Name: "Baht"},
+	"TJS": {Code: "TJS", Exponent: 2, Name: "Somoni"},
+	"TMT": {Code: "TMT", Exponent: 2, Name: "Turkmenistan New Manat"},
+	"TND": {Code: "TND", Exponent: 3, Name: "Tunisian Dinar"},
+	"TOP": {Code: "TOP", Exponent: 2, Name: "Pa'anga"},
+	"TRY": {Code: "TRY", Exponent: 2, Name: "Turkish Lira"},
+	"TTD": {Code: "TTD", Exponent: 2, Name: "Trinidad and Tobago Dollar"},
+	"TWD": {Code: "TWD", Exponent: 2, Name: "New Taiwan Dollar"},
+	"TZS": {Code: "TZS", Exponent: 2, Name: "Tanzanian Shilling"},
+	"UAH": {Code: "UAH", Exponent: 2, Name: "Hryvnia"},
+	"UGX": {Code: "UGX", Exponent: 0, Name: "Uganda Shilling"},
+	"USD": {Code: "USD", Exponent: 2, Name: "US Dollar"},
+	"UYU": {Code: "UYU", Exponent: 2, Name: "Peso Uruguayo"},
+	"UZS": {Code: "UZS", Exponent: 2, Name: "Uzbekistan Sum"},
+	"VES": {Code: "VES", Exponent: 2, Name: "Bolivar Soberano"},
+	"VND": {Code: "VND", Exponent: 0, Name: "Dong"},
+	"VUV": {Code: "VUV", Exponent: 0, Name: "Vatu"},
+	"WST": {Code: "WST", Exponent: 2, Name: "Tala"},
+	"XAF": {Code: "XAF", Exponent: 0, Name: "CFA Franc BEAC"},
+	"XCD": {Code: "XCD", Exponent: 2, Name: "East Caribbean Dollar"},
+	"XOF": {Code: "XOF", Exponent: 0, Name: "CFA Franc BCEAO"},
+	"YER": {Code: "YER", Exponent: 2, Name: "Yemeni Rial"},
+	"ZAR": {Code: "ZAR", Exponent: 2, Name: "Rand"},
+	"ZMW": {Code: "ZMW", Exponent: 2, Name: "Zambian Kwacha"},
+	"ZWL": {Code: "ZWL", Exponent: 2, Name: "Zimbabwe Dollar"},
+}
+
+// LookupCurrency returns the ISO 4217 currency with the given code, ignoring case and surrounding spaces.
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := iso4217Currencies[strings.ToUpper(strings.TrimSpace(code))]
+	return currency, ok
+}
+
+// CurrencyExponent returns the number of minor units of the currency with the given code. Unknown currencies
+// fall back to two minor units.
+func CurrencyExponent(code string) int {
+	if currency, ok := LookupCurrency(code); ok {
+		return currency.Exponent
+	}
+	return defaultCurrencyExponent
+}
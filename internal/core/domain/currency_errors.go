@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to currency resolution in the domain layer.
+
+var (
+	// ErrUnknownCurrencyCode is returned when a currency code does not match any currency with exchange rates.
+	ErrUnknownCurrencyCode = errors.New("currency code is unknown; it must be the ISO 4217 code of a currency with published exchange rates")
+
+	// ErrAmbiguousCurrencyCode is returned when a currency code is used by several countries and cannot be resolved to
+	// a single exchange rate.
+	ErrAmbiguousCurrencyCode = errors.New("currency code is ambiguous; it is used by several countries, use the country-currency name instead")
+)
@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// This file contains tests for the Currency domain model. It uses Table Driven Tests to test different scenarios.
+// It uses Testify for assertions and runs the tests in parallel.
+
+// TestLookupCurrency tests the LookupCurrency and CurrencyExponent functions. It tests the following scenarios:
+//
+// 1. Currency With Two Minor Units.
+// 2. Currency Without Minor Units.
+// 3. Currency With Three Minor Units.
+// 4. Lowercase Code With Spaces.
+// 5. Unknown Code Falls Back To Two Minor Units.
+func TestLookupCurrency(t *testing.T) {
+	tests := []struct {
+		name             string
+		code             string
+		expectedFound    bool
+		expectedCode     string
+		expectedExponent int
+	}{
+		{
+			name:             "Currency With Two Minor Units",
+			code:             "EUR",
+			expectedFound:    true,
+			expectedCode:     "EUR",
+			expectedExponent: 2,
+		},
+		{
+			name:             "Currency Without Minor Units",
+			code:             "JPY",
+			expectedFound:    true,
+			expectedCode:     "JPY",
+			expectedExponent: 0,
+		},
+		{
+			name:             "Currency With Three Minor Units",
+			code:             "BHD",
+			expectedFound:    true,
+			expectedCode:     "BHD",
+			expectedExponent: 3,
+		},
+		{
+			name:             "Lowercase Code With Spaces",
+			code:             " krw ",
+			expectedFound:    true,
+			expectedCode:     "KRW",
+			expectedExponent: 0,
+		},
+		{
+			name:             "Unknown Code Falls Back To Two Minor Units",
+			code:             "XYZ",
+			expectedFound:    false,
+			expectedExponent: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			currency, found := domain.LookupCurrency(tt.code)
+
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedCode, currency.Code)
+			assert.Equal(t, tt.expectedExponent, domain.CurrencyExponent(tt.code))
+			if found {
+				assert.Equal(t, tt.expectedExponent, currency.Exponent)
+				assert.NotEmpty(t, currency.Name)
+			}
+		})
+	}
+}
@@ -8,31 +8,57 @@ import (
 
 // This file contains the ExchangeRate struct, its constructor and validation functions.
 
+// Sources of the exchange rates.
+const (
+	// ExchangeRateSourceTreasury identifies the exchange rates published by the Treasury Reporting Rates of Exchange
+	// API.
+	ExchangeRateSourceTreasury = "treasury"
+	// ExchangeRateSourceECB identifies the exchange rates derived from the euro foreign exchange reference rates of
+	// the European Central Bank.
+	ExchangeRateSourceECB = "ecb"
+	// ExchangeRateSourceFile identifies the exchange rates read from a local CSV or JSON file.
+	ExchangeRateSourceFile = "file"
+)
+
 // ExchangeRate represents an exchange rate.
 type ExchangeRate struct {
 	CurrencyName string
-	Rate         *big.Float
+	// CountryCurrencyDesc is the country-currency name of the currency (e.g. "Japan-Yen"). Empty when not published.
+	CountryCurrencyDesc string
+	// CurrencyCode is the ISO 4217 code of the currency (e.g. "JPY"). Empty when the currency is not known.
+	CurrencyCode string
+	// Rate is the exact value of the exchange rate, as many units of the currency as one USD buys.
+	Rate *big.Rat
+	// RateText is the exchange rate exactly as published by the source (e.g. "0.857").
+	RateText     string
 	DateOfRecord time.Time
+	// Source identifies the provider the exchange rate came from (e.g. "treasury"). Empty when not known.
+	Source string
 }
 
-// NewExchangeRate creates a new ExchangeRate instance with input validation.
-func NewExchangeRate(currencyName string, rate float64, dateOfRecord time.Time) (*ExchangeRate, []error) {
+// NewExchangeRate creates a new ExchangeRate instance with input validation. The rate is a decimal string kept at
+// full precision.
+func NewExchangeRate(currencyName string, rate string, dateOfRecord time.Time) (*ExchangeRate, []error) {
 	currencyName = strings.TrimSpace(currencyName)
+	rate = strings.TrimSpace(rate)
 
 	// Validate the inputs before constructing the object
 	if errs := ValidateExchangeRate(currencyName, rate, dateOfRecord); len(errs) > 0 {
 		return nil, errs
 	}
 
+	rateRat, _ := ParseDecimal(rate)
+
 	return &ExchangeRate{
 		CurrencyName: currencyName,
-		Rate:         new(big.Float).SetPrec(64).SetFloat64(rate),
+		Rate:         rateRat,
+		RateText:     rate,
 		DateOfRecord: dateOfRecord,
 	}, nil
 }
 
 // ValidateExchangeRate validates the currency name, rate and date of record for the ExchangeRate struct.
-func ValidateExchangeRate(currencyName string, rate float64, dateOfRecord time.Time) []error {
+func ValidateExchangeRate(currencyName string, rate string, dateOfRecord time.Time) []error {
 	errors := make([]error, 0, 3)
 
 	// Validate the currency name length: must not be empty
@@ -40,8 +66,8 @@ func ValidateExchangeRate(currencyName string, rate float64, dateOfRecord time.T
 		errors = append(errors, ErrCurrencyNameEmpty)
 	}
 
-	// Validate the rate: must be positive
-	if rate <= 0 {
+	// Validate the rate: must be a positive decimal number
+	if rateRat, err := ParseDecimal(rate); err != nil || rateRat.Sign() <= 0 {
 		errors = append(errors, ErrInvalidExchangeRate)
 	}
 
@@ -52,3 +78,31 @@ func ValidateExchangeRate(currencyName string, rate float64, dateOfRecord time.T
 
 	return errors
 }
+
+// Convert converts an amount with the exchange rate. The multiplication is done at the full precision of the
+// rate and only the converted amount is rounded half up to the minor units of the target currency (e.g. no
+// decimal places for JPY, three for KWD and two when the currency is not known).
+func (e *ExchangeRate) Convert(amount Money) (Money, error) {
+	converted := new(big.Rat).Mul(amount.Rat(), e.Rate)
+	return NewMoneyFromRat(converted, e.CurrencyCode, CurrencyExponent(e.CurrencyCode), RoundHalfUp)
+}
+
+// ConvertToUSD converts an amount in the currency of the exchange rate back to USD. The division is done at the full
+// precision of the rate and only the converted amount is rounded half up to two decimal places.
+func (e *ExchangeRate) ConvertToUSD(amount Money) (Money, error) {
+	converted := new(big.Rat).Quo(amount.Rat(), e.Rate)
+	return NewMoneyFromRat(converted, CurrencyUSD, amountInUSDExponent, RoundHalfUp)
+}
+
+// currency returns the ISO 4217 code of the currency of the exchange rate, or its Treasury name when the code is
+// not known.
+func (e *ExchangeRate) currency() string {
+	switch {
+	case e.CurrencyCode != "":
+		return e.CurrencyCode
+	case e.CountryCurrencyDesc != "":
+		return e.CountryCurrencyDesc
+	default:
+		return e.CurrencyName
+	}
+}
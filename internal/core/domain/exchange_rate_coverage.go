@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// This file contains the ExchangeRateCoverage struct, which tracks the periods fully known by the local exchange rate
+// store.
+
+// ExchangeRateCoverage represents the periods over which every exchange rate published for a currency is stored, so
+// a lookup within them does not need to download the exchange rates again. The periods are sorted, do not overlap
+// and are not adjacent.
+type ExchangeRateCoverage struct {
+	Periods []ExchangeRatePeriod `json:"periods"`
+}
+
+// ExchangeRatePeriod represents a period of days, from and to included, in UTC.
+type ExchangeRatePeriod struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Add merges the days from and to, included, into the coverage. An empty period (to before from) is ignored.
+func (c *ExchangeRateCoverage) Add(from, to time.Time) {
+	from, to = dateOf(from), dateOf(to)
+	if to.Before(from) {
+		return
+	}
+
+	periods := append(slices.Clone(c.Periods), ExchangeRatePeriod{From: from, To: to})
+	slices.SortFunc(periods, func(a, b ExchangeRatePeriod) int {
+		return a.From.Compare(b.From)
+	})
+
+	// Overlapping periods, and periods following each other the next day, are merged into one
+	merged := make([]ExchangeRatePeriod, 0, len(periods))
+	for _, period := range periods {
+		last := len(merged) - 1
+		if last >= 0 && !period.From.After(merged[last].To.AddDate(0, 0, 1)) {
+			if period.To.After(merged[last].To) {
+				merged[last].To = period.To
+			}
+			continue
+		}
+		merged = append(merged, period)
+	}
+	c.Periods = merged
+}
+
+// Merge adds all the periods of another coverage into the coverage.
+func (c *ExchangeRateCoverage) Merge(other ExchangeRateCoverage) {
+	for _, period := range other.Periods {
+		c.Add(period.From, period.To)
+	}
+}
+
+// Covers reports whether every day from from to to, included, is covered. An empty period is always covered.
+func (c ExchangeRateCoverage) Covers(from, to time.Time) bool {
+	from, to = dateOf(from), dateOf(to)
+	if to.Before(from) {
+		return true
+	}
+	for _, period := range c.Periods {
+		if !period.From.After(from) && !period.To.Before(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// End returns the last covered day, or the zero time when nothing is covered.
+func (c ExchangeRateCoverage) End() time.Time {
+	if len(c.Periods) == 0 {
+		return time.Time{}
+	}
+	return c.Periods[len(c.Periods)-1].To
+}
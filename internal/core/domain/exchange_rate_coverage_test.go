@@ -0,0 +1,113 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// This file contains tests for the ExchangeRateCoverage domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestExchangeRateCoverage tests the Add, Covers and End methods of the ExchangeRateCoverage struct. It tests the
+// following scenarios:
+//
+// 1. Empty Coverage.
+// 2. Window Within A Period.
+// 3. Window Across A Gap.
+// 4. Overlapping Periods Merged.
+// 5. Adjacent Days Merged.
+// 6. Empty Period Ignored.
+// 7. Times Of Day Ignored.
+func TestExchangeRateCoverage(t *testing.T) {
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2023, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name            string
+		periods         [][2]time.Time
+		from            time.Time
+		to              time.Time
+		expectedCovered bool
+		expectedPeriods int
+		expectedEnd     time.Time
+	}{
+		{
+			name:            "Empty Coverage",
+			from:            day(1, 1),
+			to:              day(1, 2),
+			expectedCovered: false,
+		},
+		{
+			name:            "Window Within A Period",
+			periods:         [][2]time.Time{{day(1, 1), day(12, 31)}},
+			from:            day(1, 15),
+			to:              day(7, 15),
+			expectedCovered: true,
+			expectedPeriods: 1,
+			expectedEnd:     day(12, 31),
+		},
+		{
+			name:            "Window Across A Gap",
+			periods:         [][2]time.Time{{day(1, 1), day(3, 31)}, {day(7, 1), day(9, 30)}},
+			from:            day(1, 15),
+			to:              day(7, 15),
+			expectedCovered: false,
+			expectedPeriods: 2,
+			expectedEnd:     day(9, 30),
+		},
+		{
+			name:            "Overlapping Periods Merged",
+			periods:         [][2]time.Time{{day(5, 1), day(9, 30)}, {day(1, 1), day(6, 30)}},
+			from:            day(1, 15),
+			to:              day(7, 15),
+			expectedCovered: true,
+			expectedPeriods: 1,
+			expectedEnd:     day(9, 30),
+		},
+		{
+			name:            "Adjacent Days Merged",
+			periods:         [][2]time.Time{{day(1, 1), day(3, 31)}, {day(4, 1), day(7, 31)}},
+			from:            day(1, 15),
+			to:              day(7, 15),
+			expectedCovered: true,
+			expectedPeriods: 1,
+			expectedEnd:     day(7, 31),
+		},
+		{
+			name:            "Empty Period Ignored",
+			periods:         [][2]time.Time{{day(3, 31), day(1, 1)}},
+			from:            day(2, 1),
+			to:              day(2, 1),
+			expectedCovered: false,
+		},
+		{
+			name: "Times Of Day Ignored",
+			periods: [][2]time.Time{{time.Date(2023, 1, 1, 18, 0, 0, 0, time.UTC),
+				time.Date(2023, 7, 15, 6, 0, 0, 0, time.UTC)}},
+			from:            time.Date(2023, 1, 1, 6, 0, 0, 0, time.UTC),
+			to:              time.Date(2023, 7, 15, 18, 0, 0, 0, time.UTC),
+			expectedCovered: true,
+			expectedPeriods: 1,
+			expectedEnd:     day(7, 15),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var coverage domain.ExchangeRateCoverage
+
+			for _, period := range tt.periods {
+				coverage.Add(period[0], period[1])
+			}
+
+			assert.Equal(t, tt.expectedCovered, coverage.Covers(tt.from, tt.to))
+			assert.Len(t, coverage.Periods, tt.expectedPeriods)
+			assert.Equal(t, tt.expectedEnd, coverage.End())
+		})
+	}
+}
@@ -9,8 +9,15 @@ var (
 	ErrCurrencyNameEmpty = errors.New("exchange rate currency name is required; it cannot be empty")
 
 	// ErrInvalidExchangeRate is returned when the exchange rate is invalid.
-	ErrInvalidExchangeRate = errors.New("exchange rate is invalid; it must be greater than 0")
+	ErrInvalidExchangeRate = errors.New("exchange rate is invalid; it must be a decimal number greater than 0")
 
 	// ErrInvalidDateOfRecord is returned when the date of record is invalid.
 	ErrInvalidDateOfRecord = errors.New("exchange rate date of record is invalid; it cannot be in the future")
+
+	// ErrExchangeRateNotFound is returned when no exchange rate is found for the requested currency.
+	ErrExchangeRateNotFound = errors.New("no exchange rates found for the provided currency")
+
+	// ErrExchangeRatesUnavailable is returned when the exchange rate provider is temporarily unavailable and is not
+	// called.
+	ErrExchangeRatesUnavailable = errors.New("the exchange rate provider is temporarily unavailable; retry later")
 )
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+	"time"
+)
+
+// This file contains the ExchangeRateQuery and ExchangeRatePage structs, their constructor and validation functions.
+
+// Limits applied to the number of exchange rates returned in a single page.
+const (
+	DefaultExchangeRateQueryLimit = 100
+	MaxExchangeRateQueryLimit     = 1000
+)
+
+// exchangeRateCursorSeparator separates the date of record from the country-currency name in a cursor.
+const exchangeRateCursorSeparator = "|"
+
+// ExchangeRateQuery represents the filters and pagination parameters used to list the exchange rate history of a
+// currency.
+type ExchangeRateQuery struct {
+	// CurrencyName is the currency as accepted by the conversions (ISO 4217 code, country-currency or currency name).
+	CurrencyName string
+	// From keeps only the exchange rates with a date of record at or after it, when set.
+	From *time.Time
+	// To keeps only the exchange rates with a date of record at or before it, when set.
+	To *time.Time
+	// Cursor is the opaque position returned by a previous page. Empty means the first page.
+	Cursor string
+	// Limit is the maximum number of exchange rates returned in a page.
+	Limit int
+}
+
+// ExchangeRatePage represents a page of exchange rates, most recent first, and the cursor to fetch the next one.
+type ExchangeRatePage struct {
+	ExchangeRates []*ExchangeRate
+	// NextCursor is empty when there are no more exchange rates to fetch.
+	NextCursor string
+}
+
+// NewExchangeRateQuery creates a new ExchangeRateQuery instance with input validation. A zero limit falls back to
+// DefaultExchangeRateQueryLimit.
+func NewExchangeRateQuery(currencyName string, from, to *time.Time, cursor string,
+	limit int) (*ExchangeRateQuery, []error) {
+	currencyName = strings.TrimSpace(currencyName)
+	cursor = strings.TrimSpace(cursor)
+	if limit == 0 {
+		limit = DefaultExchangeRateQueryLimit
+	}
+
+	query := ExchangeRateQuery{
+		CurrencyName: currencyName,
+		From:         from,
+		To:           to,
+		Cursor:       cursor,
+		Limit:        limit,
+	}
+
+	// Validate the inputs before constructing the object
+	if errs := ValidateExchangeRateQuery(query); len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &query, nil
+}
+
+// ValidateExchangeRateQuery validates the currency name, date range and limit of the ExchangeRateQuery struct.
+func ValidateExchangeRateQuery(query ExchangeRateQuery) []error {
+	errors := make([]error, 0, 3)
+
+	// Validate the currency name length: must not be empty
+	if query.CurrencyName == "" {
+		errors = append(errors, ErrCurrencyNameEmpty)
+	}
+
+	// Validate the date range: the start cannot be after the end
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		errors = append(errors, ErrInvalidExchangeRateQueryDateRange)
+	}
+
+	// Validate the limit: must be between 1 and the maximum limit
+	if query.Limit < 1 || query.Limit > MaxExchangeRateQueryLimit {
+		errors = append(errors, ErrInvalidExchangeRateQueryLimit)
+	}
+
+	return errors
+}
+
+// Matches reports whether the exchange rate satisfies the date range of the query. Pagination is not considered.
+func (q ExchangeRateQuery) Matches(exchangeRate ExchangeRate) bool {
+	if q.From != nil && exchangeRate.DateOfRecord.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && exchangeRate.DateOfRecord.After(*q.To) {
+		return false
+	}
+	return true
+}
+
+// Paginate returns the page of the exchange rates requested by the query. The exchange rates are ordered most recent
+// first, then by country-currency name, and the cursor holds the position of the last exchange rate of the previous
+// page, so pages stay consistent when new exchange rates are published.
+func (q ExchangeRateQuery) Paginate(exchangeRates []*ExchangeRate) (*ExchangeRatePage, error) {
+	var after *ExchangeRate
+	if q.Cursor != "" {
+		cursorExchangeRate, err := decodeExchangeRateCursor(q.Cursor)
+		if err != nil {
+			return nil, ErrInvalidExchangeRateCursor
+		}
+		after = cursorExchangeRate
+	}
+
+	sorted := make([]*ExchangeRate, 0, len(exchangeRates))
+	for _, exchangeRate := range exchangeRates {
+		if q.Matches(*exchangeRate) && (after == nil || compareExchangeRates(exchangeRate, after) > 0) {
+			sorted = append(sorted, exchangeRate)
+		}
+	}
+	slices.SortStableFunc(sorted, compareExchangeRates)
+
+	page := &ExchangeRatePage{ExchangeRates: sorted}
+	if len(sorted) > q.Limit {
+		page.ExchangeRates = sorted[:q.Limit]
+		page.NextCursor = encodeExchangeRateCursor(sorted[q.Limit-1])
+	}
+	return page, nil
+}
+
+// compareExchangeRates orders the exchange rates most recent first, then by country-currency name.
+func compareExchangeRates(a, b *ExchangeRate) int {
+	if c := b.DateOfRecord.Compare(a.DateOfRecord); c != 0 {
+		return c
+	}
+	return strings.Compare(a.CountryCurrencyDesc, b.CountryCurrencyDesc)
+}
+
+// encodeExchangeRateCursor turns the position of an exchange rate into an opaque cursor safe to be used in URLs.
+func encodeExchangeRateCursor(exchangeRate *ExchangeRate) string {
+	position := exchangeRate.DateOfRecord.Format(time.DateOnly) + exchangeRateCursorSeparator +
+		exchangeRate.CountryCurrencyDesc
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+// decodeExchangeRateCursor turns an opaque cursor back into the position of an exchange rate.
+func decodeExchangeRateCursor(cursor string) (*ExchangeRate, error) {
+	position, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	date, countryCurrencyDesc, found := strings.Cut(string(position), exchangeRateCursorSeparator)
+	if !found {
+		return nil, ErrInvalidExchangeRateCursor
+	}
+	dateOfRecord, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return nil, err
+	}
+	return &ExchangeRate{CountryCurrencyDesc: countryCurrencyDesc, DateOfRecord: dateOfRecord}, nil
+}
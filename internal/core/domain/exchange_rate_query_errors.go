@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to exchange rate query validation in the domain layer.
+
+var (
+	// ErrInvalidExchangeRateQueryDateRange is returned when the query start date is after the end date.
+	ErrInvalidExchangeRateQueryDateRange = errors.New("exchange rate query date range is invalid; from cannot be after to")
+
+	// ErrInvalidExchangeRateQueryLimit is returned when the query page limit is out of bounds.
+	ErrInvalidExchangeRateQueryLimit = errors.New("exchange rate query limit is invalid; it must be between 1 and 1000")
+
+	// ErrInvalidExchangeRateCursor is returned when the pagination cursor cannot be decoded.
+	ErrInvalidExchangeRateCursor = errors.New("exchange rate query cursor is invalid")
+)
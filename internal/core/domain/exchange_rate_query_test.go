@@ -0,0 +1,173 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the ExchangeRateQuery domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestNewExchangeRateQuery tests the NewExchangeRateQuery constructor function. It tests the following scenarios:
+//
+// 1. Valid Query Without Filters.
+// 2. Valid Query With A Date Range.
+// 3. Empty Currency.
+// 4. Inverted Date Range.
+// 5. Limit Too High.
+func TestNewExchangeRateQuery(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		currencyName   string
+		from           *time.Time
+		to             *time.Time
+		limit          int
+		expectedLimit  int
+		expectedErrors []error
+	}{
+		{
+			name:          "Valid Query Without Filters",
+			currencyName:  "EUR",
+			expectedLimit: domain.DefaultExchangeRateQueryLimit,
+		},
+		{
+			name:          "Valid Query With A Date Range",
+			currencyName:  "Real",
+			from:          &from,
+			to:            &to,
+			limit:         10,
+			expectedLimit: 10,
+		},
+		{
+			name:           "Empty Currency",
+			currencyName:   "  ",
+			expectedErrors: []error{domain.ErrCurrencyNameEmpty},
+		},
+		{
+			name:           "Inverted Date Range",
+			currencyName:   "EUR",
+			from:           &to,
+			to:             &from,
+			expectedErrors: []error{domain.ErrInvalidExchangeRateQueryDateRange},
+		},
+		{
+			name:           "Limit Too High",
+			currencyName:   "EUR",
+			limit:          domain.MaxExchangeRateQueryLimit + 1,
+			expectedErrors: []error{domain.ErrInvalidExchangeRateQueryLimit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			query, errs := domain.NewExchangeRateQuery(tt.currencyName, tt.from, tt.to, "", tt.limit)
+
+			if len(tt.expectedErrors) > 0 {
+				assert.Equal(t, tt.expectedErrors, errs)
+				assert.Nil(t, query)
+				return
+			}
+			require.Empty(t, errs)
+			assert.Equal(t, tt.expectedLimit, query.Limit)
+		})
+	}
+}
+
+// TestExchangeRateQueryPaginate tests the Paginate method of the ExchangeRateQuery. It tests the following scenarios:
+//
+// 1. Most Recent First Within The Date Range.
+// 2. Pages Follow Each Other.
+// 3. Same Date In Several Countries.
+// 4. Invalid Cursor.
+func TestExchangeRateQueryPaginate(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	newExchangeRate := func(countryCurrencyDesc, rate string, dateOfRecord time.Time) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Dollar", rate, dateOfRecord)
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+		return exchangeRate
+	}
+	rateTexts := func(page *domain.ExchangeRatePage) []string {
+		texts := make([]string, len(page.ExchangeRates))
+		for i, exchangeRate := range page.ExchangeRates {
+			texts[i] = exchangeRate.RateText
+		}
+		return texts
+	}
+	exchangeRates := []*domain.ExchangeRate{
+		newExchangeRate("Canada-Dollar", "1.34", date(3, 31)),
+		newExchangeRate("Canada-Dollar", "1.37", date(6, 30)),
+		newExchangeRate("Canada-Dollar", "1.35", date(9, 30)),
+		newExchangeRate("Australia-Dollar", "1.44", date(9, 30)),
+		newExchangeRate("Canada-Dollar", "1.43", date(12, 31)),
+	}
+
+	t.Run("Most Recent First Within The Date Range", func(t *testing.T) {
+		t.Parallel()
+		from, to := date(4, 1), date(10, 1)
+		query, errs := domain.NewExchangeRateQuery("Canada-Dollar", &from, &to, "", 10)
+		require.Empty(t, errs)
+
+		page, err := query.Paginate(exchangeRates)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.44", "1.35", "1.37"}, rateTexts(page))
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Pages Follow Each Other", func(t *testing.T) {
+		t.Parallel()
+		collected := make([]string, 0, len(exchangeRates))
+		cursor := ""
+		for pages := 0; pages < 3; pages++ {
+			query, errs := domain.NewExchangeRateQuery("Dollar", nil, nil, cursor, 2)
+			require.Empty(t, errs)
+			page, err := query.Paginate(exchangeRates)
+			require.NoError(t, err)
+			collected = append(collected, rateTexts(page)...)
+			cursor = page.NextCursor
+			if cursor == "" {
+				break
+			}
+		}
+
+		assert.Equal(t, []string{"1.43", "1.44", "1.35", "1.37", "1.34"}, collected)
+		assert.Empty(t, cursor)
+	})
+
+	t.Run("Same Date In Several Countries", func(t *testing.T) {
+		t.Parallel()
+		query, errs := domain.NewExchangeRateQuery("Dollar", nil, nil, "", 2)
+		require.Empty(t, errs)
+		firstPage, err := query.Paginate(exchangeRates)
+		require.NoError(t, err)
+
+		// The cursor points between the two exchange rates of September
+		query.Cursor = firstPage.NextCursor
+		secondPage, err := query.Paginate(exchangeRates)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.43", "1.44"}, rateTexts(firstPage))
+		assert.Equal(t, []string{"1.35", "1.37"}, rateTexts(secondPage))
+	})
+
+	t.Run("Invalid Cursor", func(t *testing.T) {
+		t.Parallel()
+		query, errs := domain.NewExchangeRateQuery("Dollar", nil, nil, "not a cursor!", 2)
+		require.Empty(t, errs)
+
+		_, err := query.Paginate(exchangeRates)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidExchangeRateCursor)
+	})
+}
@@ -25,7 +25,7 @@ func TestNewExchangeRate(t *testing.T) {
 	tests := []struct {
 		name                 string
 		currencyName         string
-		rate                 float64
+		rate                 string
 		dateOfRecord         time.Time
 		expectedErrors       []error
 		expectedExchangeRate *domain.ExchangeRate
@@ -33,52 +33,52 @@ func TestNewExchangeRate(t *testing.T) {
 		{
 			name:           "Valid Exchange Rate",
 			currencyName:   "Brazil-Real",
-			rate:           5.434,
+			rate:           "5.434",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{},
 			expectedExchangeRate: &domain.ExchangeRate{
 				CurrencyName: "Brazil-Real",
-				Rate:         new(big.Float).SetFloat64(5.434),
+				Rate:         big.NewRat(5434, 1000),
 				DateOfRecord: time.Now(),
 			},
 		},
 		{
 			name:           "Empty Currency Name",
 			currencyName:   "",
-			rate:           1.2,
+			rate:           "1.2",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{domain.ErrCurrencyNameEmpty},
 		},
 		{
 			name:           "Negative Rate",
 			currencyName:   "Brazil-Real",
-			rate:           -5.434,
+			rate:           "-5.434",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{domain.ErrInvalidExchangeRate},
 		},
 		{
 			name:           "Rate Zero",
 			currencyName:   "Brazil-Real",
-			rate:           0,
+			rate:           "0",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{domain.ErrInvalidExchangeRate},
 		},
 		{
 			name:           "Future Date Of Record",
 			currencyName:   "Brazil-Real",
-			rate:           5.434,
+			rate:           "5.434",
 			dateOfRecord:   time.Now().Add(24 * time.Hour),
 			expectedErrors: []error{domain.ErrInvalidDateOfRecord},
 		},
 		{
 			name:           "Valid Exchange Rate With Current Date Of Record",
 			currencyName:   "Brazil-Real",
-			rate:           5.434,
+			rate:           "5.434",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{},
 			expectedExchangeRate: &domain.ExchangeRate{
 				CurrencyName: "Brazil-Real",
-				Rate:         new(big.Float).SetFloat64(5.434),
+				Rate:         big.NewRat(5434, 1000),
 				DateOfRecord: time.Now(),
 			},
 		},
@@ -105,6 +105,7 @@ func TestNewExchangeRate(t *testing.T) {
 				require.NotNil(t, exchangeRate)
 				assert.Equal(t, tt.expectedExchangeRate.CurrencyName, exchangeRate.CurrencyName)
 				assert.Equal(t, tt.expectedExchangeRate.Rate.Cmp(exchangeRate.Rate), 0)
+				assert.Equal(t, tt.rate, exchangeRate.RateText)
 				assert.True(t, exchangeRate.DateOfRecord.Before(time.Now().Add(time.Second)))
 			} else {
 				assert.Nil(t, exchangeRate)
@@ -120,49 +121,57 @@ func TestNewExchangeRate(t *testing.T) {
 // 3. Negative Rate.
 // 4. Rate Zero.
 // 5. Future Date Of Record.
+// 6. Rate Not A Decimal Number.
 func TestValidateExchangeRate(t *testing.T) {
 	tests := []struct {
 		name           string
 		currencyName   string
-		rate           float64
+		rate           string
 		dateOfRecord   time.Time
 		expectedErrors []error
 	}{
 		{
 			name:           "Valid Exchange Rate",
 			currencyName:   "United Kingdom-Pound",
-			rate:           0.745,
+			rate:           "0.745",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{},
 		},
 		{
 			name:           "Empty Currency Name",
 			currencyName:   "",
-			rate:           1.2,
+			rate:           "1.2",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{domain.ErrCurrencyNameEmpty},
 		},
 		{
 			name:           "Negative Rate",
 			currencyName:   "United Kingdom-Pound",
-			rate:           -0.745,
+			rate:           "-0.745",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{domain.ErrInvalidExchangeRate},
 		},
 		{
 			name:           "Rate Zero",
 			currencyName:   "United Kingdom-Pound",
-			rate:           0,
+			rate:           "0",
 			dateOfRecord:   time.Now(),
 			expectedErrors: []error{domain.ErrInvalidExchangeRate},
 		},
 		{
 			name:           "Future Date Of Record",
 			currencyName:   "United Kingdom-Pound",
-			rate:           0.745,
+			rate:           "0.745",
 			dateOfRecord:   time.Now().Add(24 * time.Hour),
 			expectedErrors: []error{domain.ErrInvalidDateOfRecord},
 		},
+		{
+			name:           "Rate Not A Decimal Number",
+			currencyName:   "United Kingdom-Pound",
+			rate:           "zero point seven",
+			dateOfRecord:   time.Now(),
+			expectedErrors: []error{domain.ErrInvalidExchangeRate},
+		},
 	}
 
 	for _, tt := range tests {
@@ -176,3 +185,135 @@ func TestValidateExchangeRate(t *testing.T) {
 		})
 	}
 }
+
+// TestExchangeRateConvert tests the Convert method of the ExchangeRate. It tests the following scenarios:
+//
+// 1. Rate With Three Decimal Places.
+// 2. High-Value Rate With Many Decimal Places.
+// 3. Only The Converted Amount Is Rounded.
+// 4. Rate Equal To One.
+// 5. Currency Without Minor Units (JPY).
+// 6. Currency With Three Minor Units (KWD).
+// 7. Currency With Two Minor Units (EUR).
+func TestExchangeRateConvert(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       string
+		rate         string
+		currencyCode string
+		expected     string
+	}{
+		{
+			// Rounding the rate to 0.86 first would give 86.00
+			name:     "Rate With Three Decimal Places",
+			amount:   "100.00",
+			rate:     "0.857",
+			expected: "85.70",
+		},
+		{
+			// Rounding the rate to 15474.35 first would give 15474350.00
+			name:     "High-Value Rate With Many Decimal Places",
+			amount:   "1000.00",
+			rate:     "15474.3456",
+			expected: "15474345.60",
+		},
+		{
+			name:     "Only The Converted Amount Is Rounded",
+			amount:   "28.75",
+			rate:     "1.3333",
+			expected: "38.33",
+		},
+		{
+			name:     "Rate Equal To One",
+			amount:   "12.34",
+			rate:     "1.0",
+			expected: "12.34",
+		},
+		{
+			name:         "Currency Without Minor Units (JPY)",
+			amount:       "28.75",
+			rate:         "143.57",
+			currencyCode: "JPY",
+			expected:     "4128",
+		},
+		{
+			name:         "Currency With Three Minor Units (KWD)",
+			amount:       "28.75",
+			rate:         "0.306",
+			currencyCode: "KWD",
+			expected:     "8.798",
+		},
+		{
+			name:         "Currency With Two Minor Units (EUR)",
+			amount:       "28.75",
+			rate:         "0.897",
+			currencyCode: "EUR",
+			expected:     "25.79",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			exchangeRate, errs := domain.NewExchangeRate("Any-Currency", tt.rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+			require.Empty(t, errs)
+			exchangeRate.CurrencyCode = tt.currencyCode
+
+			converted, err := exchangeRate.Convert(domain.MustParseMoney(tt.amount, domain.CurrencyUSD))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, converted.String())
+			assert.Equal(t, tt.currencyCode, converted.Currency())
+		})
+	}
+}
+
+// TestExchangeRateConvertToUSD tests the ConvertToUSD method of the ExchangeRate. It tests the following scenarios:
+//
+// 1. Currency With Two Minor Units (EUR).
+// 2. Currency Without Minor Units (JPY).
+// 3. Only The Converted Amount Is Rounded.
+func TestExchangeRateConvertToUSD(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       string
+		rate         string
+		currencyCode string
+		expected     string
+	}{
+		{
+			name:         "Currency With Two Minor Units (EUR)",
+			amount:       "25.79",
+			rate:         "0.897",
+			currencyCode: "EUR",
+			expected:     "28.75",
+		},
+		{
+			name:         "Currency Without Minor Units (JPY)",
+			amount:       "4128",
+			rate:         "143.57",
+			currencyCode: "JPY",
+			expected:     "28.75",
+		},
+		{
+			name:         "Only The Converted Amount Is Rounded",
+			amount:       "10.00",
+			rate:         "3",
+			currencyCode: "BRL",
+			expected:     "3.33",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			exchangeRate, errs := domain.NewExchangeRate("Any-Currency", tt.rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+			require.Empty(t, errs)
+			exchangeRate.CurrencyCode = tt.currencyCode
+
+			converted, err := exchangeRate.ConvertToUSD(domain.MustParseMoney(tt.amount, tt.currencyCode))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, converted.String())
+			assert.Equal(t, domain.CurrencyUSD, converted.Currency())
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// This file contains the IdempotencyKey struct, its constructor and validation functions.
+
+// Constants for the idempotency keys.
+const (
+	// DefaultIdempotencyKeyTTL is the time an idempotency key is kept, long enough to cover the retries of a client.
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	// maxIdempotencyKeyLength is the maximum number of characters of an idempotency key.
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyKey represents a key sent by a client with a request creating a transaction, so a retry of the request
+// returns the transaction created the first time instead of creating another one. The hash of the request tells a
+// retry from another request reusing the key.
+type IdempotencyKey struct {
+	Key string `json:"key"`
+	// RequestHash identifies the content of the request the key was first used with.
+	RequestHash string `json:"request_hash"`
+	// TransactionID is the ID of the transaction created by the request.
+	TransactionID uuid.UUID `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	// ExpiresAt is the time the key can be used again for another request.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewIdempotencyKey creates a new IdempotencyKey instance with input validation. The key expires after the ttl, or
+// after DefaultIdempotencyKeyTTL when the ttl is not positive.
+func NewIdempotencyKey(key, requestHash string, transactionID uuid.UUID, createdAt time.Time,
+	ttl time.Duration) (*IdempotencyKey, []error) {
+	key = strings.TrimSpace(key)
+
+	// Validate the inputs before constructing the object
+	if errs := ValidateIdempotencyKey(key, requestHash); len(errs) > 0 {
+		return nil, errs
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+	return &IdempotencyKey{
+		Key:           key,
+		RequestHash:   requestHash,
+		TransactionID: transactionID,
+		CreatedAt:     createdAt.UTC(),
+		ExpiresAt:     createdAt.Add(ttl).UTC(),
+	}, nil
+}
+
+// ValidateIdempotencyKey validates the key and the request hash for the IdempotencyKey struct.
+func ValidateIdempotencyKey(key, requestHash string) []error {
+	errors := make([]error, 0, 2)
+
+	// Validate the key: must not be empty, must not exceed 255 characters and must be printable ASCII
+	switch {
+	case key == "":
+		errors = append(errors, ErrIdempotencyKeyEmpty)
+	case len(key) > maxIdempotencyKeyLength:
+		errors = append(errors, ErrIdempotencyKeyTooLong)
+	case strings.IndexFunc(key, func(r rune) bool { return r < ' ' || r > '~' }) >= 0:
+		errors = append(errors, ErrIdempotencyKeyInvalid)
+	}
+
+	// Validate the request hash: must not be empty
+	if requestHash == "" {
+		errors = append(errors, ErrIdempotencyRequestHashEmpty)
+	}
+
+	return errors
+}
+
+// Matches reports whether a request with the given hash is a retry of the request the key was first used with.
+func (k *IdempotencyKey) Matches(requestHash string) bool {
+	return k.RequestHash == requestHash
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to idempotency keys in the domain layer.
+
+var (
+	// ErrIdempotencyKeyEmpty is returned when the idempotency key is empty.
+	ErrIdempotencyKeyEmpty = errors.New("idempotency key is required; it cannot be empty")
+
+	// ErrIdempotencyKeyTooLong is returned when the idempotency key exceeds the allowed character limit.
+	ErrIdempotencyKeyTooLong = errors.New("idempotency key is invalid; it must not exceed 255 characters")
+
+	// ErrIdempotencyKeyInvalid is returned when the idempotency key has characters other than printable ASCII.
+	ErrIdempotencyKeyInvalid = errors.New("idempotency key is invalid; it must only contain printable ASCII characters")
+
+	// ErrIdempotencyRequestHashEmpty is returned when the hash of the request of an idempotency key is empty.
+	ErrIdempotencyRequestHashEmpty = errors.New("idempotency key request hash is required; it cannot be empty")
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key is replayed with a different request.
+	ErrIdempotencyKeyReused = errors.New("the idempotency key was already used with a different request")
+)
@@ -0,0 +1,106 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the IdempotencyKey domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestNewIdempotencyKey tests the NewIdempotencyKey constructor function. It tests the following scenarios:
+//
+// 1. Valid Idempotency Key.
+// 2. Default Time To Live.
+// 3. Empty Key.
+// 4. Key Too Long.
+// 5. Key With Control Characters.
+// 6. Empty Request Hash.
+func TestNewIdempotencyKey(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		key               string
+		requestHash       string
+		ttl               time.Duration
+		expectedKey       string
+		expectedExpiresAt time.Time
+		expectedErrors    []error
+	}{
+		{
+			name:              "Valid Idempotency Key",
+			key:               " 3f1c2a8e-retry ",
+			requestHash:       "hash",
+			ttl:               time.Hour,
+			expectedKey:       "3f1c2a8e-retry",
+			expectedExpiresAt: createdAt.Add(time.Hour),
+		},
+		{
+			name:              "Default Time To Live",
+			key:               "key",
+			requestHash:       "hash",
+			expectedKey:       "key",
+			expectedExpiresAt: createdAt.Add(domain.DefaultIdempotencyKeyTTL),
+		},
+		{
+			name:           "Empty Key",
+			key:            "  ",
+			requestHash:    "hash",
+			expectedErrors: []error{domain.ErrIdempotencyKeyEmpty},
+		},
+		{
+			name:           "Key Too Long",
+			key:            strings.Repeat("k", 256),
+			requestHash:    "hash",
+			expectedErrors: []error{domain.ErrIdempotencyKeyTooLong},
+		},
+		{
+			name:           "Key With Control Characters",
+			key:            "key\x00value",
+			requestHash:    "hash",
+			expectedErrors: []error{domain.ErrIdempotencyKeyInvalid},
+		},
+		{
+			name:           "Empty Request Hash",
+			key:            "key",
+			expectedErrors: []error{domain.ErrIdempotencyRequestHashEmpty},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			transactionID := uuid.New()
+
+			idempotencyKey, errs := domain.NewIdempotencyKey(tt.key, tt.requestHash, transactionID, createdAt, tt.ttl)
+
+			if len(tt.expectedErrors) > 0 {
+				assert.Equal(t, tt.expectedErrors, errs)
+				assert.Nil(t, idempotencyKey)
+				return
+			}
+			require.Empty(t, errs)
+			assert.Equal(t, tt.expectedKey, idempotencyKey.Key)
+			assert.Equal(t, transactionID, idempotencyKey.TransactionID)
+			assert.Equal(t, createdAt, idempotencyKey.CreatedAt)
+			assert.Equal(t, tt.expectedExpiresAt, idempotencyKey.ExpiresAt)
+		})
+	}
+}
+
+// TestIdempotencyKeyMatches tests the Matches method of the IdempotencyKey.
+func TestIdempotencyKeyMatches(t *testing.T) {
+	t.Parallel()
+	idempotencyKey, errs := domain.NewIdempotencyKey("key", "hash", uuid.New(), time.Now(), time.Hour)
+	require.Empty(t, errs)
+
+	assert.True(t, idempotencyKey.Matches("hash"))
+	assert.False(t, idempotencyKey.Matches("other-hash"))
+}
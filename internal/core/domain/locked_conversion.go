@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// This file contains the LockedConversion struct, its constructor and the conversion it was locked from.
+
+// LockedConversion represents the conversion of a transaction frozen when it was locked, with the exchange rates and
+// the policy that selected them, so later reads return the same figures even after the Treasury publishes new or
+// revised exchange rates.
+type LockedConversion struct {
+	// Currency is the ISO 4217 code of the target currency, or its Treasury name when the code is not known.
+	Currency string `json:"currency"`
+	// CountryCurrencyDesc is the Treasury country-currency of the target currency. Empty for USD.
+	CountryCurrencyDesc string `json:"country_currency_desc,omitempty"`
+	// AmountInTargetCurrency is the converted amount, rounded to the minor units of the target currency.
+	AmountInTargetCurrency Money `json:"amount_in_target_currency"`
+	// Legs are the conversions to and from USD the amount went through, in order.
+	Legs []LockedConversionLeg `json:"legs"`
+	// RateSelectionPolicy is the name of the policy that selected the exchange rates.
+	RateSelectionPolicy string `json:"rate_selection_policy"`
+	// LookbackMonths is the lookback window of the policy that selected the exchange rates.
+	LookbackMonths int `json:"lookback_months"`
+	// LockedAt is the time the conversion was locked, stored in UTC.
+	LockedAt time.Time `json:"locked_at"`
+}
+
+// LockedConversionLeg represents a conversion leg of a locked conversion, with the exchange rate it used.
+type LockedConversionLeg struct {
+	FromCurrency        string    `json:"from_currency"`
+	ToCurrency          string    `json:"to_currency"`
+	CurrencyName        string    `json:"currency_name"`
+	CountryCurrencyDesc string    `json:"country_currency_desc,omitempty"`
+	CurrencyCode        string    `json:"currency_code,omitempty"`
+	Rate                string    `json:"rate"`
+	DateOfRecord        time.Time `json:"date_of_record"`
+	Source              string    `json:"source,omitempty"`
+	Amount              Money     `json:"amount"`
+}
+
+// NewLockedConversion freezes a successful conversion of a transaction with the policy that selected its exchange
+// rates.
+func NewLockedConversion(conversion *TransactionConversion, rateSelectionPolicy RateSelectionPolicy,
+	lockedAt time.Time) (*LockedConversion, error) {
+	if conversion.Err != nil {
+		return nil, conversion.Err
+	}
+
+	lockedConversion := &LockedConversion{
+		Currency:               CurrencyUSD,
+		AmountInTargetCurrency: conversion.AmountInTargetCurrency,
+		Legs:                   make([]LockedConversionLeg, len(conversion.Legs)),
+		RateSelectionPolicy:    rateSelectionPolicy.Name(),
+		LookbackMonths:         rateSelectionPolicy.LookbackMonths(),
+		LockedAt:               lockedAt.UTC(),
+	}
+	if conversion.ExchangeRate != nil {
+		lockedConversion.Currency = conversion.ExchangeRate.currency()
+		lockedConversion.CountryCurrencyDesc = conversion.ExchangeRate.CountryCurrencyDesc
+	}
+	for i, leg := range conversion.Legs {
+		lockedConversion.Legs[i] = LockedConversionLeg{
+			FromCurrency:        leg.FromCurrency,
+			ToCurrency:          leg.ToCurrency,
+			CurrencyName:        leg.ExchangeRate.CurrencyName,
+			CountryCurrencyDesc: leg.ExchangeRate.CountryCurrencyDesc,
+			CurrencyCode:        leg.ExchangeRate.CurrencyCode,
+			Rate:                leg.ExchangeRate.RateText,
+			DateOfRecord:        leg.ExchangeRate.DateOfRecord,
+			Source:              leg.ExchangeRate.Source,
+			Amount:              leg.Amount,
+		}
+	}
+
+	return lockedConversion, nil
+}
+
+// Matches reports whether the conversion was locked for a currency, given as an ISO 4217 code or a Treasury
+// country-currency (case-insensitive).
+func (c *LockedConversion) Matches(currency string) bool {
+	currency = strings.TrimSpace(currency)
+	return strings.EqualFold(currency, c.Currency) ||
+		(c.CountryCurrencyDesc != "" && strings.EqualFold(currency, c.CountryCurrencyDesc))
+}
+
+// TransactionConversion returns the locked conversion as a TransactionConversion to a requested currency name, with
+// the exchange rates it was locked with.
+func (c *LockedConversion) TransactionConversion(currencyName string) (*TransactionConversion, error) {
+	conversion := &TransactionConversion{
+		CurrencyName:           currencyName,
+		AmountInTargetCurrency: c.AmountInTargetCurrency,
+		Legs:                   make([]ConversionLeg, len(c.Legs)),
+	}
+	for i, leg := range c.Legs {
+		exchangeRate, errs := NewExchangeRate(leg.CurrencyName, leg.Rate, leg.DateOfRecord)
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		exchangeRate.CountryCurrencyDesc = leg.CountryCurrencyDesc
+		exchangeRate.CurrencyCode = leg.CurrencyCode
+		exchangeRate.Source = leg.Source
+		conversion.Legs[i] = ConversionLeg{
+			FromCurrency: leg.FromCurrency,
+			ToCurrency:   leg.ToCurrency,
+			ExchangeRate: exchangeRate,
+			Amount:       leg.Amount,
+		}
+		// The exchange rate of the target currency is the one of the leg from USD
+		if leg.FromCurrency == CurrencyUSD {
+			conversion.ExchangeRate = exchangeRate
+		}
+	}
+	return conversion, nil
+}
+
+// UnmarshalJSON decodes a locked conversion and restores the currencies of its amounts, which are not part of their
+// encoded values.
+func (c *LockedConversion) UnmarshalJSON(data []byte) error {
+	// The alias drops the methods of LockedConversion to avoid an infinite recursion
+	type lockedConversionAlias LockedConversion
+	var raw lockedConversionAlias
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = LockedConversion(raw)
+	c.AmountInTargetCurrency = restoreCurrency(c.AmountInTargetCurrency, c.Currency)
+	for i := range c.Legs {
+		c.Legs[i].Amount = restoreCurrency(c.Legs[i].Amount, c.Legs[i].ToCurrency)
+	}
+	return nil
+}
+
+// restoreCurrency returns the amount in the currency, with the minor units of the currency, when it is an ISO 4217
+// code, and the amount unchanged otherwise.
+func restoreCurrency(amount Money, currency string) Money {
+	withCurrency, err := NewMoneyFromRat(amount.Rat(), currency, CurrencyExponent(currency), RoundHalfUp)
+	if err != nil {
+		return amount
+	}
+	return withCurrency
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to locked conversions in the domain layer.
+
+var (
+	// ErrConversionAlreadyLocked is returned when the conversion of a transaction to a currency is locked twice.
+	ErrConversionAlreadyLocked = errors.New("the conversion of the transaction to this currency is already locked")
+)
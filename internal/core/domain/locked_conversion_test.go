@@ -0,0 +1,130 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the LockedConversion domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// newLockedConversionFixture converts a transaction of 25.79 EUR to MXN through USD and locks the conversion.
+func newLockedConversionFixture(t *testing.T) (*domain.TransactionConversion, *domain.LockedConversion) {
+	newExchangeRate := func(currencyName, rate, countryCurrencyDesc, currencyCode string) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate(currencyName, rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+		exchangeRate.CurrencyCode = currencyCode
+		exchangeRate.Source = domain.ExchangeRateSourceTreasury
+		return exchangeRate
+	}
+	transaction, errs := domain.NewTransactionInCurrency("Purchase", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.79", "EUR"))
+	require.Empty(t, errs)
+	conversion, err := domain.NewTransactionConversion(transaction, "Peso",
+		newExchangeRate("Euro", "0.897", "Euro Zone-Euro", "EUR"), newExchangeRate("Peso", "19.6", "Mexico-Peso", "MXN"))
+	require.NoError(t, err)
+	lockedConversion, err := domain.NewLockedConversion(conversion, domain.DefaultRateSelectionPolicy(),
+		time.Date(2024, 10, 2, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	return conversion, lockedConversion
+}
+
+// TestNewLockedConversion tests the NewLockedConversion constructor function. It tests the following scenarios:
+//
+// 1. Conversion Through USD.
+// 2. Failed Conversion.
+func TestNewLockedConversion(t *testing.T) {
+	t.Run("Conversion Through USD", func(t *testing.T) {
+		t.Parallel()
+		_, lockedConversion := newLockedConversionFixture(t)
+
+		assert.Equal(t, "MXN", lockedConversion.Currency)
+		assert.Equal(t, "Mexico-Peso", lockedConversion.CountryCurrencyDesc)
+		assert.Equal(t, "563.53", lockedConversion.AmountInTargetCurrency.String())
+		assert.Equal(t, domain.RateSelectionLatestOnOrBefore, lockedConversion.RateSelectionPolicy)
+		assert.Equal(t, domain.DefaultRateSelectionLookbackMonths, lockedConversion.LookbackMonths)
+		require.Len(t, lockedConversion.Legs, 2)
+		assert.Equal(t, "0.897", lockedConversion.Legs[0].Rate)
+		assert.Equal(t, "19.6", lockedConversion.Legs[1].Rate)
+		assert.Equal(t, domain.ExchangeRateSourceTreasury, lockedConversion.Legs[1].Source)
+	})
+
+	t.Run("Failed Conversion", func(t *testing.T) {
+		t.Parallel()
+		conversion := &domain.TransactionConversion{CurrencyName: "XYZ", Err: domain.ErrUnknownCurrencyCode}
+
+		lockedConversion, err := domain.NewLockedConversion(conversion, domain.DefaultRateSelectionPolicy(), time.Now())
+
+		assert.ErrorIs(t, err, domain.ErrUnknownCurrencyCode)
+		assert.Nil(t, lockedConversion)
+	})
+}
+
+// TestLockedConversionJSON tests the JSON encoding and decoding of the LockedConversion. It tests the following
+// scenarios:
+//
+// 1. Round Trip Restores The Conversion.
+func TestLockedConversionJSON(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	t.Run("Round Trip Restores The Conversion", func(t *testing.T) {
+		t.Parallel()
+		conversion, lockedConversion := newLockedConversionFixture(t)
+
+		data, err := json.Marshal(lockedConversion)
+		require.NoError(t, err)
+		var decoded domain.LockedConversion
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		assert.Equal(t, *lockedConversion, decoded)
+		restored, err := decoded.TransactionConversion("Peso")
+		require.NoError(t, err)
+		assert.Equal(t, conversion.AmountInTargetCurrency, restored.AmountInTargetCurrency)
+		assert.Equal(t, conversion.Legs, restored.Legs)
+		assert.Equal(t, conversion.ExchangeRate, restored.ExchangeRate)
+	})
+}
+
+// TestLockedConversionMatches tests the Matches method of the LockedConversion. It tests the following scenarios:
+//
+// 1. Currency Code.
+// 2. Country-Currency (Case-Insensitive).
+// 3. Other Currency.
+func TestLockedConversionMatches(t *testing.T) {
+	_, lockedConversion := newLockedConversionFixture(t)
+
+	tests := []struct {
+		name     string
+		currency string
+		expected bool
+	}{
+		{
+			name:     "Currency Code",
+			currency: "MXN",
+			expected: true,
+		},
+		{
+			name:     "Country-Currency (Case-Insensitive)",
+			currency: "mexico-peso",
+			expected: true,
+		},
+		{
+			name:     "Other Currency",
+			currency: "EUR",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, lockedConversion.Matches(tt.currency))
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package domain
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This file contains the Money struct, its constructors and the exact decimal arithmetic used for amounts.
+
+// CurrencyUSD is the ISO 4217 code of the United States Dollar, the currency transactions are recorded in.
+const CurrencyUSD = "USD"
+
+// maxMoneyExponent is the maximum number of decimal places a Money amount can hold.
+const maxMoneyExponent = 18
+
+// decimalPattern matches a plain decimal number, with an optional minus sign and decimal places. The other forms
+// accepted by big.Rat (exponents, fractions, hexadecimal or underscores) are not amounts.
+var decimalPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// RoundingMode defines how an amount is rounded when it has more decimal places than the target exponent.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest value and ties away from zero (e.g. 0.125 -> 0.13, -0.125 -> -0.13).
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest value and ties to the even neighbour, also known as banker's rounding
+	// (e.g. 0.125 -> 0.12, 0.135 -> 0.14).
+	RoundHalfEven
+)
+
+// Money represents an exact monetary amount as an integer number of minor units of a currency. The exponent is the
+// number of decimal places of the amount, so 1234 minor units with exponent 2 is 12.34.
+type Money struct {
+	units    int64
+	exponent int
+	currency string
+}
+
+// NewMoney creates a new Money instance from its minor units, exponent and currency code with input validation.
+func NewMoney(minorUnits int64, exponent int, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	if err := ValidateMoney(exponent, currency); err != nil {
+		return Money{}, err
+	}
+
+	return Money{units: minorUnits, exponent: exponent, currency: currency}, nil
+}
+
+// ParseMoney creates a new Money instance from a decimal string (e.g. "12.34") without any rounding. The exponent is
+// the smallest number of decimal places able to represent the amount exactly.
+func ParseMoney(amount string, currency string) (Money, error) {
+	value, err := ParseDecimal(amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	exponent, ok := exactExponent(value)
+	if !ok {
+		return Money{}, ErrMoneyTooPrecise
+	}
+
+	return NewMoneyFromRat(value, currency, exponent, RoundHalfUp)
+}
+
+// MustParseMoney is like ParseMoney but panics if the amount cannot be parsed. It simplifies the initialization
+// of constants and test fixtures.
+func MustParseMoney(amount string, currency string) Money {
+	money, err := ParseMoney(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+// NewMoneyFromRat creates a new Money instance from an exact rational value, rounded to the exponent with the
+// rounding mode.
+func NewMoneyFromRat(value *big.Rat, currency string, exponent int, mode RoundingMode) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	if err := ValidateMoney(exponent, currency); err != nil {
+		return Money{}, err
+	}
+
+	units, err := roundRatToUnits(value, exponent, mode)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{units: units, exponent: exponent, currency: currency}, nil
+}
+
+// ValidateMoney validates the exponent and the currency code for the Money struct. An empty currency code is
+// allowed for amounts whose currency is given by the context they are used in.
+func ValidateMoney(exponent int, currency string) error {
+	// Validate the exponent: must be between 0 and the maximum exponent
+	if exponent < 0 || exponent > maxMoneyExponent {
+		return ErrInvalidMoneyExponent
+	}
+
+	// Validate the currency code: must be empty or three uppercase letters
+	if currency != "" {
+		if len(currency) != 3 {
+			return ErrInvalidCurrencyCode
+		}
+		for _, letter := range currency {
+			if letter < 'A' || letter > 'Z' {
+				return ErrInvalidCurrencyCode
+			}
+		}
+	}
+
+	return nil
+}
+
+// MinorUnits returns the amount as an integer number of minor units.
+func (m Money) MinorUnits() int64 {
+	return m.units
+}
+
+// Exponent returns the number of decimal places of the amount.
+func (m Money) Exponent() int {
+	return m.exponent
+}
+
+// Currency returns the ISO 4217 currency code of the amount.
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount.
+func (m Money) Sign() int {
+	switch {
+	case m.units < 0:
+		return -1
+	case m.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.units == 0
+}
+
+// Rat returns the exact value of the amount as a rational number.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.units), pow10(m.exponent))
+}
+
+// Cmp compares the values of the amounts regardless of their exponents and returns -1, 0 or +1. The amounts are
+// expected to be in the same currency.
+func (m Money) Cmp(other Money) int {
+	return m.Rat().Cmp(other.Rat())
+}
+
+// Round returns the amount rounded to the exponent with the rounding mode.
+func (m Money) Round(exponent int, mode RoundingMode) (Money, error) {
+	return NewMoneyFromRat(m.Rat(), m.currency, exponent, mode)
+}
+
+// WithCurrency returns the same amount in another currency code. No conversion is done.
+func (m Money) WithCurrency(currency string) (Money, error) {
+	return NewMoney(m.units, m.exponent, currency)
+}
+
+// Add returns the exact sum of the amounts. Both amounts must be in the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	exponent := max(m.exponent, other.exponent)
+	return NewMoneyFromRat(new(big.Rat).Add(m.Rat(), other.Rat()), m.currency, exponent, RoundHalfUp)
+}
+
+// Sub returns the exact difference of the amounts. Both amounts must be in the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	exponent := max(m.exponent, other.exponent)
+	return NewMoneyFromRat(new(big.Rat).Sub(m.Rat(), other.Rat()), m.currency, exponent, RoundHalfUp)
+}
+
+// String returns the amount as a decimal string with exactly as many decimal places as its exponent.
+func (m Money) String() string {
+	return m.Rat().FloatString(m.exponent)
+}
+
+// MarshalJSON encodes the amount as a JSON string (e.g. "12.34") so no precision is lost by JSON number parsers.
+// The currency code is not part of the encoded value.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON decodes the amount from a JSON string or a JSON number without going through a float. The
+// currency code of the receiver is preserved.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	money, err := ParseMoney(text, m.currency)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+// ParseDecimal parses a plain decimal string (e.g. "12.34" or "-0.5") into an exact rational number.
+func ParseDecimal(value string) (*big.Rat, error) {
+	value = strings.TrimSpace(value)
+
+	if !decimalPattern.MatchString(value) {
+		return nil, ErrInvalidDecimal
+	}
+
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, ErrInvalidDecimal
+	}
+	return rat, nil
+}
+
+// exactExponent returns the smallest exponent able to represent the value exactly, if any.
+func exactExponent(value *big.Rat) (int, bool) {
+	for exponent := 0; exponent <= maxMoneyExponent; exponent++ {
+		scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(pow10(exponent)))
+		if scaled.IsInt() {
+			return exponent, true
+		}
+	}
+	return 0, false
+}
+
+// roundRatToUnits scales the value by 10^exponent and rounds it to an integer with the rounding mode.
+func roundRatToUnits(value *big.Rat, exponent int, mode RoundingMode) (int64, error) {
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(pow10(exponent)))
+
+	// Splits the scaled value into quotient and remainder, both truncated toward zero
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	// Compares twice the remainder with the denominator to know if the discarded part is below, at or above half
+	doubleRemainder := new(big.Int).Abs(remainder)
+	doubleRemainder.Lsh(doubleRemainder, 1)
+	half := doubleRemainder.Cmp(scaled.Denom())
+
+	awayFromZero := false
+	switch {
+	case half > 0:
+		awayFromZero = true
+	case half == 0 && mode == RoundHalfUp:
+		awayFromZero = true
+	case half == 0 && mode == RoundHalfEven:
+		awayFromZero = quotient.Bit(0) == 1
+	}
+	if awayFromZero {
+		if scaled.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	if !quotient.IsInt64() {
+		return 0, ErrMoneyOverflow
+	}
+	return quotient.Int64(), nil
+}
+
+// pow10 returns 10 raised to the exponent.
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to money validation and arithmetic in the domain layer.
+
+var (
+	// ErrInvalidDecimal is returned when an amount is not a valid decimal number.
+	ErrInvalidDecimal = errors.New("amount is invalid; it must be a decimal number")
+
+	// ErrInvalidMoneyExponent is returned when the number of decimal places of an amount is out of bounds.
+	ErrInvalidMoneyExponent = errors.New("amount exponent is invalid; it must be between 0 and 18")
+
+	// ErrInvalidCurrencyCode is returned when a currency code is not made of three letters.
+	ErrInvalidCurrencyCode = errors.New("currency code is invalid; it must be an ISO 4217 code of three letters")
+
+	// ErrMoneyTooPrecise is returned when an amount has more decimal places than supported.
+	ErrMoneyTooPrecise = errors.New("amount is invalid; it must not exceed 18 decimal places")
+
+	// ErrMoneyOverflow is returned when an amount does not fit in the supported range.
+	ErrMoneyOverflow = errors.New("amount is invalid; it is too large")
+
+	// ErrCurrencyMismatch is returned when an operation involves amounts in different currencies.
+	ErrCurrencyMismatch = errors.New("amounts must be in the same currency")
+)
@@ -0,0 +1,326 @@
+package domain_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the Money domain model. It uses Table Driven Tests to test different scenarios.
+// It uses Testify for assertions and runs the tests in parallel.
+
+// TestParseMoney tests the ParseMoney constructor function. It tests the following scenarios:
+//
+// 1. Integer Amount.
+// 2. Decimal Amount.
+// 3. Trailing Zeros.
+// 4. Negative Amount.
+// 5. Lowercase Currency.
+// 6. Invalid Amount.
+// 7. Fraction Amount.
+// 8. Exponent Notation.
+// 9. Hexadecimal Amount.
+// 10. Underscore Separators.
+// 11. Missing Integer Part.
+// 12. Invalid Currency.
+// 13. Too Many Decimal Places.
+// 14. Too Large Amount.
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name               string
+		amount             string
+		currency           string
+		expectedMinorUnits int64
+		expectedExponent   int
+		expectedCurrency   string
+		expectedError      error
+	}{
+		{
+			name:               "Integer Amount",
+			amount:             "42",
+			currency:           "USD",
+			expectedMinorUnits: 42,
+			expectedExponent:   0,
+			expectedCurrency:   "USD",
+		},
+		{
+			name:               "Decimal Amount",
+			amount:             "28.745",
+			currency:           "USD",
+			expectedMinorUnits: 28745,
+			expectedExponent:   3,
+			expectedCurrency:   "USD",
+		},
+		{
+			name:               "Trailing Zeros",
+			amount:             "100.50",
+			currency:           "USD",
+			expectedMinorUnits: 1005,
+			expectedExponent:   1,
+			expectedCurrency:   "USD",
+		},
+		{
+			name:               "Negative Amount",
+			amount:             "-0.01",
+			currency:           "USD",
+			expectedMinorUnits: -1,
+			expectedExponent:   2,
+			expectedCurrency:   "USD",
+		},
+		{
+			name:               "Lowercase Currency",
+			amount:             "1.5",
+			currency:           " eur ",
+			expectedMinorUnits: 15,
+			expectedExponent:   1,
+			expectedCurrency:   "EUR",
+		},
+		{
+			name:          "Invalid Amount",
+			amount:        "ten",
+			currency:      "USD",
+			expectedError: domain.ErrInvalidDecimal,
+		},
+		{
+			name:          "Fraction Amount",
+			amount:        "1/3",
+			currency:      "USD",
+			expectedError: domain.ErrInvalidDecimal,
+		},
+		{
+			name:          "Exponent Notation",
+			amount:        "1e+06",
+			currency:      "USD",
+			expectedError: domain.ErrInvalidDecimal,
+		},
+		{
+			name:          "Hexadecimal Amount",
+			amount:        "0x10",
+			currency:      "USD",
+			expectedError: domain.ErrInvalidDecimal,
+		},
+		{
+			name:          "Underscore Separators",
+			amount:        "1_000",
+			currency:      "USD",
+			expectedError: domain.ErrInvalidDecimal,
+		},
+		{
+			name:          "Missing Integer Part",
+			amount:        ".5",
+			currency:      "USD",
+			expectedError: domain.ErrInvalidDecimal,
+		},
+		{
+			name:          "Invalid Currency",
+			amount:        "1.5",
+			currency:      "DOLLAR",
+			expectedError: domain.ErrInvalidCurrencyCode,
+		},
+		{
+			name:          "Too Many Decimal Places",
+			amount:        "0.0000000000000000001",
+			currency:      "USD",
+			expectedError: domain.ErrMoneyTooPrecise,
+		},
+		{
+			name:          "Too Large Amount",
+			amount:        "100000000000000000000",
+			currency:      "USD",
+			expectedError: domain.ErrMoneyOverflow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			money, err := domain.ParseMoney(tt.amount, tt.currency)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMinorUnits, money.MinorUnits())
+			assert.Equal(t, tt.expectedExponent, money.Exponent())
+			assert.Equal(t, tt.expectedCurrency, money.Currency())
+		})
+	}
+}
+
+// TestMoneyRound tests the Round method of the Money. It tests the following scenarios:
+//
+// 1. Half Up Rounds Ties Away From Zero.
+// 2. Half Up Rounds Negative Ties Away From Zero.
+// 3. Half Even Rounds Ties To Even (Down).
+// 4. Half Even Rounds Ties To Even (Up).
+// 5. Half Even Rounds Above Half Up.
+// 6. Below Half Rounds Down.
+// 7. Increasing The Exponent Is Exact.
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		exponent int
+		mode     domain.RoundingMode
+		expected string
+	}{
+		{
+			name:     "Half Up Rounds Ties Away From Zero",
+			amount:   "0.125",
+			exponent: 2,
+			mode:     domain.RoundHalfUp,
+			expected: "0.13",
+		},
+		{
+			name:     "Half Up Rounds Negative Ties Away From Zero",
+			amount:   "-0.125",
+			exponent: 2,
+			mode:     domain.RoundHalfUp,
+			expected: "-0.13",
+		},
+		{
+			name:     "Half Even Rounds Ties To Even (Down)",
+			amount:   "0.125",
+			exponent: 2,
+			mode:     domain.RoundHalfEven,
+			expected: "0.12",
+		},
+		{
+			name:     "Half Even Rounds Ties To Even (Up)",
+			amount:   "0.135",
+			exponent: 2,
+			mode:     domain.RoundHalfEven,
+			expected: "0.14",
+		},
+		{
+			name:     "Half Even Rounds Above Half Up",
+			amount:   "0.1251",
+			exponent: 2,
+			mode:     domain.RoundHalfEven,
+			expected: "0.13",
+		},
+		{
+			name:     "Below Half Rounds Down",
+			amount:   "28.744999",
+			exponent: 2,
+			mode:     domain.RoundHalfUp,
+			expected: "28.74",
+		},
+		{
+			name:     "Increasing The Exponent Is Exact",
+			amount:   "1.5",
+			exponent: 3,
+			mode:     domain.RoundHalfUp,
+			expected: "1.500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rounded, err := domain.MustParseMoney(tt.amount, "USD").Round(tt.exponent, tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rounded.String())
+			assert.Equal(t, tt.exponent, rounded.Exponent())
+		})
+	}
+}
+
+// TestMoneyArithmetic tests the arithmetic methods of the Money. It tests the following scenarios:
+//
+// 1. Add With Different Exponents.
+// 2. Sub To Negative.
+// 3. Add With Different Currencies.
+// 4. Cmp Regardless Of Exponents.
+// 5. Exact Sum Of Tenths.
+func TestMoneyArithmetic(t *testing.T) {
+	t.Run("Add With Different Exponents", func(t *testing.T) {
+		t.Parallel()
+		sum, err := domain.MustParseMoney("1.5", "USD").Add(domain.MustParseMoney("0.25", "USD"))
+		require.NoError(t, err)
+		assert.Equal(t, "1.75", sum.String())
+		assert.Equal(t, "USD", sum.Currency())
+	})
+
+	t.Run("Sub To Negative", func(t *testing.T) {
+		t.Parallel()
+		difference, err := domain.MustParseMoney("1", "USD").Sub(domain.MustParseMoney("1.01", "USD"))
+		require.NoError(t, err)
+		assert.Equal(t, "-0.01", difference.String())
+		assert.Equal(t, -1, difference.Sign())
+	})
+
+	t.Run("Add With Different Currencies", func(t *testing.T) {
+		t.Parallel()
+		_, err := domain.MustParseMoney("1", "USD").Add(domain.MustParseMoney("1", "EUR"))
+		assert.ErrorIs(t, err, domain.ErrCurrencyMismatch)
+	})
+
+	t.Run("Cmp Regardless Of Exponents", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, 0, domain.MustParseMoney("1.5", "USD").Cmp(domain.MustParseMoney("1.500", "USD")))
+		assert.Equal(t, -1, domain.MustParseMoney("1.49", "USD").Cmp(domain.MustParseMoney("1.5", "USD")))
+		assert.Equal(t, 1, domain.MustParseMoney("2", "USD").Cmp(domain.MustParseMoney("1.999", "USD")))
+	})
+
+	t.Run("Exact Sum Of Tenths", func(t *testing.T) {
+		t.Parallel()
+		// 0.1 + 0.2 is not 0.3 with floats
+		sum, err := domain.MustParseMoney("0.1", "USD").Add(domain.MustParseMoney("0.2", "USD"))
+		require.NoError(t, err)
+		assert.Equal(t, 0, sum.Rat().Cmp(big.NewRat(3, 10)))
+	})
+}
+
+// TestMoneyJSON tests the JSON encoding and decoding of the Money. It tests the following scenarios:
+//
+// 1. Encodes As A String.
+// 2. Decodes From A String.
+// 3. Decodes From A Number Without Float Rounding.
+// 4. Keeps The Receiver Currency.
+// 5. Invalid JSON Value.
+func TestMoneyJSON(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	t.Run("Encodes As A String", func(t *testing.T) {
+		t.Parallel()
+		data, err := json.Marshal(struct {
+			Amount domain.Money `json:"amount"`
+		}{Amount: domain.MustParseMoney("12.30", "USD")})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"amount":"12.3"}`, string(data))
+	})
+
+	t.Run("Decodes From A String", func(t *testing.T) {
+		t.Parallel()
+		var money domain.Money
+		require.NoError(t, json.Unmarshal([]byte(`"12.34"`), &money))
+		assert.Equal(t, "12.34", money.String())
+	})
+
+	t.Run("Decodes From A Number Without Float Rounding", func(t *testing.T) {
+		t.Parallel()
+		var money domain.Money
+		require.NoError(t, json.Unmarshal([]byte(`28.745`), &money))
+		assert.Equal(t, int64(28745), money.MinorUnits())
+		assert.Equal(t, 3, money.Exponent())
+	})
+
+	t.Run("Keeps The Receiver Currency", func(t *testing.T) {
+		t.Parallel()
+		money := domain.MustParseMoney("0", "EUR")
+		require.NoError(t, json.Unmarshal([]byte(`"1.5"`), &money))
+		assert.Equal(t, "EUR", money.Currency())
+	})
+
+	t.Run("Invalid JSON Value", func(t *testing.T) {
+		t.Parallel()
+		var money domain.Money
+		assert.Error(t, json.Unmarshal([]byte(`true`), &money))
+	})
+}
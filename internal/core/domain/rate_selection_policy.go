@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// This file contains the RateSelectionPolicy interface, its implementations, constructor and validation functions.
+
+// Names of the exchange rate selection policies.
+const (
+	// RateSelectionLatestOnOrBefore selects the most recent exchange rate dated on or before the purchase date.
+	RateSelectionLatestOnOrBefore = "latest_on_or_before"
+	// RateSelectionNearest selects the exchange rate dated the closest to the purchase date, before or after it.
+	RateSelectionNearest = "nearest"
+	// RateSelectionFirstAfter selects the first exchange rate dated after the purchase date.
+	RateSelectionFirstAfter = "first_after"
+)
+
+const (
+	// DefaultRateSelectionLookbackMonths is the default window, in months around the purchase date, in which an
+	// exchange rate is considered.
+	DefaultRateSelectionLookbackMonths = 6
+	// maxRateSelectionLookbackMonths bounds the window to the history kept by the Treasury API.
+	maxRateSelectionLookbackMonths = 120
+)
+
+// RateSelectionPolicy selects the exchange rate applicable to a purchase among the published exchange rates of a
+// currency. Only the exchange rates dated within the lookback window of the purchase date are considered.
+type RateSelectionPolicy interface {
+	// Name returns the name of the policy (e.g. "latest_on_or_before").
+	Name() string
+	// LookbackMonths returns the size of the window, in months, in which an exchange rate is considered.
+	LookbackMonths() int
+	// Window returns the first and last dates of record, inclusive, of the exchange rates considered for a purchase.
+	Window(purchaseDate time.Time) (time.Time, time.Time)
+	// Select returns the applicable exchange rate, or nil when none applies. The exchange rates may be in any order.
+	Select(exchangeRates []*ExchangeRate, purchaseDate time.Time) *ExchangeRate
+}
+
+// LatestOnOrBeforeRateSelectionPolicy selects the most recent exchange rate dated on or before the purchase date and
+// within the lookback window before it.
+type LatestOnOrBeforeRateSelectionPolicy struct {
+	lookbackMonths int
+}
+
+// NearestRateSelectionPolicy selects the exchange rate dated the closest to the purchase date and within the lookback
+// window around it. Between two exchange rates as close, the one dated before the purchase is selected.
+type NearestRateSelectionPolicy struct {
+	lookbackMonths int
+}
+
+// FirstAfterRateSelectionPolicy selects the first exchange rate dated after the purchase date and within the lookback
+// window after it.
+type FirstAfterRateSelectionPolicy struct {
+	lookbackMonths int
+}
+
+// NewRateSelectionPolicy creates a new RateSelectionPolicy from its name and lookback window with input validation.
+// The name is case-insensitive.
+func NewRateSelectionPolicy(name string, lookbackMonths int) (RateSelectionPolicy, []error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	// Validate the inputs before constructing the object
+	if errs := ValidateRateSelectionPolicy(name, lookbackMonths); len(errs) > 0 {
+		return nil, errs
+	}
+
+	switch name {
+	case RateSelectionNearest:
+		return &NearestRateSelectionPolicy{lookbackMonths: lookbackMonths}, nil
+	case RateSelectionFirstAfter:
+		return &FirstAfterRateSelectionPolicy{lookbackMonths: lookbackMonths}, nil
+	default:
+		return &LatestOnOrBeforeRateSelectionPolicy{lookbackMonths: lookbackMonths}, nil
+	}
+}
+
+// DefaultRateSelectionPolicy returns the policy applied when none is configured: the most recent exchange rate dated
+// on or before the purchase date, within the last DefaultRateSelectionLookbackMonths months.
+func DefaultRateSelectionPolicy() RateSelectionPolicy {
+	return &LatestOnOrBeforeRateSelectionPolicy{lookbackMonths: DefaultRateSelectionLookbackMonths}
+}
+
+// ValidateRateSelectionPolicy validates the name and lookback window of a RateSelectionPolicy.
+func ValidateRateSelectionPolicy(name string, lookbackMonths int) []error {
+	errors := make([]error, 0, 2)
+
+	// Validate the name: must be one of the known policies
+	switch name {
+	case RateSelectionLatestOnOrBefore, RateSelectionNearest, RateSelectionFirstAfter:
+	default:
+		errors = append(errors, ErrUnknownRateSelectionPolicy)
+	}
+
+	// Validate the lookback window: must be at least one month and at most the history kept
+	if lookbackMonths < 1 || lookbackMonths > maxRateSelectionLookbackMonths {
+		errors = append(errors, ErrInvalidRateSelectionLookback)
+	}
+
+	return errors
+}
+
+// Name returns the name of the policy.
+func (p *LatestOnOrBeforeRateSelectionPolicy) Name() string {
+	return RateSelectionLatestOnOrBefore
+}
+
+// LookbackMonths returns the size of the window, in months, in which an exchange rate is considered.
+func (p *LatestOnOrBeforeRateSelectionPolicy) LookbackMonths() int {
+	return p.lookbackMonths
+}
+
+// Window returns the lookback window before the purchase date, including the purchase day.
+func (p *LatestOnOrBeforeRateSelectionPolicy) Window(purchaseDate time.Time) (time.Time, time.Time) {
+	purchaseDay := dateOf(purchaseDate)
+	return purchaseDay.AddDate(0, -p.lookbackMonths, 0), purchaseDay
+}
+
+// Select returns the most recent exchange rate dated on or before the purchase date, or nil when none applies.
+func (p *LatestOnOrBeforeRateSelectionPolicy) Select(exchangeRates []*ExchangeRate, purchaseDate time.Time) *ExchangeRate {
+	windowStart, windowEnd := p.Window(purchaseDate)
+
+	var selected *ExchangeRate
+	for _, exchangeRate := range exchangeRates {
+		if exchangeRate.DateOfRecord.Before(windowStart) || exchangeRate.DateOfRecord.After(windowEnd) {
+			continue
+		}
+		if selected == nil || exchangeRate.DateOfRecord.After(selected.DateOfRecord) {
+			selected = exchangeRate
+		}
+	}
+	return selected
+}
+
+// Name returns the name of the policy.
+func (p *NearestRateSelectionPolicy) Name() string {
+	return RateSelectionNearest
+}
+
+// LookbackMonths returns the size of the window, in months, in which an exchange rate is considered.
+func (p *NearestRateSelectionPolicy) LookbackMonths() int {
+	return p.lookbackMonths
+}
+
+// Window returns the lookback window around the purchase date.
+func (p *NearestRateSelectionPolicy) Window(purchaseDate time.Time) (time.Time, time.Time) {
+	purchaseDay := dateOf(purchaseDate)
+	return purchaseDay.AddDate(0, -p.lookbackMonths, 0), purchaseDay.AddDate(0, p.lookbackMonths, 0)
+}
+
+// Select returns the exchange rate dated the closest to the purchase date, or nil when none applies.
+func (p *NearestRateSelectionPolicy) Select(exchangeRates []*ExchangeRate, purchaseDate time.Time) *ExchangeRate {
+	purchaseDay := dateOf(purchaseDate)
+	windowStart, windowEnd := p.Window(purchaseDate)
+
+	var selected *ExchangeRate
+	var selectedDistance time.Duration
+	for _, exchangeRate := range exchangeRates {
+		if exchangeRate.DateOfRecord.Before(windowStart) || exchangeRate.DateOfRecord.After(windowEnd) {
+			continue
+		}
+		distance := exchangeRate.DateOfRecord.Sub(purchaseDay).Abs()
+		// Ties are broken in favor of the exchange rate published before the purchase
+		if selected == nil || distance < selectedDistance ||
+			distance == selectedDistance && exchangeRate.DateOfRecord.Before(selected.DateOfRecord) {
+			selected = exchangeRate
+			selectedDistance = distance
+		}
+	}
+	return selected
+}
+
+// Name returns the name of the policy.
+func (p *FirstAfterRateSelectionPolicy) Name() string {
+	return RateSelectionFirstAfter
+}
+
+// LookbackMonths returns the size of the window, in months, in which an exchange rate is considered.
+func (p *FirstAfterRateSelectionPolicy) LookbackMonths() int {
+	return p.lookbackMonths
+}
+
+// Window returns the lookback window after the purchase date, from the day following the purchase.
+func (p *FirstAfterRateSelectionPolicy) Window(purchaseDate time.Time) (time.Time, time.Time) {
+	purchaseDay := dateOf(purchaseDate)
+	return purchaseDay.AddDate(0, 0, 1), purchaseDay.AddDate(0, p.lookbackMonths, 0)
+}
+
+// Select returns the first exchange rate dated after the purchase date, or nil when none applies.
+func (p *FirstAfterRateSelectionPolicy) Select(exchangeRates []*ExchangeRate, purchaseDate time.Time) *ExchangeRate {
+	windowStart, windowEnd := p.Window(purchaseDate)
+
+	var selected *ExchangeRate
+	for _, exchangeRate := range exchangeRates {
+		if exchangeRate.DateOfRecord.Before(windowStart) || exchangeRate.DateOfRecord.After(windowEnd) {
+			continue
+		}
+		if selected == nil || exchangeRate.DateOfRecord.Before(selected.DateOfRecord) {
+			selected = exchangeRate
+		}
+	}
+	return selected
+}
+
+// dateOf returns the calendar date of a timestamp as a UTC midnight, like the dates of record of the exchange rates.
+func dateOf(timestamp time.Time) time.Time {
+	year, month, day := timestamp.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to exchange rate selection in the domain layer.
+
+var (
+	// ErrUnknownRateSelectionPolicy is returned when the name of an exchange rate selection policy is not known.
+	ErrUnknownRateSelectionPolicy = errors.New("exchange rate selection policy must be one of latest_on_or_before, nearest or first_after")
+
+	// ErrInvalidRateSelectionLookback is returned when the lookback window of an exchange rate selection policy is
+	// invalid.
+	ErrInvalidRateSelectionLookback = errors.New("exchange rate selection lookback must be between 1 and 120 months")
+
+	// ErrNoApplicableExchangeRate is returned when no exchange rate applies to a purchase under the selection policy.
+	ErrNoApplicableExchangeRate = errors.New("no exchange rate applies to the purchase date")
+)
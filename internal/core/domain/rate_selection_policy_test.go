@@ -0,0 +1,247 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the RateSelectionPolicy domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestNewRateSelectionPolicy tests the NewRateSelectionPolicy constructor function. It tests the following scenarios:
+//
+// 1. Latest On Or Before Policy.
+// 2. Nearest Policy With Uppercase Name.
+// 3. First After Policy.
+// 4. Unknown Policy.
+// 5. Lookback Too Short.
+// 6. Lookback Too Long.
+func TestNewRateSelectionPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policyName     string
+		lookbackMonths int
+		expectedName   string
+		expectedErrors []error
+	}{
+		{
+			name:           "Latest On Or Before Policy",
+			policyName:     "latest_on_or_before",
+			lookbackMonths: 6,
+			expectedName:   domain.RateSelectionLatestOnOrBefore,
+		},
+		{
+			name:           "Nearest Policy With Uppercase Name",
+			policyName:     " NEAREST ",
+			lookbackMonths: 3,
+			expectedName:   domain.RateSelectionNearest,
+		},
+		{
+			name:           "First After Policy",
+			policyName:     "first_after",
+			lookbackMonths: 12,
+			expectedName:   domain.RateSelectionFirstAfter,
+		},
+		{
+			name:           "Unknown Policy",
+			policyName:     "cheapest",
+			lookbackMonths: 6,
+			expectedErrors: []error{domain.ErrUnknownRateSelectionPolicy},
+		},
+		{
+			name:           "Lookback Too Short",
+			policyName:     "nearest",
+			lookbackMonths: 0,
+			expectedErrors: []error{domain.ErrInvalidRateSelectionLookback},
+		},
+		{
+			name:           "Lookback Too Long",
+			policyName:     "nearest",
+			lookbackMonths: 121,
+			expectedErrors: []error{domain.ErrInvalidRateSelectionLookback},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			policy, errs := domain.NewRateSelectionPolicy(tt.policyName, tt.lookbackMonths)
+
+			if len(tt.expectedErrors) > 0 {
+				assert.Equal(t, tt.expectedErrors, errs)
+				assert.Nil(t, policy)
+				return
+			}
+			require.Empty(t, errs)
+			assert.Equal(t, tt.expectedName, policy.Name())
+			assert.Equal(t, tt.lookbackMonths, policy.LookbackMonths())
+		})
+	}
+}
+
+// TestRateSelectionPolicySelect tests the Select method of the RateSelectionPolicy implementations. It tests the
+// following scenarios:
+//
+// 1. Latest On Or Before Ignores Later Rates.
+// 2. Latest On Or Before Includes The Purchase Day.
+// 3. Latest On Or Before Outside The Window.
+// 4. Nearest Picks A Later Rate When Closer.
+// 5. Nearest Breaks Ties Before The Purchase.
+// 6. First After Ignores Earlier Rates.
+// 7. First After Outside The Window.
+// 8. Default Policy.
+func TestRateSelectionPolicySelect(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	newExchangeRate := func(rate string, dateOfRecord time.Time) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Real", rate, dateOfRecord)
+		require.Empty(t, errs)
+		return exchangeRate
+	}
+	// Quarterly exchange rates in no particular order
+	exchangeRates := []*domain.ExchangeRate{
+		newExchangeRate("5.6", date(2024, 6, 30)),
+		newExchangeRate("5.4", date(2024, 12, 31)),
+		newExchangeRate("5.5", date(2024, 3, 31)),
+		newExchangeRate("5.7", date(2024, 9, 30)),
+	}
+
+	tests := []struct {
+		name           string
+		policyName     string
+		lookbackMonths int
+		purchaseDate   time.Time
+		expectedRate   string
+	}{
+		{
+			name:           "Latest On Or Before Ignores Later Rates",
+			policyName:     domain.RateSelectionLatestOnOrBefore,
+			lookbackMonths: 6,
+			purchaseDate:   time.Date(2024, 10, 1, 15, 30, 0, 0, time.UTC),
+			expectedRate:   "5.7",
+		},
+		{
+			name:           "Latest On Or Before Includes The Purchase Day",
+			policyName:     domain.RateSelectionLatestOnOrBefore,
+			lookbackMonths: 6,
+			purchaseDate:   time.Date(2024, 6, 30, 23, 59, 0, 0, time.UTC),
+			expectedRate:   "5.6",
+		},
+		{
+			name:           "Latest On Or Before Outside The Window",
+			policyName:     domain.RateSelectionLatestOnOrBefore,
+			lookbackMonths: 1,
+			purchaseDate:   date(2024, 2, 15),
+		},
+		{
+			name:           "Nearest Picks A Later Rate When Closer",
+			policyName:     domain.RateSelectionNearest,
+			lookbackMonths: 6,
+			purchaseDate:   date(2024, 9, 20),
+			expectedRate:   "5.7",
+		},
+		{
+			name:           "Nearest Breaks Ties Before The Purchase",
+			policyName:     domain.RateSelectionNearest,
+			lookbackMonths: 6,
+			purchaseDate:   date(2024, 8, 15),
+			expectedRate:   "5.6",
+		},
+		{
+			name:           "First After Ignores Earlier Rates",
+			policyName:     domain.RateSelectionFirstAfter,
+			lookbackMonths: 6,
+			purchaseDate:   date(2024, 6, 30),
+			expectedRate:   "5.7",
+		},
+		{
+			name:           "First After Outside The Window",
+			policyName:     domain.RateSelectionFirstAfter,
+			lookbackMonths: 1,
+			purchaseDate:   date(2024, 10, 15),
+		},
+		{
+			name:         "Default Policy",
+			purchaseDate: date(2024, 12, 30),
+			expectedRate: "5.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			policy := domain.DefaultRateSelectionPolicy()
+			if tt.policyName != "" {
+				var errs []error
+				policy, errs = domain.NewRateSelectionPolicy(tt.policyName, tt.lookbackMonths)
+				require.Empty(t, errs)
+			}
+
+			selected := policy.Select(exchangeRates, tt.purchaseDate)
+
+			if tt.expectedRate == "" {
+				assert.Nil(t, selected)
+				return
+			}
+			require.NotNil(t, selected)
+			assert.Equal(t, tt.expectedRate, selected.RateText)
+		})
+	}
+}
+
+// TestRateSelectionPolicyWindow tests the Window method of the rate selection policies. It tests the following
+// scenarios:
+//
+// 1. Latest On Or Before Ends On The Purchase Day.
+// 2. Nearest Spans Both Sides.
+// 3. First After Starts The Next Day.
+func TestRateSelectionPolicyWindow(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	purchaseDate := time.Date(2024, 10, 1, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		policyName    string
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{
+			name:          "Latest On Or Before Ends On The Purchase Day",
+			policyName:    domain.RateSelectionLatestOnOrBefore,
+			expectedStart: date(2024, 4, 1),
+			expectedEnd:   date(2024, 10, 1),
+		},
+		{
+			name:          "Nearest Spans Both Sides",
+			policyName:    domain.RateSelectionNearest,
+			expectedStart: date(2024, 4, 1),
+			expectedEnd:   date(2025, 4, 1),
+		},
+		{
+			name:          "First After Starts The Next Day",
+			policyName:    domain.RateSelectionFirstAfter,
+			expectedStart: date(2024, 10, 2),
+			expectedEnd:   date(2025, 4, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			policy, errs := domain.NewRateSelectionPolicy(tt.policyName, 6)
+			require.Empty(t, errs)
+
+			windowStart, windowEnd := policy.Window(purchaseDate)
+
+			assert.Equal(t, tt.expectedStart, windowStart)
+			assert.Equal(t, tt.expectedEnd, windowEnd)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// This file contains the SupportedCurrency struct, its constructor and validation functions.
+
+// SupportedCurrency represents a currency with published exchange rates.
+type SupportedCurrency struct {
+	// CountryCurrencyDesc is the country-currency name published by the source (e.g. "Canada-Dollar").
+	CountryCurrencyDesc string
+	// CurrencyName is the currency name published by the source (e.g. "Dollar").
+	CurrencyName string
+	// CurrencyCode is the ISO 4217 code of the currency (e.g. "CAD"). Empty when the currency is not known.
+	CurrencyCode string
+	// LatestDateOfRecord is the date of record of the most recent exchange rate of the currency.
+	LatestDateOfRecord time.Time
+}
+
+// NewSupportedCurrency creates a new SupportedCurrency instance with input validation.
+func NewSupportedCurrency(countryCurrencyDesc, currencyName, currencyCode string,
+	latestDateOfRecord time.Time) (*SupportedCurrency, []error) {
+	countryCurrencyDesc = strings.TrimSpace(countryCurrencyDesc)
+	currencyName = strings.TrimSpace(currencyName)
+	currencyCode = strings.TrimSpace(currencyCode)
+
+	// Validate the inputs before constructing the object
+	if errs := ValidateSupportedCurrency(countryCurrencyDesc, currencyCode, latestDateOfRecord); len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &SupportedCurrency{
+		CountryCurrencyDesc: countryCurrencyDesc,
+		CurrencyName:        currencyName,
+		CurrencyCode:        currencyCode,
+		LatestDateOfRecord:  latestDateOfRecord,
+	}, nil
+}
+
+// ValidateSupportedCurrency validates the country-currency name, currency code and latest date of record for the
+// SupportedCurrency struct.
+func ValidateSupportedCurrency(countryCurrencyDesc, currencyCode string, latestDateOfRecord time.Time) []error {
+	errors := make([]error, 0, 3)
+
+	// Validate the country-currency name length: must not be empty
+	if countryCurrencyDesc == "" {
+		errors = append(errors, ErrSupportedCurrencyNameEmpty)
+	}
+
+	// Validate the currency code: must be empty (unknown) or a known ISO 4217 code
+	if currency, ok := LookupCurrency(currencyCode); currencyCode != "" && (!ok || currency.Code != currencyCode) {
+		errors = append(errors, ErrUnknownCurrencyCode)
+	}
+
+	// Validate the latest date of record: cannot be in the future
+	if latestDateOfRecord.After(time.Now()) {
+		errors = append(errors, ErrInvalidSupportedCurrencyDateOfRecord)
+	}
+
+	return errors
+}
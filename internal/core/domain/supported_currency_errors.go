@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to supported currency validation in the domain layer.
+
+var (
+	// ErrSupportedCurrencyNameEmpty is returned when the country-currency name of a supported currency is empty.
+	ErrSupportedCurrencyNameEmpty = errors.New("supported currency name is required; it cannot be empty")
+
+	// ErrInvalidSupportedCurrencyDateOfRecord is returned when the latest date of record of a supported currency is
+	// invalid.
+	ErrInvalidSupportedCurrencyDateOfRecord = errors.New("supported currency latest date of record is invalid; it cannot be in the future")
+)
@@ -0,0 +1,88 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the SupportedCurrency domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestNewSupportedCurrency tests the NewSupportedCurrency constructor function. It tests the following scenarios:
+//
+// 1. Valid Supported Currency.
+// 2. Valid Supported Currency Without Code.
+// 3. Empty Country-Currency Name.
+// 4. Unknown Currency Code.
+// 5. Date Of Record In The Future.
+func TestNewSupportedCurrency(t *testing.T) {
+	dateOfRecord := time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		countryCurrencyDesc string
+		currencyCode        string
+		dateOfRecord        time.Time
+		expectedErrors      []error
+	}{
+		{
+			name:                "Valid Supported Currency",
+			countryCurrencyDesc: " Canada-Dollar ",
+			currencyCode:        "CAD",
+			dateOfRecord:        dateOfRecord,
+		},
+		{
+			name:                "Valid Supported Currency Without Code",
+			countryCurrencyDesc: "Canada-Dollar",
+			dateOfRecord:        dateOfRecord,
+		},
+		{
+			name:           "Empty Country-Currency Name",
+			currencyCode:   "CAD",
+			dateOfRecord:   dateOfRecord,
+			expectedErrors: []error{domain.ErrSupportedCurrencyNameEmpty},
+		},
+		{
+			name:                "Unknown Currency Code",
+			countryCurrencyDesc: "Canada-Dollar",
+			currencyCode:        "XYZ",
+			dateOfRecord:        dateOfRecord,
+			expectedErrors:      []error{domain.ErrUnknownCurrencyCode},
+		},
+		{
+			name:                "Date Of Record In The Future",
+			countryCurrencyDesc: "Canada-Dollar",
+			currencyCode:        "CAD",
+			dateOfRecord:        time.Now().AddDate(0, 0, 1),
+			expectedErrors:      []error{domain.ErrInvalidSupportedCurrencyDateOfRecord},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			supportedCurrency, errs := domain.NewSupportedCurrency(tt.countryCurrencyDesc, "Dollar", tt.currencyCode, tt.dateOfRecord)
+
+			// Check expected errors
+			if len(tt.expectedErrors) > 0 {
+				require.Len(t, errs, len(tt.expectedErrors))
+				for i, expectedError := range tt.expectedErrors {
+					assert.ErrorIs(t, errs[i], expectedError)
+				}
+				assert.Nil(t, supportedCurrency)
+				return
+			}
+
+			assert.Empty(t, errs)
+			require.NotNil(t, supportedCurrency)
+			assert.Equal(t, "Canada-Dollar", supportedCurrency.CountryCurrencyDesc)
+			assert.Equal(t, "Dollar", supportedCurrency.CurrencyName)
+			assert.Equal(t, tt.currencyCode, supportedCurrency.CurrencyCode)
+			assert.Equal(t, tt.dateOfRecord, supportedCurrency.LatestDateOfRecord)
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package domain
 
 import (
-	"math"
+	"encoding/json"
+	"fmt"
 	"math/big"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,12 +22,33 @@ type Transaction struct {
 	Description string `json:"description"`
 	// Timestamp is the time when the transaction occurred, stored in UTC.
 	Timestamp time.Time `json:"timestamp"`
-	// AmountInUSD is the transaction amount in USD, rounded to two decimal places.
-	AmountInUSD *big.Float `json:"amount_in_usd"`
+	// AmountInUSD is the transaction amount in USD, rounded half up to two decimal places. It is zero for a
+	// transaction recorded in another currency.
+	AmountInUSD Money `json:"amount_in_usd"`
+	// Amount is the transaction amount in the currency it was recorded in, rounded half up to the minor units of that
+	// currency. It is nil for a transaction recorded in USD, whose amount is AmountInUSD.
+	Amount *Money `json:"amount,omitempty"`
+	// CurrencyCode is the ISO 4217 code of the currency the transaction was recorded in. Empty for USD.
+	CurrencyCode string `json:"currency_code,omitempty"`
+	// LockedConversions are the conversions frozen on the transaction, at most one per target currency.
+	LockedConversions []LockedConversion `json:"locked_conversions,omitempty"`
+	// Version is the revision number of the transaction, starting at 1 and incremented by every change.
+	Version int `json:"version"`
+	// UpdatedAt is the time the revision was made. It is nil for the first revision.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// DeletedAt is the time the transaction was deleted. It is only set on the tombstone of a deleted transaction.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
-// NewTransaction creates a new Transaction instance with input validation.
-func NewTransaction(description string, timestamp time.Time, amountInUSD float64) (*Transaction, []error) {
+// amountInUSDExponent is the number of decimal places transaction amounts in USD are rounded to.
+const amountInUSDExponent = 2
+
+// firstTransactionVersion is the version of a newly created transaction.
+const firstTransactionVersion = 1
+
+// NewTransaction creates a new Transaction instance with input validation. The amount is rounded half up to two
+// decimal places and its currency is set to USD.
+func NewTransaction(description string, timestamp time.Time, amountInUSD Money) (*Transaction, []error) {
 	description = strings.TrimSpace(description)
 
 	// Validate the inputs before constructing the object and stop the transaction creation if any errors are found
@@ -32,19 +56,160 @@ func NewTransaction(description string, timestamp time.Time, amountInUSD float64
 		return nil, errs
 	}
 
-	amountInUSDBigFloat := new(big.Float).SetPrec(64).SetFloat64(RoundToTwoDecimalPlaces(amountInUSD))
+	roundedAmountInUSD, err := NewMoneyFromRat(amountInUSD.Rat(), CurrencyUSD, amountInUSDExponent, RoundHalfUp)
+	if err != nil {
+		return nil, []error{err}
+	}
 	id := uuid.New()
 
 	return &Transaction{
 		ID:          id,
 		Description: description,
 		Timestamp:   timestamp.UTC(),
-		AmountInUSD: amountInUSDBigFloat,
+		AmountInUSD: roundedAmountInUSD,
+		Version:     firstTransactionVersion,
+	}, nil
+}
+
+// NewTransactionInCurrency creates a new Transaction instance recorded in the currency of the amount, with input
+// validation. The amount is rounded half up to the minor units of its currency. An amount in USD, or without a
+// currency, creates the same transaction as NewTransaction.
+func NewTransactionInCurrency(description string, timestamp time.Time, amount Money) (*Transaction, []error) {
+	currencyCode := amount.Currency()
+	if currencyCode == "" || currencyCode == CurrencyUSD {
+		return NewTransaction(description, timestamp, amount)
+	}
+	description = strings.TrimSpace(description)
+
+	// Validate the inputs before constructing the object and stop the transaction creation if any errors are found
+	if errs := ValidateTransactionInCurrency(description, timestamp, amount); len(errs) > 0 {
+		return nil, errs
+	}
+
+	roundedAmount, err := amount.Round(CurrencyExponent(currencyCode), RoundHalfUp)
+	if err != nil {
+		return nil, []error{err}
+	}
+	id := uuid.New()
+
+	return &Transaction{
+		ID:           id,
+		Description:  description,
+		Timestamp:    timestamp.UTC(),
+		Amount:       &roundedAmount,
+		CurrencyCode: currencyCode,
+		Version:      firstTransactionVersion,
 	}, nil
 }
 
+// Currency returns the ISO 4217 code of the currency the transaction was recorded in.
+func (t *Transaction) Currency() string {
+	if t.CurrencyCode == "" {
+		return CurrencyUSD
+	}
+	return t.CurrencyCode
+}
+
+// RecordedAmount returns the transaction amount in the currency it was recorded in.
+func (t *Transaction) RecordedAmount() Money {
+	if t.Amount != nil {
+		return *t.Amount
+	}
+	return t.AmountInUSD
+}
+
+// FindLockedConversion returns the conversion locked for any of the currencies, given as ISO 4217 codes or Treasury
+// country-currencies, or nil when none is locked.
+func (t *Transaction) FindLockedConversion(currencies ...string) *LockedConversion {
+	for i := range t.LockedConversions {
+		for _, currency := range currencies {
+			if currency != "" && t.LockedConversions[i].Matches(currency) {
+				return &t.LockedConversions[i]
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a transaction and restores the USD currency of its amount. Amounts persisted before the
+// Money type existed were 64-bit floats and are rounded half up to two decimal places, and transactions persisted
+// before the versions existed are at version 1.
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	// The alias drops the methods of Transaction to avoid an infinite recursion
+	type transactionAlias Transaction
+	var raw struct {
+		transactionAlias
+		AmountInUSD jsonRawAmount  `json:"amount_in_usd"`
+		Amount      *jsonRawAmount `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	value, err := parseStoredDecimal(string(raw.AmountInUSD))
+	if err != nil {
+		return err
+	}
+	amountInUSD, err := NewMoneyFromRat(value, CurrencyUSD, amountInUSDExponent, RoundHalfUp)
+	if err != nil {
+		return err
+	}
+
+	*t = Transaction(raw.transactionAlias)
+	t.AmountInUSD = amountInUSD
+	// Transactions persisted before the versions existed are at their first version
+	if t.Version == 0 {
+		t.Version = firstTransactionVersion
+	}
+
+	// Restores the currency of the amount of a transaction recorded in another currency
+	if raw.Amount != nil {
+		value, err := parseStoredDecimal(string(*raw.Amount))
+		if err != nil {
+			return err
+		}
+		amount, err := NewMoneyFromRat(value, t.CurrencyCode, CurrencyExponent(t.CurrencyCode), RoundHalfUp)
+		if err != nil {
+			return err
+		}
+		t.Amount = &amount
+	}
+	return nil
+}
+
+// jsonRawAmount holds the text of an amount encoded either as a JSON string or as a JSON number.
+type jsonRawAmount string
+
+// UnmarshalJSON keeps the text of the amount without interpreting it.
+func (a *jsonRawAmount) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	*a = jsonRawAmount(text)
+	return nil
+}
+
+// storedFloatPattern matches a 64-bit float as encoded in JSON, the form of the amounts persisted before the Money
+// type existed (e.g. "28.739999999999998" or "1e+06").
+var storedFloatPattern = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][+-]?\d+)?$`)
+
+// parseStoredDecimal parses a persisted amount into an exact rational number. Unlike ParseDecimal, it accepts the
+// exponent notation of the amounts persisted as floats.
+func parseStoredDecimal(value string) (*big.Rat, error) {
+	value = strings.TrimSpace(value)
+	if !storedFloatPattern.MatchString(value) {
+		return nil, ErrInvalidDecimal
+	}
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, ErrInvalidDecimal
+	}
+	return rat, nil
+}
+
 // ValidateTransaction validates the description, timestamp and the amount in USD for the Transaction struct.
-func ValidateTransaction(description string, timestamp time.Time, amountInUSD float64) []error {
+func ValidateTransaction(description string, timestamp time.Time, amountInUSD Money) []error {
 	errors := make([]error, 0, 5)
 
 	// Aggregate the validation errors
@@ -59,6 +224,23 @@ func ValidateTransaction(description string, timestamp time.Time, amountInUSD fl
 	return errors
 }
 
+// ValidateTransactionInCurrency validates the description, timestamp and the amount for a Transaction recorded in
+// another currency than USD.
+func ValidateTransactionInCurrency(description string, timestamp time.Time, amount Money) []error {
+	errors := make([]error, 0, 5)
+
+	// Aggregate the validation errors
+	errors = append(errors, ValidateDescription(description)...)
+	errors = append(errors, ValidateAmount(amount)...)
+
+	// Validate the timestamp: cannot be in the future
+	if timestamp.After(time.Now()) {
+		errors = append(errors, ErrInvalidTimestamp)
+	}
+
+	return errors
+}
+
 // ValidateDescription validates the transaction description.
 func ValidateDescription(description string) []error {
 	errors := make([]error, 0, 1)
@@ -77,18 +259,37 @@ func ValidateDescription(description string) []error {
 }
 
 // ValidateAmountInUSD validates the transaction amount in USD.
-func ValidateAmountInUSD(amountInUSD float64) []error {
-	errors := make([]error, 0, 1)
+func ValidateAmountInUSD(amountInUSD Money) []error {
+	errors := make([]error, 0, 2)
 
-	// Validate the amount in USD: must be positive
-	if amountInUSD <= 0 {
+	// Validate the amount currency: must be USD, or empty when the currency is implied
+	if amountInUSD.Currency() != "" && amountInUSD.Currency() != CurrencyUSD {
+		errors = append(errors, ErrAmountNotInUSD)
+	}
+
+	// Validate the amount in USD: must be positive once rounded to two decimal places
+	rounded, err := amountInUSD.Round(amountInUSDExponent, RoundHalfUp)
+	if err != nil || rounded.Sign() <= 0 {
 		errors = append(errors, ErrInvalidAmountInUSD)
 	}
 
 	return errors
 }
 
-// RoundToTwoDecimalPlaces rounds a float64 to two decimal places.
-func RoundToTwoDecimalPlaces(value float64) float64 {
-	return math.Round(value*100) / 100
+// ValidateAmount validates the transaction amount in the currency it is recorded in.
+func ValidateAmount(amount Money) []error {
+	errors := make([]error, 0, 2)
+
+	// Validate the amount currency: must be a known ISO 4217 currency
+	if _, ok := LookupCurrency(amount.Currency()); !ok {
+		errors = append(errors, fmt.Errorf("%w: %s", ErrUnknownCurrencyCode, amount.Currency()))
+	}
+
+	// Validate the amount: must be positive once rounded to the minor units of its currency
+	rounded, err := amount.Round(CurrencyExponent(amount.Currency()), RoundHalfUp)
+	if err != nil || rounded.Sign() <= 0 {
+		errors = append(errors, ErrInvalidAmount)
+	}
+
+	return errors
 }
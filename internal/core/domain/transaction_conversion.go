@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"math/big"
+	"strings"
+)
+
+// This file contains the TransactionConversion struct, its constructor and the validation of the currencies a
+// transaction is converted to in a single request.
+
+// MaxTransactionConversionCurrencies is the maximum number of currencies a transaction can be converted to in a
+// single request.
+const MaxTransactionConversionCurrencies = 20
+
+// TransactionConversion represents the conversion of a transaction to a target currency. Treasury exchange rates
+// are all quoted against USD, so a transaction recorded in another currency is converted through USD in two legs.
+// A failed conversion keeps its error, so the other currencies of a request can still be reported.
+type TransactionConversion struct {
+	// CurrencyName is the target currency as requested (ISO 4217 code, country-currency or currency name).
+	CurrencyName string
+	// ExchangeRate is the exchange rate of the target currency, nil when the target currency is USD or when the
+	// conversion failed.
+	ExchangeRate *ExchangeRate
+	// AmountInTargetCurrency is the amount rounded to the minor units of the target currency.
+	AmountInTargetCurrency Money
+	// Legs are the conversions to and from USD the amount went through, in order.
+	Legs []ConversionLeg
+	// Err is the reason the conversion failed, nil when it succeeded.
+	Err error
+	// Locked is the conversion locked on the transaction for the target currency, nil when none is locked. The other
+	// fields always hold the current conversion.
+	Locked *LockedConversion
+}
+
+// ConversionLeg represents a conversion of an amount between USD and another currency with a Treasury exchange rate.
+type ConversionLeg struct {
+	// FromCurrency is the ISO 4217 code (or the Treasury name when unknown) of the converted currency.
+	FromCurrency string
+	// ToCurrency is the ISO 4217 code (or the Treasury name when unknown) of the currency converted to.
+	ToCurrency string
+	// ExchangeRate is the exchange rate of the non-USD currency of the leg.
+	ExchangeRate *ExchangeRate
+	// Amount is the amount the leg converted to, rounded to the minor units of its currency.
+	Amount Money
+}
+
+// NewTransactionConversion converts a transaction to a target currency with the exchange rate of the currency the
+// transaction was recorded in (nil for USD) and the exchange rate of the target currency (nil for USD). A
+// conversion between two non-USD currencies is done at the full precision of both rates, and only the amounts of
+// the legs are rounded.
+func NewTransactionConversion(transaction *Transaction, currencyName string, sourceExchangeRate,
+	targetExchangeRate *ExchangeRate) (*TransactionConversion, error) {
+	conversion := &TransactionConversion{
+		CurrencyName: currencyName,
+		ExchangeRate: targetExchangeRate,
+		Legs:         make([]ConversionLeg, 0, 2),
+	}
+
+	// Converts the amount to USD first when the transaction was recorded in another currency
+	exactAmountInUSD := transaction.AmountInUSD.Rat()
+	if transaction.Currency() != CurrencyUSD {
+		if sourceExchangeRate == nil {
+			return nil, ErrSourceExchangeRateMissing
+		}
+		amount := transaction.RecordedAmount()
+		amountInUSD, err := sourceExchangeRate.ConvertToUSD(amount)
+		if err != nil {
+			return nil, err
+		}
+		exactAmountInUSD = new(big.Rat).Quo(amount.Rat(), sourceExchangeRate.Rate)
+		conversion.Legs = append(conversion.Legs, ConversionLeg{
+			FromCurrency: transaction.Currency(),
+			ToCurrency:   CurrencyUSD,
+			ExchangeRate: sourceExchangeRate,
+			Amount:       amountInUSD,
+		})
+	}
+
+	if targetExchangeRate == nil {
+		amountInUSD, err := NewMoneyFromRat(exactAmountInUSD, CurrencyUSD, amountInUSDExponent, RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+		conversion.AmountInTargetCurrency = amountInUSD
+		return conversion, nil
+	}
+
+	amountInTargetCurrency, err := NewMoneyFromRat(new(big.Rat).Mul(exactAmountInUSD, targetExchangeRate.Rate),
+		targetExchangeRate.CurrencyCode, CurrencyExponent(targetExchangeRate.CurrencyCode), RoundHalfUp)
+	if err != nil {
+		return nil, err
+	}
+	conversion.AmountInTargetCurrency = amountInTargetCurrency
+	conversion.Legs = append(conversion.Legs, ConversionLeg{
+		FromCurrency: CurrencyUSD,
+		ToCurrency:   targetExchangeRate.currency(),
+		ExchangeRate: targetExchangeRate,
+		Amount:       amountInTargetCurrency,
+	})
+
+	return conversion, nil
+}
+
+// NewConversionCurrencies trims the target currencies of a multi-currency conversion and removes the duplicates
+// (case-insensitive), keeping the order of their first occurrence, before validating them.
+func NewConversionCurrencies(currencyNames []string) ([]string, []error) {
+	uniqueCurrencyNames := make([]string, 0, len(currencyNames))
+	seen := make(map[string]struct{}, len(currencyNames))
+	for _, currencyName := range currencyNames {
+		currencyName = strings.TrimSpace(currencyName)
+		if currencyName == "" {
+			continue
+		}
+		key := strings.ToLower(currencyName)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		uniqueCurrencyNames = append(uniqueCurrencyNames, currencyName)
+	}
+
+	// Validate the currencies before returning them
+	if errs := ValidateConversionCurrencies(uniqueCurrencyNames); len(errs) > 0 {
+		return nil, errs
+	}
+
+	return uniqueCurrencyNames, nil
+}
+
+// ValidateConversionCurrencies validates the target currencies of a multi-currency conversion.
+func ValidateConversionCurrencies(currencyNames []string) []error {
+	errors := make([]error, 0, 2)
+
+	// Validate the number of currencies: between 1 and MaxTransactionConversionCurrencies
+	if len(currencyNames) == 0 {
+		errors = append(errors, ErrConversionCurrenciesEmpty)
+	}
+	if len(currencyNames) > MaxTransactionConversionCurrencies {
+		errors = append(errors, ErrTooManyConversionCurrencies)
+	}
+
+	// Validate each currency: cannot be blank
+	for _, currencyName := range currencyNames {
+		if strings.TrimSpace(currencyName) == "" {
+			errors = append(errors, ErrCurrencyNameEmpty)
+			break
+		}
+	}
+
+	return errors
+}
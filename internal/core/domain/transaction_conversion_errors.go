@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to transaction conversions in the domain layer.
+
+var (
+	// ErrConversionCurrenciesEmpty is returned when no target currency is provided for a multi-currency conversion.
+	ErrConversionCurrenciesEmpty = errors.New("conversion currencies are required; at least one must be provided")
+
+	// ErrTooManyConversionCurrencies is returned when too many target currencies are provided for a multi-currency
+	// conversion.
+	ErrTooManyConversionCurrencies = errors.New("too many conversion currencies; at most 20 can be provided")
+
+	// ErrSourceExchangeRateMissing is returned when a transaction recorded in another currency than USD is converted
+	// without the exchange rate of its currency.
+	ErrSourceExchangeRateMissing = errors.New("the exchange rate of the transaction currency is required to convert it")
+)
@@ -0,0 +1,161 @@
+package domain_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the TransactionConversion domain model and the validation of the currencies of a
+// multi-currency conversion. It uses Table Driven Tests to test different scenarios. It uses Testify for assertions
+// and runs the tests in parallel.
+
+// TestNewConversionCurrencies tests the NewConversionCurrencies constructor function. It tests the following
+// scenarios:
+//
+// 1. Valid Currencies.
+// 2. Duplicates And Blanks Removed.
+// 3. No Currency.
+// 4. Too Many Currencies.
+func TestNewConversionCurrencies(t *testing.T) {
+	tooManyCurrencies := make([]string, domain.MaxTransactionConversionCurrencies+1)
+	for i := range tooManyCurrencies {
+		tooManyCurrencies[i] = fmt.Sprintf("Currency %d", i)
+	}
+
+	tests := []struct {
+		name               string
+		currencyNames      []string
+		expectedCurrencies []string
+		expectedErrors     []error
+	}{
+		{
+			name:               "Valid Currencies",
+			currencyNames:      []string{"EUR", "GBP", "JPY"},
+			expectedCurrencies: []string{"EUR", "GBP", "JPY"},
+		},
+		{
+			name:               "Duplicates And Blanks Removed",
+			currencyNames:      []string{" EUR", "", "gbp", "eur ", "GBP"},
+			expectedCurrencies: []string{"EUR", "gbp"},
+		},
+		{
+			name:           "No Currency",
+			currencyNames:  []string{" ", ""},
+			expectedErrors: []error{domain.ErrConversionCurrenciesEmpty},
+		},
+		{
+			name:           "Too Many Currencies",
+			currencyNames:  tooManyCurrencies,
+			expectedErrors: []error{domain.ErrTooManyConversionCurrencies},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			currencyNames, errs := domain.NewConversionCurrencies(tt.currencyNames)
+
+			if len(tt.expectedErrors) > 0 {
+				assert.Equal(t, tt.expectedErrors, errs)
+				assert.Nil(t, currencyNames)
+				return
+			}
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.expectedCurrencies, currencyNames)
+		})
+	}
+}
+
+// TestNewTransactionConversion tests the NewTransactionConversion constructor function. It tests the following
+// scenarios:
+//
+// 1. USD Transaction To Another Currency.
+// 2. Cross-Currency Conversion Through USD.
+// 3. Transaction In Another Currency To USD.
+// 4. Missing Exchange Rate Of The Transaction Currency.
+func TestNewTransactionConversion(t *testing.T) {
+	timestamp := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	newExchangeRate := func(currencyName, rate, currencyCode string) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate(currencyName, rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(t, errs)
+		exchangeRate.CurrencyCode = currencyCode
+		return exchangeRate
+	}
+	euroExchangeRate := newExchangeRate("Euro", "0.897", "EUR")
+	yenExchangeRate := newExchangeRate("Yen", "143.57", "JPY")
+	pesoExchangeRate := newExchangeRate("Peso", "19.6", "MXN")
+	transactionInUSD, errs := domain.NewTransaction("Purchase", timestamp, domain.MustParseMoney("28.75", domain.CurrencyUSD))
+	require.Empty(t, errs)
+	transactionInEuros, errs := domain.NewTransactionInCurrency("Purchase", timestamp, domain.MustParseMoney("25.79", "EUR"))
+	require.Empty(t, errs)
+
+	type expectedLeg struct {
+		from, to, amount string
+	}
+	tests := []struct {
+		name               string
+		transaction        *domain.Transaction
+		sourceExchangeRate *domain.ExchangeRate
+		targetExchangeRate *domain.ExchangeRate
+		expectedAmount     string
+		expectedLegs       []expectedLeg
+		expectedErr        error
+	}{
+		{
+			name:               "USD Transaction To Another Currency",
+			transaction:        transactionInUSD,
+			targetExchangeRate: yenExchangeRate,
+			expectedAmount:     "4128",
+			expectedLegs:       []expectedLeg{{"USD", "JPY", "4128"}},
+		},
+		{
+			// 25.79 / 0.897 * 19.6 = 563.527..., while converting the rounded USD leg would give 563.50
+			name:               "Cross-Currency Conversion Through USD",
+			transaction:        transactionInEuros,
+			sourceExchangeRate: euroExchangeRate,
+			targetExchangeRate: pesoExchangeRate,
+			expectedAmount:     "563.53",
+			expectedLegs:       []expectedLeg{{"EUR", "USD", "28.75"}, {"USD", "MXN", "563.53"}},
+		},
+		{
+			name:               "Transaction In Another Currency To USD",
+			transaction:        transactionInEuros,
+			sourceExchangeRate: euroExchangeRate,
+			expectedAmount:     "28.75",
+			expectedLegs:       []expectedLeg{{"EUR", "USD", "28.75"}},
+		},
+		{
+			name:               "Missing Exchange Rate Of The Transaction Currency",
+			transaction:        transactionInEuros,
+			targetExchangeRate: yenExchangeRate,
+			expectedErr:        domain.ErrSourceExchangeRateMissing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			conversion, err := domain.NewTransactionConversion(tt.transaction, "JPY", tt.sourceExchangeRate,
+				tt.targetExchangeRate)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, conversion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAmount, conversion.AmountInTargetCurrency.String())
+			require.Len(t, conversion.Legs, len(tt.expectedLegs))
+			for i, leg := range tt.expectedLegs {
+				assert.Equal(t, leg.from, conversion.Legs[i].FromCurrency)
+				assert.Equal(t, leg.to, conversion.Legs[i].ToCurrency)
+				assert.Equal(t, leg.amount, conversion.Legs[i].Amount.String())
+			}
+		})
+	}
+}
@@ -16,4 +16,10 @@ var (
 
 	// ErrInvalidAmountInUSD is returned when the transaction amount in USD is invalid.
 	ErrInvalidAmountInUSD = errors.New("transaction amount in USD is invalid; it must be greater than 0")
+
+	// ErrInvalidAmount is returned when the transaction amount in the currency it is recorded in is invalid.
+	ErrInvalidAmount = errors.New("transaction amount is invalid; it must be greater than 0")
+
+	// ErrAmountNotInUSD is returned when the transaction amount is in a currency other than USD.
+	ErrAmountNotInUSD = errors.New("transaction amount is invalid; it must be in USD")
 )
@@ -0,0 +1,24 @@
+package domain
+
+// This file contains the TransactionImportRow struct, a row of a bulk import of transactions.
+
+// TransactionImportRow represents a row of a bulk import of transactions, with the transaction created from it or
+// the errors that rejected it.
+type TransactionImportRow struct {
+	// Line is the line of the row in the imported file, starting at 1.
+	Line int
+	// Transaction is the transaction created from the row. It is nil when the row is invalid.
+	Transaction *Transaction
+	// Errs are the errors that rejected the row, either when validating it or when saving its transaction.
+	Errs []error
+}
+
+// Accepted reports whether the transaction of the row is valid and was not rejected when saved.
+func (r *TransactionImportRow) Accepted() bool {
+	return r.Transaction != nil && len(r.Errs) == 0
+}
+
+// Reject adds an error rejecting the row.
+func (r *TransactionImportRow) Reject(err error) {
+	r.Errs = append(r.Errs, err)
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to the bulk import of transactions in the domain layer.
+
+var (
+	// ErrTransactionImportRejected is returned when an all-or-nothing import of transactions has invalid rows, so none
+	// of them is saved.
+	ErrTransactionImportRejected = errors.New("the import has invalid rows; no transaction was saved")
+
+	// ErrTransactionImportNotSaved is returned for the valid rows of an all-or-nothing import that was rejected.
+	ErrTransactionImportNotSaved = errors.New("the transaction was not saved, as other rows of the import are invalid")
+)
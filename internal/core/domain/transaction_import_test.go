@@ -0,0 +1,63 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the TransactionImportRow domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestTransactionImportRowAccepted tests the Accepted and Reject methods of the TransactionImportRow. It tests the
+// following scenarios:
+//
+// 1. Valid Row.
+// 2. Invalid Row.
+// 3. Valid Row Rejected When Saved.
+func TestTransactionImportRowAccepted(t *testing.T) {
+	transaction, errs := domain.NewTransaction("Purchase", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.70", domain.CurrencyUSD))
+	require.Empty(t, errs)
+
+	tests := []struct {
+		name        string
+		row         domain.TransactionImportRow
+		rejectWith  error
+		expectedOK  bool
+		expectedLen int
+	}{
+		{
+			name:       "Valid Row",
+			row:        domain.TransactionImportRow{Line: 2, Transaction: transaction},
+			expectedOK: true,
+		},
+		{
+			name:        "Invalid Row",
+			row:         domain.TransactionImportRow{Line: 3, Errs: []error{domain.ErrDescriptionEmpty}},
+			expectedLen: 1,
+		},
+		{
+			name:        "Valid Row Rejected When Saved",
+			row:         domain.TransactionImportRow{Line: 4, Transaction: transaction},
+			rejectWith:  domain.ErrTransactionImportNotSaved,
+			expectedLen: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			row := tt.row
+			if tt.rejectWith != nil {
+				row.Reject(tt.rejectWith)
+			}
+
+			assert.Equal(t, tt.expectedOK, row.Accepted())
+			assert.Len(t, row.Errs, tt.expectedLen)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// This file contains the TransactionQuery and TransactionPage structs, their constructor and validation functions.
+
+// Limits applied to the number of transactions returned in a single page.
+const (
+	DefaultTransactionQueryLimit = 50
+	MaxTransactionQueryLimit     = 1000
+)
+
+// TransactionQuery represents the filters and pagination parameters used to list transactions.
+type TransactionQuery struct {
+	// From keeps only the transactions that occurred at or after it, when set.
+	From *time.Time
+	// To keeps only the transactions that occurred at or before it, when set.
+	To *time.Time
+	// MinAmountInUSD keeps only the transactions with an amount greater than or equal to it, when set.
+	MinAmountInUSD *Money
+	// MaxAmountInUSD keeps only the transactions with an amount less than or equal to it, when set.
+	MaxAmountInUSD *Money
+	// DescriptionContains keeps only the transactions whose description contains it (case-insensitive), when set.
+	DescriptionContains string
+	// Cursor is the opaque position returned by a previous page. Empty means the first page.
+	Cursor string
+	// Limit is the maximum number of transactions returned in a page.
+	Limit int
+}
+
+// TransactionPage represents a page of transactions and the cursor to fetch the next one.
+type TransactionPage struct {
+	Transactions []*Transaction
+	// NextCursor is empty when there are no more transactions to fetch.
+	NextCursor string
+}
+
+// NewTransactionQuery creates a new TransactionQuery instance with input validation. A zero limit falls back to
+// DefaultTransactionQueryLimit.
+func NewTransactionQuery(from, to *time.Time, minAmountInUSD, maxAmountInUSD *Money, descriptionContains string,
+	cursor string, limit int) (*TransactionQuery, []error) {
+	descriptionContains = strings.TrimSpace(descriptionContains)
+	cursor = strings.TrimSpace(cursor)
+	if limit == 0 {
+		limit = DefaultTransactionQueryLimit
+	}
+
+	query := TransactionQuery{
+		From:                from,
+		To:                  to,
+		MinAmountInUSD:      minAmountInUSD,
+		MaxAmountInUSD:      maxAmountInUSD,
+		DescriptionContains: descriptionContains,
+		Cursor:              cursor,
+		Limit:               limit,
+	}
+
+	// Validate the inputs before constructing the object
+	if errs := ValidateTransactionQuery(query); len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &query, nil
+}
+
+// ValidateTransactionQuery validates the date range, amount range and limit of the TransactionQuery struct.
+func ValidateTransactionQuery(query TransactionQuery) []error {
+	errors := make([]error, 0, 3)
+
+	// Validate the date range: the start cannot be after the end
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		errors = append(errors, ErrInvalidTransactionQueryDateRange)
+	}
+
+	// Validate the amount range: the minimum cannot be greater than the maximum
+	if query.MinAmountInUSD != nil && query.MaxAmountInUSD != nil && query.MinAmountInUSD.Cmp(*query.MaxAmountInUSD) > 0 {
+		errors = append(errors, ErrInvalidTransactionQueryAmountRange)
+	}
+
+	// Validate the limit: must be between 1 and the maximum limit
+	if query.Limit < 1 || query.Limit > MaxTransactionQueryLimit {
+		errors = append(errors, ErrInvalidTransactionQueryLimit)
+	}
+
+	return errors
+}
+
+// Matches reports whether the transaction satisfies all the filters of the query. Pagination is not considered.
+func (q TransactionQuery) Matches(transaction Transaction) bool {
+	if q.From != nil && transaction.Timestamp.Before(*q.From) {
+		return false
+	}
+	if q.To != nil && transaction.Timestamp.After(*q.To) {
+		return false
+	}
+	// The amount filters are in USD, so they only match the transactions recorded in USD
+	if (q.MinAmountInUSD != nil || q.MaxAmountInUSD != nil) && transaction.Currency() != CurrencyUSD {
+		return false
+	}
+	if q.MinAmountInUSD != nil && transaction.AmountInUSD.Cmp(*q.MinAmountInUSD) < 0 {
+		return false
+	}
+	if q.MaxAmountInUSD != nil && transaction.AmountInUSD.Cmp(*q.MaxAmountInUSD) > 0 {
+		return false
+	}
+	if q.DescriptionContains != "" &&
+		!strings.Contains(strings.ToLower(transaction.Description), strings.ToLower(q.DescriptionContains)) {
+		return false
+	}
+	return true
+}
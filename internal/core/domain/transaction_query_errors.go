@@ -0,0 +1,19 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to transaction query validation in the domain layer.
+
+var (
+	// ErrInvalidTransactionQueryDateRange is returned when the query start date is after the end date.
+	ErrInvalidTransactionQueryDateRange = errors.New("transaction query date range is invalid; from cannot be after to")
+
+	// ErrInvalidTransactionQueryAmountRange is returned when the query minimum amount is greater than the maximum.
+	ErrInvalidTransactionQueryAmountRange = errors.New("transaction query amount range is invalid; the minimum cannot be greater than the maximum")
+
+	// ErrInvalidTransactionQueryLimit is returned when the query page limit is out of bounds.
+	ErrInvalidTransactionQueryLimit = errors.New("transaction query limit is invalid; it must be between 1 and 1000")
+
+	// ErrInvalidTransactionCursor is returned when the pagination cursor cannot be decoded.
+	ErrInvalidTransactionCursor = errors.New("transaction query cursor is invalid")
+)
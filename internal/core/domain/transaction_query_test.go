@@ -0,0 +1,195 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the TransactionQuery domain model. It uses Table Driven Tests to test different
+// scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// TestNewTransactionQuery tests the NewTransactionQuery constructor function. It tests the following scenarios:
+//
+// 1. Valid Query Without Filters.
+// 2. Valid Query With All Filters.
+// 3. Inverted Date Range.
+// 4. Inverted Amount Range.
+// 5. Limit Too High.
+// 6. Negative Limit.
+func TestNewTransactionQuery(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	ten := domain.MustParseMoney("10", domain.CurrencyUSD)
+	twenty := domain.MustParseMoney("20", domain.CurrencyUSD)
+
+	tests := []struct {
+		name           string
+		from           *time.Time
+		to             *time.Time
+		minAmount      *domain.Money
+		maxAmount      *domain.Money
+		limit          int
+		expectedLimit  int
+		expectedErrors []error
+	}{
+		{
+			name:           "Valid Query Without Filters",
+			expectedLimit:  domain.DefaultTransactionQueryLimit,
+			expectedErrors: []error{},
+		},
+		{
+			name:           "Valid Query With All Filters",
+			from:           &from,
+			to:             &to,
+			minAmount:      &ten,
+			maxAmount:      &twenty,
+			limit:          10,
+			expectedLimit:  10,
+			expectedErrors: []error{},
+		},
+		{
+			name:           "Inverted Date Range",
+			from:           &to,
+			to:             &from,
+			expectedErrors: []error{domain.ErrInvalidTransactionQueryDateRange},
+		},
+		{
+			name:           "Inverted Amount Range",
+			minAmount:      &twenty,
+			maxAmount:      &ten,
+			expectedErrors: []error{domain.ErrInvalidTransactionQueryAmountRange},
+		},
+		{
+			name:           "Limit Too High",
+			limit:          domain.MaxTransactionQueryLimit + 1,
+			expectedErrors: []error{domain.ErrInvalidTransactionQueryLimit},
+		},
+		{
+			name:           "Negative Limit",
+			limit:          -1,
+			expectedErrors: []error{domain.ErrInvalidTransactionQueryLimit},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			query, errs := domain.NewTransactionQuery(tt.from, tt.to, tt.minAmount, tt.maxAmount, " food ", "", tt.limit)
+
+			// Check expected errors
+			if len(tt.expectedErrors) > 0 {
+				require.Len(t, errs, len(tt.expectedErrors))
+				for i, expectedError := range tt.expectedErrors {
+					assert.ErrorIs(t, errs[i], expectedError)
+				}
+				assert.Nil(t, query)
+				return
+			}
+
+			assert.Empty(t, errs)
+			require.NotNil(t, query)
+			assert.Equal(t, tt.expectedLimit, query.Limit)
+			assert.Equal(t, "food", query.DescriptionContains)
+		})
+	}
+}
+
+// TestTransactionQueryMatches tests the Matches method of the TransactionQuery. It tests the following scenarios:
+//
+// 1. Empty Query Matches Everything.
+// 2. Before The Date Range.
+// 3. After The Date Range.
+// 4. Inside The Date Range.
+// 5. Below The Minimum Amount.
+// 6. Above The Maximum Amount.
+// 7. Description Contains (Case-Insensitive).
+// 8. Description Does Not Contain.
+// 9. Amount Filter Skips Other Currencies.
+func TestTransactionQueryMatches(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	transaction := domain.Transaction{
+		Description: "Lunch at the Airport",
+		Timestamp:   time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC),
+		AmountInUSD: domain.MustParseMoney("42.50", domain.CurrencyUSD),
+	}
+	amountInEuros := domain.MustParseMoney("42.50", "EUR")
+	transactionInEuros := domain.Transaction{
+		Description:  "Lunch in Paris",
+		Timestamp:    time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC),
+		Amount:       &amountInEuros,
+		CurrencyCode: "EUR",
+	}
+	forty := domain.MustParseMoney("40", domain.CurrencyUSD)
+	fifty := domain.MustParseMoney("50", domain.CurrencyUSD)
+
+	tests := []struct {
+		name        string
+		query       domain.TransactionQuery
+		transaction *domain.Transaction
+		expected    bool
+	}{
+		{
+			name:     "Empty Query Matches Everything",
+			query:    domain.TransactionQuery{},
+			expected: true,
+		},
+		{
+			name:     "Before The Date Range",
+			query:    domain.TransactionQuery{From: &to},
+			expected: false,
+		},
+		{
+			name:     "After The Date Range",
+			query:    domain.TransactionQuery{To: &from},
+			expected: false,
+		},
+		{
+			name:     "Inside The Date Range",
+			query:    domain.TransactionQuery{From: &from, To: &to},
+			expected: true,
+		},
+		{
+			name:     "Below The Minimum Amount",
+			query:    domain.TransactionQuery{MinAmountInUSD: &fifty},
+			expected: false,
+		},
+		{
+			name:     "Above The Maximum Amount",
+			query:    domain.TransactionQuery{MaxAmountInUSD: &forty},
+			expected: false,
+		},
+		{
+			name:     "Description Contains (Case-Insensitive)",
+			query:    domain.TransactionQuery{DescriptionContains: "airport"},
+			expected: true,
+		},
+		{
+			name:     "Description Does Not Contain",
+			query:    domain.TransactionQuery{DescriptionContains: "dinner"},
+			expected: false,
+		},
+		{
+			name:        "Amount Filter Skips Other Currencies",
+			query:       domain.TransactionQuery{MaxAmountInUSD: &fifty},
+			transaction: &transactionInEuros,
+			expected:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.transaction != nil {
+				assert.Equal(t, tt.expected, tt.query.Matches(*tt.transaction))
+				return
+			}
+			assert.Equal(t, tt.expected, tt.query.Matches(transaction))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+)
+
+// This file contains the revisions of a transaction: its updates, its locked conversions and its deletion.
+
+// Deleted reports whether the transaction is the tombstone of a deleted transaction.
+func (t *Transaction) Deleted() bool {
+	return t.DeletedAt != nil
+}
+
+// Revise creates the next revision of the transaction with the description, timestamp and amount of a revised
+// transaction. The ID and the locked conversions of the transaction are kept, so the timestamp and the amount of a
+// transaction with locked conversions cannot be revised, as the locked figures would no longer match them.
+func (t *Transaction) Revise(revised Transaction, revisedAt time.Time) (*Transaction, error) {
+	if t.Deleted() {
+		return nil, ErrTransactionDeleted
+	}
+	if len(t.LockedConversions) > 0 && (!revised.Timestamp.Equal(t.Timestamp) ||
+		revised.Currency() != t.Currency() || revised.RecordedAmount().Cmp(t.RecordedAmount()) != 0) {
+		return nil, ErrTransactionConversionsLocked
+	}
+
+	revision := revised
+	revision.ID = t.ID
+	revision.LockedConversions = t.LockedConversions
+	revision.DeletedAt = nil
+	t.nextRevision(&revision, revisedAt)
+	return &revision, nil
+}
+
+// WithLockedConversion creates the next revision of the transaction with a conversion locked on it. A conversion to
+// a currency can only be locked once.
+func (t *Transaction) WithLockedConversion(lockedConversion LockedConversion, revisedAt time.Time) (*Transaction, error) {
+	if t.Deleted() {
+		return nil, ErrTransactionDeleted
+	}
+	// A locked conversion is never replaced
+	if t.FindLockedConversion(lockedConversion.Currency, lockedConversion.CountryCurrencyDesc) != nil {
+		return nil, ErrConversionAlreadyLocked
+	}
+
+	revision := *t
+	revision.LockedConversions = append(append([]LockedConversion{}, t.LockedConversions...), lockedConversion)
+	t.nextRevision(&revision, revisedAt)
+	return &revision, nil
+}
+
+// Delete creates the tombstone of the transaction, its last revision. The tombstone keeps the figures of the
+// transaction so its history stays complete.
+func (t *Transaction) Delete(deletedAt time.Time) (*Transaction, error) {
+	if t.Deleted() {
+		return nil, ErrTransactionDeleted
+	}
+
+	tombstone := *t
+	t.nextRevision(&tombstone, deletedAt)
+	tombstone.DeletedAt = tombstone.UpdatedAt
+	return &tombstone, nil
+}
+
+// nextRevision sets the version and the revision time of a revision following the transaction.
+func (t *Transaction) nextRevision(revision *Transaction, revisedAt time.Time) {
+	revisedAt = revisedAt.UTC()
+	revision.Version = t.Version + 1
+	revision.UpdatedAt = &revisedAt
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "errors"
+
+// This file defines error variables related to the revisions of transactions in the domain layer.
+
+var (
+	// ErrTransactionDeleted is returned when a deleted transaction is revised or deleted again.
+	ErrTransactionDeleted = errors.New("the transaction is deleted")
+
+	// ErrTransactionVersionMismatch is returned when a transaction is revised from another version than its current
+	// one, because it was revised concurrently.
+	ErrTransactionVersionMismatch = errors.New("the transaction version does not match its current version")
+
+	// ErrTransactionConversionsLocked is returned when the timestamp or the amount of a transaction with locked
+	// conversions is revised.
+	ErrTransactionConversionsLocked = errors.New("the timestamp and the amount of a transaction with locked " +
+		"conversions cannot be changed")
+)
@@ -0,0 +1,193 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the revisions of the Transaction domain model. It uses Table Driven Tests to test
+// different scenarios. It uses Testify for assertions and runs the tests in parallel.
+
+// newRevisionFixture creates a transaction of 25.70 USD, with a conversion locked on it when locked is true.
+func newRevisionFixture(t *testing.T, locked bool) *domain.Transaction {
+	transaction, errs := domain.NewTransaction("Purchase", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.70", domain.CurrencyUSD))
+	require.Empty(t, errs)
+	if locked {
+		_, lockedConversion := newLockedConversionFixture(t)
+		transaction.LockedConversions = []domain.LockedConversion{*lockedConversion}
+	}
+	return transaction
+}
+
+// TestTransactionRevise tests the Revise method of the Transaction. It tests the following scenarios:
+//
+// 1. Description, Timestamp And Amount Revised.
+// 2. Description Revised With Locked Conversions.
+// 3. Amount Revised With Locked Conversions.
+// 4. Currency Revised With Locked Conversions.
+// 5. Deleted Transaction.
+func TestTransactionRevise(t *testing.T) {
+	revisedAt := time.Date(2024, 10, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		locked        bool
+		deleted       bool
+		description   string
+		amount        domain.Money
+		expectedError error
+	}{
+		{
+			name:        "Description, Timestamp And Amount Revised",
+			description: "Corrected purchase",
+			amount:      domain.MustParseMoney("12.34", "EUR"),
+		},
+		{
+			name:        "Description Revised With Locked Conversions",
+			locked:      true,
+			description: "Corrected purchase",
+			amount:      domain.MustParseMoney("25.70", domain.CurrencyUSD),
+		},
+		{
+			name:          "Amount Revised With Locked Conversions",
+			locked:        true,
+			description:   "Purchase",
+			amount:        domain.MustParseMoney("25.71", domain.CurrencyUSD),
+			expectedError: domain.ErrTransactionConversionsLocked,
+		},
+		{
+			name:          "Currency Revised With Locked Conversions",
+			locked:        true,
+			description:   "Purchase",
+			amount:        domain.MustParseMoney("25.70", "EUR"),
+			expectedError: domain.ErrTransactionConversionsLocked,
+		},
+		{
+			name:          "Deleted Transaction",
+			deleted:       true,
+			description:   "Corrected purchase",
+			amount:        domain.MustParseMoney("25.70", domain.CurrencyUSD),
+			expectedError: domain.ErrTransactionDeleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			transaction := newRevisionFixture(t, tt.locked)
+			if tt.deleted {
+				tombstone, err := transaction.Delete(revisedAt)
+				require.NoError(t, err)
+				transaction = tombstone
+			}
+			timestamp := transaction.Timestamp
+			if !tt.locked {
+				timestamp = timestamp.Add(-time.Hour)
+			}
+			revised, errs := domain.NewTransactionInCurrency(tt.description, timestamp, tt.amount)
+			require.Empty(t, errs)
+
+			revision, err := transaction.Revise(*revised, revisedAt)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, revision)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, transaction.ID, revision.ID)
+			assert.Equal(t, 2, revision.Version)
+			assert.Equal(t, revisedAt, *revision.UpdatedAt)
+			assert.Equal(t, tt.description, revision.Description)
+			assert.Equal(t, timestamp, revision.Timestamp)
+			assert.Zero(t, tt.amount.Cmp(revision.RecordedAmount()))
+			assert.Equal(t, transaction.LockedConversions, revision.LockedConversions)
+			// The revised transaction is left unchanged
+			assert.Equal(t, 1, transaction.Version)
+			assert.Nil(t, transaction.UpdatedAt)
+		})
+	}
+}
+
+// TestTransactionWithLockedConversion tests the WithLockedConversion method of the Transaction. It tests the
+// following scenarios:
+//
+// 1. Conversion Locked.
+// 2. Conversion Already Locked.
+// 3. Deleted Transaction.
+func TestTransactionWithLockedConversion(t *testing.T) {
+	revisedAt := time.Date(2024, 10, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		locked        bool
+		deleted       bool
+		expectedError error
+	}{
+		{
+			name: "Conversion Locked",
+		},
+		{
+			name:          "Conversion Already Locked",
+			locked:        true,
+			expectedError: domain.ErrConversionAlreadyLocked,
+		},
+		{
+			name:          "Deleted Transaction",
+			deleted:       true,
+			expectedError: domain.ErrTransactionDeleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			transaction := newRevisionFixture(t, tt.locked)
+			if tt.deleted {
+				tombstone, err := transaction.Delete(revisedAt)
+				require.NoError(t, err)
+				transaction = tombstone
+			}
+			_, lockedConversion := newLockedConversionFixture(t)
+
+			revision, err := transaction.WithLockedConversion(*lockedConversion, revisedAt)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, revision)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 2, revision.Version)
+			assert.Equal(t, []domain.LockedConversion{*lockedConversion}, revision.LockedConversions)
+			assert.Empty(t, transaction.LockedConversions)
+		})
+	}
+}
+
+// TestTransactionDelete tests the Delete method of the Transaction. It tests the following scenarios:
+//
+// 1. Tombstone Keeps The Figures.
+// 2. Deleted Twice.
+func TestTransactionDelete(t *testing.T) {
+	t.Parallel()
+	deletedAt := time.Date(2024, 10, 2, 9, 0, 0, 0, time.UTC)
+	transaction := newRevisionFixture(t, false)
+
+	tombstone, err := transaction.Delete(deletedAt)
+	require.NoError(t, err)
+	assert.True(t, tombstone.Deleted())
+	assert.False(t, transaction.Deleted())
+	assert.Equal(t, deletedAt, *tombstone.DeletedAt)
+	assert.Equal(t, 2, tombstone.Version)
+	assert.Equal(t, transaction.AmountInUSD, tombstone.AmountInUSD)
+
+	again, err := tombstone.Delete(deletedAt)
+	assert.ErrorIs(t, err, domain.ErrTransactionDeleted)
+	assert.Nil(t, again)
+}
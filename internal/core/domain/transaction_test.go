@@ -1,11 +1,11 @@
 package domain_test
 
 import (
-	"math/big"
 	"testing"
 	"time"
 
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,7 +25,7 @@ func TestNewTransaction(t *testing.T) {
 		name                string
 		description         string
 		timestamp           time.Time
-		amountInUSD         float64
+		amountInUSD         domain.Money
 		expectedErrors      []error
 		expectedTransaction *domain.Transaction
 	}{
@@ -33,40 +33,40 @@ func TestNewTransaction(t *testing.T) {
 			name:           "Valid Transaction",
 			description:    "Valid Transaction",
 			timestamp:      time.Now().UTC(),
-			amountInUSD:    500.50,
+			amountInUSD:    domain.MustParseMoney("500.50", ""),
 			expectedErrors: []error{},
 			expectedTransaction: &domain.Transaction{
 				Description: "Valid Transaction",
 				Timestamp:   time.Now().UTC(),
-				AmountInUSD: new(big.Float).SetPrec(64).SetFloat64(500.50),
+				AmountInUSD: domain.MustParseMoney("500.50", domain.CurrencyUSD),
 			},
 		},
 		{
 			name:           "Empty Description",
 			description:    "",
 			timestamp:      time.Now().UTC(),
-			amountInUSD:    100.0,
+			amountInUSD:    domain.MustParseMoney("100.0", ""),
 			expectedErrors: []error{domain.ErrDescriptionEmpty},
 		},
 		{
 			name:           "Description Too Long",
 			description:    "This description is way too long and should trigger a validation error",
 			timestamp:      time.Now().UTC(),
-			amountInUSD:    250.0,
+			amountInUSD:    domain.MustParseMoney("250.0", ""),
 			expectedErrors: []error{domain.ErrDescriptionTooLong},
 		},
 		{
 			name:           "Negative Amount In USD",
 			description:    "Negative Amount In USD",
 			timestamp:      time.Now().UTC(),
-			amountInUSD:    -50.0,
+			amountInUSD:    domain.MustParseMoney("-50.0", ""),
 			expectedErrors: []error{domain.ErrInvalidAmountInUSD},
 		},
 		{
 			name:           "Correct Format But Future Timestamp",
 			description:    "Correct Format But Future Timestamp",
 			timestamp:      time.Now().Add(24 * time.Hour),
-			amountInUSD:    499.0,
+			amountInUSD:    domain.MustParseMoney("499.0", ""),
 			expectedErrors: []error{domain.ErrInvalidTimestamp},
 		},
 	}
@@ -101,6 +101,146 @@ func TestNewTransaction(t *testing.T) {
 	}
 }
 
+// TestTransactionJSON tests the JSON encoding and decoding of the Transaction. It tests the following scenarios:
+//
+// 1. Rounds The Amount Half Up On Creation.
+// 2. Round Trip.
+// 3. Legacy Float Amount.
+// 4. Legacy Float Amount In Exponent Notation.
+// 5. Round Trip In Another Currency.
+func TestTransactionJSON(t *testing.T) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	t.Run("Rounds The Amount Half Up On Creation", func(t *testing.T) {
+		t.Parallel()
+		transaction, errs := domain.NewTransaction("Rounding", time.Now(), domain.MustParseMoney("28.745", ""))
+		require.Empty(t, errs)
+		assert.Equal(t, "28.75", transaction.AmountInUSD.String())
+		assert.Equal(t, domain.CurrencyUSD, transaction.AmountInUSD.Currency())
+	})
+
+	t.Run("Round Trip", func(t *testing.T) {
+		t.Parallel()
+		transaction, errs := domain.NewTransaction("Round Trip", time.Now(), domain.MustParseMoney("10.10", ""))
+		require.Empty(t, errs)
+
+		data, err := json.Marshal(transaction)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"amount_in_usd":"10.10"`)
+
+		var decoded domain.Transaction
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, transaction.ID, decoded.ID)
+		assert.Equal(t, transaction.Description, decoded.Description)
+		assert.Equal(t, transaction.AmountInUSD, decoded.AmountInUSD)
+	})
+
+	t.Run("Legacy Float Amount", func(t *testing.T) {
+		t.Parallel()
+		var decoded domain.Transaction
+		data := `{"id":"8f1b7a52-5b0e-4a4c-9d42-1f3a0b5c7e11","description":"Legacy","timestamp":"2024-01-01T00:00:00Z",` +
+			`"amount_in_usd":"28.739999999999998437"}`
+		require.NoError(t, json.Unmarshal([]byte(data), &decoded))
+		assert.Equal(t, "28.74", decoded.AmountInUSD.String())
+		assert.Equal(t, domain.CurrencyUSD, decoded.AmountInUSD.Currency())
+	})
+
+	t.Run("Legacy Float Amount In Exponent Notation", func(t *testing.T) {
+		t.Parallel()
+		var decoded domain.Transaction
+		data := `{"id":"8f1b7a52-5b0e-4a4c-9d42-1f3a0b5c7e11","description":"Legacy","timestamp":"2024-01-01T00:00:00Z",` +
+			`"amount_in_usd":"1e+06"}`
+		require.NoError(t, json.Unmarshal([]byte(data), &decoded))
+		assert.Equal(t, "1000000.00", decoded.AmountInUSD.String())
+	})
+
+	t.Run("Round Trip In Another Currency", func(t *testing.T) {
+		t.Parallel()
+		transaction, errs := domain.NewTransactionInCurrency("Round Trip", time.Now(), domain.MustParseMoney("1500", "JPY"))
+		require.Empty(t, errs)
+
+		data, err := json.Marshal(transaction)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"amount":"1500","currency_code":"JPY"`)
+
+		var decoded domain.Transaction
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.NotNil(t, decoded.Amount)
+		assert.Equal(t, *transaction.Amount, *decoded.Amount)
+		assert.Equal(t, "JPY", decoded.Currency())
+		assert.True(t, decoded.AmountInUSD.IsZero())
+	})
+}
+
+// TestNewTransactionInCurrency tests the NewTransactionInCurrency constructor function. It tests the following
+// scenarios:
+//
+// 1. Amount Rounded To The Minor Units Of Its Currency.
+// 2. Currency Without Minor Units (JPY).
+// 3. Amount In USD.
+// 4. Unknown Currency.
+// 5. Amount Zero Once Rounded.
+func TestNewTransactionInCurrency(t *testing.T) {
+	timestamp := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		amount           domain.Money
+		expectedAmount   string
+		expectedCurrency string
+		expectedErrors   []error
+	}{
+		{
+			name:             "Amount Rounded To The Minor Units Of Its Currency",
+			amount:           domain.MustParseMoney("25.745", "EUR"),
+			expectedAmount:   "25.75",
+			expectedCurrency: "EUR",
+		},
+		{
+			name:             "Currency Without Minor Units (JPY)",
+			amount:           domain.MustParseMoney("1500.5", "JPY"),
+			expectedAmount:   "1501",
+			expectedCurrency: "JPY",
+		},
+		{
+			name:             "Amount In USD",
+			amount:           domain.MustParseMoney("10.005", domain.CurrencyUSD),
+			expectedAmount:   "10.01",
+			expectedCurrency: domain.CurrencyUSD,
+		},
+		{
+			name:           "Unknown Currency",
+			amount:         domain.MustParseMoney("10", "ABC"),
+			expectedErrors: []error{domain.ErrUnknownCurrencyCode},
+		},
+		{
+			name:           "Amount Zero Once Rounded",
+			amount:         domain.MustParseMoney("0.4", "JPY"),
+			expectedErrors: []error{domain.ErrInvalidAmount},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			transaction, errs := domain.NewTransactionInCurrency("Purchase", timestamp, tt.amount)
+
+			if len(tt.expectedErrors) > 0 {
+				require.Len(t, errs, len(tt.expectedErrors))
+				for i, expectedError := range tt.expectedErrors {
+					assert.ErrorIs(t, errs[i], expectedError)
+				}
+				assert.Nil(t, transaction)
+				return
+			}
+			require.Empty(t, errs)
+			assert.Equal(t, tt.expectedAmount, transaction.RecordedAmount().String())
+			assert.Equal(t, tt.expectedCurrency, transaction.RecordedAmount().Currency())
+			assert.Equal(t, tt.expectedCurrency, transaction.Currency())
+		})
+	}
+}
+
 // TestValidateDescription tests the ValidateDescription function. It tests the following scenarios:
 //
 // 1. Valid Description.
@@ -155,22 +295,22 @@ func TestValidateDescription(t *testing.T) {
 func TestValidateAmountInUSD(t *testing.T) {
 	tests := []struct {
 		name           string
-		amountInUSD    float64
+		amountInUSD    domain.Money
 		expectedErrors []error
 	}{
 		{
 			name:           "Valid Amount In USD",
-			amountInUSD:    10.5,
+			amountInUSD:    domain.MustParseMoney("10.5", ""),
 			expectedErrors: []error{},
 		},
 		{
 			name:           "Zero Amount In USD",
-			amountInUSD:    0.0,
+			amountInUSD:    domain.MustParseMoney("0.0", ""),
 			expectedErrors: []error{domain.ErrInvalidAmountInUSD},
 		},
 		{
 			name:           "Negative Amount In USD",
-			amountInUSD:    -5.0,
+			amountInUSD:    domain.MustParseMoney("-5.0", ""),
 			expectedErrors: []error{domain.ErrInvalidAmountInUSD},
 		},
 	}
@@ -192,52 +332,3 @@ func TestValidateAmountInUSD(t *testing.T) {
 		})
 	}
 }
-
-// TestRoundToTwoDecimalPlaces tests the RoundToTwoDecimalPlaces function. It tests the following scenarios:
-//
-// 1. Normal Rounding.
-// 2. Round Down.
-// 3. Exact Two Decimals.
-// 4. Negative Rounding.
-// 5. Boundary Rounding Up.
-func TestRoundToTwoDecimalPlaces(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    float64
-		expected float64
-	}{
-		{
-			name:     "Normal Rounding",
-			input:    123.456,
-			expected: 123.46,
-		},
-		{
-			name:     "Round Down",
-			input:    123.454,
-			expected: 123.45,
-		},
-		{
-			name:     "Exact Two Decimals",
-			input:    123.45,
-			expected: 123.45,
-		},
-		{
-			name:     "Negative Rounding",
-			input:    -123.456,
-			expected: -123.46,
-		},
-		{
-			name:     "Boundary Rounding Up",
-			input:    0.005,
-			expected: 0.01,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			actual := domain.RoundToTwoDecimalPlaces(tt.input)
-			assert.Equal(t, tt.expected, actual)
-		})
-	}
-}
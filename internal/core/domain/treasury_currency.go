@@ -0,0 +1,298 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// This file contains the mapping between the Treasury country-currency descriptions and the ISO 4217 codes.
+
+// treasuryCurrencyCodes maps the Treasury "country_currency_desc" values to their ISO 4217 codes. Keep it in sync
+// with the currencies published by the Treasury Reporting Rates of Exchange dataset.
+var treasuryCurrencyCodes = map[string]string{
+	"Afghanistan-Afghani": "AFN",
+	"Albania-Lek":         "ALL",
+	"Algeria-Dinar":       "DZD",
+	"Angola-Kwanza":       "AOA",
+	"Antigua & Barbuda-East Caribbean Dollar": "XCD",
+	"Argentina-Peso":                     "ARS",
+	"Armenia-Dram":                       "AMD",
+	"Australia-Dollar":                   "AUD",
+	"Austria-Euro":                       "EUR",
+	"Azerbaijan-Manat":                   "AZN",
+	"Bahamas-Dollar":                     "BSD",
+	"Bahrain-Dinar":                      "BHD",
+	"Bangladesh-Taka":                    "BDT",
+	"Barbados-Dollar":                    "BBD",
+	"Belarus-New Ruble":                  "BYN",
+	"Belgium-Euro":                       "EUR",
+	"Belize-Dollar":                      "BZD",
+	"Benin-Cfa Franc":                    "XOF",
+	"Bermuda-Dollar":                     "BMD",
+	"Bolivia-Boliviano":                  "BOB",
+	"Bosnia-Marka":                       "BAM",
+	"Botswana-Pula":                      "BWP",
+	"Brazil-Real":                        "BRL",
+	"Brunei-Dollar":                      "BND",
+	"Bulgaria-Lev":                       "BGN",
+	"Burkina Faso-Cfa Franc":             "XOF",
+	"Burundi-Franc":                      "BIF",
+	"Cambodia-Riel":                      "KHR",
+	"Cameroon-Cfa Franc":                 "XAF",
+	"Canada-Dollar":                      "CAD",
+	"Cape Verde-Escudo":                  "CVE",
+	"Cayman Island-Dollar":               "KYD",
+	"Central African Republic-Cfa Franc": "XAF",
+	"Chad-Cfa Franc":                     "XAF",
+	"Chile-Peso":                         "CLP",
+	"China-Renminbi":                     "CNY",
+	"Colombia-Peso":                      "COP",
+	"Comoros-Franc":                      "KMF",
+	"Congo-Cfa Franc":                    "XAF",
+	"Costa Rica-Colon":                   "CRC",
+	"Cote D'Ivoire-Cfa Franc":            "XOF",
+	"Croatia-Euro":                       "EUR",
+	"Cuba-Peso":                          "CUP",
+	"Cyprus-Euro":                        "EUR",
+	"Czech Republic-Koruna":              "CZK",
+	"Dem. Rep. Of Congo-Congolese Franc": "CDF",
+	"Denmark-Krone":                      "DKK",
+	"Djibouti-Franc":                     "DJF",
+	"Dominican Republic-Peso":            "DOP",
+	"Ecuador-Dolares":                    "USD",
+	"Egypt-Pound":                        "EGP",
+	"El Salvador-Dollar":                 "USD",
+	"Equatorial Guinea-Cfa Franc":        "XAF",
+	"Eritrea-Nakfa":                      "ERN",
+	"Estonia-Euro":                       "EUR",
+	"Eswatini-Lilangeni":                 "SZL",
+	"Ethiopia-Birr":                      "ETB",
+	"Euro Zone-Euro":                     "EUR",
+	"Fiji-Dollar":                        "FJD",
+	"Finland-Euro":                       "EUR",
+	"France-Euro":                        "EUR",
+	"Gabon-Cfa Franc":                    "XAF",
+	"Gambia-Dalasi":                      "GMD",
+	"Georgia-Lari":                       "GEL",
+	"Germany-Euro":                       "EUR",
+	"Ghana-Cedi":                         "GHS",
+	"Greece-Euro":                        "EUR",
+	"Grenada-East Caribbean Dollar":      "XCD",
+	"Guatemala-Quetzal":                  "GTQ",
+	"Guinea Bissau-Cfa Franc":            "XOF",
+	"Guinea-Franc":                       "GNF",
+	"Guyana-Dollar":                      "GYD",
+	"Haiti-Gourde":                       "HTG",
+	"Honduras-Lempira":                   "HNL",
+	"Hong Kong-Dollar":                   "HKD",
+	"Hungary-Forint":                     "HUF",
+	"Iceland-Krona":                      "ISK",
+	"India-Rupee":                        "INR",
+	"Indonesia-Rupiah":                   "IDR",
+	"Iran-Rial":                          "IRR",
+	"Iraq-Dinar":                         "IQD",
+	"Ireland-Euro":                       "EUR",
+	"Israel-Shekel":                      "ILS",
+	"Italy-Euro":                         "EUR",
+	"Jamaica-Dollar":                     "JMD",
+	"Japan-Yen":                          "JPY",
+	"Jordan-Dinar":                       "JOD",
+	"Kazakhstan-Tenge":                   "KZT",
+	"Kenya-Shilling":                     "KES",
+	"Korea-Won":                          "KRW",
+	"Kosovo-Euro":                        "EUR",
+	"Kuwait-Dinar":                       "KWD",
+	"Kyrgyzstan-Som":                     "KGS",
+	"Laos-Kip":                           "LAK",
+	"Latvia-Euro":                        "EUR",
+	"Lebanon-Pound":                      "LBP",
+	"Lesotho-Maloti":                     "LSL",
+	"Liberia-Dollar":                     "LRD",
+	"Libya-Dinar":                        "LYD",
+	"Lithuania-Euro":                     "EUR",
+	"Luxembourg-Euro":                    "EUR",
+	"Macao-Mop":                          "MOP",
+	"Madagascar-Ariary":                  "MGA",
+	"Malawi-Kwacha":                      "MWK",
+	"Malaysia-Ringgit":                   "MYR",
+	"Maldives-Rufiyaa":                   "MVR",
+	"Mali-Cfa Franc":                     "XOF",
+	"Malta-Euro":                         "EUR",
+	"Marshall Islands-Dollar":            "USD",
+	"Mauritania-Ouguiya":                 "MRU",
+	"Mauritius-Rupee":                    "MUR",
+	"Mexico-Peso":                        "MXN",
+	"Micronesia-Dollar":                  "USD",
+	"Moldova-Leu":                        "MDL",
+	"Mongolia-Tugrik":                    "MNT",
+	"Montenegro-Euro":                    "EUR",
+	"Morocco-Dirham":                     "MAD",
+	"Mozambique-Metical":                 "MZN",
+	"Myanmar-Kyat":                       "MMK",
+	"Namibia-Dollar":                     "NAD",
+	"Nepal-Rupee":                        "NPR",
+	"Netherlands Antilles-Guilder":       "ANG",
+	"Netherlands-Euro":                   "EUR",
+	"New Zealand-Dollar":                 "NZD",
+	"Nicaragua-Cordoba":                  "NIO",
+	"Niger-Cfa Franc":                    "XOF",
+	"Nigeria-Naira":                      "NGN",
+	"North Macedonia-Denar":              "MKD",
+	"Norway-Krone":                       "NOK",
+	"Oman-Rial":                          "OMR",
+	"Pakistan-Rupee":                     "PKR",
+	"Palau-Dollar":                       "USD",
+	"Panama-Dollar":                      "USD",
+	"Papua New Guinea-Kina":              "PGK",
+	"Paraguay-Guarani":                   "PYG",
+	"Peru-Sol":                           "PEN",
+	"Philippines-Peso":                   "PHP",
+	"Poland-Zloty":                       "PLN",
+	"Portugal-Euro":                      "EUR",
+	"Qatar-Riyal":                        "QAR",
+	"Romania-New Leu":                    "RON",
+	"Russia-Ruble":                       "RUB",
+	"Rwanda-Franc":                       "RWF",
+	"Sao Tome & Principe-New Dobras":     "STN",
+	"Saudi Arabia-Riyal":                 "SAR",
+	"Senegal-Cfa Franc":                  "XOF",
+	"Serbia-Dinar":                       "RSD",
+	"Seychelles-Rupee":                   "SCR",
+	"Sierra Leone-Leone":                 "SLE",
+	"Singapore-Dollar":                   "SGD",
+	"Slovakia-Euro":                      "EUR",
+	"Slovenia-Euro":                      "EUR",
+	"Solomon Islands-Dollar":             "SBD",
+	"Somali-Shilling":                    "SOS",
+	"South Africa-Rand":                  "ZAR",
+	"South Sudan-Sudanese Pound":         "SSP",
+	"Spain-Euro":                         "EUR",
+	"Sri Lanka-Rupee":                    "LKR",
+	"St Lucia-East Caribbean Dollar":     "XCD",
+	"Sudan-Pound":                        "SDG",
+	"Suriname-Dollar":                    "SRD",
+	"Sweden-Krona":                       "SEK",
+	"Switzerland-Franc":                  "CHF",
+	"Syria-Pound":                        "SYP",
+	"Taiwan-Dollar":                      "TWD",
+	"Tajikistan-Somoni":                  "TJS",
+	"Tanzania-Shilling":                  "TZS",
+	"Thailand-Baht":                      "THB",
+	"Togo-Cfa Franc":                     "XOF",
+	"Tonga-Pa'anga":                      "TOP",
+	"Trinidad & Tobago-Dollar":           "TTD",
+	"Tunisia-Dinar":                      "TND",
+	"Turkey-New Lira":                    "TRY",
+	"Turkmenistan-New Manat":             "TMT",
+	"Uganda-Shilling":                    "UGX",
+	"Ukraine-Hryvnia":                    "UAH",
+	"United Arab Emirates-Dirham":        "AED",
+	"United Kingdom-Pound":               "GBP",
+	"Uruguay-Peso":                       "UYU",
+	"Uzbekistan-Som":                     "UZS",
+	"Vanuatu-Vatu":                       "VUV",
+	"Venezuela-Bolivar Soberano":         "VES",
+	"Vietnam-Dong":                       "VND",
+	"Western Samoa-Tala":                 "WST",
+	"Yemen-Rial":                         "YER",
+	"Zambia-New Kwacha":                  "ZMW",
+	"Zimbabwe-Rtgs":                      "ZWL",
+}
+
+// treasuryPreferredCurrencyDescs maps the ISO 4217 codes shared by several countries to the country-currency
+// description used when resolving them. All the countries sharing these codes publish the same exchange rate. Codes
+// shared by several countries and missing from this map are ambiguous and cannot be resolved.
+var treasuryPreferredCurrencyDescs = map[string]string{
+	"EUR": "Euro Zone-Euro",
+	"XAF": "Cameroon-Cfa Franc",
+	"XCD": "St Lucia-East Caribbean Dollar",
+	"XOF": "Senegal-Cfa Franc",
+}
+
+// treasuryCurrencyDescs maps the ISO 4217 codes to all their Treasury country-currency descriptions, sorted.
+var treasuryCurrencyDescs = func() map[string][]string {
+	descs := make(map[string][]string)
+	for desc, code := range treasuryCurrencyCodes {
+		descs[code] = append(descs[code], desc)
+	}
+	for code := range descs {
+		sort.Strings(descs[code])
+	}
+	return descs
+}()
+
+// IsCurrencyCode reports whether a currency is given as an ISO 4217 code, made of three uppercase letters (e.g.
+// "EUR"), rather than as a Treasury currency name (e.g. "Yen").
+func IsCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveTreasuryCurrency returns the Treasury country-currency description of an ISO 4217 code (e.g. "CAD" gives
+// "Canada-Dollar"). Codes shared by several countries resolve to their preferred description when they have one,
+// otherwise ErrAmbiguousCurrencyCode is returned. Codes without exchange rates return
+// ErrUnknownCurrencyCode.
+func ResolveTreasuryCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	descs, ok := treasuryCurrencyDescs[code]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCurrencyCode, code)
+	}
+	if len(descs) == 1 {
+		return descs[0], nil
+	}
+	if desc, ok := treasuryPreferredCurrencyDescs[code]; ok {
+		return desc, nil
+	}
+	return "", fmt.Errorf("%w: %s is used by %s", ErrAmbiguousCurrencyCode, code, strings.Join(descs, ", "))
+}
+
+// ResolveCountryCurrencyDesc returns the Treasury country-currency description of a currency given as an ISO 4217
+// code (e.g. "BRL"), a country-currency description (e.g. "brazil-real") or a currency name (e.g. "Real"). It returns
+// an empty description when a currency name is used by several countries (e.g. "Dollar") or is not known.
+func ResolveCountryCurrencyDesc(currencyName string) (string, error) {
+	currencyName = strings.TrimSpace(currencyName)
+	if IsCurrencyCode(currencyName) {
+		return ResolveTreasuryCurrency(currencyName)
+	}
+
+	resolved, matches := "", 0
+	for desc := range treasuryCurrencyCodes {
+		if strings.EqualFold(desc, currencyName) {
+			return desc, nil
+		}
+		// The currency name is the part after the country (e.g. "Real" in "Brazil-Real")
+		if strings.HasSuffix(strings.ToLower(desc), "-"+strings.ToLower(currencyName)) {
+			resolved = desc
+			matches++
+		}
+	}
+	if matches != 1 {
+		return "", nil
+	}
+	return resolved, nil
+}
+
+// TreasuryCurrencyCode returns the ISO 4217 code of a Treasury country-currency description (e.g. "Japan-Yen"
+// gives "JPY"). The lookup ignores case and surrounding spaces.
+func TreasuryCurrencyCode(countryCurrencyDesc string) (string, bool) {
+	countryCurrencyDesc = strings.TrimSpace(countryCurrencyDesc)
+	if code, ok := treasuryCurrencyCodes[countryCurrencyDesc]; ok {
+		return code, true
+	}
+	for desc, code := range treasuryCurrencyCodes {
+		if strings.EqualFold(desc, countryCurrencyDesc) {
+			return code, true
+		}
+	}
+	return "", false
+}
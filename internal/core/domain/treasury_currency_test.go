@@ -0,0 +1,191 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// This file contains tests for the mapping between the Treasury country-currency descriptions and the ISO 4217
+// codes. It uses Table Driven Tests to test different scenarios. It uses Testify for assertions and runs the tests
+// in parallel.
+
+// TestTreasuryCurrencyCode tests the TreasuryCurrencyCode function. It tests the following scenarios:
+//
+// 1. Known Description.
+// 2. Description With Different Case And Spaces.
+// 3. Currency Shared By Several Countries.
+// 4. Unknown Description.
+func TestTreasuryCurrencyCode(t *testing.T) {
+	tests := []struct {
+		name                string
+		countryCurrencyDesc string
+		expectedCode        string
+		expectedFound       bool
+	}{
+		{
+			name:                "Known Description",
+			countryCurrencyDesc: "Japan-Yen",
+			expectedCode:        "JPY",
+			expectedFound:       true,
+		},
+		{
+			name:                "Description With Different Case And Spaces",
+			countryCurrencyDesc: "  kuwait-dinar ",
+			expectedCode:        "KWD",
+			expectedFound:       true,
+		},
+		{
+			name:                "Currency Shared By Several Countries",
+			countryCurrencyDesc: "France-Euro",
+			expectedCode:        "EUR",
+			expectedFound:       true,
+		},
+		{
+			name:                "Unknown Description",
+			countryCurrencyDesc: "Atlantis-Drachma",
+			expectedFound:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			code, found := domain.TreasuryCurrencyCode(tt.countryCurrencyDesc)
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedCode, code)
+		})
+	}
+}
+
+// TestResolveTreasuryCurrency tests the ResolveTreasuryCurrency function. It tests the following scenarios:
+//
+// 1. Code Used By One Country.
+// 2. Lowercase Code.
+// 3. Code Used By Several Countries With A Preferred Description.
+// 4. Code Used By Several Countries Without A Preferred Description.
+// 5. ISO 4217 Code Without Exchange Rates.
+// 6. Unknown Code.
+func TestResolveTreasuryCurrency(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          string
+		expectedDesc  string
+		expectedError error
+	}{
+		{
+			name:         "Code Used By One Country",
+			code:         "CAD",
+			expectedDesc: "Canada-Dollar",
+		},
+		{
+			name:         "Lowercase Code",
+			code:         "jpy",
+			expectedDesc: "Japan-Yen",
+		},
+		{
+			name:         "Code Used By Several Countries With A Preferred Description",
+			code:         "EUR",
+			expectedDesc: "Euro Zone-Euro",
+		},
+		{
+			name:          "Code Used By Several Countries Without A Preferred Description",
+			code:          "USD",
+			expectedError: domain.ErrAmbiguousCurrencyCode,
+		},
+		{
+			name:          "ISO 4217 Code Without Exchange Rates",
+			code:          "CLF",
+			expectedError: domain.ErrUnknownCurrencyCode,
+		},
+		{
+			name:          "Unknown Code",
+			code:          "XYZ",
+			expectedError: domain.ErrUnknownCurrencyCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			desc, err := domain.ResolveTreasuryCurrency(tt.code)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Empty(t, desc)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDesc, desc)
+		})
+	}
+}
+
+// TestIsCurrencyCode tests the IsCurrencyCode function. It tests the following scenarios:
+//
+// 1. Uppercase Code.
+// 2. Currency Name Of Three Letters.
+// 3. Country-Currency Description.
+func TestIsCurrencyCode(t *testing.T) {
+	t.Parallel()
+	assert.True(t, domain.IsCurrencyCode("EUR"))
+	assert.False(t, domain.IsCurrencyCode("Yen"))
+	assert.False(t, domain.IsCurrencyCode("Euro Zone-Euro"))
+}
+
+// TestResolveCountryCurrencyDesc tests the ResolveCountryCurrencyDesc function. It tests the following scenarios:
+//
+// 1. ISO 4217 Code.
+// 2. Country-Currency Description With Different Case.
+// 3. Currency Name Used By One Country.
+// 4. Currency Name Used By Several Countries.
+// 5. Unknown ISO 4217 Code.
+func TestResolveCountryCurrencyDesc(t *testing.T) {
+	tests := []struct {
+		name          string
+		currency      string
+		expectedDesc  string
+		expectedError error
+	}{
+		{
+			name:         "ISO 4217 Code",
+			currency:     "BRL",
+			expectedDesc: "Brazil-Real",
+		},
+		{
+			name:         "Country-Currency Description With Different Case",
+			currency:     "brazil-real",
+			expectedDesc: "Brazil-Real",
+		},
+		{
+			name:         "Currency Name Used By One Country",
+			currency:     "Real",
+			expectedDesc: "Brazil-Real",
+		},
+		{
+			name:         "Currency Name Used By Several Countries",
+			currency:     "Dollar",
+			expectedDesc: "",
+		},
+		{
+			name:          "Unknown ISO 4217 Code",
+			currency:      "XYZ",
+			expectedError: domain.ErrUnknownCurrencyCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			desc, err := domain.ResolveCountryCurrencyDesc(tt.currency)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDesc, desc)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/google/uuid"
+)
+
+// This file contains the ports provided by the business logic to the external world.
+
+// ConversionJobService is the interface that the business logic provides for any adapter that wants to implement
+// the conversion of the stored transactions in background jobs.
+type ConversionJobService interface {
+	CreateConversionJob(ctx context.Context, job domain.ConversionJob) error
+	FindConversionJob(ctx context.Context, id uuid.UUID) (*domain.ConversionJob, error)
+	StreamConversionJobResults(ctx context.Context, id uuid.UUID, export func(result *domain.ConversionJobResult) error) error
+}
+
+// ConversionJobRepository is the interface that the business logic provides for any adapter that wants to implement
+// data persistence to the conversion job model.
+type ConversionJobRepository interface {
+	SaveConversionJob(ctx context.Context, job domain.ConversionJob) error
+	FindConversionJob(ctx context.Context, id uuid.UUID) (*domain.ConversionJob, error)
+	ClaimConversionJob(ctx context.Context, startedAt time.Time) (*domain.ConversionJob, error)
+	RequeueConversionJobs(ctx context.Context) (int, error)
+	SaveConversionJobResults(ctx context.Context, job domain.ConversionJob, results []*domain.ConversionJobResult) error
+	StreamConversionJobResults(ctx context.Context, id uuid.UUID, export func(result *domain.ConversionJobResult) error) error
+}
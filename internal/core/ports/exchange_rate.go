@@ -1,6 +1,9 @@
 package ports
 
 import (
+	"context"
+	"time"
+
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
 )
 
@@ -9,5 +12,17 @@ import (
 // ExchangeRateService is the interface that the business logic provides for any adapter that wants to implement
 // exchange rate retrieval.
 type ExchangeRateService interface {
-	GetExchangeRates(currencyName string) ([]*domain.ExchangeRate, error)
+	GetExchangeRates(ctx context.Context, currencyName string) ([]*domain.ExchangeRate, error)
+	GetExchangeRatesBetween(ctx context.Context, currencyName string, from, to time.Time) ([]*domain.ExchangeRate, error)
+	GetExchangeRatesSince(ctx context.Context, since time.Time) ([]*domain.ExchangeRate, error)
+	GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error)
+}
+
+// ExchangeRateRepository is the interface that the business logic provides for any adapter that wants to implement
+// data persistence to the exchange rate model.
+type ExchangeRateRepository interface {
+	SaveExchangeRates(ctx context.Context, exchangeRates []*domain.ExchangeRate) error
+	FindExchangeRates(ctx context.Context, countryCurrencyDesc string) ([]*domain.ExchangeRate, error)
+	SaveExchangeRateCoverage(ctx context.Context, countryCurrencyDesc string, from, to time.Time) error
+	FindExchangeRateCoverage(ctx context.Context, countryCurrencyDesc string) (domain.ExchangeRateCoverage, error)
 }
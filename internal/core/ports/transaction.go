@@ -1,6 +1,9 @@
 package ports
 
 import (
+	"context"
+	"time"
+
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
 	"github.com/google/uuid"
 )
@@ -10,13 +13,35 @@ import (
 // TransactionRepository is the interface that the business logic provides for any adapter that wants to implement
 // data persistence to the transaction model.
 type TransactionRepository interface {
-	SaveTransaction(transaction domain.Transaction) error
-	FindTransaction(id uuid.UUID) (*domain.Transaction, error)
+	SaveTransaction(ctx context.Context, transaction domain.Transaction) error
+	SaveTransactions(ctx context.Context, transactions []domain.Transaction) error
+	SaveTransactionWithIdempotencyKey(ctx context.Context, transaction domain.Transaction, idempotencyKey domain.IdempotencyKey) (*domain.IdempotencyKey, error)
+	FindTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error)
+	FindTransactions(ctx context.Context, query domain.TransactionQuery) (*domain.TransactionPage, error)
+	StreamTransactions(ctx context.Context, query domain.TransactionQuery, export func(transaction *domain.Transaction) error) error
+	SaveLockedConversion(ctx context.Context, id uuid.UUID, lockedConversion domain.LockedConversion) (*domain.Transaction, error)
+	SaveTransactionRevision(ctx context.Context, revision domain.Transaction) error
+	FindTransactionHistory(ctx context.Context, id uuid.UUID) ([]*domain.Transaction, error)
 }
 
 // TransactionService is the interface that the business logic provides for any adapter that wants to implement
 // user facing transaction saving and retrieval with currency conversion data.
 type TransactionService interface {
-	SaveTransaction(transaction domain.Transaction) error
-	FindTransactionAndExchangeRateFromCurrency(id uuid.UUID, currencyName string) (*domain.Transaction, *domain.ExchangeRate, error)
+	SaveTransaction(ctx context.Context, transaction domain.Transaction) error
+	SaveTransactionWithIdempotencyKey(ctx context.Context, transaction domain.Transaction, key, requestHash string) (uuid.UUID, bool, error)
+	ImportTransactions(ctx context.Context, rows []*domain.TransactionImportRow, allOrNothing bool) error
+	FindTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error)
+	UpdateTransaction(ctx context.Context, id uuid.UUID, version int, revised domain.Transaction) (*domain.Transaction, error)
+	DeleteTransaction(ctx context.Context, id uuid.UUID, version int) error
+	FindTransactionHistory(ctx context.Context, id uuid.UUID) ([]*domain.Transaction, error)
+	FindTransactionAndExchangeRateFromCurrency(ctx context.Context, id uuid.UUID, currencyName string) (*domain.Transaction, *domain.ExchangeRate, error)
+	ConvertTransaction(ctx context.Context, id uuid.UUID, currencyNames []string) (*domain.Transaction, []*domain.TransactionConversion, error)
+	LockConversion(ctx context.Context, id uuid.UUID, currencyName string) (*domain.Transaction, *domain.LockedConversion, error)
+	FindTransactions(ctx context.Context, query domain.TransactionQuery) (*domain.TransactionPage, error)
+	ExportTransactions(ctx context.Context, query domain.TransactionQuery, currencyName string, export func(transaction *domain.Transaction, conversion *domain.TransactionConversion) error) error
+	GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error)
+	ConvertAmount(ctx context.Context, amountInUSD domain.Money, currencyName string, date time.Time) (*domain.Conversion, error)
+	FindExchangeRateAsOf(ctx context.Context, currencyName string, date time.Time) (*domain.ExchangeRate, error)
+	FindExchangeRateHistory(ctx context.Context, query domain.ExchangeRateQuery) (*domain.ExchangeRatePage, error)
+	RateSelectionPolicy() domain.RateSelectionPolicy
 }
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/ports"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// This file implements the ConversionJobService interface and the background workers converting the stored
+// transactions of the conversion jobs.
+
+const (
+	// DefaultConversionJobWorkers is the default number of conversion jobs running concurrently. Each job looks up an
+	// exchange rate once per purchase date, so a few workers keep the load on the exchange rate providers low.
+	DefaultConversionJobWorkers = 2
+	// conversionJobBatchSize is the number of results of a job saved at once, along with the progress of the job.
+	conversionJobBatchSize = 100
+)
+
+// ConversionJobService holds the conversion job repository, the transaction service converting the transactions,
+// and the state of the background workers running the jobs.
+type ConversionJobService struct {
+	conversionJobRepository ports.ConversionJobRepository
+	transactionService      ports.TransactionService
+	workers                 int
+	// wake tells an idle worker a job was queued
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewConversionJobService creates a new ConversionJobService instance. A non-positive number of workers falls back
+// to DefaultConversionJobWorkers.
+func NewConversionJobService(conversionJobRepository ports.ConversionJobRepository,
+	transactionService ports.TransactionService, workers int) *ConversionJobService {
+	if workers <= 0 {
+		workers = DefaultConversionJobWorkers
+	}
+	return &ConversionJobService{
+		conversionJobRepository: conversionJobRepository,
+		transactionService:      transactionService,
+		workers:                 workers,
+		wake:                    make(chan struct{}, workers),
+	}
+}
+
+// CreateConversionJob saves a queued conversion job and wakes a worker up to run it. A job to a currency that cannot
+// be resolved is rejected, as none of its transactions could be converted.
+func (s *ConversionJobService) CreateConversionJob(ctx context.Context, job domain.ConversionJob) error {
+	log.Info().Str("job_id", job.ID.String()).Str("currency_name", job.CurrencyName).Msg("creating a conversion job")
+
+	if !strings.EqualFold(job.CurrencyName, domain.CurrencyUSD) {
+		if _, err := domain.ResolveCountryCurrencyDesc(job.CurrencyName); err != nil {
+			return err
+		}
+	}
+	if err := s.conversionJobRepository.SaveConversionJob(ctx, job); err != nil {
+		return err
+	}
+
+	// The workers are all busy when the channel is full, and they look for queued jobs before waiting again
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// FindConversionJob retrieves a conversion job with its progress.
+func (s *ConversionJobService) FindConversionJob(ctx context.Context, id uuid.UUID) (*domain.ConversionJob, error) {
+	return s.conversionJobRepository.FindConversionJob(ctx, id)
+}
+
+// StreamConversionJobResults streams the results of a completed conversion job to the export function, in the
+// chronological order of their transactions. The results of a job that has not completed are rejected with
+// domain.ErrConversionJobNotCompleted.
+func (s *ConversionJobService) StreamConversionJobResults(ctx context.Context, id uuid.UUID,
+	export func(result *domain.ConversionJobResult) error) error {
+	job, err := s.conversionJobRepository.FindConversionJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job.Status != domain.ConversionJobCompleted {
+		return domain.ErrConversionJobNotCompleted
+	}
+	return s.conversionJobRepository.StreamConversionJobResults(ctx, id, export)
+}
+
+// Start queues again the jobs interrupted by the last stop and starts the workers, which run the queued jobs in the
+// background, oldest first, until Stop is called or the context is done.
+func (s *ConversionJobService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	if requeued, err := s.conversionJobRepository.RequeueConversionJobs(ctx); err != nil {
+		log.Warn().Err(err).Msg("interrupted conversion jobs could not be queued again")
+	} else if requeued > 0 {
+		log.Info().Int("conversion_jobs", requeued).Msg("interrupted conversion jobs queued again")
+	}
+
+	for range s.workers {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for {
+				// Runs the queued jobs until none is left
+				for s.runNextConversionJob(ctx) {
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-s.wake:
+				}
+			}
+		}()
+	}
+}
+
+// Stop stops the workers, interrupts the running jobs and waits for the workers to return. The interrupted jobs are
+// run again from the start by the next Start.
+func (s *ConversionJobService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.cancel = nil
+}
+
+// runNextConversionJob claims the oldest queued job and runs it. It reports whether a job was claimed.
+func (s *ConversionJobService) runNextConversionJob(ctx context.Context) bool {
+	job, err := s.conversionJobRepository.ClaimConversionJob(ctx, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("failed to claim a conversion job")
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	log.Info().Str("job_id", job.ID.String()).Str("currency_name", job.CurrencyName).Msg("running the conversion job")
+	err = s.runConversionJob(ctx, job)
+	switch {
+	case err == nil:
+		log.Info().
+			Str("job_id", job.ID.String()).
+			Int("converted", job.Converted).
+			Int("failed", job.Failed).
+			Msg("conversion job completed")
+	case ctx.Err() != nil:
+		// The job stays running, so it is queued again by the next start
+		log.Warn().Str("job_id", job.ID.String()).Msg("conversion job interrupted")
+		return false
+	default:
+		log.Error().Err(err).Str("job_id", job.ID.String()).Msg("conversion job failed")
+		job.Fail(time.Now(), err)
+		if err := s.conversionJobRepository.SaveConversionJob(ctx, *job); err != nil {
+			log.Error().Err(err).Str("job_id", job.ID.String()).Msg("failed to save the failed conversion job")
+		}
+	}
+	return true
+}
+
+// runConversionJob converts the transactions of a running job to its target currency, and saves the results in
+// batches along with the progress of the job. A transaction that cannot be converted is recorded with its error
+// without stopping the job.
+func (s *ConversionJobService) runConversionJob(ctx context.Context, job *domain.ConversionJob) error {
+	// Counts the transactions first, so the progress of the job can be reported. The total is set to the number of
+	// transactions converted once the job completes, as transactions may be created or deleted in between
+	total := 0
+	err := s.transactionService.ExportTransactions(ctx, job.Query(), "",
+		func(*domain.Transaction, *domain.TransactionConversion) error {
+			total++
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	job.Start(*job.StartedAt, total)
+	if err := s.conversionJobRepository.SaveConversionJob(ctx, *job); err != nil {
+		return err
+	}
+
+	rateSelectionPolicy := s.transactionService.RateSelectionPolicy()
+	results := make([]*domain.ConversionJobResult, 0, conversionJobBatchSize)
+	err = s.transactionService.ExportTransactions(ctx, job.Query(), job.CurrencyName,
+		func(transaction *domain.Transaction, conversion *domain.TransactionConversion) error {
+			result := domain.NewConversionJobResult(transaction, conversion, rateSelectionPolicy, time.Now())
+			job.Record(result)
+			results = append(results, result)
+			if len(results) < conversionJobBatchSize {
+				return nil
+			}
+			err := s.conversionJobRepository.SaveConversionJobResults(ctx, *job, results)
+			results = results[:0]
+			return err
+		})
+	if err != nil {
+		return err
+	}
+
+	job.Complete(time.Now())
+	return s.conversionJobRepository.SaveConversionJobResults(ctx, *job, results)
+}
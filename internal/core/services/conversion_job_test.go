@@ -0,0 +1,202 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the ConversionJobService. It uses Testify for assertions and mocking, and runs the
+// tests in parallel.
+
+// TestConversionJobService tests the conversion jobs run in the background. It tests the following scenarios:
+//
+// 1. Completed Job With Stored Exchange Rates.
+// 2. Failed Conversions Recorded In The Results.
+// 3. Unknown Currency Rejected.
+// 4. Results Of A Queued Job Rejected.
+// 5. Interrupted Job Queued Again On Start.
+func TestConversionJobService(t *testing.T) {
+	testDatabasePath := "testdata/conversion_job_test.db"
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB(testDatabasePath, "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close BoltDB")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates")
+	require.NoError(t, err)
+	exchangeRates := make([]*domain.ExchangeRate, 0, 2)
+	for _, values := range [][]string{{"Euro", "0.897", "Euro Zone-Euro", "EUR"}, {"Peso", "19.6", "Mexico-Peso", "MXN"}} {
+		exchangeRate, errs := domain.NewExchangeRate(values[0], values[1], time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = values[2]
+		exchangeRate.CurrencyCode = values[3]
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+	require.NoError(t, exchangeRateRepo.SaveExchangeRates(context.Background(), exchangeRates))
+
+	// The purchase of 2023 has no stored exchange rate, and the provider has none either
+	mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+	mockAdapter.On("GetExchangeRatesBetween", mock.Anything, mock.Anything, mock.Anything).
+		Return([]*domain.ExchangeRate(nil), nil)
+	transactionService := services.NewTransactionService(transactionRepo, exchangeRateRepo, mockAdapter, nil)
+	ids := make([]uuid.UUID, 0, 3)
+	for _, purchase := range []struct {
+		description string
+		timestamp   time.Time
+		amount      domain.Money
+	}{
+		{"Old Purchase", time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC), domain.MustParseMoney("5.00", domain.CurrencyUSD)},
+		{"Coffee", time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC), domain.MustParseMoney("10.00", domain.CurrencyUSD)},
+		{"Hotel & Spa", time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC), domain.MustParseMoney("25.79", "EUR")},
+	} {
+		transaction, errs := domain.NewTransactionInCurrency(purchase.description, purchase.timestamp, purchase.amount)
+		require.Empty(t, errs)
+		require.NoError(t, transactionRepo.SaveTransaction(context.Background(), *transaction))
+		ids = append(ids, transaction.ID)
+	}
+
+	// Each test uses its own bucket of the shared database
+	newConversionJobService := func(t *testing.T) (*services.ConversionJobService, *repository.ConversionJobRepositoryBoltDB) {
+		conversionJobRepo, err := repository.NewConversionJobRepositoryBoltDB(transactionRepo.GetBoltDB(), "conversion_jobs_"+uuid.New().String())
+		require.NoError(t, err)
+		return services.NewConversionJobService(conversionJobRepo, transactionService, 0), conversionJobRepo
+	}
+	// waitForConversionJob waits for a job to be done and returns it
+	waitForConversionJob := func(t *testing.T, conversionJobService *services.ConversionJobService, id uuid.UUID) *domain.ConversionJob {
+		var job *domain.ConversionJob
+		require.Eventually(t, func() bool {
+			var err error
+			job, err = conversionJobService.FindConversionJob(context.Background(), id)
+			return err == nil && job.Done()
+		}, 5*time.Second, 10*time.Millisecond)
+		return job
+	}
+	// streamResults streams the results of a job
+	streamResults := func(t *testing.T, conversionJobService *services.ConversionJobService, id uuid.UUID) []*domain.ConversionJobResult {
+		results := make([]*domain.ConversionJobResult, 0)
+		require.NoError(t, conversionJobService.StreamConversionJobResults(context.Background(), id,
+			func(result *domain.ConversionJobResult) error {
+				results = append(results, result)
+				return nil
+			}))
+		return results
+	}
+	september := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Completed Job With Stored Exchange Rates", func(t *testing.T) {
+		t.Parallel()
+		conversionJobService, _ := newConversionJobService(t)
+		conversionJobService.Start(context.Background())
+		t.Cleanup(conversionJobService.Stop)
+		job, errs := domain.NewConversionJob("MXN", &september, nil, time.Now())
+		require.Empty(t, errs)
+
+		require.NoError(t, conversionJobService.CreateConversionJob(context.Background(), *job))
+
+		completedJob := waitForConversionJob(t, conversionJobService, job.ID)
+		assert.Equal(t, domain.ConversionJobCompleted, completedJob.Status)
+		assert.Equal(t, 2, completedJob.Total)
+		assert.Equal(t, 2, completedJob.Converted)
+		assert.Zero(t, completedJob.Failed)
+		assert.Equal(t, float64(1), completedJob.Progress())
+		results := streamResults(t, conversionJobService, job.ID)
+		require.Len(t, results, 2)
+		for i, expectedAmount := range []string{"196.00", "563.53"} {
+			assert.Equal(t, ids[i+1], results[i].Transaction.ID)
+			conversion, err := results[i].TransactionConversion("MXN")
+			require.NoError(t, err)
+			require.NoError(t, conversion.Err)
+			assert.Equal(t, expectedAmount, conversion.AmountInTargetCurrency.String())
+		}
+	})
+
+	t.Run("Failed Conversions Recorded In The Results", func(t *testing.T) {
+		t.Parallel()
+		conversionJobService, _ := newConversionJobService(t)
+		conversionJobService.Start(context.Background())
+		t.Cleanup(conversionJobService.Stop)
+		job, errs := domain.NewConversionJob("Mexico-Peso", nil, nil, time.Now())
+		require.Empty(t, errs)
+
+		require.NoError(t, conversionJobService.CreateConversionJob(context.Background(), *job))
+
+		completedJob := waitForConversionJob(t, conversionJobService, job.ID)
+		assert.Equal(t, domain.ConversionJobCompleted, completedJob.Status)
+		assert.Equal(t, 3, completedJob.Processed)
+		assert.Equal(t, 2, completedJob.Converted)
+		assert.Equal(t, 1, completedJob.Failed)
+		results := streamResults(t, conversionJobService, job.ID)
+		require.Len(t, results, 3)
+		assert.Equal(t, ids[0], results[0].Transaction.ID)
+		assert.ErrorIs(t, results[0].Err(), domain.ErrNoApplicableExchangeRate)
+	})
+
+	t.Run("Unknown Currency Rejected", func(t *testing.T) {
+		t.Parallel()
+		conversionJobService, _ := newConversionJobService(t)
+		job, errs := domain.NewConversionJob("XYZ", nil, nil, time.Now())
+		require.Empty(t, errs)
+
+		err := conversionJobService.CreateConversionJob(context.Background(), *job)
+
+		assert.ErrorIs(t, err, domain.ErrUnknownCurrencyCode)
+		_, err = conversionJobService.FindConversionJob(context.Background(), job.ID)
+		assert.ErrorIs(t, err, repository.ErrConversionJobNotFound)
+	})
+
+	t.Run("Results Of A Queued Job Rejected", func(t *testing.T) {
+		t.Parallel()
+		// The workers are not started, so the job stays queued
+		conversionJobService, _ := newConversionJobService(t)
+		job, errs := domain.NewConversionJob("USD", nil, nil, time.Now())
+		require.Empty(t, errs)
+		require.NoError(t, conversionJobService.CreateConversionJob(context.Background(), *job))
+
+		err := conversionJobService.StreamConversionJobResults(context.Background(), job.ID,
+			func(*domain.ConversionJobResult) error { return nil })
+
+		assert.ErrorIs(t, err, domain.ErrConversionJobNotCompleted)
+		queuedJob, err := conversionJobService.FindConversionJob(context.Background(), job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ConversionJobQueued, queuedJob.Status)
+	})
+
+	t.Run("Interrupted Job Queued Again On Start", func(t *testing.T) {
+		t.Parallel()
+		conversionJobService, conversionJobRepo := newConversionJobService(t)
+		job, errs := domain.NewConversionJob("USD", nil, nil, time.Now())
+		require.Empty(t, errs)
+		// The job was running when the service stopped, with some results already saved
+		job.Start(time.Now(), 3)
+		staleResult := domain.NewConversionJobResult(&domain.Transaction{ID: ids[0]},
+			&domain.TransactionConversion{CurrencyName: "USD", Err: domain.ErrExchangeRatesUnavailable},
+			domain.DefaultRateSelectionPolicy(), time.Now())
+		job.Record(staleResult)
+		require.NoError(t, conversionJobRepo.SaveConversionJobResults(context.Background(), *job,
+			[]*domain.ConversionJobResult{staleResult}))
+
+		conversionJobService.Start(context.Background())
+		t.Cleanup(conversionJobService.Stop)
+
+		completedJob := waitForConversionJob(t, conversionJobService, job.ID)
+		assert.Equal(t, domain.ConversionJobCompleted, completedJob.Status)
+		assert.Equal(t, 3, completedJob.Converted)
+		assert.Zero(t, completedJob.Failed)
+		assert.Len(t, streamResults(t, conversionJobService, job.ID), 3)
+	})
+}
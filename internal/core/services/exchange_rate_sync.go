@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/ports"
+	"github.com/rs/zerolog/log"
+)
+
+// This file implements the background synchronization of the local exchange rate store with the exchange rate
+// adapter.
+
+const (
+	// DefaultExchangeRateSyncInterval is the default time between two synchronizations. The Treasury publishes new
+	// exchange rates quarterly, so a few synchronizations a day are enough to pick them up quickly.
+	DefaultExchangeRateSyncInterval = 6 * time.Hour
+	// exchangeRateSyncInitialLookback is how far back the first synchronization of an empty store goes. Older
+	// exchange rates are downloaded on demand by the conversions that need them.
+	exchangeRateSyncInitialLookback = 2 * 365 * 24 * time.Hour
+)
+
+// ExchangeRateSyncService holds the exchange rate repository and adapter, and the state of the background worker
+// that keeps the repository up to date.
+type ExchangeRateSyncService struct {
+	exchangeRateRepository ports.ExchangeRateRepository
+	exchangeRateAdapter    ports.ExchangeRateService
+	interval               time.Duration
+	cancel                 context.CancelFunc
+	done                   chan struct{}
+}
+
+// NewExchangeRateSyncService creates a new ExchangeRateSyncService instance. A non-positive interval falls back to
+// DefaultExchangeRateSyncInterval.
+func NewExchangeRateSyncService(exchangeRateRepository ports.ExchangeRateRepository,
+	exchangeRateAdapter ports.ExchangeRateService, interval time.Duration) *ExchangeRateSyncService {
+	if interval <= 0 {
+		interval = DefaultExchangeRateSyncInterval
+	}
+	return &ExchangeRateSyncService{
+		exchangeRateRepository: exchangeRateRepository,
+		exchangeRateAdapter:    exchangeRateAdapter,
+		interval:               interval,
+	}
+}
+
+// Sync downloads the exchange rates published after the ones already synchronized and saves them. It returns the
+// number of exchange rates saved. The days up to the latest date of record downloaded are then recorded as covered
+// for all the currencies, which is where the next synchronization starts.
+func (s *ExchangeRateSyncService) Sync(ctx context.Context) (int, error) {
+	coverage, err := s.exchangeRateRepository.FindExchangeRateCoverage(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+	since := coverage.End()
+	if since.IsZero() {
+		since = time.Now().Add(-exchangeRateSyncInitialLookback)
+	}
+
+	exchangeRates, err := s.exchangeRateAdapter.GetExchangeRatesSince(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+	if len(exchangeRates) == 0 {
+		return 0, nil
+	}
+	if err := s.exchangeRateRepository.SaveExchangeRates(ctx, exchangeRates); err != nil {
+		return 0, err
+	}
+
+	// Only the exchange rates published after the day since are downloaded
+	latestDateOfRecord := since
+	for _, exchangeRate := range exchangeRates {
+		if exchangeRate.DateOfRecord.After(latestDateOfRecord) {
+			latestDateOfRecord = exchangeRate.DateOfRecord
+		}
+	}
+	err = s.exchangeRateRepository.SaveExchangeRateCoverage(ctx, "", since.AddDate(0, 0, 1), latestDateOfRecord)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(exchangeRates), nil
+}
+
+// Start runs the synchronization right away and then at every interval, in the background, until Stop is called or
+// the context is done. Failed synchronizations are logged and retried at the next interval.
+func (s *ExchangeRateSyncService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if saved, err := s.Sync(ctx); err != nil {
+				log.Warn().Err(err).Msg("exchange rate synchronization failed")
+			} else {
+				log.Info().Int("exchange_rates", saved).Msg("exchange rates synchronized")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the background synchronization, cancels the running one and waits for it to return.
+func (s *ExchangeRateSyncService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+}
@@ -0,0 +1,132 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/repository"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
+	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// This file contains tests for the ExchangeRateSyncService. It uses Testify for assertions and mocking, and runs the
+// tests in parallel.
+
+// TestExchangeRateSyncService tests the synchronization of the local exchange rate store. It tests the following
+// scenarios:
+//
+// 1. First Synchronization Of An Empty Store.
+// 2. Incremental Synchronization Since The Synchronized Days.
+// 3. Adapter Failure.
+// 4. Background Worker Synchronizes On Start.
+// 5. Canceled Context.
+func TestExchangeRateSyncService(t *testing.T) {
+	testDatabasePath := "testdata/exchange_rate_sync_test.db"
+	transactionRepo, err := repository.NewTransactionRepositoryBoltDB(testDatabasePath, "transactions")
+	require.NoError(t, err)
+
+	// Ensure cleanup happens after all tests
+	t.Cleanup(func() {
+		require.NoError(t, transactionRepo.Close(), "failed to close BoltDB")
+		require.NoError(t, os.RemoveAll("testdata"), "failed to clean up test data directory")
+	})
+
+	// Each test uses its own bucket of the shared database
+	newRepository := func(t *testing.T) *repository.ExchangeRateRepositoryBoltDB {
+		exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(transactionRepo.GetBoltDB(), "exchange_rates_"+uuid.New().String())
+		require.NoError(t, err)
+		return exchangeRateRepo
+	}
+	newExchangeRate := func(t *testing.T, dateOfRecord time.Time) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Real", "5.434", dateOfRecord)
+		require.Empty(t, errs)
+		exchangeRate.CountryCurrencyDesc = "Brazil-Real"
+		return exchangeRate
+	}
+	june := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	september := time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	t.Run("First Synchronization Of An Empty Store", func(t *testing.T) {
+		t.Parallel()
+		exchangeRateRepo := newRepository(t)
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRatesSince", mock.MatchedBy(func(since time.Time) bool {
+			return since.Before(time.Now().AddDate(-1, 0, 0))
+		})).Return([]*domain.ExchangeRate{newExchangeRate(t, september), newExchangeRate(t, june)}, nil)
+
+		saved, err := services.NewExchangeRateSyncService(exchangeRateRepo, mockAdapter, time.Hour).Sync(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, saved)
+		exchangeRates, err := exchangeRateRepo.FindExchangeRates(context.Background(), "Brazil-Real")
+		require.NoError(t, err)
+		assert.Len(t, exchangeRates, 2)
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Incremental Synchronization Since The Synchronized Days", func(t *testing.T) {
+		t.Parallel()
+		exchangeRateRepo := newRepository(t)
+		// A rate downloaded on demand does not move the synchronization forward
+		require.NoError(t, exchangeRateRepo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{newExchangeRate(t, september)}))
+		require.NoError(t, exchangeRateRepo.SaveExchangeRateCoverage(context.Background(), "", june.AddDate(0, -3, 0), june))
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRatesSince", june).Return([]*domain.ExchangeRate{newExchangeRate(t, september)}, nil)
+
+		saved, err := services.NewExchangeRateSyncService(exchangeRateRepo, mockAdapter, time.Hour).Sync(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, saved)
+		coverage, err := exchangeRateRepo.FindExchangeRateCoverage(context.Background(), "")
+		require.NoError(t, err)
+		assert.True(t, coverage.Covers(june.AddDate(0, -3, 0), september))
+		mockAdapter.AssertExpectations(t)
+	})
+
+	t.Run("Adapter Failure", func(t *testing.T) {
+		t.Parallel()
+		exchangeRateRepo := newRepository(t)
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRatesSince", mock.Anything).Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue)
+
+		saved, err := services.NewExchangeRateSyncService(exchangeRateRepo, mockAdapter, time.Hour).Sync(context.Background())
+
+		assert.ErrorIs(t, err, client.ErrNetworkIssue)
+		assert.Zero(t, saved)
+	})
+
+	t.Run("Background Worker Synchronizes On Start", func(t *testing.T) {
+		t.Parallel()
+		exchangeRateRepo := newRepository(t)
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		mockAdapter.On("GetExchangeRatesSince", mock.Anything).Return([]*domain.ExchangeRate{newExchangeRate(t, september)}, nil)
+
+		syncService := services.NewExchangeRateSyncService(exchangeRateRepo, mockAdapter, time.Hour)
+		syncService.Start(context.Background())
+		assert.Eventually(t, func() bool {
+			exchangeRates, err := exchangeRateRepo.FindExchangeRates(context.Background(), "Brazil-Real")
+			return err == nil && len(exchangeRates) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		syncService.Stop()
+	})
+
+	t.Run("Canceled Context", func(t *testing.T) {
+		t.Parallel()
+		mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		saved, err := services.NewExchangeRateSyncService(newRepository(t), mockAdapter, time.Hour).Sync(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, saved)
+		mockAdapter.AssertNotCalled(t, "GetExchangeRatesSince", mock.Anything)
+	})
+}
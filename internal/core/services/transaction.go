@@ -1,9 +1,14 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/dainfoo/wex-technical-implementation-project/internal/adapters/client"
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/domain"
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/ports"
 	"github.com/google/uuid"
@@ -13,58 +18,760 @@ import (
 // This file implements the TransactionService interface and handles the access of external services to the transaction
 // repository and exchange rate adapter through a controlled way.
 
-// TransactionService holds the transaction repository and exchange rate adapter.
+// conversionWorkers is the maximum number of exchange rate lookups running concurrently for a multi-currency
+// conversion.
+const conversionWorkers = 4
+
+// exchangeRatePublicationDelay is how long after a date of record its exchange rates are surely published. The
+// Treasury publishes the exchange rates of a quarter within days of its end.
+const exchangeRatePublicationDelay = 31 * 24 * time.Hour
+
+// importBatchSize is the maximum number of transactions of an import saved in a single write transaction.
+const importBatchSize = 500
+
+// exportPageSize is the number of transactions of an export converted at once.
+const exportPageSize = 100
+
+// TransactionService holds the transaction repository, the local exchange rate repository, the exchange rate
+// adapter, the policy selecting the exchange rate applicable to a purchase and the time the idempotency keys are kept.
 type TransactionService struct {
-	transactionRepository ports.TransactionRepository
-	exchangeRateAdapter   client.TreasuryExchangeRateAdapter
+	transactionRepository  ports.TransactionRepository
+	exchangeRateRepository ports.ExchangeRateRepository
+	exchangeRateAdapter    ports.ExchangeRateService
+	rateSelectionPolicy    domain.RateSelectionPolicy
+	idempotencyKeyTTL      time.Duration
 }
 
-// NewTransactionService creates a new TransactionService instance.
-func NewTransactionService(transactionRepository ports.TransactionRepository, exchangeRateAdapter client.TreasuryExchangeRateAdapter) *TransactionService {
+// NewTransactionService creates a new TransactionService instance. A nil rate selection policy falls back to
+// domain.DefaultRateSelectionPolicy.
+func NewTransactionService(transactionRepository ports.TransactionRepository,
+	exchangeRateRepository ports.ExchangeRateRepository,
+	exchangeRateAdapter ports.ExchangeRateService,
+	rateSelectionPolicy domain.RateSelectionPolicy) *TransactionService {
+	if rateSelectionPolicy == nil {
+		rateSelectionPolicy = domain.DefaultRateSelectionPolicy()
+	}
 	return &TransactionService{
-		transactionRepository: transactionRepository,
-		exchangeRateAdapter:   exchangeRateAdapter,
+		transactionRepository:  transactionRepository,
+		exchangeRateRepository: exchangeRateRepository,
+		exchangeRateAdapter:    exchangeRateAdapter,
+		rateSelectionPolicy:    rateSelectionPolicy,
+		idempotencyKeyTTL:      domain.DefaultIdempotencyKeyTTL,
+	}
+}
+
+// WithIdempotencyKeyTTL replaces the time the idempotency keys are kept, and returns the service. A non-positive ttl
+// falls back to domain.DefaultIdempotencyKeyTTL.
+func (ts *TransactionService) WithIdempotencyKeyTTL(ttl time.Duration) *TransactionService {
+	if ttl <= 0 {
+		ttl = domain.DefaultIdempotencyKeyTTL
+	}
+	ts.idempotencyKeyTTL = ttl
+	return ts
+}
+
+// SaveTransaction saves a transaction. A transaction recorded in another currency than USD is rejected when its
+// currency has no published exchange rates, as it could never be converted.
+func (ts *TransactionService) SaveTransaction(ctx context.Context, transaction domain.Transaction) error {
+	if transaction.Currency() != domain.CurrencyUSD {
+		if _, err := domain.ResolveTreasuryCurrency(transaction.Currency()); err != nil {
+			return err
+		}
+	}
+	return ts.transactionRepository.SaveTransaction(ctx, transaction)
+}
+
+// SaveTransactionWithIdempotencyKey saves a transaction once per idempotency key. A retry of the request that created
+// a transaction, with the same key and request hash, saves nothing and returns the ID of the transaction created the
+// first time, with replayed set to true. Another request reusing the key before it expires is rejected with
+// domain.ErrIdempotencyKeyReused.
+func (ts *TransactionService) SaveTransactionWithIdempotencyKey(ctx context.Context, transaction domain.Transaction,
+	key, requestHash string) (uuid.UUID, bool, error) {
+	idempotencyKey, errs := domain.NewIdempotencyKey(key, requestHash, transaction.ID, time.Now(), ts.idempotencyKeyTTL)
+	if len(errs) > 0 {
+		return uuid.Nil, false, errors.Join(errs...)
+	}
+	if transaction.Currency() != domain.CurrencyUSD {
+		if _, err := domain.ResolveTreasuryCurrency(transaction.Currency()); err != nil {
+			return uuid.Nil, false, err
+		}
+	}
+
+	storedKey, err := ts.transactionRepository.SaveTransactionWithIdempotencyKey(ctx, transaction, *idempotencyKey)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	if storedKey.TransactionID == transaction.ID {
+		return transaction.ID, false, nil
+	}
+
+	if !storedKey.Matches(requestHash) {
+		log.Warn().Str("idempotency_key", idempotencyKey.Key).Msg("idempotency key reused with a different request")
+		return uuid.Nil, false, domain.ErrIdempotencyKeyReused
+	}
+	log.Info().
+		Str("idempotency_key", idempotencyKey.Key).
+		Str("transaction_id", storedKey.TransactionID.String()).
+		Msg("transaction creation replayed")
+	return storedKey.TransactionID, true, nil
+}
+
+// ImportTransactions saves the transactions of the valid rows of a bulk import, in batches, and rejects the rows
+// recorded in a currency without published exchange rates. The rows report their own errors: a batch that cannot be
+// saved rejects its rows without stopping the import, and the rows left when the context is done are rejected with
+// the context error. In all-or-nothing mode, the transactions are saved in a single batch only when all the rows are
+// valid, otherwise nothing is saved and domain.ErrTransactionImportRejected is returned.
+func (ts *TransactionService) ImportTransactions(ctx context.Context, rows []*domain.TransactionImportRow,
+	allOrNothing bool) error {
+	validRows := make([]*domain.TransactionImportRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Accepted() && row.Transaction.Currency() != domain.CurrencyUSD {
+			if _, err := domain.ResolveTreasuryCurrency(row.Transaction.Currency()); err != nil {
+				row.Reject(err)
+			}
+		}
+		if row.Accepted() {
+			validRows = append(validRows, row)
+		}
+	}
+	log.Info().Int("rows", len(rows)).Int("valid_rows", len(validRows)).Bool("all_or_nothing", allOrNothing).Msg("importing transactions")
+
+	if allOrNothing {
+		if len(validRows) < len(rows) {
+			for _, row := range validRows {
+				row.Reject(domain.ErrTransactionImportNotSaved)
+			}
+			return domain.ErrTransactionImportRejected
+		}
+		return ts.saveImportBatch(ctx, validRows)
+	}
+
+	for start, end := 0, 0; start < len(validRows); start = end {
+		end = min(start+importBatchSize, len(validRows))
+		if ctx.Err() != nil {
+			// The rows left cannot be saved once the request is over
+			end = len(validRows)
+		}
+		batch := validRows[start:end]
+		if err := ts.saveImportBatch(ctx, batch); err != nil {
+			log.Warn().Err(err).Int("first_line", batch[0].Line).Int("rows", len(batch)).Msg("failed to save a batch of imported transactions")
+			for _, row := range batch {
+				row.Reject(err)
+			}
+		}
+	}
+	return nil
+}
+
+// saveImportBatch saves the transactions of a batch of rows of an import in a single write transaction.
+func (ts *TransactionService) saveImportBatch(ctx context.Context, rows []*domain.TransactionImportRow) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	transactions := make([]domain.Transaction, len(rows))
+	for i, row := range rows {
+		transactions[i] = *row.Transaction
+	}
+	return ts.transactionRepository.SaveTransactions(ctx, transactions)
+}
+
+// FindTransaction retrieves the current revision of a transaction. A deleted transaction is not found.
+func (ts *TransactionService) FindTransaction(ctx context.Context, id uuid.UUID) (*domain.Transaction, error) {
+	return ts.transactionRepository.FindTransaction(ctx, id)
+}
+
+// UpdateTransaction revises the description, timestamp and amount of a transaction with those of a revised
+// transaction, and returns the new revision. The version is the one the revision is made from: when the transaction
+// was revised since, the update is rejected with domain.ErrTransactionVersionMismatch. The prior revision is kept in
+// the history of the transaction.
+func (ts *TransactionService) UpdateTransaction(ctx context.Context, id uuid.UUID, version int,
+	revised domain.Transaction) (*domain.Transaction, error) {
+	log.Info().Str("transaction_id", id.String()).Int("version", version).Msg("updating the transaction")
+
+	if revised.Currency() != domain.CurrencyUSD {
+		if _, err := domain.ResolveTreasuryCurrency(revised.Currency()); err != nil {
+			return nil, err
+		}
+	}
+	transaction, err := ts.findTransactionVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := transaction.Revise(revised, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.transactionRepository.SaveTransactionRevision(ctx, *revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// DeleteTransaction soft deletes a transaction: it is replaced by a tombstone, so it is no longer found, while its
+// history is kept. The version is the one the deletion is made from, like for UpdateTransaction.
+func (ts *TransactionService) DeleteTransaction(ctx context.Context, id uuid.UUID, version int) error {
+	log.Info().Str("transaction_id", id.String()).Int("version", version).Msg("deleting the transaction")
+
+	transaction, err := ts.findTransactionVersion(ctx, id, version)
+	if err != nil {
+		return err
+	}
+
+	tombstone, err := transaction.Delete(time.Now())
+	if err != nil {
+		return err
+	}
+	return ts.transactionRepository.SaveTransactionRevision(ctx, *tombstone)
+}
+
+// FindTransactionHistory retrieves all the revisions of a transaction, oldest first, ending with its current
+// revision, or its tombstone when it is deleted.
+func (ts *TransactionService) FindTransactionHistory(ctx context.Context, id uuid.UUID) ([]*domain.Transaction, error) {
+	return ts.transactionRepository.FindTransactionHistory(ctx, id)
+}
+
+// findTransactionVersion retrieves a transaction, checking that its current version is the given one. The repository
+// checks the version again when the revision is saved, in case the transaction is revised in between.
+func (ts *TransactionService) findTransactionVersion(ctx context.Context, id uuid.UUID,
+	version int) (*domain.Transaction, error) {
+	transaction, err := ts.transactionRepository.FindTransaction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Version != version {
+		log.Warn().
+			Str("transaction_id", id.String()).
+			Int("version", transaction.Version).
+			Int("expected_version", version).
+			Msg("transaction version mismatch")
+		return nil, domain.ErrTransactionVersionMismatch
+	}
+	return transaction, nil
+}
+
+// FindTransactions retrieves a page of transactions matching the query filters.
+func (ts *TransactionService) FindTransactions(ctx context.Context,
+	query domain.TransactionQuery) (*domain.TransactionPage, error) {
+	return ts.transactionRepository.FindTransactions(ctx, query)
+}
+
+// ExportTransactions streams the transactions matching the query filters to the export function, in chronological
+// order. With a target currency, each transaction is passed with its conversion to that currency, and a transaction
+// that cannot be converted keeps the error in its conversion without stopping the export. The conversion locked on a
+// transaction for the target currency is attached to its conversion. The transactions are converted a page at a
+// time: the exchange rates a page needs are looked up concurrently before it is exported, once per currency and
+// purchase date for the whole export. The export stops at the first error of the export function.
+func (ts *TransactionService) ExportTransactions(ctx context.Context, query domain.TransactionQuery,
+	currencyName string, export func(transaction *domain.Transaction,
+		conversion *domain.TransactionConversion) error) error {
+	log.Info().Str("currency_name", currencyName).Msg("exporting transactions")
+
+	if currencyName == "" {
+		return ts.transactionRepository.StreamTransactions(ctx, query, func(transaction *domain.Transaction) error {
+			return export(transaction, nil)
+		})
+	}
+	exporter, err := ts.newTransactionExporter(currencyName)
+	if err != nil {
+		return err
+	}
+
+	page := make([]*domain.Transaction, 0, exportPageSize)
+	exportPage := func() error {
+		if err := exporter.lookUp(ctx, page); err != nil {
+			return err
+		}
+		for _, transaction := range page {
+			conversion := exporter.convert(transaction)
+			ts.attachLockedConversions(transaction, []*domain.TransactionConversion{conversion})
+			if err := export(transaction, conversion); err != nil {
+				return err
+			}
+		}
+		page = page[:0]
+		return nil
+	}
+	err = ts.transactionRepository.StreamTransactions(ctx, query, func(transaction *domain.Transaction) error {
+		page = append(page, transaction)
+		if len(page) < exportPageSize {
+			return nil
+		}
+		return exportPage()
+	})
+	if err != nil {
+		return err
+	}
+	return exportPage()
+}
+
+// exportExchangeRateKey identifies the exchange rate of a currency applicable on a purchase date.
+type exportExchangeRateKey struct {
+	currencyName string
+	date         string
+}
+
+// newExportExchangeRateKey creates the key of the exchange rate of a currency applicable on a purchase date.
+func newExportExchangeRateKey(currencyName string, date time.Time) exportExchangeRateKey {
+	return exportExchangeRateKey{currencyName: currencyName, date: date.UTC().Format(time.DateOnly)}
+}
+
+// exportExchangeRate is the lookup of an exchange rate, made for the first purchase date it applies to and kept for
+// the rest of an export.
+type exportExchangeRate struct {
+	key          exportExchangeRateKey
+	date         time.Time
+	exchangeRate *domain.ExchangeRate
+	err          error
+}
+
+// transactionExporter converts the transactions of an export to a currency, looking up each exchange rate once.
+type transactionExporter struct {
+	service       *TransactionService
+	currencyName  string
+	exchangeRates map[exportExchangeRateKey]*exportExchangeRate
+}
+
+// newTransactionExporter creates a transactionExporter to a currency, which is validated before any transaction is
+// read.
+func (ts *TransactionService) newTransactionExporter(currencyName string) (*transactionExporter, error) {
+	if !strings.EqualFold(strings.TrimSpace(currencyName), domain.CurrencyUSD) {
+		if _, err := domain.ResolveCountryCurrencyDesc(currencyName); err != nil {
+			return nil, err
+		}
+	}
+	return &transactionExporter{service: ts, currencyName: currencyName,
+		exchangeRates: make(map[exportExchangeRateKey]*exportExchangeRate)}, nil
+}
+
+// lookUp looks up the exchange rates needed to convert the transactions of a page and not looked up yet, with a
+// bounded pool of workers. A failed lookup is kept as the answer for the rest of the export, and only a done context
+// is returned as an error.
+func (e *transactionExporter) lookUp(ctx context.Context, transactions []*domain.Transaction) error {
+	pending := make([]*exportExchangeRate, 0)
+	add := func(currencyName string, date time.Time) {
+		key := newExportExchangeRateKey(currencyName, date)
+		if _, ok := e.exchangeRates[key]; !ok {
+			lookup := &exportExchangeRate{key: key, date: date}
+			e.exchangeRates[key] = lookup
+			pending = append(pending, lookup)
+		}
 	}
+	for _, transaction := range transactions {
+		if transaction.Currency() != domain.CurrencyUSD {
+			add(transaction.Currency(), transaction.Timestamp)
+		}
+		if !strings.EqualFold(strings.TrimSpace(e.currencyName), domain.CurrencyUSD) {
+			add(e.currencyName, transaction.Timestamp)
+		}
+	}
+
+	lookups := make(chan *exportExchangeRate)
+	var wg sync.WaitGroup
+	for range min(conversionWorkers, len(pending)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for lookup := range lookups {
+				lookup.exchangeRate, lookup.err = e.service.FindExchangeRateAsOf(ctx, lookup.key.currencyName, lookup.date)
+				if lookup.err != nil {
+					log.Warn().Err(lookup.err).Str("currency_name", lookup.key.currencyName).Str("date", lookup.key.date).Msg("failed to find the exchange rate of an exported transaction")
+				}
+			}
+		}()
+	}
+	for _, lookup := range pending {
+		lookups <- lookup
+	}
+	close(lookups)
+	wg.Wait()
+
+	// A lookup interrupted by the end of the request is not an answer
+	return ctx.Err()
 }
 
-// SaveTransaction saves a transaction.
-func (ts *TransactionService) SaveTransaction(transaction domain.Transaction) error {
-	return ts.transactionRepository.SaveTransaction(transaction)
+// convert converts a transaction of an export with the exchange rates looked up for its page. A failed conversion
+// keeps its error.
+func (e *transactionExporter) convert(transaction *domain.Transaction) *domain.TransactionConversion {
+	var sourceExchangeRate, targetExchangeRate *domain.ExchangeRate
+	var err error
+	if transaction.Currency() != domain.CurrencyUSD {
+		sourceExchangeRate, err = e.exchangeRate(transaction.Currency(), transaction.Timestamp)
+	}
+	if err == nil && !strings.EqualFold(strings.TrimSpace(e.currencyName), domain.CurrencyUSD) {
+		targetExchangeRate, err = e.exchangeRate(e.currencyName, transaction.Timestamp)
+	}
+	if err != nil {
+		return &domain.TransactionConversion{CurrencyName: e.currencyName, Err: err}
+	}
+
+	conversion, err := domain.NewTransactionConversion(transaction, e.currencyName, sourceExchangeRate, targetExchangeRate)
+	if err != nil {
+		return &domain.TransactionConversion{CurrencyName: e.currencyName, Err: err}
+	}
+	return conversion
+}
+
+// exchangeRate returns the exchange rate of a currency looked up for a purchase date.
+func (e *transactionExporter) exchangeRate(currencyName string, date time.Time) (*domain.ExchangeRate, error) {
+	lookup, ok := e.exchangeRates[newExportExchangeRateKey(currencyName, date)]
+	if !ok {
+		return nil, domain.ErrExchangeRatesUnavailable
+	}
+	return lookup.exchangeRate, lookup.err
+}
+
+// RateSelectionPolicy returns the policy selecting the exchange rate applicable to a purchase.
+func (ts *TransactionService) RateSelectionPolicy() domain.RateSelectionPolicy {
+	return ts.rateSelectionPolicy
+}
+
+// GetCurrencies retrieves the currencies that transactions can be converted to.
+func (ts *TransactionService) GetCurrencies(ctx context.Context) ([]*domain.SupportedCurrency, error) {
+	return ts.exchangeRateAdapter.GetCurrencies(ctx)
 }
 
 // FindTransactionAndExchangeRateFromCurrency retrieves a transaction along with the exchange rate applicable on the
-// purchase date for a given currency name. The exchange rate is considered only if it is found within the past 6
-// months from the purchase date.
-func (ts *TransactionService) FindTransactionAndExchangeRateFromCurrency(id uuid.UUID, currencyName string) (*domain.Transaction, *domain.ExchangeRate, error) {
+// purchase date for a given currency name, as selected by the rate selection policy.
+func (ts *TransactionService) FindTransactionAndExchangeRateFromCurrency(ctx context.Context, id uuid.UUID,
+	currencyName string) (*domain.Transaction, *domain.ExchangeRate, error) {
 	log.Info().Str("transaction_id", id.String()).Str("currency_name", currencyName).Msg("retrieving transaction and exchange rates")
 
-	transaction, err := ts.transactionRepository.FindTransaction(id)
+	transaction, err := ts.transactionRepository.FindTransaction(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	exchangeRate, err := ts.FindExchangeRateAsOf(ctx, currencyName, transaction.Timestamp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transaction, exchangeRate, nil
+}
+
+// ConvertTransaction retrieves a transaction and converts it to several currencies, looking up their exchange rates
+// concurrently with a bounded pool of workers. A transaction recorded in another currency than USD is converted
+// through USD with the exchange rate of its currency, looked up once. The conversions are returned in the order of
+// the currencies, and a currency that cannot be converted keeps its error in its conversion instead of failing the
+// others. The conversions locked on the transaction are attached to the conversions of their currencies.
+func (ts *TransactionService) ConvertTransaction(ctx context.Context, id uuid.UUID,
+	currencyNames []string) (*domain.Transaction, []*domain.TransactionConversion, error) {
+	log.Info().Str("transaction_id", id.String()).Strs("currency_names", currencyNames).Msg("converting transaction to several currencies")
+
+	// Validates the currencies before looking up the transaction
+	if errs := domain.ValidateConversionCurrencies(currencyNames); len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	transaction, err := ts.transactionRepository.FindTransaction(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conversions := make([]*domain.TransactionConversion, len(currencyNames))
+
+	// None of the currencies can be converted without the exchange rate of the transaction currency
+	var sourceExchangeRate *domain.ExchangeRate
+	if transaction.Currency() != domain.CurrencyUSD {
+		sourceExchangeRate, err = ts.FindExchangeRateAsOf(ctx, transaction.Currency(), transaction.Timestamp)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, nil, ctxErr
+			}
+			log.Warn().Err(err).Str("transaction_id", id.String()).Str("currency", transaction.Currency()).Msg("failed to find the exchange rate of the transaction currency")
+			for i, currencyName := range currencyNames {
+				conversions[i] = &domain.TransactionConversion{CurrencyName: currencyName, Err: err}
+			}
+			ts.attachLockedConversions(transaction, conversions)
+			return transaction, conversions, nil
+		}
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(conversionWorkers, len(currencyNames)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				conversions[i] = ts.convertTransaction(ctx, transaction, sourceExchangeRate, currencyNames[i])
+			}
+		}()
+	}
+	for i := range currencyNames {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// The conversions are incomplete when the request is over
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	ts.attachLockedConversions(transaction, conversions)
+	return transaction, conversions, nil
+}
+
+// LockConversion converts a transaction to a currency and freezes the conversion on the transaction, with the
+// exchange rates and the policy that selected them. A conversion to a currency can only be locked once. It returns
+// the updated transaction and the locked conversion.
+func (ts *TransactionService) LockConversion(ctx context.Context, id uuid.UUID,
+	currencyName string) (*domain.Transaction, *domain.LockedConversion, error) {
+	log.Info().Str("transaction_id", id.String()).Str("currency_name", currencyName).Msg("locking the conversion of the transaction")
+
+	_, conversions, err := ts.ConvertTransaction(ctx, id, []string{currencyName})
 	if err != nil {
 		return nil, nil, err
 	}
-	exchangeRates, err := ts.exchangeRateAdapter.GetExchangeRates(currencyName)
+	conversion := conversions[0]
+	if conversion.Locked != nil {
+		return nil, nil, domain.ErrConversionAlreadyLocked
+	}
+	lockedConversion, err := domain.NewLockedConversion(conversion, ts.rateSelectionPolicy, time.Now())
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Finds the exchange rate closest to the transaction date (within the last 6 months)
-	var closestExchangeRate *domain.ExchangeRate
+	transaction, err := ts.transactionRepository.SaveLockedConversion(ctx, id, *lockedConversion)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transaction, lockedConversion, nil
+}
+
+// attachLockedConversions attaches the conversions locked on a transaction to the conversions of their currencies.
+func (ts *TransactionService) attachLockedConversions(transaction *domain.Transaction,
+	conversions []*domain.TransactionConversion) {
+	if len(transaction.LockedConversions) == 0 {
+		return
+	}
+	for _, conversion := range conversions {
+		// The requested currency may be a name, while the conversions are locked by code and country-currency
+		countryCurrencyDesc, _ := domain.ResolveCountryCurrencyDesc(conversion.CurrencyName)
+		conversion.Locked = transaction.FindLockedConversion(conversion.CurrencyName, countryCurrencyDesc)
+	}
+}
+
+// convertTransaction converts a transaction to a currency with the exchange rate applicable on the purchase date,
+// and the exchange rate of the transaction currency when it is not USD. USD needs no exchange rate as a target.
+func (ts *TransactionService) convertTransaction(ctx context.Context, transaction *domain.Transaction,
+	sourceExchangeRate *domain.ExchangeRate, currencyName string) *domain.TransactionConversion {
+	var targetExchangeRate *domain.ExchangeRate
+	if !strings.EqualFold(strings.TrimSpace(currencyName), domain.CurrencyUSD) {
+		exchangeRate, err := ts.FindExchangeRateAsOf(ctx, currencyName, transaction.Timestamp)
+		if err != nil {
+			log.Warn().Err(err).Str("transaction_id", transaction.ID.String()).Str("currency_name", currencyName).Msg("failed to convert the transaction")
+			return &domain.TransactionConversion{CurrencyName: currencyName, Err: err}
+		}
+		targetExchangeRate = exchangeRate
+	}
+
+	conversion, err := domain.NewTransactionConversion(transaction, currencyName, sourceExchangeRate, targetExchangeRate)
+	if err != nil {
+		return &domain.TransactionConversion{CurrencyName: currencyName, Err: err}
+	}
+	return conversion
+}
+
+// FindExchangeRateAsOf retrieves the exchange rate of a currency applicable on a date, as selected by the rate
+// selection policy. The exchange rates are read from the local store when it covers the whole window of the policy,
+// and downloaded from the exchange rate adapter otherwise, since a gap in the store would select another exchange
+// rate than the published one. Only the exchange rates within the window are downloaded. When the adapter is
+// unreachable, the stored exchange rates are used anyway, so conversions keep working.
+func (ts *TransactionService) FindExchangeRateAsOf(ctx context.Context, currencyName string,
+	date time.Time) (*domain.ExchangeRate, error) {
+	countryCurrencyDesc, err := domain.ResolveCountryCurrencyDesc(currencyName)
+	if err != nil {
+		return nil, err
+	}
+	windowStart, windowEnd := ts.rateSelectionPolicy.Window(date)
+
+	// Reads the exchange rates from the local store first (currency names shared by several countries cannot be
+	// resolved to a stored country-currency)
+	var storedExchangeRates []*domain.ExchangeRate
+	if countryCurrencyDesc != "" {
+		var covered bool
+		storedExchangeRates, covered = ts.findStoredExchangeRates(ctx, countryCurrencyDesc, windowStart, windowEnd)
+		if covered {
+			return ts.selectExchangeRate(storedExchangeRates, currencyName, date)
+		}
+	}
+
+	exchangeRates, err := ts.exchangeRateAdapter.GetExchangeRatesBetween(ctx, currencyName, windowStart, windowEnd)
+	if err != nil {
+		exchangeRate := ts.rateSelectionPolicy.Select(storedExchangeRates, date)
+		if exchangeRate == nil || ctx.Err() != nil {
+			return nil, err
+		}
+		log.Warn().Err(err).Str("currency", countryCurrencyDesc).Msg("selecting the exchange rate from a partial local store")
+		return exchangeRate, nil
+	}
+	// Only the Treasury exchange rates are kept, so the ones of a fallback provider never replace them in the local
+	// store nor take precedence over the configured providers on the next lookups
+	if fromTreasury(exchangeRates) {
+		ts.storeExchangeRates(ctx, currencyName, exchangeRates)
+		if countryCurrencyDesc != "" {
+			ts.storeExchangeRateCoverage(ctx, countryCurrencyDesc, exchangeRates, windowStart, windowEnd)
+		}
+	}
+
+	// The downloaded exchange rates replace the stored ones of the same date of record
+	return ts.selectExchangeRate(mergeExchangeRates(storedExchangeRates, exchangeRates), currencyName, date)
+}
+
+// findStoredExchangeRates reads the stored exchange rates of a country-currency, and reports whether the store
+// covers the days of the window already published. A failure is only logged, and reported as not covered.
+func (ts *TransactionService) findStoredExchangeRates(ctx context.Context, countryCurrencyDesc string,
+	windowStart, windowEnd time.Time) ([]*domain.ExchangeRate, bool) {
+	storedExchangeRates, err := ts.exchangeRateRepository.FindExchangeRates(ctx, countryCurrencyDesc)
+	if err != nil {
+		log.Warn().Err(err).Str("currency", countryCurrencyDesc).Msg("failed to read the stored exchange rates")
+		return nil, false
+	}
+	coverage, err := ts.exchangeRateRepository.FindExchangeRateCoverage(ctx, countryCurrencyDesc)
+	if err != nil {
+		log.Warn().Err(err).Str("currency", countryCurrencyDesc).Msg("failed to read the stored exchange rate coverage")
+		return storedExchangeRates, false
+	}
+
+	// No exchange rate is published after today yet
+	if today := time.Now().UTC(); windowEnd.After(today) {
+		windowEnd = today
+	}
+	return storedExchangeRates, coverage.Covers(windowStart, windowEnd)
+}
+
+// selectExchangeRate selects the exchange rate applicable on a date under the rate selection policy, or returns
+// domain.ErrNoApplicableExchangeRate when none applies.
+func (ts *TransactionService) selectExchangeRate(exchangeRates []*domain.ExchangeRate, currencyName string,
+	date time.Time) (*domain.ExchangeRate, error) {
+	exchangeRate := ts.rateSelectionPolicy.Select(exchangeRates, date)
+	if exchangeRate == nil {
+		return nil, fmt.Errorf("%w: none found for currency %s with the %s policy within %d months",
+			domain.ErrNoApplicableExchangeRate, currencyName, ts.rateSelectionPolicy.Name(),
+			ts.rateSelectionPolicy.LookbackMonths())
+	}
+	return exchangeRate, nil
+}
+
+// fromTreasury reports whether exchange rates were published by the Treasury, the only source kept in the local
+// store. Exchange rates without source are assumed to come from the Treasury.
+func fromTreasury(exchangeRates []*domain.ExchangeRate) bool {
 	for _, exchangeRate := range exchangeRates {
-		// Ensures the exchange rate is within the last 6 months
-		if exchangeRate.DateOfRecord.Before(transaction.Timestamp.AddDate(0, -6, 0)) {
-			continue
+		if exchangeRate.Source != "" && exchangeRate.Source != domain.ExchangeRateSourceTreasury {
+			return false
 		}
+	}
+	return true
+}
+
+// mergeExchangeRates merges downloaded exchange rates into stored ones. A downloaded exchange rate replaces the
+// stored one of the same date of record.
+func mergeExchangeRates(storedExchangeRates, downloadedExchangeRates []*domain.ExchangeRate) []*domain.ExchangeRate {
+	downloaded := make(map[time.Time]bool, len(downloadedExchangeRates))
+	for _, exchangeRate := range downloadedExchangeRates {
+		downloaded[exchangeRate.DateOfRecord] = true
+	}
 
-		// Sets the closest exchange rate to the first one or if it is closer to the transaction date
-		if closestExchangeRate == nil || exchangeRate.DateOfRecord.After(transaction.Timestamp) && exchangeRate.DateOfRecord.Before(closestExchangeRate.DateOfRecord) {
-			closestExchangeRate = exchangeRate
+	exchangeRates := slices.Clone(downloadedExchangeRates)
+	for _, exchangeRate := range storedExchangeRates {
+		if !downloaded[exchangeRate.DateOfRecord] {
+			exchangeRates = append(exchangeRates, exchangeRate)
 		}
 	}
+	return exchangeRates
+}
 
-	// Returns an error ff no exchange rate is found within the last 6 months
-	if closestExchangeRate == nil {
-		return nil, nil, fmt.Errorf("no exchange rate found within the last 6 months for currency %s", currencyName)
+// ConvertAmount converts an amount in USD to a currency with the exchange rate applicable on a date, as selected by
+// the rate selection policy. The amount and date are validated like a transaction, but no transaction is read or
+// saved. Validation errors are joined in the returned error.
+func (ts *TransactionService) ConvertAmount(ctx context.Context, amountInUSD domain.Money, currencyName string,
+	date time.Time) (*domain.Conversion, error) {
+	// Validates the inputs before looking up the exchange rate
+	if errs := domain.ValidateConversion(amountInUSD, date); len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
-	return transaction, closestExchangeRate, nil
+	exchangeRate, err := ts.FindExchangeRateAsOf(ctx, currencyName, date)
+	if err != nil {
+		return nil, err
+	}
+	conversion, errs := domain.NewConversion(amountInUSD, date, exchangeRate)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return conversion, nil
+}
+
+// FindExchangeRateHistory retrieves a page of the exchange rate history of a currency, most recent first. The
+// history is downloaded from the exchange rate adapter, and read from the local store when the adapter fails and
+// some exchange rates of the currency are stored. A currency without published exchange rates has an empty history.
+func (ts *TransactionService) FindExchangeRateHistory(ctx context.Context,
+	query domain.ExchangeRateQuery) (*domain.ExchangeRatePage, error) {
+	countryCurrencyDesc, err := domain.ResolveCountryCurrencyDesc(query.CurrencyName)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRates, err := ts.downloadExchangeRates(ctx, query.CurrencyName)
+	switch {
+	case errors.Is(err, domain.ErrExchangeRateNotFound):
+		exchangeRates = []*domain.ExchangeRate{}
+	case err != nil && countryCurrencyDesc != "" && ctx.Err() == nil:
+		log.Warn().Err(err).Str("currency", countryCurrencyDesc).Msg("reading the exchange rate history from the local store")
+		storedExchangeRates, storeErr := ts.exchangeRateRepository.FindExchangeRates(ctx, countryCurrencyDesc)
+		if storeErr != nil || len(storedExchangeRates) == 0 {
+			return nil, err
+		}
+		exchangeRates = storedExchangeRates
+	case err != nil:
+		return nil, err
+	}
+
+	return query.Paginate(exchangeRates)
+}
+
+// downloadExchangeRates retrieves the exchange rates of a currency from the exchange rate adapter, and keeps the
+// Treasury ones in the local store for the next conversions.
+func (ts *TransactionService) downloadExchangeRates(ctx context.Context, currencyName string) ([]*domain.ExchangeRate, error) {
+	exchangeRates, err := ts.exchangeRateAdapter.GetExchangeRates(ctx, currencyName)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromTreasury(exchangeRates) {
+		ts.storeExchangeRates(ctx, currencyName, exchangeRates)
+	}
+	return exchangeRates, nil
+}
+
+// storeExchangeRates keeps the downloaded exchange rates of a currency in the local store for the next conversions.
+// A failure is only logged, since the exchange rates can be downloaded again.
+func (ts *TransactionService) storeExchangeRates(ctx context.Context, currencyName string,
+	exchangeRates []*domain.ExchangeRate) {
+	if err := ts.exchangeRateRepository.SaveExchangeRates(ctx, exchangeRates); err != nil {
+		log.Warn().Err(err).Str("currency", currencyName).Msg("failed to store the downloaded exchange rates")
+	}
+}
+
+// storeExchangeRateCoverage records the days of a window whose exchange rates were all downloaded, so the next
+// lookups within them are served by the local store. The days after the latest date of record downloaded are only
+// recorded once exchangeRatePublicationDelay has passed, since their exchange rates may not be published yet. A
+// failure is only logged, since the exchange rates can be downloaded again.
+func (ts *TransactionService) storeExchangeRateCoverage(ctx context.Context, countryCurrencyDesc string,
+	exchangeRates []*domain.ExchangeRate, windowStart, windowEnd time.Time) {
+	publishedUntil := time.Now().UTC().Add(-exchangeRatePublicationDelay)
+	for _, exchangeRate := range exchangeRates {
+		if exchangeRate.DateOfRecord.After(publishedUntil) {
+			publishedUntil = exchangeRate.DateOfRecord
+		}
+	}
+
+	if windowEnd.After(publishedUntil) {
+		windowEnd = publishedUntil
+	}
+	if err := ts.exchangeRateRepository.SaveExchangeRateCoverage(ctx, countryCurrencyDesc, windowStart, windowEnd); err != nil {
+		log.Warn().Err(err).Str("currency", countryCurrencyDesc).Msg("failed to store the exchange rate coverage")
+	}
 }
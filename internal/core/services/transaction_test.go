@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/dainfoo/wex-technical-implementation-project/internal/core/services"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -22,9 +24,10 @@ import (
 // TransactionServiceIntegrationTestSuite represents the test suite.
 type TransactionServiceIntegrationTestSuite struct {
 	suite.Suite
-	transactionRepo ports.TransactionRepository
-	exchangeAdapter *client.MockTreasuryExchangeRateAdapter
-	service         *services.TransactionService
+	transactionRepo  ports.TransactionRepository
+	exchangeRateRepo ports.ExchangeRateRepository
+	exchangeAdapter  *client.MockTreasuryExchangeRateAdapter
+	service          *services.TransactionService
 }
 
 // SetupTest initializes the test suite.
@@ -33,10 +36,14 @@ func (suite *TransactionServiceIntegrationTestSuite) SetupTest() {
 	boltDBRepo, err := repository.NewTransactionRepositoryBoltDB(testDatabasePath, "transactions")
 	suite.NoError(err)
 
+	exchangeRateRepo, err := repository.NewExchangeRateRepositoryBoltDB(boltDBRepo.GetBoltDB(), "exchange_rates")
+	suite.NoError(err)
+
 	mockAdapter := new(client.MockTreasuryExchangeRateAdapter)
 
-	suite.service = services.NewTransactionService(boltDBRepo, mockAdapter)
+	suite.service = services.NewTransactionService(boltDBRepo, exchangeRateRepo, mockAdapter, nil)
 	suite.transactionRepo = boltDBRepo
+	suite.exchangeRateRepo = exchangeRateRepo
 	suite.exchangeAdapter = mockAdapter
 	// Clean up the database after the test suite finishes
 	suite.T().Cleanup(func() {
@@ -50,13 +57,25 @@ func (suite *TransactionServiceIntegrationTestSuite) SetupTest() {
 	})
 }
 
+// storeExchangeRates stores exchange rates in the local store, with their currencies covered over 2024, so they are
+// used without calling the adapter.
+func (suite *TransactionServiceIntegrationTestSuite) storeExchangeRates(exchangeRates ...*domain.ExchangeRate) {
+	suite.Require().NoError(suite.exchangeRateRepo.SaveExchangeRates(context.Background(), exchangeRates))
+	for _, exchangeRate := range exchangeRates {
+		suite.Require().NoError(suite.exchangeRateRepo.SaveExchangeRateCoverage(context.Background(),
+			exchangeRate.CountryCurrencyDesc, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)))
+	}
+}
+
 // TestFindTransactionAndExchangeRate tests the FindTransactionAndExchangeRate method of the TransactionService.
 func (suite *TransactionServiceIntegrationTestSuite) TestFindTransactionAndExchangeRate() {
 	// Expected results
-	successTransaction, err := domain.NewTransaction("giberish", time.Now(), 25.7)
+	successTransaction, err := domain.NewTransaction("giberish", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.70", domain.CurrencyUSD))
 	// Stops the test if the expected results are not as expected (probably the business logic changed)
 	require.Empty(suite.T(), err)
-	successExchangeRate, err := domain.NewExchangeRate("Real", 5.434, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	successExchangeRate, err := domain.NewExchangeRate("Real", "5.434", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
 	require.Empty(suite.T(), err)
 
 	tests := []struct {
@@ -94,13 +113,13 @@ func (suite *TransactionServiceIntegrationTestSuite) TestFindTransactionAndExcha
 		suite.Run(tt.name, func() {
 			// Tests the setup of the test case
 			if tt.setupTransaction != nil {
-				err := suite.transactionRepo.SaveTransaction(*tt.setupTransaction)
+				err := suite.transactionRepo.SaveTransaction(context.Background(), *tt.setupTransaction)
 				suite.NoError(err)
 			}
-			suite.exchangeAdapter.On("GetExchangeRates", tt.currencyName).
+			suite.exchangeAdapter.On("GetExchangeRatesBetween", tt.currencyName, mock.Anything, mock.Anything).
 				Return([]*domain.ExchangeRate{tt.mockRate}, tt.mockRateErr)
 
-			foundTransaction, exchangeRate, err := suite.service.FindTransactionAndExchangeRateFromCurrency(tt.transactionID, tt.currencyName)
+			foundTransaction, exchangeRate, err := suite.service.FindTransactionAndExchangeRateFromCurrency(context.Background(), tt.transactionID, tt.currencyName)
 
 			if tt.expectedErr != nil {
 				assert.Error(suite.T(), err)
@@ -121,6 +140,663 @@ func (suite *TransactionServiceIntegrationTestSuite) TestFindTransactionAndExcha
 	}
 }
 
+// TestFindTransactionAndExchangeRateFromLocalStore tests that the FindTransactionAndExchangeRate method of the
+// TransactionService reads the exchange rates from the local store first, and stores the downloaded ones.
+func (suite *TransactionServiceIntegrationTestSuite) TestFindTransactionAndExchangeRateFromLocalStore() {
+	transaction, errs := domain.NewTransaction("giberish", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.70", domain.CurrencyUSD))
+	require.Empty(suite.T(), errs)
+	suite.NoError(suite.transactionRepo.SaveTransaction(context.Background(), *transaction))
+
+	newExchangeRate := func(currencyName, countryCurrencyDesc, rate string) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate(currencyName, rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(suite.T(), errs)
+		exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+		return exchangeRate
+	}
+
+	suite.Run("Stored Exchange Rate Is Used Without Calling The Adapter", func() {
+		suite.storeExchangeRates(newExchangeRate("Real", "Brazil-Real", "5.434"))
+
+		_, exchangeRate, err := suite.service.FindTransactionAndExchangeRateFromCurrency(context.Background(), transaction.ID, "BRL")
+
+		suite.NoError(err)
+		suite.Equal("5.434", exchangeRate.RateText)
+		suite.exchangeAdapter.AssertNotCalled(suite.T(), "GetExchangeRatesBetween", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	suite.Run("Downloaded Exchange Rates Are Stored", func() {
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "Yen", mock.Anything, mock.Anything).
+			Return([]*domain.ExchangeRate{newExchangeRate("Yen", "Japan-Yen", "143.57")}, nil).Once()
+
+		_, exchangeRate, err := suite.service.FindTransactionAndExchangeRateFromCurrency(context.Background(), transaction.ID, "Yen")
+		suite.NoError(err)
+		suite.Equal("143.57", exchangeRate.RateText)
+
+		// The second conversion is answered from the local store
+		_, exchangeRate, err = suite.service.FindTransactionAndExchangeRateFromCurrency(context.Background(), transaction.ID, "JPY")
+		suite.NoError(err)
+		suite.Equal("143.57", exchangeRate.RateText)
+		suite.exchangeAdapter.AssertExpectations(suite.T())
+	})
+
+	suite.Run("Unknown Currency Code", func() {
+		_, _, err := suite.service.FindTransactionAndExchangeRateFromCurrency(context.Background(), transaction.ID, "XYZ")
+		suite.ErrorIs(err, domain.ErrUnknownCurrencyCode)
+	})
+}
+
+// TestFindTransactionAndExchangeRateWithPolicy tests that the FindTransactionAndExchangeRate method of the
+// TransactionService selects the exchange rate with the configured policy.
+func (suite *TransactionServiceIntegrationTestSuite) TestFindTransactionAndExchangeRateWithPolicy() {
+	transaction, errs := domain.NewTransaction("giberish", time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.70", domain.CurrencyUSD))
+	require.Empty(suite.T(), errs)
+	suite.NoError(suite.transactionRepo.SaveTransaction(context.Background(), *transaction))
+
+	newExchangeRate := func(rate string, dateOfRecord time.Time) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Dollar", rate, dateOfRecord)
+		require.Empty(suite.T(), errs)
+		return exchangeRate
+	}
+	// Currency names shared by several countries are not read from the local store
+	exchangeRates := []*domain.ExchangeRate{
+		newExchangeRate("1.37", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)),
+		newExchangeRate("1.36", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}
+
+	suite.Run("Default Policy Selects The Latest Rate On Or Before", func() {
+		// Only the exchange rates within the window of the policy are downloaded
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "Dollar", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)).Return(exchangeRates, nil).Once()
+
+		_, exchangeRate, err := suite.service.FindTransactionAndExchangeRateFromCurrency(context.Background(), transaction.ID, "Dollar")
+
+		suite.NoError(err)
+		suite.Equal("1.36", exchangeRate.RateText)
+		suite.Equal(domain.RateSelectionLatestOnOrBefore, suite.service.RateSelectionPolicy().Name())
+	})
+
+	suite.Run("First After Policy", func() {
+		policy, errs := domain.NewRateSelectionPolicy(domain.RateSelectionFirstAfter, 6)
+		require.Empty(suite.T(), errs)
+		service := services.NewTransactionService(suite.transactionRepo, suite.exchangeRateRepo, suite.exchangeAdapter, policy)
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "Dollar", time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)).Return(exchangeRates, nil).Once()
+
+		_, exchangeRate, err := service.FindTransactionAndExchangeRateFromCurrency(context.Background(), transaction.ID, "Dollar")
+
+		suite.NoError(err)
+		suite.Equal("1.37", exchangeRate.RateText)
+	})
+
+	suite.Run("No Rate Within The Lookback", func() {
+		policy, errs := domain.NewRateSelectionPolicy(domain.RateSelectionLatestOnOrBefore, 1)
+		require.Empty(suite.T(), errs)
+		service := services.NewTransactionService(suite.transactionRepo, suite.exchangeRateRepo, suite.exchangeAdapter, policy)
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "Dollar", mock.Anything, mock.Anything).Return(exchangeRates, nil).Once()
+
+		_, _, err := service.FindTransactionAndExchangeRateFromCurrency(context.Background(), transaction.ID, "Dollar")
+
+		suite.ErrorIs(err, domain.ErrNoApplicableExchangeRate)
+	})
+}
+
+// TestFindExchangeRateAsOf tests the FindExchangeRateAsOf method of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestFindExchangeRateAsOf() {
+	exchangeRate, errs := domain.NewExchangeRate("Krone", "6.58", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(suite.T(), errs)
+	exchangeRate.CountryCurrencyDesc = "Denmark-Krone"
+	suite.storeExchangeRates(exchangeRate)
+
+	suite.Run("Same Selection As The Conversions", func() {
+		found, err := suite.service.FindExchangeRateAsOf(context.Background(), "DKK", time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC))
+
+		suite.NoError(err)
+		suite.Equal("6.58", found.RateText)
+		suite.exchangeAdapter.AssertNotCalled(suite.T(), "GetExchangeRatesBetween", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	suite.Run("No Applicable Exchange Rate", func() {
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "DKK", mock.Anything, mock.Anything).Return([]*domain.ExchangeRate{exchangeRate}, nil).Once()
+
+		_, err := suite.service.FindExchangeRateAsOf(context.Background(), "DKK", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+		suite.ErrorIs(err, domain.ErrNoApplicableExchangeRate)
+		suite.exchangeAdapter.AssertExpectations(suite.T())
+	})
+
+	suite.Run("Gap In The Local Store Is Downloaded", func() {
+		newExchangeRate := func(rate string, dateOfRecord time.Time) *domain.ExchangeRate {
+			exchangeRate, errs := domain.NewExchangeRate("Krona", rate, dateOfRecord)
+			require.Empty(suite.T(), errs)
+			exchangeRate.CountryCurrencyDesc = "Sweden-Krona"
+			return exchangeRate
+		}
+		march := newExchangeRate("10.28", time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC))
+		june := newExchangeRate("10.78", time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC))
+		// Only the first quarter was downloaded before
+		suite.NoError(suite.exchangeRateRepo.SaveExchangeRates(context.Background(), []*domain.ExchangeRate{march}))
+		suite.NoError(suite.exchangeRateRepo.SaveExchangeRateCoverage(context.Background(), "Sweden-Krona",
+			time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), march.DateOfRecord))
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "SEK", time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 7, 15, 0, 0, 0, 0, time.UTC)).Return([]*domain.ExchangeRate{june}, nil).Once()
+
+		found, err := suite.service.FindExchangeRateAsOf(context.Background(), "SEK", time.Date(2023, 7, 15, 0, 0, 0, 0, time.UTC))
+
+		suite.NoError(err)
+		suite.Equal("10.78", found.RateText)
+
+		// The window is now covered, so the next lookup is answered from the local store
+		found, err = suite.service.FindExchangeRateAsOf(context.Background(), "SEK", time.Date(2023, 7, 15, 0, 0, 0, 0, time.UTC))
+
+		suite.NoError(err)
+		suite.Equal("10.78", found.RateText)
+		suite.exchangeAdapter.AssertExpectations(suite.T())
+	})
+
+	suite.Run("Stored Exchange Rate Used When The Adapter Fails", func() {
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "SEK", mock.Anything, mock.Anything).
+			Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue).Once()
+
+		// The window goes past the coverage of the local store
+		found, err := suite.service.FindExchangeRateAsOf(context.Background(), "SEK", time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC))
+
+		suite.NoError(err)
+		suite.Equal("10.78", found.RateText)
+		suite.exchangeAdapter.AssertExpectations(suite.T())
+	})
+
+	suite.Run("Exchange Rates Of A Fallback Provider Are Not Stored", func() {
+		exchangeRate, errs := domain.NewExchangeRate("Krone", "10.55", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(suite.T(), errs)
+		exchangeRate.CountryCurrencyDesc = "Norway-Krone"
+		exchangeRate.Source = domain.ExchangeRateSourceECB
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "NOK", mock.Anything, mock.Anything).
+			Return([]*domain.ExchangeRate{exchangeRate}, nil).Twice()
+
+		for range 2 {
+			found, err := suite.service.FindExchangeRateAsOf(context.Background(), "NOK", time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC))
+
+			suite.NoError(err)
+			suite.Equal(domain.ExchangeRateSourceECB, found.Source)
+		}
+		storedExchangeRates, err := suite.exchangeRateRepo.FindExchangeRates(context.Background(), "Norway-Krone")
+		suite.NoError(err)
+		suite.Empty(storedExchangeRates)
+		suite.exchangeAdapter.AssertExpectations(suite.T())
+	})
+}
+
+// TestConvertAmount tests the ConvertAmount method of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestConvertAmount() {
+	exchangeRate, errs := domain.NewExchangeRate("Peso", "19.6", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(suite.T(), errs)
+	exchangeRate.CountryCurrencyDesc = "Mexico-Peso"
+	exchangeRate.CurrencyCode = "MXN"
+	suite.storeExchangeRates(exchangeRate)
+	date := time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	suite.Run("Amount Converted With The Selected Exchange Rate", func() {
+		conversion, err := suite.service.ConvertAmount(context.Background(), domain.MustParseMoney("10.005", domain.CurrencyUSD), "MXN", date)
+
+		suite.NoError(err)
+		suite.Equal("10.01", conversion.AmountInUSD.String())
+		suite.Equal("196.20", conversion.AmountInTargetCurrency.String())
+		suite.Equal("19.6", conversion.ExchangeRate.RateText)
+	})
+
+	suite.Run("Invalid Amount Is Rejected Before The Lookup", func() {
+		_, err := suite.service.ConvertAmount(context.Background(), domain.MustParseMoney("-1", domain.CurrencyUSD), "MXN", date)
+
+		suite.ErrorIs(err, domain.ErrInvalidAmountInUSD)
+		suite.exchangeAdapter.AssertNotCalled(suite.T(), "GetExchangeRatesBetween", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestConvertTransaction tests the ConvertTransaction method of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestConvertTransaction() {
+	transaction, errs := domain.NewTransaction("multi-currency", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("10.00", domain.CurrencyUSD))
+	require.Empty(suite.T(), errs)
+	suite.NoError(suite.transactionRepo.SaveTransaction(context.Background(), *transaction))
+	exchangeRate, errs := domain.NewExchangeRate("Peso", "19.6", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(suite.T(), errs)
+	exchangeRate.CountryCurrencyDesc = "Mexico-Peso"
+	exchangeRate.CurrencyCode = "MXN"
+	suite.storeExchangeRates(exchangeRate)
+
+	suite.Run("Failures Are Reported Per Currency", func() {
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "Real", mock.Anything, mock.Anything).Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue).Once()
+
+		foundTransaction, conversions, err := suite.service.ConvertTransaction(context.Background(), transaction.ID,
+			[]string{"MXN", "XYZ", "Real"})
+
+		suite.NoError(err)
+		suite.Equal(transaction.ID, foundTransaction.ID)
+		suite.Require().Len(conversions, 3)
+		suite.NoError(conversions[0].Err)
+		suite.Equal("MXN", conversions[0].CurrencyName)
+		suite.Equal("196.00", conversions[0].AmountInTargetCurrency.String())
+		suite.ErrorIs(conversions[1].Err, domain.ErrUnknownCurrencyCode)
+		suite.Nil(conversions[1].ExchangeRate)
+		suite.ErrorIs(conversions[2].Err, client.ErrNetworkIssue)
+	})
+
+	suite.Run("Transaction Not Found", func() {
+		_, _, err := suite.service.ConvertTransaction(context.Background(), uuid.New(), []string{"MXN"})
+
+		suite.ErrorIs(err, repository.ErrTransactionNotFound)
+	})
+
+	suite.Run("No Currency", func() {
+		_, _, err := suite.service.ConvertTransaction(context.Background(), transaction.ID, nil)
+
+		suite.ErrorIs(err, domain.ErrConversionCurrenciesEmpty)
+	})
+}
+
+// TestConvertTransactionInAnotherCurrency tests the conversion of a transaction recorded in another currency than
+// USD, through USD.
+func (suite *TransactionServiceIntegrationTestSuite) TestConvertTransactionInAnotherCurrency() {
+	transaction, errs := domain.NewTransactionInCurrency("cross-currency", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("25.79", "EUR"))
+	require.Empty(suite.T(), errs)
+	suite.NoError(suite.service.SaveTransaction(context.Background(), *transaction))
+	newExchangeRate := func(currencyName, rate, countryCurrencyDesc, currencyCode string) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate(currencyName, rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(suite.T(), errs)
+		exchangeRate.CountryCurrencyDesc = countryCurrencyDesc
+		exchangeRate.CurrencyCode = currencyCode
+		return exchangeRate
+	}
+
+	suite.Run("Both Legs Use The Rates Of The Purchase Date", func() {
+		suite.storeExchangeRates(newExchangeRate("Euro", "0.897", "Euro Zone-Euro", "EUR"),
+			newExchangeRate("Peso", "19.6", "Mexico-Peso", "MXN"))
+
+		_, conversions, err := suite.service.ConvertTransaction(context.Background(), transaction.ID, []string{"MXN", "USD"})
+
+		suite.NoError(err)
+		suite.Require().Len(conversions, 2)
+		suite.Require().NoError(conversions[0].Err)
+		suite.Equal("563.53", conversions[0].AmountInTargetCurrency.String())
+		suite.Require().Len(conversions[0].Legs, 2)
+		suite.Equal("28.75", conversions[0].Legs[0].Amount.String())
+		suite.Equal("0.897", conversions[0].Legs[0].ExchangeRate.RateText)
+		suite.Equal("19.6", conversions[0].Legs[1].ExchangeRate.RateText)
+		suite.Require().NoError(conversions[1].Err)
+		suite.Equal("28.75", conversions[1].AmountInTargetCurrency.String())
+		suite.Len(conversions[1].Legs, 1)
+	})
+
+	suite.Run("Currency Without Exchange Rates Is Rejected", func() {
+		amount := domain.MustParseMoney("10.00", "SHP")
+		unsupportedTransaction := domain.Transaction{ID: uuid.New(), Description: "unsupported",
+			Timestamp: time.Now(), Amount: &amount, CurrencyCode: "SHP"}
+
+		err := suite.service.SaveTransaction(context.Background(), unsupportedTransaction)
+
+		suite.ErrorIs(err, domain.ErrUnknownCurrencyCode)
+	})
+}
+
+// TestLockConversion tests the LockConversion method of the TransactionService, and the locked conversions attached
+// to the conversions of the transaction.
+func (suite *TransactionServiceIntegrationTestSuite) TestLockConversion() {
+	transaction, errs := domain.NewTransaction("locked", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("10.00", domain.CurrencyUSD))
+	require.Empty(suite.T(), errs)
+	suite.NoError(suite.transactionRepo.SaveTransaction(context.Background(), *transaction))
+	newExchangeRate := func(rate string) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Peso", rate, time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(suite.T(), errs)
+		exchangeRate.CountryCurrencyDesc = "Mexico-Peso"
+		exchangeRate.CurrencyCode = "MXN"
+		return exchangeRate
+	}
+	suite.storeExchangeRates(newExchangeRate("19.6"))
+
+	suite.Run("Lock And Read After A Rate Revision", func() {
+		lockedTransaction, lockedConversion, err := suite.service.LockConversion(context.Background(), transaction.ID, "MXN")
+
+		suite.Require().NoError(err)
+		suite.Equal("MXN", lockedConversion.Currency)
+		suite.Equal("196.00", lockedConversion.AmountInTargetCurrency.String())
+		suite.Equal("latest_on_or_before", lockedConversion.RateSelectionPolicy)
+		suite.Len(lockedTransaction.LockedConversions, 1)
+
+		// The Treasury revises the exchange rate after the conversion was locked
+		suite.NoError(suite.exchangeRateRepo.SaveExchangeRates(context.Background(),
+			[]*domain.ExchangeRate{newExchangeRate("20.0")}))
+		_, conversions, err := suite.service.ConvertTransaction(context.Background(), transaction.ID,
+			[]string{"MXN", "Mexico-Peso"})
+
+		suite.NoError(err)
+		suite.Require().Len(conversions, 2)
+		for _, conversion := range conversions {
+			suite.Equal("200.00", conversion.AmountInTargetCurrency.String())
+			suite.Require().NotNil(conversion.Locked)
+			suite.Equal("196.00", conversion.Locked.AmountInTargetCurrency.String())
+		}
+	})
+
+	suite.Run("Conversion Locked Twice", func() {
+		_, _, err := suite.service.LockConversion(context.Background(), transaction.ID, "Mexico-Peso")
+
+		suite.ErrorIs(err, domain.ErrConversionAlreadyLocked)
+	})
+
+	suite.Run("Unknown Currency", func() {
+		_, _, err := suite.service.LockConversion(context.Background(), transaction.ID, "XYZ")
+
+		suite.ErrorIs(err, domain.ErrUnknownCurrencyCode)
+	})
+
+	suite.Run("Transaction Not Found", func() {
+		_, _, err := suite.service.LockConversion(context.Background(), uuid.New(), "MXN")
+
+		suite.ErrorIs(err, repository.ErrTransactionNotFound)
+	})
+}
+
+// TestUpdateAndDeleteTransaction tests the UpdateTransaction, DeleteTransaction and FindTransactionHistory methods
+// of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestUpdateAndDeleteTransaction() {
+	transaction, errs := domain.NewTransaction("typo", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		domain.MustParseMoney("10.00", domain.CurrencyUSD))
+	require.Empty(suite.T(), errs)
+	suite.NoError(suite.transactionRepo.SaveTransaction(context.Background(), *transaction))
+	newRevised := func(amount domain.Money) domain.Transaction {
+		revised, errs := domain.NewTransactionInCurrency("fixed", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC), amount)
+		require.Empty(suite.T(), errs)
+		return *revised
+	}
+
+	suite.Run("Update From The Current Version", func() {
+		revision, err := suite.service.UpdateTransaction(context.Background(), transaction.ID, 1,
+			newRevised(domain.MustParseMoney("12.00", "EUR")))
+
+		suite.Require().NoError(err)
+		suite.Equal(transaction.ID, revision.ID)
+		suite.Equal(2, revision.Version)
+		suite.Equal("EUR", revision.Currency())
+		foundTransaction, err := suite.service.FindTransaction(context.Background(), transaction.ID)
+		suite.Require().NoError(err)
+		suite.Equal("fixed", foundTransaction.Description)
+	})
+
+	suite.Run("Update From A Stale Version", func() {
+		_, err := suite.service.UpdateTransaction(context.Background(), transaction.ID, 1,
+			newRevised(domain.MustParseMoney("12.00", domain.CurrencyUSD)))
+
+		suite.ErrorIs(err, domain.ErrTransactionVersionMismatch)
+	})
+
+	suite.Run("Delete From A Stale Version", func() {
+		err := suite.service.DeleteTransaction(context.Background(), transaction.ID, 1)
+
+		suite.ErrorIs(err, domain.ErrTransactionVersionMismatch)
+	})
+
+	suite.Run("Delete Keeps The History", func() {
+		suite.Require().NoError(suite.service.DeleteTransaction(context.Background(), transaction.ID, 2))
+
+		_, err := suite.service.FindTransaction(context.Background(), transaction.ID)
+		suite.ErrorIs(err, repository.ErrTransactionNotFound)
+		err = suite.service.DeleteTransaction(context.Background(), transaction.ID, 3)
+		suite.ErrorIs(err, repository.ErrTransactionNotFound)
+		revisions, err := suite.service.FindTransactionHistory(context.Background(), transaction.ID)
+		suite.Require().NoError(err)
+		suite.Require().Len(revisions, 3)
+		suite.Equal("typo", revisions[0].Description)
+		suite.Equal("fixed", revisions[1].Description)
+		suite.True(revisions[2].Deleted())
+	})
+}
+
+// TestImportTransactions tests the ImportTransactions method of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestImportTransactions() {
+	newRows := func() []*domain.TransactionImportRow {
+		transaction, errs := domain.NewTransaction("Imported", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+			domain.MustParseMoney("10.00", domain.CurrencyUSD))
+		require.Empty(suite.T(), errs)
+		transactionInCurrency, errs := domain.NewTransactionInCurrency("Imported", time.Date(2024, 10, 1, 0, 0, 0, 0,
+			time.UTC), domain.MustParseMoney("12.00", "EUR"))
+		require.Empty(suite.T(), errs)
+		return []*domain.TransactionImportRow{
+			{Line: 2, Transaction: transaction},
+			{Line: 3, Errs: []error{domain.ErrDescriptionEmpty}},
+			{Line: 4, Transaction: transactionInCurrency},
+		}
+	}
+
+	suite.Run("All Or Nothing With An Invalid Row", func() {
+		rows := newRows()
+
+		err := suite.service.ImportTransactions(context.Background(), rows, true)
+
+		suite.ErrorIs(err, domain.ErrTransactionImportRejected)
+		for _, row := range rows {
+			suite.False(row.Accepted())
+		}
+		suite.ErrorIs(rows[0].Errs[0], domain.ErrTransactionImportNotSaved)
+		_, err = suite.service.FindTransaction(context.Background(), rows[0].Transaction.ID)
+		suite.ErrorIs(err, repository.ErrTransactionNotFound)
+	})
+
+	suite.Run("Valid Rows Saved Despite Invalid Rows", func() {
+		rows := newRows()
+
+		suite.Require().NoError(suite.service.ImportTransactions(context.Background(), rows, false))
+
+		suite.True(rows[0].Accepted())
+		suite.False(rows[1].Accepted())
+		suite.True(rows[2].Accepted())
+		foundTransaction, err := suite.service.FindTransaction(context.Background(), rows[2].Transaction.ID)
+		suite.Require().NoError(err)
+		suite.Equal("EUR", foundTransaction.Currency())
+	})
+
+	suite.Run("All Or Nothing With Valid Rows", func() {
+		rows := newRows()
+		rows = append(rows[:1], rows[2:]...)
+
+		suite.Require().NoError(suite.service.ImportTransactions(context.Background(), rows, true))
+
+		for _, row := range rows {
+			suite.True(row.Accepted())
+			_, err := suite.service.FindTransaction(context.Background(), row.Transaction.ID)
+			suite.NoError(err)
+		}
+	})
+
+	suite.Run("Canceled Context Rejects The Rows", func() {
+		rows := newRows()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		suite.Require().NoError(suite.service.ImportTransactions(ctx, rows, false))
+
+		suite.ErrorIs(rows[0].Errs[0], context.Canceled)
+		suite.ErrorIs(rows[2].Errs[0], context.Canceled)
+		suite.Len(rows[0].Errs, 1)
+		_, err := suite.service.FindTransaction(context.Background(), rows[0].Transaction.ID)
+		suite.ErrorIs(err, repository.ErrTransactionNotFound)
+	})
+
+	suite.Run("Canceled Context Rejects Each Row Of Several Batches Once", func() {
+		rows := make([]*domain.TransactionImportRow, 1200)
+		for i := range rows {
+			transaction, errs := domain.NewTransaction("Imported", time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+				domain.MustParseMoney("10.00", domain.CurrencyUSD))
+			require.Empty(suite.T(), errs)
+			rows[i] = &domain.TransactionImportRow{Line: i + 2, Transaction: transaction}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		suite.Require().NoError(suite.service.ImportTransactions(ctx, rows, false))
+
+		for _, row := range rows {
+			suite.Require().Len(row.Errs, 1)
+			suite.ErrorIs(row.Errs[0], context.Canceled)
+		}
+	})
+}
+
+// TestExportTransactions tests the ExportTransactions method of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestExportTransactions() {
+	newTransaction := func(day, hour int, amount domain.Money) *domain.Transaction {
+		transaction, errs := domain.NewTransactionInCurrency("exported", time.Date(2024, 10, day, hour, 0, 0, 0, time.UTC),
+			amount)
+		require.Empty(suite.T(), errs)
+		suite.NoError(suite.service.SaveTransaction(context.Background(), *transaction))
+		return transaction
+	}
+	transactions := []*domain.Transaction{
+		newTransaction(1, 9, domain.MustParseMoney("10.00", domain.CurrencyUSD)),
+		newTransaction(1, 12, domain.MustParseMoney("25.79", "EUR")),
+		newTransaction(2, 9, domain.MustParseMoney("5.00", domain.CurrencyUSD)),
+	}
+	exchangeRates := make([]*domain.ExchangeRate, 0, 2)
+	for _, values := range [][]string{{"Euro", "0.897", "Euro Zone-Euro", "EUR"}, {"Peso", "19.6", "Mexico-Peso", "MXN"}} {
+		exchangeRate, errs := domain.NewExchangeRate(values[0], values[1], time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+		require.Empty(suite.T(), errs)
+		exchangeRate.CountryCurrencyDesc = values[2]
+		exchangeRate.CurrencyCode = values[3]
+		exchangeRates = append(exchangeRates, exchangeRate)
+	}
+	suite.storeExchangeRates(exchangeRates...)
+
+	// export collects the exported transactions and their conversions
+	export := func(currencyName string, query domain.TransactionQuery) ([]*domain.Transaction,
+		[]*domain.TransactionConversion, error) {
+		exported := make([]*domain.Transaction, 0)
+		conversions := make([]*domain.TransactionConversion, 0)
+		err := suite.service.ExportTransactions(context.Background(), query, currencyName,
+			func(transaction *domain.Transaction, conversion *domain.TransactionConversion) error {
+				exported = append(exported, transaction)
+				conversions = append(conversions, conversion)
+				return nil
+			})
+		return exported, conversions, err
+	}
+
+	suite.Run("Without Target Currency", func() {
+		exported, conversions, err := export("", domain.TransactionQuery{})
+
+		suite.Require().NoError(err)
+		suite.Require().Len(exported, 3)
+		suite.Equal(transactions[2].ID, exported[2].ID)
+		suite.Equal([]*domain.TransactionConversion{nil, nil, nil}, conversions)
+	})
+
+	suite.Run("Date Filters", func() {
+		from := time.Date(2024, 10, 2, 0, 0, 0, 0, time.UTC)
+
+		exported, _, err := export("", domain.TransactionQuery{From: &from})
+
+		suite.Require().NoError(err)
+		suite.Require().Len(exported, 1)
+		suite.Equal(transactions[2].ID, exported[0].ID)
+	})
+
+	suite.Run("Converted To The Target Currency", func() {
+		_, conversions, err := export("MXN", domain.TransactionQuery{})
+
+		suite.Require().NoError(err)
+		suite.Require().Len(conversions, 3)
+		for _, conversion := range conversions {
+			suite.Require().NoError(conversion.Err)
+			suite.Equal("MXN", conversion.AmountInTargetCurrency.Currency())
+		}
+		suite.Equal("563.53", conversions[1].AmountInTargetCurrency.String())
+	})
+
+	suite.Run("Failed Lookups Are Not Repeated", func() {
+		suite.exchangeAdapter.On("GetExchangeRatesBetween", "JPY", mock.Anything, mock.Anything).
+			Return([]*domain.ExchangeRate(nil), domain.ErrExchangeRatesUnavailable)
+
+		_, conversions, err := export("JPY", domain.TransactionQuery{})
+
+		suite.Require().NoError(err)
+		suite.Require().Len(conversions, 3)
+		for _, conversion := range conversions {
+			suite.ErrorIs(conversion.Err, domain.ErrExchangeRatesUnavailable)
+		}
+		// The exchange rate is looked up once per purchase date
+		suite.exchangeAdapter.AssertNumberOfCalls(suite.T(), "GetExchangeRatesBetween", 2)
+	})
+
+	suite.Run("Unknown Target Currency", func() {
+		_, _, err := export("XYZ", domain.TransactionQuery{})
+
+		suite.ErrorIs(err, domain.ErrUnknownCurrencyCode)
+	})
+}
+
+// TestFindExchangeRateHistory tests the FindExchangeRateHistory method of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestFindExchangeRateHistory() {
+	newExchangeRate := func(rate string, dateOfRecord time.Time) *domain.ExchangeRate {
+		exchangeRate, errs := domain.NewExchangeRate("Franc", rate, dateOfRecord)
+		require.Empty(suite.T(), errs)
+		exchangeRate.CountryCurrencyDesc = "Switzerland-Franc"
+		return exchangeRate
+	}
+	exchangeRates := []*domain.ExchangeRate{
+		newExchangeRate("0.845", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC)),
+		newExchangeRate("0.899", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)),
+	}
+	query, errs := domain.NewExchangeRateQuery("CHF", nil, nil, "", 1)
+	require.Empty(suite.T(), errs)
+
+	suite.Run("Downloaded History Is Paginated", func() {
+		suite.exchangeAdapter.On("GetExchangeRates", "CHF").Return(exchangeRates, nil).Once()
+
+		page, err := suite.service.FindExchangeRateHistory(context.Background(), *query)
+
+		suite.NoError(err)
+		suite.Require().Len(page.ExchangeRates, 1)
+		suite.Equal("0.845", page.ExchangeRates[0].RateText)
+		suite.NotEmpty(page.NextCursor)
+	})
+
+	suite.Run("Stored History Is Used When The Adapter Fails", func() {
+		suite.exchangeAdapter.On("GetExchangeRates", "CHF").Return([]*domain.ExchangeRate(nil), client.ErrNetworkIssue).Once()
+
+		page, err := suite.service.FindExchangeRateHistory(context.Background(), *query)
+
+		suite.NoError(err)
+		suite.Require().Len(page.ExchangeRates, 1)
+		suite.Equal("0.845", page.ExchangeRates[0].RateText)
+	})
+
+	suite.Run("Currency Without Exchange Rates", func() {
+		suite.exchangeAdapter.On("GetExchangeRates", "Drachma").Return([]*domain.ExchangeRate(nil), client.ErrExchangeRateNotFound).Once()
+		emptyQuery, errs := domain.NewExchangeRateQuery("Drachma", nil, nil, "", 10)
+		require.Empty(suite.T(), errs)
+
+		page, err := suite.service.FindExchangeRateHistory(context.Background(), *emptyQuery)
+
+		suite.NoError(err)
+		suite.Empty(page.ExchangeRates)
+	})
+}
+
+// TestGetCurrencies tests the GetCurrencies method of the TransactionService.
+func (suite *TransactionServiceIntegrationTestSuite) TestGetCurrencies() {
+	supportedCurrency, errs := domain.NewSupportedCurrency("Brazil-Real", "Real", "BRL", time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC))
+	require.Empty(suite.T(), errs)
+	suite.exchangeAdapter.On("GetCurrencies").Return([]*domain.SupportedCurrency{supportedCurrency}, nil)
+
+	currencies, err := suite.service.GetCurrencies(context.Background())
+
+	suite.NoError(err)
+	suite.Equal([]*domain.SupportedCurrency{supportedCurrency}, currencies)
+	suite.exchangeAdapter.AssertExpectations(suite.T())
+}
+
 // TestTransactionServiceIntegrationTestSuite initializes the test suite.
 func TestTransactionServiceIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(TransactionServiceIntegrationTestSuite))